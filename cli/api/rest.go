@@ -4,6 +4,9 @@ package api
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -13,6 +16,55 @@ import (
 
 const HTTPTimeout = time.Second * 90
 
+// tlsConfig is used by InvokeRESTAPI for HTTPS requests, if set by SetTLSConfig.
+var tlsConfig *tls.Config
+
+// SetTLSConfig configures the TLS settings of the HTTP client used by InvokeRESTAPI.  The server
+// certificate is verified against the CA certificate in caCertFile, if specified, and the client
+// certificate and key in certFile and keyFile, if specified, are presented to the server.  An error
+// is returned if a file can't be read or doesn't contain a valid certificate or key.  If no files are
+// specified, the default TLS settings are restored.
+func SetTLSConfig(caCertFile, certFile, keyFile string) error {
+
+	if caCertFile == "" && certFile == "" && keyFile == "" {
+		tlsConfig = nil
+		return nil
+	}
+
+	config := &tls.Config{}
+
+	if caCertFile != "" {
+		caCert, err := ioutil.ReadFile(caCertFile)
+		if err != nil {
+			return fmt.Errorf("could not read CA certificate file: %v", err)
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return fmt.Errorf("no certificates found in CA certificate file %s", caCertFile)
+		}
+		config.RootCAs = caCertPool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return errors.New("a client certificate and key must be specified together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("could not load client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	tlsConfig = config
+	return nil
+}
+
+// TLSConfigured reports whether SetTLSConfig has configured any TLS settings.
+func TLSConfigured() bool {
+	return tlsConfig != nil
+}
+
 func InvokeRESTAPI(method string, url string, requestBody []byte, debug bool) (*http.Response, []byte, error) {
 
 	var request *http.Request
@@ -34,6 +86,12 @@ func InvokeRESTAPI(method string, url string, requestBody []byte, debug bool) (*
 	}
 
 	client := &http.Client{Timeout: HTTPTimeout}
+	if tlsConfig != nil {
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+	}
 	response, err := client.Do(request)
 
 	responseBody := []byte{}
@@ -0,0 +1,149 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/netapp/trident/utils"
+)
+
+// writeFile writes data to a file in dir, decoding it first if it is base64 encoded.
+func writeFile(t *testing.T, dir, name string, data []byte, encoded bool) string {
+
+	if encoded {
+		decoded, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = decoded
+	}
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInvokeRESTAPIWithTLS(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tridentctl-certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer SetTLSConfig("", "", "")
+
+	// The client certificate is signed by a custom CA, which the server requires
+	certInfo, err := utils.MakeHTTPCertInfo("trident-ca", "trident-csi", "trident-client")
+	if err != nil {
+		t.Fatalf("Could not create certificates: %v", err)
+	}
+	clientCAFile := writeFile(t, dir, "clientCA", []byte(certInfo.CACert), true)
+	clientCertFile := writeFile(t, dir, "clientCert", []byte(certInfo.ClientCert), true)
+	clientKeyFile := writeFile(t, dir, "clientKey", []byte(certInfo.ClientKey), true)
+
+	clientCA, err := ioutil.ReadFile(clientCAFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAPool := x509.NewCertPool()
+	clientCAPool.AppendCertsFromPEM(clientCA)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version":"19.07.0"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAPool}
+	server.StartTLS()
+	defer server.Close()
+
+	// The server certificate is self-signed, so it serves as its own CA
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	serverCAFile := writeFile(t, dir, "serverCA", serverCA, false)
+
+	// Without the CA, the server certificate can't be verified
+	if _, _, err = InvokeRESTAPI("GET", server.URL, nil, false); err == nil {
+		t.Error("Expected an error verifying the server certificate without its CA")
+	}
+
+	// Without a client certificate, the server rejects the connection
+	if err = SetTLSConfig(serverCAFile, "", ""); err != nil {
+		t.Fatalf("Unexpected error configuring TLS: %v", err)
+	}
+	if _, _, err = InvokeRESTAPI("GET", server.URL, nil, false); err == nil {
+		t.Error("Expected an error connecting without a client certificate")
+	}
+
+	if err = SetTLSConfig(serverCAFile, clientCertFile, clientKeyFile); err != nil {
+		t.Fatalf("Unexpected error configuring TLS: %v", err)
+	}
+	response, responseBody, err := InvokeRESTAPI("GET", server.URL, nil, false)
+	if err != nil {
+		t.Fatalf("Unexpected error invoking the HTTPS REST API: %v", err)
+	}
+	if response.StatusCode != http.StatusOK || string(responseBody) != `{"version":"19.07.0"}` {
+		t.Errorf("Unexpected response %s: %s", response.Status, string(responseBody))
+	}
+}
+
+func TestSetTLSConfigValidation(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tridentctl-certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer SetTLSConfig("", "", "")
+
+	certInfo, err := utils.MakeHTTPCertInfo("trident-ca", "trident-csi", "trident-client")
+	if err != nil {
+		t.Fatalf("Could not create certificates: %v", err)
+	}
+	caFile := writeFile(t, dir, "caCert", []byte(certInfo.CACert), true)
+	certFile := writeFile(t, dir, "clientCert", []byte(certInfo.ClientCert), true)
+	keyFile := writeFile(t, dir, "clientKey", []byte(certInfo.ClientKey), true)
+	invalidFile := writeFile(t, dir, "invalid", []byte("invalid"), false)
+	missingFile := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name       string
+		caCertFile string
+		certFile   string
+		keyFile    string
+		valid      bool
+	}{
+		{"none", "", "", "", true},
+		{"CA only", caFile, "", "", true},
+		{"client only", "", certFile, keyFile, true},
+		{"all", caFile, certFile, keyFile, true},
+		{"missing CA", missingFile, "", "", false},
+		{"invalid CA", invalidFile, "", "", false},
+		{"cert without key", caFile, certFile, "", false},
+		{"key without cert", caFile, "", keyFile, false},
+		{"missing cert", caFile, missingFile, keyFile, false},
+		{"invalid key", caFile, certFile, invalidFile, false},
+		{"mismatched pair", caFile, certFile, writeFile(t, dir, "serverKey", []byte(certInfo.ServerKey), true), false},
+	}
+
+	for _, test := range tests {
+		err := SetTLSConfig(test.caCertFile, test.certFile, test.keyFile)
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+		if test.valid && TLSConfigured() != (test.name != "none") {
+			t.Errorf("%s: unexpected TLS configured state %v", test.name, TLSConfigured())
+		}
+	}
+}
@@ -2,6 +2,7 @@
 
 package api
 
+import persistentstore "github.com/netapp/trident/persistent_store"
 import "github.com/netapp/trident/storage"
 import "github.com/netapp/trident/utils"
 
@@ -18,6 +19,15 @@ type MultipleBackendResponse struct {
 	Items []storage.BackendExternal `json:"items"`
 }
 
+type BackendPools struct {
+	Backend string                          `json:"backend"`
+	Pools   []*storage.PoolCapacityExternal `json:"pools"`
+}
+
+type MultipleBackendPoolsResponse struct {
+	Items []BackendPools `json:"items"`
+}
+
 type StorageClass struct {
 	Config struct {
 		Version         string              `json:"version"`
@@ -42,6 +52,10 @@ type MultipleVolumeResponse struct {
 	Items []storage.VolumeExternal `json:"items"`
 }
 
+type MultipleVolumeEventResponse struct {
+	Items []*storage.VolumeEvent `json:"items"`
+}
+
 type MultipleNodeResponse struct {
 	Items []utils.Node `json:"items"`
 }
@@ -50,6 +64,10 @@ type MultipleSnapshotResponse struct {
 	Items []storage.SnapshotExternal `json:"items"`
 }
 
+type MultipleTransactionResponse struct {
+	Items []*persistentstore.VolumeTransaction `json:"items"`
+}
+
 type Version struct {
 	Version       string `json:"version"`
 	MajorVersion  uint   `json:"majorVersion"`
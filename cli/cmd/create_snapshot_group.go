@@ -0,0 +1,125 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+)
+
+var snapshotGroupVolumes []string
+
+func init() {
+	createCmd.AddCommand(createSnapshotGroupCmd)
+	createSnapshotGroupCmd.Flags().StringSliceVar(&snapshotGroupVolumes, "volumes", []string{},
+		"Volumes to snapshot together, as a comma-separated list")
+}
+
+var createSnapshotGroupCmd = &cobra.Command{
+	Use:     "snapshot-group <name> --volumes <volume>,<volume>[,...]",
+	Short:   "Snapshot a group of volumes together",
+	Aliases: []string{"sg", "snapshotgroup"},
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		groupConfig, err := getSnapshotGroupConfig(args[0], snapshotGroupVolumes)
+		if err != nil {
+			return err
+		}
+
+		if OperatingMode == ModeTunnel {
+			command := []string{"create", "snapshot-group", "--volumes", strings.Join(snapshotGroupVolumes, ",")}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return snapshotGroupCreate(groupConfig)
+		}
+	},
+}
+
+// getSnapshotGroupConfig builds the request to snapshot a group of volumes.
+func getSnapshotGroupConfig(groupName string, volumes []string) (*storage.SnapshotGroupConfig, error) {
+
+	if len(volumes) == 0 {
+		return nil, errors.New("no volumes were specified")
+	}
+
+	seen := make(map[string]bool)
+	for _, volume := range volumes {
+		if volume == "" {
+			return nil, errors.New("volume names may not be empty")
+		} else if seen[volume] {
+			return nil, fmt.Errorf("volume %s is listed more than once", volume)
+		}
+		seen[volume] = true
+	}
+
+	return &storage.SnapshotGroupConfig{
+		Name:    groupName,
+		Volumes: volumes,
+	}, nil
+}
+
+func snapshotGroupCreate(groupConfig *storage.SnapshotGroupConfig) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := CreateSnapshotGroup(baseURL, groupConfig)
+	if err != nil {
+		return err
+	}
+
+	WriteSnapshots(snapshots)
+
+	return nil
+}
+
+func CreateSnapshotGroup(
+	baseURL string, groupConfig *storage.SnapshotGroupConfig,
+) ([]storage.SnapshotExternal, error) {
+
+	requestBytes, err := json.Marshal(groupConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request to Trident
+	url := baseURL + "/snapshot/group"
+
+	response, responseBody, err := api.InvokeRESTAPI("POST", url, requestBytes, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("could not create snapshot group %s: %v", groupConfig.Name,
+			GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var addResponse rest.AddSnapshotGroupResponse
+	if err = json.Unmarshal(responseBody, &addResponse); err != nil {
+		return nil, err
+	}
+
+	// Retrieve the newly created snapshots
+	snapshots := make([]storage.SnapshotExternal, 0, len(addResponse.SnapshotIDs))
+	for _, snapshotID := range addResponse.SnapshotIDs {
+		snapshot, err := GetSnapshot(baseURL, snapshotID)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
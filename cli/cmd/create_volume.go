@@ -0,0 +1,113 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+)
+
+var (
+	createFromSnapshot string
+	createVolumeSize   string
+)
+
+func init() {
+	createCmd.AddCommand(createVolumeCmd)
+	createVolumeCmd.Flags().StringVar(&createFromSnapshot, "from-snapshot", "",
+		"Snapshot to restore from, as <volume>/<snapshot>")
+	createVolumeCmd.Flags().StringVar(&createVolumeSize, "size", "", "Size of the new volume")
+}
+
+var createVolumeCmd = &cobra.Command{
+	Use:     "volume <name>",
+	Short:   "Create a volume from a snapshot",
+	Aliases: []string{"v"},
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		volumeConfig, err := getCloneVolumeConfig(args[0], createFromSnapshot, createVolumeSize)
+		if err != nil {
+			return err
+		}
+
+		if OperatingMode == ModeTunnel {
+			command := []string{"create", "volume", "--from-snapshot", createFromSnapshot, "--size", createVolumeSize}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return volumeCreate(volumeConfig)
+		}
+	},
+}
+
+// getCloneVolumeConfig builds the request to create a volume from an existing snapshot.
+func getCloneVolumeConfig(volumeName, snapshotID, size string) (*storage.VolumeConfig, error) {
+
+	if snapshotID == "" {
+		return nil, errors.New("no source snapshot was specified")
+	}
+	if size == "" {
+		return nil, errors.New("no volume size was specified")
+	}
+
+	sourceVolume, sourceSnapshot, err := storage.ParseSnapshotID(snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot ID %s, expected <volume>/<snapshot>", snapshotID)
+	}
+
+	return &storage.VolumeConfig{
+		Version:             config.OrchestratorAPIVersion,
+		Name:                volumeName,
+		Size:                size,
+		CloneSourceVolume:   sourceVolume,
+		CloneSourceSnapshot: sourceSnapshot,
+	}, nil
+}
+
+func volumeCreate(volumeConfig *storage.VolumeConfig) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	volume, err := CreateVolume(baseURL, volumeConfig)
+	if err != nil {
+		return err
+	}
+
+	WriteVolumes([]storage.VolumeExternal{volume})
+
+	return nil
+}
+
+func CreateVolume(baseURL string, volumeConfig *storage.VolumeConfig) (storage.VolumeExternal, error) {
+
+	requestBytes, err := json.Marshal(volumeConfig)
+	if err != nil {
+		return storage.VolumeExternal{}, err
+	}
+
+	// Send the request to Trident
+	url := baseURL + "/volume"
+
+	response, responseBody, err := api.InvokeRESTAPI("POST", url, requestBytes, Debug)
+	if err != nil {
+		return storage.VolumeExternal{}, err
+	} else if response.StatusCode != http.StatusCreated {
+		return storage.VolumeExternal{}, fmt.Errorf("could not create volume %s: %v", volumeConfig.Name,
+			GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	// Retrieve the newly created volume
+	return GetVolume(baseURL, volumeConfig.Name)
+}
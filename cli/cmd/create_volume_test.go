@@ -0,0 +1,78 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+)
+
+func TestGetCloneVolumeConfig(t *testing.T) {
+
+	volumeConfig, err := getCloneVolumeConfig("restored", "vol1/snap1", "1Gi")
+	if err != nil {
+		t.Fatalf("Unexpected error building clone request: %v", err)
+	}
+	if volumeConfig.CloneSourceVolume != "vol1" || volumeConfig.CloneSourceSnapshot != "snap1" {
+		t.Errorf("Expected clone source vol1/snap1, got %s/%s",
+			volumeConfig.CloneSourceVolume, volumeConfig.CloneSourceSnapshot)
+	}
+	if volumeConfig.Name != "restored" || volumeConfig.Size != "1Gi" {
+		t.Errorf("Expected volume restored of size 1Gi, got %s of size %s", volumeConfig.Name, volumeConfig.Size)
+	}
+
+	for _, snapshotID := range []string{"", "vol1", "vol1/", "/snap1", "vol1/snap1/extra"} {
+		if _, err := getCloneVolumeConfig("restored", snapshotID, "1Gi"); err == nil {
+			t.Errorf("Expected error for snapshot ID %q", snapshotID)
+		}
+	}
+
+	if _, err := getCloneVolumeConfig("restored", "vol1/snap1", ""); err == nil {
+		t.Error("Expected error for missing size")
+	}
+}
+
+func TestCreateVolume(t *testing.T) {
+
+	var request storage.VolumeConfig
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/volume":
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &request)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(rest.AddVolumeResponse{BackendID: "backend1"})
+		case r.Method == "GET" && r.URL.Path == "/volume/restored":
+			json.NewEncoder(w).Encode(rest.GetVolumeResponse{
+				Volume: &storage.VolumeExternal{Config: &request},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	volumeConfig, err := getCloneVolumeConfig("restored", "vol1/snap1", "1Gi")
+	if err != nil {
+		t.Fatalf("Unexpected error building clone request: %v", err)
+	}
+
+	volume, err := CreateVolume(server.URL, volumeConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error creating volume: %v", err)
+	}
+	if request.CloneSourceVolume != "vol1" || request.CloneSourceSnapshot != "snap1" {
+		t.Errorf("Expected clone source vol1/snap1 in request, got %s/%s",
+			request.CloneSourceVolume, request.CloneSourceSnapshot)
+	}
+	if volume.Config == nil || volume.Config.Name != "restored" {
+		t.Errorf("Expected volume restored to be returned, got %v", volume.Config)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+)
+
+func init() {
+	deleteCmd.AddCommand(deleteNodeCmd)
+}
+
+var deleteNodeCmd = &cobra.Command{
+	Use:     "node <name> [<name>...]",
+	Short:   "Remove one or more decommissioned nodes from Trident",
+	Long:    "Remove one or more decommissioned nodes from Trident, revoking their access to all backends",
+	Aliases: []string{"n", "nodes"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"delete", "node"}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return nodeDelete(args)
+		}
+	},
+}
+
+func nodeDelete(nodeNames []string) error {
+
+	if len(nodeNames) == 0 {
+		return errors.New("node name not specified")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	for _, nodeName := range nodeNames {
+		url := baseURL + "/node/" + nodeName + "?" + rest.CleanupQueryParameter + "=true"
+
+		response, responseBody, err := api.InvokeRESTAPI("DELETE", url, nil, Debug)
+		if err != nil {
+			return err
+		} else if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("could not delete node %s: %v", nodeName,
+				GetErrorFromHTTPResponse(response, responseBody))
+		}
+	}
+
+	return nil
+}
@@ -15,12 +15,15 @@ import (
 var (
 	allSnapshots         bool
 	allSnapshotsInVolume string
+	forceSnapshots       bool
 )
 
 func init() {
 	deleteCmd.AddCommand(deleteSnapshotCmd)
 	deleteSnapshotCmd.Flags().BoolVar(&allSnapshots, "all", false, "Delete all snapshots")
 	deleteSnapshotCmd.Flags().StringVar(&allSnapshotsInVolume, "volume", "", "Delete all snapshots in volume")
+	deleteSnapshotCmd.Flags().BoolVar(&forceSnapshots, "force", false,
+		"Delete snapshots even if volumes were cloned from them")
 }
 
 var deleteSnapshotCmd = &cobra.Command{
@@ -36,6 +39,9 @@ var deleteSnapshotCmd = &cobra.Command{
 			if allSnapshots {
 				command = append(command, "--all")
 			}
+			if forceSnapshots {
+				command = append(command, "--force")
+			}
 			TunnelCommand(append(command, args...))
 			return nil
 		} else {
@@ -83,6 +89,9 @@ func snapshotDelete(snapshotIDs []string) error {
 
 	for _, snapshotID := range snapshotIDs {
 		url := baseURL + "/snapshot/" + snapshotID
+		if forceSnapshots {
+			url += "?force=true"
+		}
 
 		response, responseBody, err := api.InvokeRESTAPI("DELETE", url, nil, Debug)
 		if err != nil {
@@ -6,16 +6,22 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
 
 	"github.com/netapp/trident/cli/api"
 	"github.com/spf13/cobra"
 )
 
-var AllVolumes bool
+var (
+	AllVolumes   bool
+	forceVolumes bool
+)
 
 func init() {
 	deleteCmd.AddCommand(deleteVolumeCmd)
 	deleteVolumeCmd.Flags().BoolVarP(&AllVolumes, "all", "", false, "Delete all volumes")
+	deleteVolumeCmd.Flags().BoolVar(&forceVolumes, "force", false,
+		"Remove volumes from Trident even if the storage system fails to delete them")
 }
 
 var deleteVolumeCmd = &cobra.Command{
@@ -28,6 +34,9 @@ var deleteVolumeCmd = &cobra.Command{
 			if AllVolumes {
 				command = append(command, "--all")
 			}
+			if forceVolumes {
+				command = append(command, "--force")
+			}
 			TunnelCommand(append(command, args...))
 			return nil
 		} else {
@@ -63,6 +72,9 @@ func volumeDelete(volumeNames []string) error {
 
 	for _, volumeName := range volumeNames {
 		url := baseURL + "/volume/" + volumeName
+		if forceVolumes {
+			url += "?force=true"
+		}
 
 		response, responseBody, err := api.InvokeRESTAPI("DELETE", url, nil, Debug)
 		if err != nil {
@@ -71,6 +83,11 @@ func volumeDelete(volumeNames []string) error {
 			return fmt.Errorf("could not delete volume %s: %v", volumeName,
 				GetErrorFromHTTPResponse(response, responseBody))
 		}
+
+		if forceVolumes {
+			fmt.Fprintf(os.Stderr, "Volume %s was removed from Trident and may remain on its storage system.\n",
+				volumeName)
+		}
 	}
 
 	return nil
@@ -0,0 +1,90 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+)
+
+var exportStateFilename string
+
+func init() {
+	exportCmd.AddCommand(exportStateCmd)
+	exportStateCmd.Flags().StringVarP(&exportStateFilename, "filename", "f", "",
+		"Path to the JSON file to write (default is standard output)")
+}
+
+var exportStateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Export all of Trident's persistent state",
+	Long: `Export all of Trident's persistent state
+
+Writes every backend, storage class, volume, snapshot, node, and volume transaction
+known to Trident to a single versioned JSON document, which may be restored into
+a fresh Trident installation with 'tridentctl import state'.  The document contains
+backend credentials, so it should be stored securely.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		var stateJSON []byte
+		var err error
+
+		if OperatingMode == ModeTunnel {
+			stateJSON, err = TunnelCommandRaw([]string{"export", "state"})
+			if err != nil {
+				if stateJSON != nil {
+					return fmt.Errorf("%v; %s", err, string(stateJSON))
+				}
+				return err
+			}
+		} else {
+			if stateJSON, err = getState(); err != nil {
+				return err
+			}
+		}
+
+		if exportStateFilename == "" {
+			_, err = os.Stdout.Write(stateJSON)
+			return err
+		}
+		return ioutil.WriteFile(exportStateFilename, stateJSON, 0600)
+	},
+}
+
+func getState() ([]byte, error) {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return nil, err
+	}
+
+	url := baseURL + "/state"
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not export state: %v", GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var exportStateResponse rest.ExportStateResponse
+	err = json.Unmarshal(responseBody, &exportStateResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	stateJSON, err := json.MarshalIndent(exportStateResponse.State, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(stateJSON, '\n'), nil
+}
@@ -5,6 +5,9 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
 
 	"github.com/ghodss/yaml"
 	"github.com/spf13/cobra"
@@ -23,15 +26,35 @@ var getCmd = &cobra.Command{
 	},
 }
 
+// selectorHelp describes the syntax of the --selector flag of the get commands.
+const selectorHelp = "Label selector to filter on by storage pool labels, as semicolon-separated " +
+	"requirements (e.g. 'performance=gold;region!=east;!deprecated')"
+
+// withSelector adds a label selector, if any, to the URL of a REST list request.
+func withSelector(listURL, selector string) string {
+	if selector == "" {
+		return listURL
+	}
+	return listURL + "?" + url.Values{"selector": []string{selector}}.Encode()
+}
+
 func WriteJSON(out interface{}) {
+	fprintJSON(os.Stdout, out)
+}
+
+func WriteYAML(out interface{}) {
+	fprintYAML(os.Stdout, out)
+}
+
+func fprintJSON(w io.Writer, out interface{}) {
 
 	jsonBytes, _ := json.MarshalIndent(out, "", "  ")
-	fmt.Println(string(jsonBytes))
+	fmt.Fprintln(w, string(jsonBytes))
 }
 
-func WriteYAML(out interface{}) {
+func fprintYAML(w io.Writer, out interface{}) {
 
 	jsonBytes, _ := json.Marshal(out)
 	yamlBytes, _ := yaml.JSONToYAML(jsonBytes)
-	fmt.Println(string(yamlBytes))
+	fmt.Fprintln(w, string(yamlBytes))
 }
@@ -4,11 +4,15 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/netapp/trident/cli/api"
 	"github.com/netapp/trident/frontend/rest"
 	"github.com/netapp/trident/storage"
@@ -17,8 +21,22 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	getBackendPools       bool
+	getBackendUnused      bool
+	getBackendShowSecrets bool
+	backendSelector       string
+)
+
 func init() {
 	getCmd.AddCommand(getBackendCmd)
+	getBackendCmd.Flags().BoolVar(&getBackendPools, "pools", false,
+		"List the storage pools of the backends and their capacity")
+	getBackendCmd.Flags().BoolVar(&getBackendUnused, "unused", false,
+		"List only the backends with no volumes")
+	getBackendCmd.Flags().StringVarP(&backendSelector, "selector", "l", "", selectorHelp)
+	getBackendCmd.Flags().BoolVar(&getBackendShowSecrets, "show-secrets", false,
+		"Include credentials and other secrets in the backend configs; requires JSON or YAML output")
 }
 
 var getBackendCmd = &cobra.Command{
@@ -26,16 +44,50 @@ var getBackendCmd = &cobra.Command{
 	Short:   "Get one or more storage backends from Trident",
 	Aliases: []string{"b", "backends"},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkShowSecrets(getBackendShowSecrets, getBackendPools); err != nil {
+			return err
+		}
+
 		if OperatingMode == ModeTunnel {
 			command := []string{"get", "backend"}
+			if getBackendPools {
+				command = append(command, "--pools")
+			}
+			if getBackendUnused {
+				command = append(command, "--unused")
+			}
+			if getBackendShowSecrets {
+				command = append(command, "--show-secrets")
+			}
+			if backendSelector != "" {
+				command = append(command, "--selector", backendSelector)
+			}
 			TunnelCommand(append(command, args...))
 			return nil
+		} else if getBackendPools {
+			if getBackendUnused {
+				return errors.New("cannot use --unused switch with --pools")
+			}
+			return backendPoolList(args)
 		} else {
 			return backendList(args)
 		}
 	},
 }
 
+// checkShowSecrets ensures that secrets are only requested where they would be shown, that is, in
+// the full backend configs written by the JSON and YAML output formats.
+func checkShowSecrets(showSecrets, pools bool) error {
+	if !showSecrets {
+		return nil
+	} else if pools {
+		return errors.New("cannot use --show-secrets switch with --pools")
+	} else if OutputFormat != FormatJSON && OutputFormat != FormatYAML {
+		return errors.New("the --show-secrets switch requires --output json or yaml")
+	}
+	return nil
+}
+
 func backendList(backendNames []string) error {
 
 	baseURL, err := GetBaseURL()
@@ -45,10 +97,12 @@ func backendList(backendNames []string) error {
 
 	// If no backends were specified, we'll get all of them
 	if len(backendNames) == 0 {
-		backendNames, err = GetBackends(baseURL)
+		backendNames, err = GetBackendsBySelector(baseURL, backendSelector)
 		if err != nil {
 			return err
 		}
+	} else if backendSelector != "" {
+		return errors.New("cannot use --selector switch and specify backend names")
 	}
 
 	backends := make([]storage.BackendExternal, 0, 10)
@@ -56,21 +110,82 @@ func backendList(backendNames []string) error {
 	// Get the actual backend objects
 	for _, backendName := range backendNames {
 
-		backend, err := GetBackend(baseURL, backendName)
+		var backend storage.BackendExternal
+		if getBackendShowSecrets {
+			backend, err = GetBackendWithSecrets(baseURL, backendName)
+		} else {
+			backend, err = GetBackend(baseURL, backendName)
+		}
 		if err != nil {
 			return err
 		}
 		backends = append(backends, backend)
 	}
 
+	if getBackendUnused {
+		backends = filterUnusedBackends(backends)
+	}
+
 	WriteBackends(backends)
 
 	return nil
 }
 
+func backendPoolList(backendNames []string) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	// If no backends were specified, we'll get all of them
+	if len(backendNames) == 0 {
+		backendNames, err = GetBackendsBySelector(baseURL, backendSelector)
+		if err != nil {
+			return err
+		}
+	} else if backendSelector != "" {
+		return errors.New("cannot use --selector switch and specify backend names")
+	}
+
+	backendPools := make([]api.BackendPools, 0, len(backendNames))
+
+	for _, backendName := range backendNames {
+
+		pools, err := GetBackendPools(baseURL, backendName)
+		if err != nil {
+			return err
+		}
+		backendPools = append(backendPools, api.BackendPools{Backend: backendName, Pools: pools})
+	}
+
+	WriteBackendPools(backendPools)
+
+	return nil
+}
+
+// filterUnusedBackends returns the backends that have no volumes.
+func filterUnusedBackends(backends []storage.BackendExternal) []storage.BackendExternal {
+
+	unusedBackends := make([]storage.BackendExternal, 0, len(backends))
+	for _, backend := range backends {
+		if len(backend.Volumes) == 0 {
+			unusedBackends = append(unusedBackends, backend)
+		}
+	}
+
+	return unusedBackends
+}
+
 func GetBackends(baseURL string) ([]string, error) {
+	return GetBackendsBySelector(baseURL, "")
+}
+
+// GetBackendsBySelector returns the names of the backends with a storage pool whose labels
+// satisfy a label selector.  An empty selector matches every backend.
+func GetBackendsBySelector(baseURL, selector string) ([]string, error) {
 
-	url := baseURL + "/backend"
+	url := withSelector(baseURL+"/backend", selector)
 
 	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
 	if err != nil {
@@ -89,9 +204,22 @@ func GetBackends(baseURL string) ([]string, error) {
 	return listBackendsResponse.Backends, nil
 }
 
+// GetBackend returns a backend, with any secrets in its config redacted.
 func GetBackend(baseURL, backendName string) (storage.BackendExternal, error) {
+	return getBackend(baseURL, backendName, false)
+}
+
+// GetBackendWithSecrets returns a backend with its full config, including any secrets.
+func GetBackendWithSecrets(baseURL, backendName string) (storage.BackendExternal, error) {
+	return getBackend(baseURL, backendName, true)
+}
+
+func getBackend(baseURL, backendName string, showSecrets bool) (storage.BackendExternal, error) {
 
 	url := baseURL + "/backend/" + backendName
+	if showSecrets {
+		url += "?" + rest.ShowSecretsQueryParameter + "=true"
+	}
 
 	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
 	if err != nil {
@@ -110,6 +238,27 @@ func GetBackend(baseURL, backendName string) (storage.BackendExternal, error) {
 	return getBackendResponse.Backend, nil
 }
 
+func GetBackendPools(baseURL, backendName string) ([]*storage.PoolCapacityExternal, error) {
+
+	url := baseURL + "/backend/" + backendName + "/pools"
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get pools of backend %s: %v", backendName,
+			GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var getBackendPoolsResponse rest.GetBackendPoolsResponse
+	err = json.Unmarshal(responseBody, &getBackendPoolsResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return getBackendPoolsResponse.Pools, nil
+}
+
 func GetBackendByBackendUUID(baseURL, backendUUID string) (storage.BackendExternal, error) {
 
 	url := baseURL + "/backend/" + backendUUID
@@ -144,6 +293,19 @@ func WriteBackends(backends []storage.BackendExternal) {
 	}
 }
 
+func WriteBackendPools(backendPools []api.BackendPools) {
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(api.MultipleBackendPoolsResponse{Items: backendPools})
+	case FormatYAML:
+		WriteYAML(api.MultipleBackendPoolsResponse{Items: backendPools})
+	case FormatName:
+		writeBackendPoolNames(backendPools)
+	default:
+		writeBackendPoolTable(backendPools)
+	}
+}
+
 func getESeriesStorageDriverConfig(configAsMap map[string]interface{}) (*drivers.ESeriesStorageDriverConfig, error) {
 	jsonBytes, marshalError := json.MarshalIndent(configAsMap, "", "  ")
 	if marshalError != nil {
@@ -231,3 +393,53 @@ func writeBackendNames(backends []storage.BackendExternal) {
 		fmt.Println(b.Name)
 	}
 }
+
+func writeBackendPoolTable(backendPools []api.BackendPools) {
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Backend", "Pool", "Total", "Used", "Available", "Attributes"})
+
+	for _, b := range backendPools {
+		for _, pool := range b.Pools {
+
+			total, used, available := "n/a", "n/a", "n/a"
+			if pool.Capacity != nil {
+				total = humanize.IBytes(pool.Capacity.TotalBytes)
+				used = humanize.IBytes(pool.Capacity.UsedBytes)
+				available = humanize.IBytes(pool.Capacity.AvailableBytes)
+			}
+
+			table.Append([]string{
+				b.Backend,
+				pool.Name,
+				total,
+				used,
+				available,
+				formatPoolAttributes(pool),
+			})
+		}
+	}
+
+	table.Render()
+}
+
+// formatPoolAttributes returns a pool's attributes as sorted key=value pairs, one per line.
+func formatPoolAttributes(pool *storage.PoolCapacityExternal) string {
+
+	attributes := make([]string, 0, len(pool.Attributes))
+	for name, offer := range pool.Attributes {
+		attributes = append(attributes, name+"="+offer.ToString())
+	}
+	sort.Strings(attributes)
+
+	return strings.Join(attributes, "\n")
+}
+
+func writeBackendPoolNames(backendPools []api.BackendPools) {
+
+	for _, b := range backendPools {
+		for _, pool := range b.Pools {
+			fmt.Println(b.Backend + "/" + pool.Name)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+)
+
+func TestFilterUnusedBackends(t *testing.T) {
+
+	backends := []storage.BackendExternal{
+		{Name: "used1", Volumes: []string{"vol1"}},
+		{Name: "unused1", Volumes: []string{}},
+		{Name: "used2", Volumes: []string{"vol2", "vol3"}},
+		{Name: "unused2"},
+	}
+
+	var names []string
+	for _, backend := range filterUnusedBackends(backends) {
+		names = append(names, backend.Name)
+	}
+
+	if expected := []string{"unused1", "unused2"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected unused backends %v, got %v", expected, names)
+	}
+}
+
+func TestGetBackendSecrets(t *testing.T) {
+
+	// The server redacts the password unless secrets are requested
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		password := storage.RedactedValue
+		if r.URL.Query().Get(rest.ShowSecretsQueryParameter) == "true" {
+			password = "secret"
+		}
+		json.NewEncoder(w).Encode(api.GetBackendResponse{
+			Backend: storage.BackendExternal{
+				Name:   "backend1",
+				Config: map[string]interface{}{"username": "admin", "password": password},
+			},
+		})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		get      func(string, string) (storage.BackendExternal, error)
+		password string
+	}{
+		{"redacted", GetBackend, storage.RedactedValue},
+		{"with secrets", GetBackendWithSecrets, "secret"},
+	}
+
+	for _, test := range tests {
+		backend, err := test.get(server.URL, "backend1")
+		if err != nil {
+			t.Fatalf("%s: unexpected error getting backend: %v", test.name, err)
+		}
+		config, ok := backend.Config.(map[string]interface{})
+		if !ok {
+			t.Fatalf("%s: expected a backend config, got %v", test.name, backend.Config)
+		}
+		if config["password"] != test.password {
+			t.Errorf("%s: expected password %s, got %v", test.name, test.password, config["password"])
+		}
+	}
+}
+
+func TestCheckShowSecrets(t *testing.T) {
+
+	savedOutputFormat := OutputFormat
+	defer func() { OutputFormat = savedOutputFormat }()
+
+	tests := []struct {
+		showSecrets  bool
+		pools        bool
+		outputFormat string
+		valid        bool
+	}{
+		{false, false, "", true},
+		{false, true, FormatWide, true},
+		{true, false, FormatJSON, true},
+		{true, false, FormatYAML, true},
+		{true, false, "", false},
+		{true, false, FormatWide, false},
+		{true, false, FormatName, false},
+		{true, true, FormatJSON, false},
+	}
+
+	for _, test := range tests {
+		OutputFormat = test.outputFormat
+		err := checkShowSecrets(test.showSecrets, test.pools)
+		if test.valid && err != nil {
+			t.Errorf("Unexpected error for %+v: %v", test, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("Expected an error for %+v", test)
+		}
+	}
+}
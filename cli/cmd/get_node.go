@@ -5,29 +5,38 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
 	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/config"
 	"github.com/netapp/trident/frontend/rest"
 	"github.com/netapp/trident/utils"
 )
 
+var getNodeVolume string
+
 func init() {
 	getCmd.AddCommand(getNodeCmd)
+	getNodeCmd.Flags().StringVar(&getNodeVolume, "volume", "",
+		"Limit query to nodes the volume is published to")
 }
 
 var getNodeCmd = &cobra.Command{
 	Use:     "node [<name>...]",
 	Short:   "Get one or more CSI provider nodes from Trident",
 	Aliases: []string{"n", "nodes"},
-	Hidden:  true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if OperatingMode == ModeTunnel {
 			command := []string{"get", "node"}
+			if getNodeVolume != "" {
+				command = append(command, "--volume", getNodeVolume)
+			}
 			TunnelCommand(append(command, args...))
 			return nil
 		} else {
@@ -51,6 +60,15 @@ func nodeList(nodeNames []string) error {
 		}
 	}
 
+	// Keep only the nodes the volume is published to
+	if getNodeVolume != "" {
+		publishedNodeNames, err := GetPublishedNodesForVolume(baseURL, getNodeVolume)
+		if err != nil {
+			return err
+		}
+		nodeNames = filterNodeNames(nodeNames, publishedNodeNames)
+	}
+
 	nodes := make([]utils.Node, 0, 10)
 
 	// Get the actual node objects
@@ -89,6 +107,44 @@ func GetNodes(baseURL string) ([]string, error) {
 	return listNodesResponse.Nodes, nil
 }
 
+// filterNodeNames returns the node names that also appear in a second list, preserving their order.
+func filterNodeNames(nodeNames, allowedNodeNames []string) []string {
+
+	allowed := make(map[string]bool, len(allowedNodeNames))
+	for _, nodeName := range allowedNodeNames {
+		allowed[nodeName] = true
+	}
+
+	filtered := make([]string, 0, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		if allowed[nodeName] {
+			filtered = append(filtered, nodeName)
+		}
+	}
+	return filtered
+}
+
+func GetPublishedNodesForVolume(baseURL, volumeName string) ([]string, error) {
+
+	url := baseURL + "/volume/" + volumeName + "/publishedNodes"
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get published nodes for volume %s: %v", volumeName,
+			GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var listNodesResponse rest.ListNodesResponse
+	err = json.Unmarshal(responseBody, &listNodesResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return listNodesResponse.Nodes, nil
+}
+
 func GetNode(baseURL, nodeName string) (*utils.Node, error) {
 
 	url := baseURL + "/node/" + nodeName
@@ -119,32 +175,37 @@ func WriteNodes(nodes []utils.Node) {
 	case FormatName:
 		writeNodeNames(nodes)
 	case FormatWide:
-		writeWideNodeTable(nodes)
+		writeWideNodeTable(os.Stdout, nodes)
 	default:
-		writeNodeTable(nodes)
+		writeNodeTable(os.Stdout, nodes)
 	}
 }
 
-func writeNodeTable(nodes []utils.Node) {
+func writeNodeTable(output io.Writer, nodes []utils.Node) {
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name"})
+	table := tablewriter.NewWriter(output)
+	table.SetHeader([]string{"Name", "IQN", "IPs"})
 
 	for _, n := range nodes {
 		table.Append([]string{
 			n.Name,
+			n.IQN,
+			strings.Join(n.IPs, ", "),
 		})
 	}
 
 	table.Render()
 }
 
-func writeWideNodeTable(nodes []utils.Node) {
+func writeWideNodeTable(output io.Writer, nodes []utils.Node) {
 
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(output)
 	header := []string{
 		"Name",
 		"IQN",
+		"IPs",
+		"Region",
+		"Zone",
 	}
 	table.SetHeader(header)
 
@@ -153,6 +214,9 @@ func writeWideNodeTable(nodes []utils.Node) {
 		table.Append([]string{
 			node.Name,
 			node.IQN,
+			strings.Join(node.IPs, ", "),
+			node.TopologyLabels[config.TopologyRegionLabel],
+			node.TopologyLabels[config.TopologyZoneLabel],
 		})
 	}
 
@@ -0,0 +1,66 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/utils"
+)
+
+func TestGetPublishedNodesForVolume(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/volume/vol1/publishedNodes" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(rest.ListNodesResponse{Error: "volume not found"})
+			return
+		}
+		json.NewEncoder(w).Encode(rest.ListNodesResponse{Nodes: []string{"node1", "node3"}})
+	}))
+	defer server.Close()
+
+	publishedNodeNames, err := GetPublishedNodesForVolume(server.URL, "vol1")
+	if err != nil {
+		t.Fatalf("Unexpected error getting published nodes: %v", err)
+	}
+
+	nodeNames := filterNodeNames([]string{"node3", "node2", "node1"}, publishedNodeNames)
+	if !reflect.DeepEqual(nodeNames, []string{"node3", "node1"}) {
+		t.Errorf("Expected nodes [node3 node1], got %v", nodeNames)
+	}
+
+	if _, err = GetPublishedNodesForVolume(server.URL, "missing"); err == nil {
+		t.Error("Expected an error getting published nodes for a missing volume")
+	}
+}
+
+func TestWriteNodeTable(t *testing.T) {
+
+	nodes := []utils.Node{{
+		Name: "node1",
+		IQN:  "iqn.2019-01.com.example:node1",
+		IPs:  []string{"10.0.0.1", "10.0.0.2"},
+	}}
+
+	var output bytes.Buffer
+	writeNodeTable(&output, nodes)
+
+	row := tableRow(output.String(), "node1")
+	if !reflect.DeepEqual(row, []string{"node1", "iqn.2019-01.com.example:node1", "10.0.0.1, 10.0.0.2"}) {
+		t.Errorf("Expected the node's name, IQN, and IPs, got %v", row)
+	}
+
+	output.Reset()
+	writeWideNodeTable(&output, nodes)
+
+	if row = tableRow(output.String(), "node1"); len(row) != 5 {
+		t.Errorf("Expected 5 columns, got %v", row)
+	}
+}
@@ -5,8 +5,10 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/dustin/go-humanize"
 	"github.com/olekukonko/tablewriter"
@@ -17,11 +19,18 @@ import (
 	"github.com/netapp/trident/storage"
 )
 
-var getSnapshotVolume string
+var (
+	getSnapshotVolume    string
+	getSnapshotBackend   string
+	getSnapshotSizeBytes bool
+)
 
 func init() {
 	getCmd.AddCommand(getSnapshotCmd)
 	getSnapshotCmd.Flags().StringVar(&getSnapshotVolume, "volume", "", "Limit query to volume")
+	getSnapshotCmd.Flags().StringVar(&getSnapshotBackend, "backend", "", "Limit query to backend")
+	getSnapshotCmd.Flags().BoolVar(&getSnapshotSizeBytes, "size-bytes", false,
+		"Add the exact snapshot size in bytes to the wide output")
 }
 
 var getSnapshotCmd = &cobra.Command{
@@ -34,6 +43,12 @@ var getSnapshotCmd = &cobra.Command{
 			if getSnapshotVolume != "" {
 				command = append(command, "--volume", getSnapshotVolume)
 			}
+			if getSnapshotBackend != "" {
+				command = append(command, "--backend", getSnapshotBackend)
+			}
+			if getSnapshotSizeBytes {
+				command = append(command, "--size-bytes")
+			}
 			TunnelCommand(append(command, args...))
 			return nil
 		} else {
@@ -69,11 +84,61 @@ func snapshotList(snapshotIDs []string) error {
 		snapshots = append(snapshots, snapshot)
 	}
 
+	if getSnapshotBackend != "" {
+		snapshots, err = filterSnapshotsByBackend(baseURL, getSnapshotBackend, snapshots)
+		if err != nil {
+			return err
+		}
+	}
+
 	WriteSnapshots(snapshots)
 
 	return nil
 }
 
+// filterSnapshotsByBackend returns only those snapshots whose source volume resides on the named backend.
+func filterSnapshotsByBackend(
+	baseURL, backendName string, snapshots []storage.SnapshotExternal,
+) ([]storage.SnapshotExternal, error) {
+
+	backend, err := GetBackend(baseURL, backendName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Many snapshots typically share a volume, so look up each volume only once
+	volumeBackends := make(map[string]string)
+
+	filtered := make([]storage.SnapshotExternal, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		backendUUID, err := getVolumeBackendUUID(baseURL, snapshot.Config.VolumeName, volumeBackends)
+		if err != nil {
+			return nil, err
+		}
+		if backendUUID == backend.BackendUUID {
+			filtered = append(filtered, snapshot)
+		}
+	}
+
+	return filtered, nil
+}
+
+// getVolumeBackendUUID returns the UUID of the backend hosting a volume, consulting and updating the supplied cache.
+func getVolumeBackendUUID(baseURL, volumeName string, cache map[string]string) (string, error) {
+
+	if backendUUID, ok := cache[volumeName]; ok {
+		return backendUUID, nil
+	}
+
+	volume, err := GetVolume(baseURL, volumeName)
+	if err != nil {
+		return "", err
+	}
+
+	cache[volumeName] = volume.BackendUUID
+	return volume.BackendUUID, nil
+}
+
 func GetSnapshots(baseURL, volume string) ([]string, error) {
 
 	var url string
@@ -129,7 +194,7 @@ func WriteSnapshots(snapshots []storage.SnapshotExternal) {
 	case FormatName:
 		writeSnapshotIDs(snapshots)
 	case FormatWide:
-		writeWideSnapshotTable(snapshots)
+		writeWideSnapshotTable(os.Stdout, snapshots, getSnapshotSizeBytes)
 	default:
 		writeSnapshotTable(snapshots)
 	}
@@ -151,25 +216,32 @@ func writeSnapshotTable(snapshots []storage.SnapshotExternal) {
 	table.Render()
 }
 
-func writeWideSnapshotTable(snapshots []storage.SnapshotExternal) {
+func writeWideSnapshotTable(w io.Writer, snapshots []storage.SnapshotExternal, sizeBytes bool) {
 
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(w)
 	header := []string{
 		"Name",
 		"Volume",
 		"Created",
 		"Size",
 	}
+	if sizeBytes {
+		header = append(header, "Size (bytes)")
+	}
 	table.SetHeader(header)
 
 	for _, snapshot := range snapshots {
 
-		table.Append([]string{
+		row := []string{
 			snapshot.Config.Name,
 			snapshot.Config.VolumeName,
 			snapshot.Created,
 			humanize.IBytes(uint64(snapshot.SizeBytes)),
-		})
+		}
+		if sizeBytes {
+			row = append(row, strconv.FormatInt(snapshot.SizeBytes, 10))
+		}
+		table.Append(row)
 	}
 
 	table.Render()
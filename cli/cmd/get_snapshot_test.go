@@ -0,0 +1,56 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/netapp/trident/storage"
+)
+
+// tableRow returns the trimmed cells of the first rendered table row containing the supplied text.
+func tableRow(table, text string) []string {
+	for _, line := range strings.Split(table, "\n") {
+		if !strings.Contains(line, text) {
+			continue
+		}
+		cells := make([]string, 0)
+		for _, cell := range strings.Split(strings.Trim(line, "|"), "|") {
+			cells = append(cells, strings.TrimSpace(cell))
+		}
+		return cells
+	}
+	return nil
+}
+
+func TestWriteWideSnapshotTableSizeBytes(t *testing.T) {
+
+	snapshot := storage.NewSnapshot(&storage.SnapshotConfig{Name: "snap1", VolumeName: "vol1"},
+		"2019-06-01T12:00:00Z", 1073741825)
+	snapshots := []storage.SnapshotExternal{*snapshot.ConstructExternal()}
+
+	// Raw sizes are rendered in an additional column
+	var output bytes.Buffer
+	writeWideSnapshotTable(&output, snapshots, true)
+
+	row := tableRow(output.String(), "snap1")
+	if len(row) != 5 {
+		t.Fatalf("Expected 5 columns, got %v", row)
+	}
+	if row[3] != "1.0 GiB" {
+		t.Errorf("Expected human-readable size 1.0 GiB, got %s", row[3])
+	}
+	if row[4] != "1073741825" {
+		t.Errorf("Expected raw size 1073741825, got %s", row[4])
+	}
+
+	// The default wide output is unchanged
+	output.Reset()
+	writeWideSnapshotTable(&output, snapshots, false)
+
+	if row = tableRow(output.String(), "snap1"); len(row) != 4 {
+		t.Errorf("Expected 4 columns, got %v", row)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	persistentstore "github.com/netapp/trident/persistent_store"
+)
+
+func init() {
+	getCmd.AddCommand(getTransactionCmd)
+}
+
+var getTransactionCmd = &cobra.Command{
+	Use:     "transaction",
+	Short:   "Get the outstanding volume transactions in Trident",
+	Aliases: []string{"txn", "transactions"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if OperatingMode == ModeTunnel {
+			command := []string{"get", "transaction"}
+			TunnelCommand(command)
+			return nil
+		} else {
+			return transactionList()
+		}
+	},
+}
+
+func transactionList() error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	transactions, err := GetTransactions(baseURL)
+	if err != nil {
+		return err
+	}
+
+	WriteTransactions(transactions)
+
+	return nil
+}
+
+func GetTransactions(baseURL string) ([]*persistentstore.VolumeTransaction, error) {
+
+	url := baseURL + "/txn"
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get transactions: %v",
+			GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var listTransactionsResponse rest.ListTransactionsResponse
+	err = json.Unmarshal(responseBody, &listTransactionsResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return listTransactionsResponse.Transactions, nil
+}
+
+func WriteTransactions(transactions []*persistentstore.VolumeTransaction) {
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(api.MultipleTransactionResponse{Items: transactions})
+	case FormatYAML:
+		WriteYAML(api.MultipleTransactionResponse{Items: transactions})
+	case FormatName:
+		writeTransactionNames(os.Stdout, transactions)
+	default:
+		writeTransactionTable(os.Stdout, transactions)
+	}
+}
+
+// transactionVolume returns the volume a transaction applies to, and the snapshot if it is a
+// snapshot operation.
+func transactionVolume(txn *persistentstore.VolumeTransaction) (string, string) {
+	if txn.SnapshotConfig != nil && (txn.Op == persistentstore.AddSnapshot ||
+		txn.Op == persistentstore.DeleteSnapshot) {
+		return txn.SnapshotConfig.VolumeName, txn.SnapshotConfig.Name
+	}
+	if txn.Config != nil {
+		return txn.Config.Name, ""
+	}
+	return "", ""
+}
+
+func writeTransactionTable(w io.Writer, transactions []*persistentstore.VolumeTransaction) {
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Operation", "Volume", "Snapshot"})
+
+	for _, txn := range transactions {
+		volume, snapshot := transactionVolume(txn)
+		table.Append([]string{
+			string(txn.Op),
+			volume,
+			snapshot,
+		})
+	}
+
+	table.Render()
+}
+
+func writeTransactionNames(w io.Writer, transactions []*persistentstore.VolumeTransaction) {
+
+	for _, txn := range transactions {
+		volume, snapshot := transactionVolume(txn)
+		if snapshot != "" {
+			fmt.Fprintf(w, "%s %s/%s\n", txn.Op, volume, snapshot)
+		} else {
+			fmt.Fprintf(w, "%s %s\n", txn.Op, volume)
+		}
+	}
+}
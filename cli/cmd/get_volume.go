@@ -4,6 +4,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -13,16 +14,30 @@ import (
 	"github.com/netapp/trident/cli/api"
 	"github.com/netapp/trident/frontend/rest"
 	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/utils"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
 var (
-	backendsByUUID map[string]*storage.BackendExternal
+	backendsByUUID     map[string]*storage.BackendExternal
+	reachableNodes     bool
+	volumeInternalName string
+	volumeEvents       bool
+	volumeSelector     string
+	volumeBackend      string
 )
 
 func init() {
 	getCmd.AddCommand(getVolumeCmd)
+	getVolumeCmd.Flags().BoolVar(&reachableNodes, "reachable-nodes", false,
+		"List the nodes that can reach the volume instead of the volume itself")
+	getVolumeCmd.Flags().StringVar(&volumeInternalName, "internal-name", "",
+		"Get the volume with this name on its storage system")
+	getVolumeCmd.Flags().BoolVar(&volumeEvents, "events", false,
+		"List the recent events recorded for the volume instead of the volume itself")
+	getVolumeCmd.Flags().StringVarP(&volumeSelector, "selector", "l", "", selectorHelp)
+	getVolumeCmd.Flags().StringVar(&volumeBackend, "backend", "", "List only the volumes on this backend")
 	backendsByUUID = make(map[string]*storage.BackendExternal)
 }
 
@@ -33,8 +48,35 @@ var getVolumeCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if OperatingMode == ModeTunnel {
 			command := []string{"get", "volume"}
+			if reachableNodes {
+				command = append(command, "--reachable-nodes")
+			}
+			if volumeInternalName != "" {
+				command = append(command, "--internal-name", volumeInternalName)
+			}
+			if volumeEvents {
+				command = append(command, "--events")
+			}
+			if volumeSelector != "" {
+				command = append(command, "--selector", volumeSelector)
+			}
+			if volumeBackend != "" {
+				command = append(command, "--backend", volumeBackend)
+			}
 			TunnelCommand(append(command, args...))
 			return nil
+		} else if volumeSelector != "" && (volumeInternalName != "" || reachableNodes || volumeEvents) {
+			return errors.New("cannot use --selector switch with --internal-name, --reachable-nodes, or --events")
+		} else if volumeBackend != "" &&
+			(volumeSelector != "" || volumeInternalName != "" || reachableNodes || volumeEvents) {
+			return errors.New(
+				"cannot use --backend switch with --selector, --internal-name, --reachable-nodes, or --events")
+		} else if volumeInternalName != "" {
+			return volumeListByInternalName(args, volumeInternalName)
+		} else if reachableNodes {
+			return volumeReachableNodeList(args)
+		} else if volumeEvents {
+			return volumeEventList(args)
 		} else {
 			return volumeList(args)
 		}
@@ -50,10 +92,18 @@ func volumeList(volumeNames []string) error {
 
 	// If no volumes were specified, we'll get all of them
 	if len(volumeNames) == 0 {
-		volumeNames, err = GetVolumes(baseURL)
+		if volumeBackend != "" {
+			volumeNames, err = GetVolumesForBackend(baseURL, volumeBackend)
+		} else {
+			volumeNames, err = GetVolumesBySelector(baseURL, volumeSelector)
+		}
 		if err != nil {
 			return err
 		}
+	} else if volumeSelector != "" {
+		return errors.New("cannot use --selector switch and specify volume names")
+	} else if volumeBackend != "" {
+		return errors.New("cannot use --backend switch and specify volume names")
 	}
 
 	volumes := make([]storage.VolumeExternal, 0, 10)
@@ -85,9 +135,151 @@ func volumeList(volumeNames []string) error {
 	return nil
 }
 
+func volumeListByInternalName(volumeNames []string, internalName string) error {
+
+	if len(volumeNames) > 0 {
+		return errors.New("cannot use --internal-name switch and specify volume names")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	volume, err := GetVolumeByInternalName(baseURL, internalName)
+	if err != nil {
+		return err
+	}
+
+	if OutputFormat == FormatWide {
+		backend, err := GetBackendByBackendUUID(baseURL, volume.BackendUUID)
+		if err != nil {
+			return err
+		}
+		backendsByUUID[volume.BackendUUID] = &backend
+	}
+
+	WriteVolumes([]storage.VolumeExternal{volume})
+
+	return nil
+}
+
+func volumeReachableNodeList(volumeNames []string) error {
+
+	switch len(volumeNames) {
+	case 0:
+		return errors.New("volume name not specified")
+	case 1:
+		break
+	default:
+		return errors.New("multiple volume names specified")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	nodeNames, err := GetReachableNodesForVolume(baseURL, volumeNames[0])
+	if err != nil {
+		return err
+	}
+
+	nodes := make([]utils.Node, 0, len(nodeNames))
+
+	// Get the actual node objects
+	for _, nodeName := range nodeNames {
+
+		node, err := GetNode(baseURL, nodeName)
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, *node)
+	}
+
+	WriteNodes(nodes)
+
+	return nil
+}
+
+func volumeEventList(volumeNames []string) error {
+
+	switch len(volumeNames) {
+	case 0:
+		return errors.New("volume name not specified")
+	case 1:
+		break
+	default:
+		return errors.New("multiple volume names specified")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	events, err := GetVolumeEvents(baseURL, volumeNames[0])
+	if err != nil {
+		return err
+	}
+
+	WriteVolumeEvents(events)
+
+	return nil
+}
+
+// GetVolumeEvents returns the recent events recorded for a volume, newest first.
+func GetVolumeEvents(baseURL, volumeName string) ([]*storage.VolumeEvent, error) {
+
+	url := baseURL + "/volume/" + volumeName + "/events"
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get events for volume %s: %v", volumeName,
+			GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var getVolumeEventsResponse rest.GetVolumeEventsResponse
+	err = json.Unmarshal(responseBody, &getVolumeEventsResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return getVolumeEventsResponse.Events, nil
+}
+
+func GetReachableNodesForVolume(baseURL, volumeName string) ([]string, error) {
+
+	url := baseURL + "/volume/" + volumeName + "/reachableNodes"
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get reachable nodes for volume %s: %v", volumeName,
+			GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var listNodesResponse rest.ListNodesResponse
+	err = json.Unmarshal(responseBody, &listNodesResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return listNodesResponse.Nodes, nil
+}
+
 func GetVolumes(baseURL string) ([]string, error) {
+	return GetVolumesBySelector(baseURL, "")
+}
+
+// GetVolumesBySelector returns the names of the volumes in a storage pool whose labels satisfy a
+// label selector.  An empty selector matches every volume.
+func GetVolumesBySelector(baseURL, selector string) ([]string, error) {
 
-	url := baseURL + "/volume"
+	url := withSelector(baseURL+"/volume", selector)
 
 	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
 	if err != nil {
@@ -106,6 +298,28 @@ func GetVolumes(baseURL string) ([]string, error) {
 	return listVolumesResponse.Volumes, nil
 }
 
+// GetVolumesForBackend returns the names of the volumes on a backend.
+func GetVolumesForBackend(baseURL, backendName string) ([]string, error) {
+
+	url := baseURL + "/backend/" + backendName + "/volume"
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get volumes for backend %s: %v", backendName,
+			GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var listVolumesResponse rest.ListVolumesResponse
+	err = json.Unmarshal(responseBody, &listVolumesResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return listVolumesResponse.Volumes, nil
+}
+
 func GetVolume(baseURL, volumeName string) (storage.VolumeExternal, error) {
 
 	url := baseURL + "/volume/" + volumeName
@@ -127,6 +341,27 @@ func GetVolume(baseURL, volumeName string) (storage.VolumeExternal, error) {
 	return *getVolumeResponse.Volume, nil
 }
 
+func GetVolumeByInternalName(baseURL, internalName string) (storage.VolumeExternal, error) {
+
+	url := baseURL + "/volume/internalName/" + internalName
+
+	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
+	if err != nil {
+		return storage.VolumeExternal{}, err
+	} else if response.StatusCode != http.StatusOK {
+		return storage.VolumeExternal{}, fmt.Errorf("could not get volume with internal name %s: %v",
+			internalName, GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var getVolumeResponse rest.GetVolumeResponse
+	err = json.Unmarshal(responseBody, &getVolumeResponse)
+	if err != nil {
+		return storage.VolumeExternal{}, err
+	}
+
+	return *getVolumeResponse.Volume, nil
+}
+
 func WriteVolumes(volumes []storage.VolumeExternal) {
 	switch OutputFormat {
 	case FormatJSON:
@@ -214,3 +449,31 @@ func writeVolumeNames(volumes []storage.VolumeExternal) {
 		fmt.Println(sc.Config.Name)
 	}
 }
+
+func WriteVolumeEvents(events []*storage.VolumeEvent) {
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(api.MultipleVolumeEventResponse{Items: events})
+	case FormatYAML:
+		WriteYAML(api.MultipleVolumeEventResponse{Items: events})
+	default:
+		writeVolumeEventTable(events)
+	}
+}
+
+func writeVolumeEventTable(events []*storage.VolumeEvent) {
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Time", "Type", "Reason", "Message"})
+
+	for _, event := range events {
+		table.Append([]string{
+			event.Timestamp,
+			event.Type,
+			event.Reason,
+			event.Message,
+		})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/netapp/trident/frontend/rest"
+)
+
+func TestGetVolumesBySelector(t *testing.T) {
+
+	var selectors []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/volume" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		selector := r.URL.Query().Get(rest.SelectorQueryParameter)
+		selectors = append(selectors, selector)
+		volumes := []string{"vol1", "vol2"}
+		if selector != "" {
+			volumes = []string{"vol1"}
+		}
+		json.NewEncoder(w).Encode(rest.ListVolumesResponse{Volumes: volumes})
+	}))
+	defer server.Close()
+
+	volumes, err := GetVolumesBySelector(server.URL, "performance=gold;!deprecated")
+	if err != nil {
+		t.Fatalf("Unexpected error getting volumes: %v", err)
+	}
+	if !reflect.DeepEqual(volumes, []string{"vol1"}) {
+		t.Errorf("Expected volumes [vol1], got %v", volumes)
+	}
+
+	// Without a selector, no query parameter is sent
+	if volumes, err = GetVolumes(server.URL); err != nil {
+		t.Fatalf("Unexpected error getting volumes: %v", err)
+	}
+	if len(volumes) != 2 {
+		t.Errorf("Expected 2 volumes, got %v", volumes)
+	}
+
+	expected := []string{"performance=gold;!deprecated", ""}
+	if !reflect.DeepEqual(selectors, expected) {
+		t.Errorf("Expected selectors %q, got %q", expected, selectors)
+	}
+}
+
+func TestGetVolumesForBackend(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/backend/nas/volume":
+			json.NewEncoder(w).Encode(rest.ListVolumesResponse{Volumes: []string{"vol1", "vol2"}})
+		case "/backend/empty/volume":
+			json.NewEncoder(w).Encode(rest.ListVolumesResponse{Volumes: []string{}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(rest.ListVolumesResponse{Error: "backend not found"})
+		}
+	}))
+	defer server.Close()
+
+	volumes, err := GetVolumesForBackend(server.URL, "nas")
+	if err != nil {
+		t.Fatalf("Unexpected error getting volumes: %v", err)
+	}
+	if !reflect.DeepEqual(volumes, []string{"vol1", "vol2"}) {
+		t.Errorf("Expected volumes [vol1 vol2], got %v", volumes)
+	}
+
+	if volumes, err = GetVolumesForBackend(server.URL, "empty"); err != nil || len(volumes) != 0 {
+		t.Errorf("Expected no volumes for an empty backend, got %v: %v", volumes, err)
+	}
+
+	if _, err = GetVolumesForBackend(server.URL, "missing"); err == nil {
+		t.Error("Expected an error for a missing backend")
+	}
+}
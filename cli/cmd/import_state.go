@@ -0,0 +1,117 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	persistentstore "github.com/netapp/trident/persistent_store"
+)
+
+var importStateFilename string
+
+func init() {
+	importCmd.AddCommand(importStateCmd)
+	importStateCmd.Flags().StringVarP(&importStateFilename, "filename", "f", "",
+		"Path to JSON file created by 'tridentctl export state', or '-' for standard input")
+}
+
+var importStateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Import persistent state previously exported from Trident",
+	Long: `Import persistent state previously exported from Trident
+
+Restores the objects in a document written by 'tridentctl export state' into
+Trident's persistent store.  Objects that already exist are skipped.  State may
+only be imported while Trident has no backends or volumes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		stateJSON, err := getStateData(importStateFilename)
+		if err != nil {
+			return err
+		}
+
+		if OperatingMode == ModeTunnel {
+			TunnelCommandWithInput([]string{"import", "state", "--filename", "-"}, stateJSON)
+			return nil
+		} else {
+			return stateImport(stateJSON)
+		}
+	},
+}
+
+func getStateData(filename string) ([]byte, error) {
+
+	var err error
+	var stateJSON []byte
+
+	switch filename {
+	case "":
+		return nil, errors.New("no input file was specified")
+	case "-":
+		stateJSON, err = ioutil.ReadAll(os.Stdin)
+	default:
+		stateJSON, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the file is a state document whose version we understand
+	var state persistentstore.State
+	if err = json.Unmarshal(stateJSON, &state); err != nil {
+		return nil, fmt.Errorf("invalid state document: %v", err)
+	}
+	if state.SchemaVersion != persistentstore.StateSchemaVersion {
+		return nil, fmt.Errorf("unsupported state schema version %s; expected %s",
+			state.SchemaVersion, persistentstore.StateSchemaVersion)
+	}
+
+	return stateJSON, nil
+}
+
+func stateImport(stateJSON []byte) error {
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	url := baseURL + "/state"
+
+	response, responseBody, err := api.InvokeRESTAPI("POST", url, stateJSON, Debug)
+	if err != nil {
+		return err
+	} else if response.StatusCode != http.StatusCreated {
+		return fmt.Errorf("could not import state: %v", GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var importStateResponse rest.ImportStateResponse
+	err = json.Unmarshal(responseBody, &importStateResponse)
+	if err != nil {
+		return err
+	} else if importStateResponse.Result == nil {
+		return errors.New("could not import state: no result returned")
+	}
+
+	switch OutputFormat {
+	case FormatJSON:
+		WriteJSON(importStateResponse.Result)
+	case FormatYAML:
+		WriteYAML(importStateResponse.Result)
+	default:
+		fmt.Printf("Imported %d objects, skipped %d existing objects.\n",
+			importStateResponse.Result.Imported, importStateResponse.Result.Skipped)
+	}
+
+	return nil
+}
@@ -34,7 +34,7 @@ func init() {
 }
 
 var importVolumeCmd = &cobra.Command{
-	Use:   "volume <backendName> <volumeName>",
+	Use:   "volume <backendName> <internalVolumeName>",
 	Short: "Import an existing volume to Trident",
 	Long: `Import an existing volume to Trident
 
@@ -127,13 +127,11 @@ func volumeImport(backendName, internalVolumeName string, noManage bool, pvcData
 	err = json.Unmarshal(responseBody, &importVolumeResponse)
 	if err != nil {
 		return err
+	} else if importVolumeResponse.Volume == nil {
+		return fmt.Errorf("could not import volume %s: no volume returned", internalVolumeName)
 	}
 
-	volume := *importVolumeResponse.Volume
-
-	volumes := make([]storage.VolumeExternal, 0, 10)
-	volumes = append(volumes, volume)
-	WriteVolumes(volumes)
+	WriteVolumes([]storage.VolumeExternal{*importVolumeResponse.Volume})
 
 	return nil
 }
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -56,18 +57,51 @@ const (
 
 var (
 	// CLI flags
-	dryRun          bool
-	generateYAML    bool
-	useYAML         bool
-	silent          bool
-	csi             bool
-	inCluster       bool
-	pvName          string
-	pvcName         string
-	tridentImage    string
-	etcdImage       string
-	k8sTimeout      time.Duration
-	migratorTimeout time.Duration
+	dryRun                bool
+	generateYAML          bool
+	generateManifestsOnly bool
+	manifestsDir          string
+	useYAML               bool
+	silent                bool
+	csi                   bool
+	inCluster             bool
+	pvName                string
+	pvcName               string
+	tridentImage          string
+	etcdImage             string
+	k8sTimeout            time.Duration
+	migratorTimeout       time.Duration
+
+	replicas           int
+	deploymentStrategy string
+	maxUnavailable     string
+	maxSurge           string
+	strategy           *k8sclient.DeploymentStrategy
+
+	readinessInitialDelay time.Duration
+	readinessPeriod       time.Duration
+	readiness             *k8sclient.ReadinessProbe
+
+	nonRoot         bool
+	runAsUser       int64
+	securityContext *k8sclient.SecurityContext
+
+	httpProxy  string
+	httpsProxy string
+	noProxy    []string
+	proxy      *k8sclient.ProxyConfig
+
+	nodeCIDRs      []string
+	apiServerCIDRs []string
+	backendCIDRs   []string
+	networkPolicy  *k8sclient.NetworkPolicyConfig
+
+	controllerSpreadKey string
+	controllerAffinity  *v1.Affinity
+
+	podInfoOnMount bool
+	topologyKeys   []string
+	kubeletDir     string
 
 	// CLI-based K8S client
 	client k8sclient.Interface
@@ -107,6 +141,11 @@ func init() {
 	RootCmd.AddCommand(installCmd)
 	installCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run all the pre-checks, but don't install anything.")
 	installCmd.Flags().BoolVar(&generateYAML, "generate-custom-yaml", false, "Generate YAML files, but don't install anything.")
+	installCmd.Flags().BoolVar(&generateManifestsOnly, "generate-manifests-only", false,
+		"Write all manifests an installation would create, but don't install anything.")
+	installCmd.Flags().StringVar(&manifestsDir, "manifests-dir", "",
+		"The directory to which generated manifests are written (default is the setup directory), "+
+			"or - to write them to stdout as a single YAML stream.")
 	installCmd.Flags().BoolVar(&useYAML, "use-custom-yaml", false, "Use any existing YAML files that exist in setup directory.")
 	installCmd.Flags().BoolVar(&silent, "silent", false, "Disable most output during installation.")
 	installCmd.Flags().BoolVar(&csi, "csi", false, "Install CSI Trident (override for Kubernetes 1.13 only, requires feature gates).")
@@ -117,6 +156,47 @@ func init() {
 	installCmd.Flags().StringVar(&tridentImage, "trident-image", "", "The Trident image to install.")
 	installCmd.Flags().StringVar(&etcdImage, "etcd-image", "", "The etcd image to install.")
 
+	installCmd.Flags().IntVar(&replicas, "replicas", 1, "The number of Trident controller replicas (CSI only).")
+	installCmd.Flags().StringVar(&deploymentStrategy, "deployment-strategy", "",
+		"The Trident controller update strategy, Recreate or RollingUpdate (default RollingUpdate if replicas > 1).")
+	installCmd.Flags().StringVar(&maxUnavailable, "max-unavailable", "",
+		"The maximum number of unavailable controller pods during a rolling update (integer or percentage).")
+	installCmd.Flags().StringVar(&maxSurge, "max-surge", "",
+		"The maximum number of extra controller pods during a rolling update (integer or percentage).")
+	installCmd.Flags().DurationVar(&readinessInitialDelay, "readiness-initial-delay",
+		k8sclient.DefaultReadinessInitialDelay,
+		"The delay before the Trident controller's readiness is first checked (CSI only).")
+	installCmd.Flags().DurationVar(&readinessPeriod, "readiness-period", k8sclient.DefaultReadinessPeriod,
+		"How often the Trident controller's readiness is checked (CSI only).")
+	installCmd.Flags().BoolVar(&nonRoot, "non-root", false,
+		"Run the Trident controller as a non-root user with a restricted security context (CSI only).")
+	installCmd.Flags().Int64Var(&runAsUser, "run-as-user", k8sclient.DefaultRunAsUser,
+		"The user ID the Trident controller runs as with --non-root (CSI only).")
+	installCmd.Flags().StringVar(&controllerSpreadKey, "controller-spread-key", "",
+		"A topology key, such as kubernetes.io/hostname, across which to spread controller replicas (CSI only).")
+	installCmd.Flags().StringVar(&httpProxy, "http-proxy", "",
+		"The HTTP proxy the Trident controller uses to reach storage backends (CSI only).")
+	installCmd.Flags().StringVar(&httpsProxy, "https-proxy", "",
+		"The HTTPS proxy the Trident controller uses to reach storage backends (CSI only).")
+	installCmd.Flags().StringSliceVar(&noProxy, "no-proxy", []string{},
+		"Destinations the Trident controller reaches without a proxy, such as the pod and service CIDRs, "+
+			"which must be added here. Localhost, the Kubernetes API server, and cluster-local service "+
+			"names are always included (CSI only).")
+	installCmd.Flags().StringSliceVar(&nodeCIDRs, "node-cidrs", []string{},
+		"The node addresses allowed to reach the Trident controller. Setting these, the API server CIDRs and the "+
+			"backend CIDRs creates a NetworkPolicy for the controller (CSI only).")
+	installCmd.Flags().StringSliceVar(&apiServerCIDRs, "api-server-cidrs", []string{},
+		"The Kubernetes API server addresses the Trident controller may reach, with --node-cidrs (CSI only).")
+	installCmd.Flags().StringSliceVar(&backendCIDRs, "backend-cidrs", []string{},
+		"The backend management addresses the Trident controller may reach, with --node-cidrs (CSI only).")
+	installCmd.Flags().BoolVar(&podInfoOnMount, "pod-info-on-mount", false,
+		"Pass pod information to the Trident node plugin on mount (CSI only).")
+	installCmd.Flags().StringSliceVar(&topologyKeys, "topology-keys", []string{},
+		"Topology keys, such as topology.kubernetes.io/zone, to record as an annotation on the CSIDriver "+
+			"and node plugin pods (CSI only).")
+	installCmd.Flags().StringVar(&kubeletDir, "kubelet-dir", k8sclient.DefaultKubeletDir,
+		"The kubelet's root directory on the nodes (CSI only).")
+
 	installCmd.Flags().DurationVar(&k8sTimeout, "k8s-timeout", 180*time.Second, "The timeout for all Kubernetes operations.")
 	installCmd.Flags().DurationVar(&migratorTimeout, "migrator-timeout", 30*time.Minute, "The timeout for etcd-to-CRD migration.")
 
@@ -141,7 +221,15 @@ var installCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 
-		if generateYAML {
+		if generateManifestsOnly {
+
+			// If generate-manifests-only was specified, write every manifest to the manifests directory
+			if err := writeInstallManifests(); err != nil {
+				log.Fatalf("Manifest generation failed; %v", err)
+			}
+			log.WithField("manifestsDir", manifestsDir).Info("Wrote installation manifests.")
+
+		} else if generateYAML {
 
 			// If generate-custom-yaml was specified, write the YAML files to the setup directory
 			if csi {
@@ -270,7 +358,7 @@ func initClient() (k8sclient.Interface, error) {
 		// The namespace file didn't exist, so assume we're outside a pod.  Create a CLI-based client.
 		log.Debug("Running outside a pod, creating CLI-based client.")
 
-		return k8sclient.NewKubectlClient("")
+		return k8sclient.NewKubectlClient("", KubeContext)
 	}
 }
 
@@ -330,6 +418,32 @@ func validateInstallationArguments() error {
 		return fmt.Errorf("'%s' is not a valid PV name; %s", pvName, subdomainFormat)
 	}
 
+	var err error
+	if strategy, err = k8sclient.NewDeploymentStrategy(replicas, deploymentStrategy, maxUnavailable,
+		maxSurge); err != nil {
+		return fmt.Errorf("invalid deployment strategy; %v", err)
+	}
+	if readiness, err = k8sclient.NewReadinessProbe(readinessInitialDelay, readinessPeriod); err != nil {
+		return fmt.Errorf("invalid readiness probe; %v", err)
+	}
+	if nonRoot {
+		if securityContext, err = k8sclient.NewSecurityContext(runAsUser); err != nil {
+			return fmt.Errorf("invalid security context; %v", err)
+		}
+	}
+	if controllerSpreadKey != "" {
+		controllerAffinity = k8sclient.NewControllerAntiAffinity(appLabelValue, controllerSpreadKey)
+	}
+	if proxy, err = k8sclient.NewProxyConfig(httpProxy, httpsProxy, noProxy); err != nil {
+		return fmt.Errorf("invalid proxy configuration; %v", err)
+	}
+	if networkPolicy, err = k8sclient.NewNetworkPolicyConfig(nodeCIDRs, apiServerCIDRs, backendCIDRs); err != nil {
+		return fmt.Errorf("invalid network policy; %v", err)
+	}
+	if err = k8sclient.ValidateKubeletDir(kubeletDir); err != nil {
+		return fmt.Errorf("invalid node plugin options; %v", err)
+	}
+
 	return nil
 }
 
@@ -385,7 +499,7 @@ func prepareYAMLFiles() error {
 		return fmt.Errorf("could not write service account YAML file; %v", err)
 	}
 
-	clusterRoleYAML := k8sclient.GetClusterRoleYAML(client.Flavor(), false)
+	clusterRoleYAML := k8sclient.GetClusterRoleYAML(client.Flavor(), false, client.ServerVersion())
 	if err = writeFile(clusterRolePath, clusterRoleYAML); err != nil {
 		return fmt.Errorf("could not write cluster role YAML file; %v", err)
 	}
@@ -424,7 +538,7 @@ func prepareCSIYAMLFiles() error {
 		return fmt.Errorf("could not write service account YAML file; %v", err)
 	}
 
-	clusterRoleYAML := k8sclient.GetClusterRoleYAML(client.Flavor(), true)
+	clusterRoleYAML := k8sclient.GetClusterRoleYAML(client.Flavor(), true, client.ServerVersion())
 	if err = writeFile(clusterRolePath, clusterRoleYAML); err != nil {
 		return fmt.Errorf("could not write cluster role YAML file; %v", err)
 	}
@@ -444,12 +558,13 @@ func prepareCSIYAMLFiles() error {
 		return fmt.Errorf("could not write service YAML file; %v", err)
 	}
 
-	deploymentYAML := k8sclient.GetCSIDeploymentYAML(tridentImage, appLabelValue, Debug, client.ServerVersion())
+	deploymentYAML := k8sclient.GetCSIDeploymentYAML(getInstallOptions())
 	if err = writeFile(deploymentPath, deploymentYAML); err != nil {
 		return fmt.Errorf("could not write deployment YAML file; %v", err)
 	}
 
-	daemonSetYAML := k8sclient.GetCSIDaemonSetYAML(tridentImage, TridentNodeLabelValue, Debug, client.ServerVersion())
+	daemonSetYAML := k8sclient.GetCSIDaemonSetYAML(tridentImage, TridentNodeLabelValue, Debug, topologyKeys,
+		kubeletDir, client.ServerVersion())
 	if err = writeFile(csiDaemonSetPath, daemonSetYAML); err != nil {
 		return fmt.Errorf("could not write daemonset YAML file; %v", err)
 	}
@@ -457,6 +572,58 @@ func prepareCSIYAMLFiles() error {
 	return nil
 }
 
+// getInstallOptions returns the set of installation choices that affect the generated manifests.
+func getInstallOptions() *k8sclient.InstallOptions {
+	return &k8sclient.InstallOptions{
+		Namespace:      TridentPodNamespace,
+		TridentImage:   tridentImage,
+		Label:          appLabelValue,
+		NodeLabel:      TridentNodeLabelValue,
+		Debug:          Debug,
+		CSI:            csi,
+		Replicas:       replicas,
+		Strategy:       strategy,
+		Readiness:      readiness,
+		Security:       securityContext,
+		Proxy:          proxy,
+		NetworkPolicy:  networkPolicy,
+		Affinity:       controllerAffinity,
+		PodInfoOnMount: podInfoOnMount,
+		TopologyKeys:   topologyKeys,
+		KubeletDir:     kubeletDir,
+		Flavor:         client.Flavor(),
+		Version:        client.ServerVersion(),
+	}
+}
+
+// writeInstallManifests writes every manifest that an installation would create to the
+// manifests directory, one file per resource type, without making any changes to the cluster.
+// A manifests directory of "-" writes them all to stdout instead.
+func writeInstallManifests() error {
+
+	if manifestsDir == "-" {
+		fmt.Print(k8sclient.GetInstallManifestsYAML(getInstallOptions()))
+		return nil
+	}
+
+	if manifestsDir == "" {
+		manifestsDir = setupPath
+	}
+	if err := os.MkdirAll(manifestsDir, 0755); err != nil {
+		return fmt.Errorf("could not create manifests directory %s; %v", manifestsDir, err)
+	}
+
+	for _, manifest := range k8sclient.GetInstallManifests(getInstallOptions()) {
+		filePath := path.Join(manifestsDir, fmt.Sprintf("trident-%s.yaml", manifest.Name))
+		if err := writeFile(filePath, manifest.YAML); err != nil {
+			return fmt.Errorf("could not write %s YAML file; %v", manifest.Name, err)
+		}
+		log.WithField("path", filePath).Debug("Wrote manifest.")
+	}
+
+	return nil
+}
+
 func fileExists(filePath string) bool {
 	_, err := os.Stat(filePath)
 	return err == nil
@@ -672,6 +839,27 @@ func installTrident() (returnError error) {
 		}
 		log.WithFields(logFields).Info("Created Trident service.")
 
+		// If OpenShift, create a route to expose the HTTPS REST interface
+		if client.Flavor() == k8sclient.FlavorOpenShift {
+			returnError = client.CreateObjectByYAML(
+				k8sclient.GetOpenShiftRouteYAML(TridentPodNamespace, appLabelValue))
+			if returnError != nil {
+				returnError = fmt.Errorf("could not create Trident route; %v", returnError)
+				return
+			}
+			log.Info("Created Trident route.")
+		}
+
+		// Limit the controller's network traffic, if requested
+		if networkPolicy != nil {
+			returnError = client.CreateObjectByYAML(k8sclient.GetNetworkPolicyYAML(getInstallOptions()))
+			if returnError != nil {
+				returnError = fmt.Errorf("could not create Trident network policy; %v", returnError)
+				return
+			}
+			log.Info("Created Trident network policy.")
+		}
+
 		// Create the certificates for the CSI controller's HTTPS REST interface
 		certInfo, err := utils.MakeHTTPCertInfo(
 			frontendrest.CACertName, frontendrest.ServerCertName, frontendrest.ClientCertName)
@@ -707,8 +895,7 @@ func installTrident() (returnError error) {
 			returnError = client.CreateObjectByFile(deploymentPath)
 			logFields = log.Fields{"path": deploymentPath}
 		} else {
-			returnError = client.CreateObjectByYAML(
-				k8sclient.GetCSIDeploymentYAML(tridentImage, appLabelValue, Debug, client.ServerVersion()))
+			returnError = client.CreateObjectByYAML(k8sclient.GetCSIDeploymentYAML(getInstallOptions()))
 			logFields = log.Fields{}
 		}
 		if returnError != nil {
@@ -728,7 +915,8 @@ func installTrident() (returnError error) {
 			logFields = log.Fields{"path": csiDaemonSetPath}
 		} else {
 			returnError = client.CreateObjectByYAML(
-				k8sclient.GetCSIDaemonSetYAML(tridentImage, TridentNodeLabelValue, Debug, client.ServerVersion()))
+				k8sclient.GetCSIDaemonSetYAML(tridentImage, TridentNodeLabelValue, Debug, topologyKeys,
+					kubeletDir, client.ServerVersion()))
 			logFields = log.Fields{}
 		}
 		if returnError != nil {
@@ -962,12 +1150,14 @@ func createK8S114CSIDriver() error {
 		return nil
 	}
 
+	csiDriverYAML := k8sclient.GetCSIDriverCRYAML(podInfoOnMount, topologyKeys)
+
 	// Delete the object in case it already exists and we need to update it
-	if err := client.DeleteObjectByYAML(k8sclient.GetCSIDriverCRYAML(), true); err != nil {
+	if err := client.DeleteObjectByYAML(csiDriverYAML, true); err != nil {
 		return fmt.Errorf("could not delete csidriver custom resource; %v", err)
 	}
 
-	if err := client.CreateObjectByYAML(k8sclient.GetCSIDriverCRYAML()); err != nil {
+	if err := client.CreateObjectByYAML(csiDriverYAML); err != nil {
 		return fmt.Errorf("could not create csidriver custom resource; %v", err)
 	}
 
@@ -997,7 +1187,7 @@ func createRBACObjects() (returnError error) {
 		returnError = client.CreateObjectByFile(clusterRolePath)
 		logFields = log.Fields{"path": clusterRolePath}
 	} else {
-		returnError = client.CreateObjectByYAML(k8sclient.GetClusterRoleYAML(client.Flavor(), csi))
+		returnError = client.CreateObjectByYAML(k8sclient.GetClusterRoleYAML(client.Flavor(), csi, client.ServerVersion()))
 		logFields = log.Fields{}
 	}
 	if returnError != nil {
@@ -1067,7 +1257,7 @@ func removeRBACObjects(logLevel log.Level) (anyErrors bool) {
 	}
 
 	// Delete cluster role
-	clusterRoleYAML := k8sclient.GetClusterRoleYAML(client.Flavor(), csi)
+	clusterRoleYAML := k8sclient.GetClusterRoleYAML(client.Flavor(), csi, client.ServerVersion())
 	if err := client.DeleteObjectByYAML(clusterRoleYAML, true); err != nil {
 		log.WithField("error", err).Warning("Could not delete cluster role.")
 		anyErrors = true
@@ -1451,6 +1641,45 @@ func installTridentInCluster() (returnError error) {
 		commandArgs = append(commandArgs, "--etcd-image")
 		commandArgs = append(commandArgs, etcdImage)
 	}
+	commandArgs = append(commandArgs, "--replicas", strconv.Itoa(replicas))
+	if deploymentStrategy != "" {
+		commandArgs = append(commandArgs, "--deployment-strategy", deploymentStrategy)
+	}
+	if maxUnavailable != "" {
+		commandArgs = append(commandArgs, "--max-unavailable", maxUnavailable)
+	}
+	if maxSurge != "" {
+		commandArgs = append(commandArgs, "--max-surge", maxSurge)
+	}
+	commandArgs = append(commandArgs, "--readiness-initial-delay", readinessInitialDelay.String())
+	commandArgs = append(commandArgs, "--readiness-period", readinessPeriod.String())
+	if nonRoot {
+		commandArgs = append(commandArgs, "--non-root", "--run-as-user", strconv.FormatInt(runAsUser, 10))
+	}
+	if controllerSpreadKey != "" {
+		commandArgs = append(commandArgs, "--controller-spread-key", controllerSpreadKey)
+	}
+	if httpProxy != "" {
+		commandArgs = append(commandArgs, "--http-proxy", httpProxy)
+	}
+	if httpsProxy != "" {
+		commandArgs = append(commandArgs, "--https-proxy", httpsProxy)
+	}
+	if len(noProxy) > 0 {
+		commandArgs = append(commandArgs, "--no-proxy", strings.Join(noProxy, ","))
+	}
+	if networkPolicy != nil {
+		commandArgs = append(commandArgs, "--node-cidrs", strings.Join(nodeCIDRs, ","))
+		commandArgs = append(commandArgs, "--api-server-cidrs", strings.Join(apiServerCIDRs, ","))
+		commandArgs = append(commandArgs, "--backend-cidrs", strings.Join(backendCIDRs, ","))
+	}
+	if podInfoOnMount {
+		commandArgs = append(commandArgs, "--pod-info-on-mount")
+	}
+	if len(topologyKeys) > 0 {
+		commandArgs = append(commandArgs, "--topology-keys", strings.Join(topologyKeys, ","))
+	}
+	commandArgs = append(commandArgs, "--kubelet-dir", kubeletDir)
 	commandArgs = append(commandArgs, "--in-cluster=false")
 
 	// Create the install pod
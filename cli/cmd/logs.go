@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -23,6 +22,8 @@ const (
 	logNameEtcd            = "etcd"
 	logNameEtcdPrevious    = "etcd-previous"
 
+	logSuffixPrevious = "-previous"
+
 	logTypeAuto    = "auto"
 	logTypeTrident = "trident"
 	logTypeEtcd    = "etcd"
@@ -35,6 +36,14 @@ var (
 	logType  string
 	archive  bool
 	previous bool
+	sidecars bool
+
+	// sidecarContainers are the CSI sidecars deployed alongside Trident, whose logs are named after them
+	sidecarContainers = []string{
+		config.ContainerCSIProvisioner,
+		config.ContainerCSIAttacher,
+		config.ContainerCSISnapshotter,
+	}
 )
 
 func init() {
@@ -42,6 +51,7 @@ func init() {
 	logsCmd.Flags().StringVarP(&logType, "log", "l", logTypeAuto, "Trident log to display. One of trident|etcd|auto|all")
 	logsCmd.Flags().BoolVarP(&archive, "archive", "a", false, "Create a support archive with all logs unless otherwise specified.")
 	logsCmd.Flags().BoolVarP(&previous, "previous", "p", false, "Get the logs for the previous container instance if it exists.")
+	logsCmd.Flags().BoolVar(&sidecars, "sidecars", false, "Also get the logs for the CSI sidecar containers.")
 }
 
 var logsCmd = &cobra.Command{
@@ -158,29 +168,42 @@ func getLogs(logMap map[string][]byte) error {
 		return errors.New("'tridentctl logs' only supports Trident running in a Kubernetes pod")
 	}
 
+	// Only a failure to get the requested Trident or etcd log is an error, since the previous
+	// and sidecar logs may not exist in every deployment
+	for i, logName := range getLogNames(logType, previous, sidecars) {
+		if logErr := getTridentLogs(logName, logMap); i == 0 && logType != logTypeAll {
+			err = logErr
+		}
+	}
+
+	return err
+}
+
+// getLogNames returns the names of the logs to get for a log type, starting with the primary log.
+func getLogNames(logType string, previous, sidecars bool) []string {
+
+	var logNames []string
+
 	switch logType {
 	case logTypeTrident, logTypeAuto:
-		err = getTridentLogs(logNameTrident, logMap)
+		logNames = []string{logNameTrident}
 	case logTypeEtcd:
-		err = getTridentLogs(logNameEtcd, logMap)
+		logNames = []string{logNameEtcd}
 	case logTypeAll:
-		getTridentLogs(logNameTrident, logMap)
-		getTridentLogs(logNameEtcd, logMap)
+		logNames = []string{logNameTrident, logNameEtcd}
+	}
+
+	if sidecars {
+		logNames = append(logNames, sidecarContainers...)
 	}
 
 	if previous {
-		switch logType {
-		case logTypeTrident, logTypeAuto:
-			getTridentLogs(logNameTridentPrevious, logMap)
-		case logTypeEtcd:
-			getTridentLogs(logNameEtcdPrevious, logMap)
-		case logTypeAll:
-			getTridentLogs(logNameTridentPrevious, logMap)
-			getTridentLogs(logNameEtcdPrevious, logMap)
+		for _, logName := range logNames {
+			logNames = append(logNames, logName+logSuffixPrevious)
 		}
 	}
 
-	return err
+	return logNames
 }
 
 func checkValidLog() error {
@@ -207,7 +230,11 @@ func getTridentLogs(logName string, logMap map[string][]byte) error {
 	case logNameEtcdPrevious:
 		container, prev = config.ContainerEtcd, true
 	default:
-		return fmt.Errorf("%s is not a valid Trident log", logName)
+		container = strings.TrimSuffix(logName, logSuffixPrevious)
+		prev = container != logName
+		if !isSidecarContainer(container) {
+			return fmt.Errorf("%s is not a valid Trident log", logName)
+		}
 	}
 
 	// Build command to get K8S logs
@@ -220,7 +247,7 @@ func getTridentLogs(logName string, logMap map[string][]byte) error {
 	}
 
 	// Get logs
-	logBytes, err := exec.Command(KubernetesCLI, logsCommand...).CombinedOutput()
+	logBytes, err := kubeCommand(logsCommand...).CombinedOutput()
 	if err != nil {
 		logMap["error"] = appendError(logMap["error"], logBytes)
 	} else {
@@ -229,6 +256,15 @@ func getTridentLogs(logName string, logMap map[string][]byte) error {
 	return err
 }
 
+func isSidecarContainer(container string) bool {
+	for _, sidecar := range sidecarContainers {
+		if container == sidecar {
+			return true
+		}
+	}
+	return false
+}
+
 func appendError(oldErrors, newError []byte) []byte {
 
 	if len(oldErrors) == 0 {
@@ -0,0 +1,35 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetLogNames(t *testing.T) {
+
+	tests := []struct {
+		logType  string
+		previous bool
+		sidecars bool
+		expected []string
+	}{
+		{logTypeAuto, false, false, []string{"trident"}},
+		{logTypeTrident, true, false, []string{"trident", "trident-previous"}},
+		{logTypeAll, false, false, []string{"trident", "etcd"}},
+		{logTypeAuto, false, true, []string{"trident", "csi-provisioner", "csi-attacher", "csi-snapshotter"}},
+		{logTypeTrident, true, true, []string{
+			"trident", "csi-provisioner", "csi-attacher", "csi-snapshotter",
+			"trident-previous", "csi-provisioner-previous", "csi-attacher-previous", "csi-snapshotter-previous",
+		}},
+	}
+
+	for _, test := range tests {
+		logNames := getLogNames(test.logType, test.previous, test.sidecars)
+		if !reflect.DeepEqual(logNames, test.expected) {
+			t.Errorf("Expected logs %v for type %s (previous=%v, sidecars=%v), got %v",
+				test.expected, test.logType, test.previous, test.sidecars, logNames)
+		}
+	}
+}
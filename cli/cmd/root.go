@@ -3,9 +3,11 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/exec"
@@ -13,6 +15,7 @@ import (
 	"syscall"
 
 	"github.com/netapp/trident/cli/api"
+	k8sclient "github.com/netapp/trident/cli/k8s_client"
 	"github.com/netapp/trident/config"
 	"github.com/spf13/cobra"
 	k8s "k8s.io/api/core/v1"
@@ -31,7 +34,7 @@ const (
 	CLIKubernetes = "kubectl"
 	CLIOpenshift  = "oc"
 
-	PodServer = "127.0.0.1:8000"
+	PodServer = "127.0.0.1:" + k8sclient.RESTPort
 
 	ExitCodeSuccess = 0
 	ExitCodeFailure = 1
@@ -62,18 +65,24 @@ var (
 	KubernetesCLI       string
 	TridentPodName      string
 	TridentPodNamespace string
+	KubeContext         string
 	ExitCode            int
 
 	Debug        bool
 	Server       string
 	OutputFormat string
+
+	TLSCACertFile string
+	TLSCertFile   string
+	TLSKeyFile    string
 )
 
 var RootCmd = &cobra.Command{
-	SilenceUsage: true,
-	Use:          "tridentctl",
-	Short:        "A CLI tool for NetApp Trident",
-	Long:         `A CLI tool for managing the NetApp Trident external storage provisioner for Kubernetes`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Use:           "tridentctl",
+	Short:         "A CLI tool for NetApp Trident",
+	Long:          `A CLI tool for managing the NetApp Trident external storage provisioner for Kubernetes`,
 }
 
 func init() {
@@ -81,6 +90,13 @@ func init() {
 	RootCmd.PersistentFlags().StringVarP(&Server, "server", "s", "", "Address/port of Trident REST interface")
 	RootCmd.PersistentFlags().StringVarP(&OutputFormat, "output", "o", "", "Output format. One of json|yaml|name|wide|ps (default)")
 	RootCmd.PersistentFlags().StringVarP(&TridentPodNamespace, "namespace", "n", "", "Namespace of Trident deployment")
+	RootCmd.PersistentFlags().StringVar(&KubeContext, "context", "", "Name of the kubeconfig context to use")
+	RootCmd.PersistentFlags().StringVar(&TLSCACertFile, "cacert", "",
+		"CA certificate file used to verify the Trident HTTPS REST interface")
+	RootCmd.PersistentFlags().StringVar(&TLSCertFile, "cert", "",
+		"Client certificate file presented to the Trident HTTPS REST interface")
+	RootCmd.PersistentFlags().StringVar(&TLSKeyFile, "key", "",
+		"Client key file presented to the Trident HTTPS REST interface")
 }
 
 func discoverOperatingMode(cmd *cobra.Command) error {
@@ -94,11 +110,16 @@ func discoverOperatingMode(cmd *cobra.Command) error {
 		case ModeDirect:
 			fmt.Printf("Operating mode = %s, Server = %s\n", OperatingMode, Server)
 		case ModeTunnel:
-			fmt.Printf("Operating mode = %s, Trident pod = %s, Namespace = %s, CLI = %s\n",
-				OperatingMode, TridentPodName, TridentPodNamespace, KubernetesCLI)
+			fmt.Printf("Operating mode = %s, Trident pod = %s, Namespace = %s, CLI = %s, Context = %s\n",
+				OperatingMode, TridentPodName, TridentPodNamespace, KubernetesCLI, KubeContext)
 		}
 	}()
 
+	// Validate any TLS files up front, so a bad path isn't reported as a connection failure
+	if err := api.SetTLSConfig(TLSCACertFile, TLSCertFile, TLSKeyFile); err != nil {
+		return err
+	}
+
 	var err error
 
 	envServer := os.Getenv("TRIDENT_SERVER")
@@ -146,14 +167,14 @@ func discoverOperatingMode(cmd *cobra.Command) error {
 func discoverKubernetesCLI() error {
 
 	// Try the OpenShift CLI first
-	_, err := exec.Command(CLIOpenshift, "version").CombinedOutput()
+	_, err := exec.Command(CLIOpenshift, append(k8sclient.KubeContextArgs(KubeContext), "version")...).CombinedOutput()
 	if GetExitCodeFromError(err) == ExitCodeSuccess {
 		KubernetesCLI = CLIOpenshift
 		return nil
 	}
 
 	// Fall back to the K8S CLI
-	_, err = exec.Command(CLIKubernetes, "version").CombinedOutput()
+	_, err = exec.Command(CLIKubernetes, append(k8sclient.KubeContextArgs(KubeContext), "version")...).CombinedOutput()
 	if GetExitCodeFromError(err) == ExitCodeSuccess {
 		KubernetesCLI = CLIKubernetes
 		return nil
@@ -162,11 +183,21 @@ func discoverKubernetesCLI() error {
 	return errors.New("could not find the Kubernetes CLI")
 }
 
-// getCurrentNamespace returns the default namespace from service account info
+// kubeCommand returns a Kubernetes CLI command that targets the selected kubeconfig context.
+func kubeCommand(args ...string) *exec.Cmd {
+	return exec.Command(KubernetesCLI, append(k8sclient.KubeContextArgs(KubeContext), args...)...)
+}
+
+// getCurrentNamespace returns the default namespace from service account info, or the namespace
+// of the selected kubeconfig context
 func getCurrentNamespace() (string, error) {
 
+	if KubeContext != "" {
+		return k8sclient.GetKubeContextNamespace(KubeContext)
+	}
+
 	// Get current namespace from service account info
-	cmd := exec.Command(KubernetesCLI, "get", "serviceaccount", "default", "-o=json")
+	cmd := kubeCommand("get", "serviceaccount", "default", "-o=json")
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return "", err
@@ -195,8 +226,7 @@ func getCurrentNamespace() (string, error) {
 func getTridentPod(namespace, appLabel string) (string, error) {
 
 	// Get 'trident' pod info
-	cmd := exec.Command(
-		KubernetesCLI,
+	cmd := kubeCommand(
 		"get", "pod",
 		"-n", namespace,
 		"-l", appLabel,
@@ -232,7 +262,12 @@ func getTridentPod(namespace, appLabel string) (string, error) {
 
 func GetBaseURL() (string, error) {
 
-	url := fmt.Sprintf("http://%s%s", Server, config.BaseURL)
+	// The HTTPS REST interface is used if any TLS files were specified
+	scheme := "http"
+	if api.TLSConfigured() {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s%s", scheme, Server, config.BaseURL)
 
 	if Debug {
 		fmt.Printf("Trident URL: %s\n", url)
@@ -264,7 +299,7 @@ func TunnelCommand(commandArgs []string) {
 	}
 
 	// Invoke tridentctl inside the Trident pod
-	out, err := exec.Command(KubernetesCLI, execCommand...).CombinedOutput()
+	out, err := kubeCommand(execCommand...).CombinedOutput()
 
 	SetExitCodeFromError(err)
 	if err != nil {
@@ -291,12 +326,49 @@ func TunnelCommandRaw(commandArgs []string) ([]byte, error) {
 	}
 
 	// Invoke tridentctl inside the Trident pod
-	output, err := exec.Command(KubernetesCLI, execCommand...).CombinedOutput()
+	output, err := kubeCommand(execCommand...).CombinedOutput()
 
 	SetExitCodeFromError(err)
 	return output, err
 }
 
+// TunnelCommandWithInput is like TunnelCommand, but also streams the supplied data to the
+// standard input of the tunneled command.  This avoids passing large documents as arguments.
+func TunnelCommandWithInput(commandArgs []string, input []byte) {
+
+	// Build tunnel command to exec command in container
+	execCommand := []string{"exec", "-i", TridentPodName, "-n", TridentPodNamespace, "-c", config.ContainerTrident, "--"}
+
+	// Build CLI command
+	cliCommand := []string{"tridentctl", "-s", Server}
+	if Debug {
+		cliCommand = append(cliCommand, "--debug")
+	}
+	if OutputFormat != "" {
+		cliCommand = append(cliCommand, []string{"--output", OutputFormat}...)
+	}
+	cliCommand = append(cliCommand, commandArgs...)
+
+	// Combine tunnel and CLI commands
+	execCommand = append(execCommand, cliCommand...)
+
+	if Debug {
+		fmt.Printf("Invoking tunneled command: %s %v\n", KubernetesCLI, strings.Join(execCommand, " "))
+	}
+
+	// Invoke tridentctl inside the Trident pod
+	cmd := kubeCommand(execCommand...)
+	cmd.Stdin = bytes.NewReader(input)
+	out, err := cmd.CombinedOutput()
+
+	SetExitCodeFromError(err)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s", string(out))
+	} else {
+		fmt.Print(string(out))
+	}
+}
+
 func GetErrorFromHTTPResponse(response *http.Response, responseBody []byte) error {
 
 	var errorResponse api.ErrorResponse
@@ -306,6 +378,19 @@ func GetErrorFromHTTPResponse(response *http.Response, responseBody []byte) erro
 	return errors.New(response.Status)
 }
 
+// WriteError reports a failed command.  In the JSON and YAML output formats the error is written
+// as a structured document, so that scripts parsing the output can handle failures too.
+func WriteError(w io.Writer, err error) {
+	switch OutputFormat {
+	case FormatJSON:
+		fprintJSON(w, api.ErrorResponse{Error: err.Error()})
+	case FormatYAML:
+		fprintYAML(w, api.ErrorResponse{Error: err.Error()})
+	default:
+		fmt.Fprintf(w, "Error: %v\n", err)
+	}
+}
+
 func SetExitCodeFromError(err error) {
 	ExitCode = GetExitCodeFromError(err)
 }
@@ -0,0 +1,113 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+)
+
+func newSnapshotServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/snapshot/vol1/snap1":
+			response := rest.GetSnapshotResponse{
+				Snapshot: &storage.SnapshotExternal{
+					Snapshot: storage.Snapshot{
+						Config: &storage.SnapshotConfig{Name: "snap1", VolumeName: "vol1"},
+					},
+				},
+			}
+			json.NewEncoder(w).Encode(response)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(api.ErrorResponse{Error: "snapshot was not found"})
+		}
+	}))
+}
+
+func TestGetSnapshotJSON(t *testing.T) {
+
+	server := newSnapshotServer()
+	defer server.Close()
+
+	snapshot, err := GetSnapshot(server.URL, "vol1/snap1")
+	if err != nil {
+		t.Fatalf("Unexpected error getting snapshot: %v", err)
+	}
+
+	var output bytes.Buffer
+	fprintJSON(&output, api.MultipleSnapshotResponse{Items: []storage.SnapshotExternal{snapshot}})
+
+	var response api.MultipleSnapshotResponse
+	if err := json.Unmarshal(output.Bytes(), &response); err != nil {
+		t.Fatalf("Expected valid JSON output, got %s: %v", output.String(), err)
+	}
+	if len(response.Items) != 1 || response.Items[0].Config.Name != "snap1" {
+		t.Errorf("Expected snapshot snap1 in output, got %s", output.String())
+	}
+}
+
+func TestWriteError(t *testing.T) {
+
+	server := newSnapshotServer()
+	defer server.Close()
+
+	_, getErr := GetSnapshot(server.URL, "vol1/snap2")
+	if getErr == nil {
+		t.Fatal("Expected error getting a missing snapshot")
+	}
+
+	savedOutputFormat := OutputFormat
+	defer func() { OutputFormat = savedOutputFormat }()
+
+	// JSON output is a structured error document
+	OutputFormat = FormatJSON
+	var output bytes.Buffer
+	WriteError(&output, getErr)
+
+	var errorResponse api.ErrorResponse
+	if err := json.Unmarshal(output.Bytes(), &errorResponse); err != nil {
+		t.Fatalf("Expected valid JSON error, got %s: %v", output.String(), err)
+	}
+	if errorResponse.Error != getErr.Error() {
+		t.Errorf("Expected error %q, got %q", getErr.Error(), errorResponse.Error)
+	}
+	if !strings.Contains(errorResponse.Error, "snapshot was not found") {
+		t.Errorf("Expected server error in message, got %q", errorResponse.Error)
+	}
+
+	// YAML output is a structured error document
+	OutputFormat = FormatYAML
+	output.Reset()
+	WriteError(&output, getErr)
+
+	errorResponse = api.ErrorResponse{}
+	if err := yaml.Unmarshal(output.Bytes(), &errorResponse); err != nil {
+		t.Fatalf("Expected valid YAML error, got %s: %v", output.String(), err)
+	}
+	if errorResponse.Error != getErr.Error() {
+		t.Errorf("Expected error %q, got %q", getErr.Error(), errorResponse.Error)
+	}
+
+	// Other formats keep the plain text error
+	for _, format := range []string{"", FormatName, FormatWide} {
+		OutputFormat = format
+		output.Reset()
+		WriteError(&output, getErr)
+
+		if output.String() != "Error: "+getErr.Error()+"\n" {
+			t.Errorf("Expected plain text error for format %q, got %s", format, output.String())
+		}
+	}
+}
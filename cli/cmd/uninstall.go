@@ -331,6 +331,30 @@ func uninstallTrident() error {
 			}
 		}
 
+		// If OpenShift, delete the route to the HTTPS REST interface
+		if client.Flavor() == k8sclient.FlavorOpenShift {
+			routeYAML := k8sclient.GetOpenShiftRouteYAML(TridentPodNamespace, appLabelValue)
+			if err := client.DeleteObjectByYAML(routeYAML, true); err != nil {
+				log.WithField("error", err).Warning("Could not delete Trident route.")
+				anyErrors = true
+			} else {
+				log.Info("Deleted Trident route.")
+			}
+		}
+
+		// Delete the network policy, which exists only if it was requested at installation
+		networkPolicyYAML := k8sclient.GetNetworkPolicyYAML(&k8sclient.InstallOptions{
+			Namespace: TridentPodNamespace,
+			Label:     appLabelValue,
+			NodeLabel: TridentNodeLabelValue,
+		})
+		if err := client.DeleteObjectByYAML(networkPolicyYAML, true); err != nil {
+			log.WithField("error", err).Warning("Could not delete Trident network policy.")
+			anyErrors = true
+		} else {
+			log.Info("Deleted Trident network policy.")
+		}
+
 		if secret, err := client.GetSecretByLabel(appLabel, true); err != nil {
 
 			log.WithFields(log.Fields{
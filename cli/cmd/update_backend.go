@@ -7,7 +7,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -17,14 +20,26 @@ import (
 )
 
 var (
-	updateFilename   string
-	updateBase64Data string
+	updateFilename             string
+	updateBase64Data           string
+	updateBackendState         string
+	updateBackendUsername      string
+	updateBackendPasswordFile  string
+	updateBackendPasswordStdin bool
 )
 
 func init() {
 	updateCmd.AddCommand(updateBackendCmd)
 	updateBackendCmd.Flags().StringVarP(&updateFilename, "filename", "f", "", "Path to YAML or JSON file")
 	updateBackendCmd.Flags().StringVarP(&updateBase64Data, "base64", "", "", "Base64 encoding")
+	updateBackendCmd.Flags().StringVarP(&updateBackendState, "state", "", "",
+		"New backend state. One of online|offline")
+	updateBackendCmd.Flags().StringVarP(&updateBackendUsername, "username", "", "",
+		"New backend username, used with --password-file or --password-stdin")
+	updateBackendCmd.Flags().StringVarP(&updateBackendPasswordFile, "password-file", "", "",
+		"Path to a file containing the new backend password; the rest of the backend definition is left unchanged")
+	updateBackendCmd.Flags().BoolVarP(&updateBackendPasswordStdin, "password-stdin", "", false,
+		"Read the new backend password from stdin; the rest of the backend definition is left unchanged")
 	updateBackendCmd.Flags().MarkHidden("base64")
 }
 
@@ -34,6 +49,49 @@ var updateBackendCmd = &cobra.Command{
 	Aliases: []string{"b"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 
+		// Taking a backend offline stops new volumes from being provisioned on it, while
+		// its existing volumes continue to be served
+		if updateBackendState != "" {
+			if updateFilename != "" || updateBase64Data != "" {
+				return errors.New("a backend state may not be combined with a backend definition")
+			}
+			newBackendState, err := getUpdateBackendState(updateBackendState)
+			if err != nil {
+				return err
+			}
+
+			if OperatingMode == ModeTunnel {
+				command := []string{"update", "backend", "--state", newBackendState}
+				TunnelCommand(append(command, args...))
+				return nil
+			} else {
+				return backendUpdateState(args, newBackendState)
+			}
+		}
+
+		// Rotating credentials rewrites only the credential fields in the stored backend config.
+		// The password is never accepted as an argument, where other users could see it.
+		if updateBackendPasswordFile != "" || updateBackendPasswordStdin || updateBackendUsername != "" {
+			if updateFilename != "" || updateBase64Data != "" {
+				return errors.New("backend credentials may not be combined with a backend definition")
+			}
+			password, err := getBackendPassword(updateBackendPasswordFile, updateBackendPasswordStdin)
+			if err != nil {
+				return err
+			}
+
+			if OperatingMode == ModeTunnel {
+				command := []string{"update", "backend", "--password-stdin"}
+				if updateBackendUsername != "" {
+					command = append(command, "--username", updateBackendUsername)
+				}
+				TunnelCommandWithInput(append(command, args...), []byte(password))
+				return nil
+			} else {
+				return backendUpdateCredentials(args, updateBackendUsername, password)
+			}
+		}
+
 		jsonData, err := getBackendData(updateFilename, updateBase64Data)
 		if err != nil {
 			return err
@@ -52,6 +110,46 @@ var updateBackendCmd = &cobra.Command{
 	},
 }
 
+// getBackendPassword reads a new backend password from a file or from stdin.  A trailing newline,
+// as most editors and echo add, is not part of the password.
+func getBackendPassword(passwordFile string, passwordStdin bool) (string, error) {
+
+	var (
+		rawData []byte
+		err     error
+	)
+
+	switch {
+	case passwordFile != "" && passwordStdin:
+		return "", errors.New("only one of --password-file and --password-stdin may be specified")
+	case passwordFile != "":
+		rawData, err = ioutil.ReadFile(passwordFile)
+	case passwordStdin:
+		rawData, err = ioutil.ReadAll(os.Stdin)
+	default:
+		return "", errors.New("a new backend password must be specified with --password-file or --password-stdin")
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read the backend password; %v", err)
+	}
+
+	password := strings.TrimRight(string(rawData), "\r\n")
+	if password == "" {
+		return "", errors.New("the new backend password is empty")
+	}
+	return password, nil
+}
+
+func getUpdateBackendState(state string) (string, error) {
+
+	switch state = strings.ToLower(state); storage.BackendState(state) {
+	case storage.Online, storage.Offline:
+		return state, nil
+	default:
+		return "", fmt.Errorf("invalid backend state %s; must be one of online|offline", state)
+	}
+}
+
 func backendUpdate(backendNames []string, postData []byte) error {
 
 	switch len(backendNames) {
@@ -99,3 +197,62 @@ func backendUpdate(backendNames []string, postData []byte) error {
 
 	return nil
 }
+
+func backendUpdateCredentials(backendNames []string, username, password string) error {
+
+	switch len(backendNames) {
+	case 0:
+		return errors.New("backend name not specified")
+	case 1:
+		break
+	default:
+		return errors.New("multiple backend names specified")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	backend, err := UpdateBackendCredentials(baseURL, backendNames[0], username, password)
+	if err != nil {
+		return err
+	}
+
+	WriteBackends([]storage.BackendExternal{backend})
+
+	return nil
+}
+
+// UpdateBackendCredentials replaces a backend's credentials and returns the updated backend.
+func UpdateBackendCredentials(baseURL, backendName, username, password string) (storage.BackendExternal, error) {
+
+	// Send the new credentials to Trident
+	url := baseURL + "/backend/" + backendName + "/credentials"
+
+	request := storage.UpdateBackendCredentialsRequest{
+		Username: username,
+		Password: password,
+	}
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return storage.BackendExternal{}, err
+	}
+
+	response, responseBody, err := api.InvokeRESTAPI("PATCH", url, requestBytes, Debug)
+	if err != nil {
+		return storage.BackendExternal{}, err
+	} else if response.StatusCode != http.StatusOK {
+		return storage.BackendExternal{}, fmt.Errorf("could not update credentials for backend %s: %v",
+			backendName, GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var updateBackendResponse rest.UpdateBackendResponse
+	err = json.Unmarshal(responseBody, &updateBackendResponse)
+	if err != nil {
+		return storage.BackendExternal{}, err
+	}
+
+	// Retrieve the updated backend
+	return GetBackend(baseURL, updateBackendResponse.BackendID)
+}
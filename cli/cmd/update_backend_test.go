@@ -0,0 +1,54 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetBackendPassword(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tridentctl-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writePassword := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		stdin    bool
+		password string
+		valid    bool
+	}{
+		{"file", writePassword("plain", "s3cret"), false, "s3cret", true},
+		{"trailing newline", writePassword("newline", "s3cret\r\n"), false, "s3cret", true},
+		{"empty file", writePassword("empty", "\n"), false, "", false},
+		{"missing file", filepath.Join(dir, "missing"), false, "", false},
+		{"file and stdin", writePassword("both", "s3cret"), true, "", false},
+		{"neither", "", false, "", false},
+	}
+
+	for _, test := range tests {
+		password, err := getBackendPassword(test.file, test.stdin)
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+		if password != test.password {
+			t.Errorf("%s: expected password %q, got %q", test.name, test.password, password)
+		}
+	}
+}
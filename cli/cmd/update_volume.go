@@ -0,0 +1,198 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netapp/trident/cli/api"
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
+)
+
+var (
+	volumeAccessMode         string
+	volumeDeletionProtection bool
+)
+
+func init() {
+	updateCmd.AddCommand(updateVolumeCmd)
+	updateVolumeCmd.Flags().StringVarP(&volumeAccessMode, "access-mode", "", "",
+		"New volume access mode (ReadWriteOnce|ReadWriteOncePod|ReadOnlyMany|ReadWriteMany, "+
+			"or RWO|RWOP|ROX|RWX)")
+	updateVolumeCmd.Flags().BoolVarP(&volumeDeletionProtection, "deletion-protection", "", false,
+		"Protect the volume from deletion (true|false)")
+}
+
+var updateVolumeCmd = &cobra.Command{
+	Use:     "volume <name>",
+	Short:   "Update a volume in Trident",
+	Aliases: []string{"v"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		updateDeletionProtection := cmd.Flags().Changed("deletion-protection")
+		if volumeAccessMode != "" && updateDeletionProtection {
+			return errors.New("the access mode and deletion protection must be updated separately")
+		}
+
+		if updateDeletionProtection {
+			if OperatingMode == ModeTunnel {
+				command := []string{
+					"update", "volume",
+					fmt.Sprintf("--deletion-protection=%v", volumeDeletionProtection),
+				}
+				TunnelCommand(append(command, args...))
+				return nil
+			} else {
+				return volumeUpdateDeletionProtection(args, volumeDeletionProtection)
+			}
+		}
+
+		accessMode, err := getVolumeAccessMode()
+		if err != nil {
+			return err
+		}
+
+		if OperatingMode == ModeTunnel {
+			command := []string{
+				"update", "volume", "--access-mode", volumeAccessMode,
+			}
+			TunnelCommand(append(command, args...))
+			return nil
+		} else {
+			return volumeUpdateAccessMode(args, accessMode)
+		}
+	},
+}
+
+func getVolumeAccessMode() (config.AccessMode, error) {
+
+	switch strings.ToLower(volumeAccessMode) {
+	case "":
+		return config.ModeAny, errors.New("no access mode was specified")
+	case "rwo", strings.ToLower(string(config.ReadWriteOnce)):
+		return config.ReadWriteOnce, nil
+	case "rwop", strings.ToLower(string(config.ReadWriteOncePod)):
+		return config.ReadWriteOncePod, nil
+	case "rox", strings.ToLower(string(config.ReadOnlyMany)):
+		return config.ReadOnlyMany, nil
+	case "rwx", strings.ToLower(string(config.ReadWriteMany)):
+		return config.ReadWriteMany, nil
+	default:
+		return config.ModeAny, fmt.Errorf("invalid access mode: %s", volumeAccessMode)
+	}
+}
+
+func volumeUpdateAccessMode(volumeNames []string, accessMode config.AccessMode) error {
+
+	switch len(volumeNames) {
+	case 0:
+		return errors.New("volume name not specified")
+	case 1:
+		break
+	default:
+		return errors.New("multiple volume names specified")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	// Send the new access mode to Trident
+	url := baseURL + "/volume/" + volumeNames[0] + "/accessMode"
+
+	request := storage.UpdateVolumeAccessModeRequest{
+		AccessMode: accessMode,
+	}
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	response, responseBody, err := api.InvokeRESTAPI("POST", url, requestBytes, Debug)
+	if err != nil {
+		return err
+	} else if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not update access mode for volume %s: %v", volumeNames[0],
+			GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var updateVolumeResponse rest.UpdateVolumeResponse
+	err = json.Unmarshal(responseBody, &updateVolumeResponse)
+	if err != nil {
+		return err
+	} else if updateVolumeResponse.Volume == nil {
+		return fmt.Errorf("could not update access mode for volume %s: no volume returned", volumeNames[0])
+	}
+
+	WriteVolumes([]storage.VolumeExternal{*updateVolumeResponse.Volume})
+
+	return nil
+}
+
+func volumeUpdateDeletionProtection(volumeNames []string, protected bool) error {
+
+	switch len(volumeNames) {
+	case 0:
+		return errors.New("volume name not specified")
+	case 1:
+		break
+	default:
+		return errors.New("multiple volume names specified")
+	}
+
+	baseURL, err := GetBaseURL()
+	if err != nil {
+		return err
+	}
+
+	volume, err := UpdateVolumeDeletionProtection(baseURL, volumeNames[0], protected)
+	if err != nil {
+		return err
+	}
+
+	WriteVolumes([]storage.VolumeExternal{*volume})
+
+	return nil
+}
+
+// UpdateVolumeDeletionProtection sets or clears a volume's deletion protection.
+func UpdateVolumeDeletionProtection(baseURL, volumeName string, protected bool) (*storage.VolumeExternal, error) {
+
+	url := baseURL + "/volume/" + volumeName + "/deletionProtection"
+
+	request := storage.UpdateVolumeDeletionProtectionRequest{
+		DeletionProtection: protected,
+	}
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	response, responseBody, err := api.InvokeRESTAPI("POST", url, requestBytes, Debug)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not update deletion protection for volume %s: %v", volumeName,
+			GetErrorFromHTTPResponse(response, responseBody))
+	}
+
+	var updateVolumeResponse rest.UpdateVolumeResponse
+	err = json.Unmarshal(responseBody, &updateVolumeResponse)
+	if err != nil {
+		return nil, err
+	} else if updateVolumeResponse.Volume == nil {
+		return nil, fmt.Errorf("could not update deletion protection for volume %s: no volume returned",
+			volumeName)
+	}
+
+	return updateVolumeResponse.Volume, nil
+}
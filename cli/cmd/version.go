@@ -62,7 +62,7 @@ var versionCmd = &cobra.Command{
 			}
 
 			// Add the client version, which is always hardcoded at compile time
-			versions := addClientVersion(parsedServerVersion)
+			versions := addClientVersion(parsedServerVersion, serverVersion.APIVersion)
 
 			writeVersions(versions)
 		}
@@ -71,7 +71,7 @@ var versionCmd = &cobra.Command{
 	},
 }
 
-// getVersion retrieves the Trident server version directly using the REST API
+// getVersionFromRest retrieves the Trident server version directly using the REST API
 func getVersionFromRest() (rest.GetVersionResponse, error) {
 
 	baseURL, err := GetBaseURL()
@@ -79,6 +79,12 @@ func getVersionFromRest() (rest.GetVersionResponse, error) {
 		return rest.GetVersionResponse{}, err
 	}
 
+	return GetServerVersion(baseURL)
+}
+
+// GetServerVersion retrieves the version and API version of the Trident server.
+func GetServerVersion(baseURL string) (rest.GetVersionResponse, error) {
+
 	url := baseURL + "/version"
 
 	response, responseBody, err := api.InvokeRESTAPI("GET", url, nil, Debug)
@@ -121,7 +127,8 @@ func getVersionFromTunnel() (rest.GetVersionResponse, error) {
 	}
 
 	version := rest.GetVersionResponse{
-		Version: tunnelVersionResponse.Server.Version,
+		Version:    tunnelVersionResponse.Server.Version,
+		APIVersion: tunnelVersionResponse.Server.APIVersion,
 	}
 	return version, nil
 }
@@ -140,8 +147,9 @@ func getClientVersion() *api.ClientVersionResponse {
 	}
 }
 
-// addClientVersion accepts the server version and fills in the client version
-func addClientVersion(serverVersion *utils.Version) *api.VersionResponse {
+// addClientVersion accepts the server version and API version and fills in the client version.
+// Servers that don't report their API version are assumed to match the client's.
+func addClientVersion(serverVersion *utils.Version, serverAPIVersion string) *api.VersionResponse {
 
 	versions := api.VersionResponse{}
 
@@ -151,7 +159,10 @@ func addClientVersion(serverVersion *utils.Version) *api.VersionResponse {
 	versions.Server.PatchVersion = serverVersion.PatchVersion()
 	versions.Server.PreRelease = serverVersion.PreRelease()
 	versions.Server.BuildMetadata = serverVersion.BuildMetadata()
-	versions.Server.APIVersion = config.OrchestratorAPIVersion
+	versions.Server.APIVersion = serverAPIVersion
+	if serverAPIVersion == "" {
+		versions.Server.APIVersion = config.OrchestratorAPIVersion
+	}
 
 	versions.Client = getClientVersion().Client
 
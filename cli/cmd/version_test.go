@@ -0,0 +1,49 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/utils"
+)
+
+func TestGetServerVersion(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/version" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(rest.GetVersionResponse{Version: "19.10.0", APIVersion: "2"})
+	}))
+	defer server.Close()
+
+	serverVersion, err := GetServerVersion(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the server version: %v", err)
+	}
+
+	versions := addClientVersion(utils.MustParseDate(serverVersion.Version), serverVersion.APIVersion)
+	if versions.Server.Version != "19.10.0" || versions.Server.MinorVersion != 10 {
+		t.Errorf("Expected server version 19.10.0, got %+v", versions.Server)
+	}
+	if versions.Server.APIVersion != "2" {
+		t.Errorf("Expected server API version 2, got %s", versions.Server.APIVersion)
+	}
+	if versions.Client.Version != config.OrchestratorVersion.String() {
+		t.Errorf("Expected client version %s, got %s", config.OrchestratorVersion.String(), versions.Client.Version)
+	}
+
+	// A server that doesn't report its API version is assumed to match the client
+	versions = addClientVersion(utils.MustParseDate("19.04.0"), "")
+	if versions.Server.APIVersion != config.OrchestratorAPIVersion {
+		t.Errorf("Expected server API version %s, got %s", config.OrchestratorAPIVersion,
+			versions.Server.APIVersion)
+	}
+}
@@ -18,9 +18,11 @@ import (
 	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/tools/clientcmd"
+
 	tridentconfig "github.com/netapp/trident/config"
 	"github.com/netapp/trident/utils"
-	"k8s.io/apimachinery/pkg/version"
 )
 
 type KubectlClient struct {
@@ -28,12 +30,16 @@ type KubectlClient struct {
 	flavor    OrchestratorFlavor
 	version   *utils.Version
 	namespace string
+	context   string
 }
 
-func NewKubectlClient(namespace string) (Interface, error) {
+// NewKubectlClient returns a client that invokes the Kubernetes CLI.  If kubeContext is specified,
+// the CLI targets that kubeconfig context instead of the current one.  As with kubectl itself, the
+// kubeconfig files are found using the KUBECONFIG environment variable, if set.
+func NewKubectlClient(namespace, kubeContext string) (Interface, error) {
 
 	// Discover which CLI to use (kubectl or oc)
-	cli, err := discoverKubernetesCLI()
+	cli, err := discoverKubernetesCLI(kubeContext)
 	if err != nil {
 		return nil, err
 	}
@@ -47,10 +53,10 @@ func NewKubectlClient(namespace string) (Interface, error) {
 		fallthrough
 	case CLIKubernetes:
 		flavor = FlavorKubernetes
-		k8sVersion, err = discoverKubernetesServerVersion(cli)
+		k8sVersion, err = discoverKubernetesServerVersion(cli, kubeContext)
 	case CLIOpenShift:
 		flavor = FlavorOpenShift
-		k8sVersion, err = discoverOpenShiftServerVersion(cli)
+		k8sVersion, err = discoverOpenShiftServerVersion(cli, kubeContext)
 	}
 	if err != nil {
 		return nil, err
@@ -71,6 +77,7 @@ func NewKubectlClient(namespace string) (Interface, error) {
 		flavor:    flavor,
 		version:   k8sVersion,
 		namespace: namespace,
+		context:   kubeContext,
 	}
 
 	// Get current namespace if one wasn't specified
@@ -86,21 +93,44 @@ func NewKubectlClient(namespace string) (Interface, error) {
 		"flavor":    flavor,
 		"version":   k8sVersion.String(),
 		"namespace": client.namespace,
+		"context":   kubeContext,
 	}).Debug("Initialized Kubernetes CLI client.")
 
 	return client, nil
 }
 
-func discoverKubernetesCLI() (string, error) {
+// KubeContextArgs returns the CLI arguments that select a kubeconfig context, if one is specified.
+func KubeContextArgs(kubeContext string) []string {
+	if kubeContext == "" {
+		return []string{}
+	}
+	return []string{"--context", kubeContext}
+}
+
+// GetKubeContextNamespace returns the namespace of a kubeconfig context, or the current context
+// if none is specified.  The kubeconfig files are found the same way kubectl finds them.
+func GetKubeContextNamespace(kubeContext string) (string, error) {
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+
+	namespace, _, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).Namespace()
+	if err != nil {
+		return "", fmt.Errorf("could not read namespace of kubeconfig context %s; %v", kubeContext, err)
+	}
+	return namespace, nil
+}
+
+func discoverKubernetesCLI(kubeContext string) (string, error) {
 
 	// Try the OpenShift CLI first
-	_, err := exec.Command(CLIOpenShift, "version").CombinedOutput()
+	_, err := exec.Command(CLIOpenShift, append(KubeContextArgs(kubeContext), "version")...).CombinedOutput()
 	if err == nil {
 		return CLIOpenShift, nil
 	}
 
 	// Fall back to the K8S CLI
-	out, err := exec.Command(CLIKubernetes, "version").CombinedOutput()
+	out, err := exec.Command(CLIKubernetes, append(KubeContextArgs(kubeContext), "version")...).CombinedOutput()
 	if err == nil {
 		return CLIKubernetes, nil
 	}
@@ -108,11 +138,11 @@ func discoverKubernetesCLI() (string, error) {
 	return "", fmt.Errorf("could not find the Kubernetes CLI; %s", string(out))
 }
 
-func discoverKubernetesServerVersion(kubernetesCLI string) (*utils.Version, error) {
+func discoverKubernetesServerVersion(kubernetesCLI, kubeContext string) (*utils.Version, error) {
 
 	const k8SServerVersionPrefix = "Server Version: "
 
-	cmd := exec.Command(kubernetesCLI, "version", "--short")
+	cmd := exec.Command(kubernetesCLI, append(KubeContextArgs(kubeContext), "version", "--short")...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -133,9 +163,9 @@ func discoverKubernetesServerVersion(kubernetesCLI string) (*utils.Version, erro
 	return nil, errors.New("could not get Kubernetes server version")
 }
 
-func discoverOpenShiftServerVersion(kubernetesCLI string) (*utils.Version, error) {
+func discoverOpenShiftServerVersion(kubernetesCLI, kubeContext string) (*utils.Version, error) {
 
-	cmd := exec.Command(kubernetesCLI, "version")
+	cmd := exec.Command(kubernetesCLI, append(KubeContextArgs(kubeContext), "version")...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -190,10 +220,20 @@ func (c *KubectlClient) SetNamespace(namespace string) {
 	c.namespace = namespace
 }
 
+// command returns a CLI command that targets the client's kubeconfig context.
+func (c *KubectlClient) command(args ...string) *exec.Cmd {
+	return exec.Command(c.cli, append(KubeContextArgs(c.context), args...)...)
+}
+
 func (c *KubectlClient) getCurrentNamespace() (string, error) {
 
+	// A specified context may declare its own namespace
+	if c.context != "" {
+		return GetKubeContextNamespace(c.context)
+	}
+
 	// Get current namespace from service account info
-	cmd := exec.Command(c.cli, "get", "serviceaccount", "default", "-o=json")
+	cmd := c.command("get", "serviceaccount", "default", "-o=json")
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return "", err
@@ -233,7 +273,7 @@ func (c *KubectlClient) Exec(podName, containerName string, commandArgs []string
 	log.Debugf("Invoking tunneled command: %s %v", c.cli, strings.Join(execCommand, " "))
 
 	// Invoke command inside the Trident pod
-	return exec.Command(c.cli, execCommand...).CombinedOutput()
+	return c.command(execCommand...).CombinedOutput()
 }
 
 // GetDeploymentByLabel returns a deployment object matching the specified label if it is unique
@@ -263,7 +303,7 @@ func (c *KubectlClient) GetDeploymentsByLabel(label string, allNamespaces bool)
 	} else {
 		cmdArgs = append(cmdArgs, "--namespace", c.namespace)
 	}
-	cmd := exec.Command(c.cli, cmdArgs...)
+	cmd := c.command(cmdArgs...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -307,7 +347,7 @@ func (c *KubectlClient) CheckDeploymentExistsByLabel(label string, allNamespaces
 func (c *KubectlClient) DeleteDeploymentByLabel(label string) error {
 
 	cmdArgs := []string{"delete", "deployment", "-l", label, "--namespace", c.namespace}
-	out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+	out, err := c.command(cmdArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -347,7 +387,7 @@ func (c *KubectlClient) GetServicesByLabel(label string, allNamespaces bool) ([]
 	} else {
 		cmdArgs = append(cmdArgs, "--namespace", c.namespace)
 	}
-	cmd := exec.Command(c.cli, cmdArgs...)
+	cmd := c.command(cmdArgs...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -391,7 +431,7 @@ func (c *KubectlClient) CheckServiceExistsByLabel(label string, allNamespaces bo
 func (c *KubectlClient) DeleteServiceByLabel(label string) error {
 
 	cmdArgs := []string{"delete", "service", "-l", label, "--namespace", c.namespace}
-	out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+	out, err := c.command(cmdArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -431,7 +471,7 @@ func (c *KubectlClient) GetStatefulSetsByLabel(label string, allNamespaces bool)
 	} else {
 		cmdArgs = append(cmdArgs, "--namespace", c.namespace)
 	}
-	cmd := exec.Command(c.cli, cmdArgs...)
+	cmd := c.command(cmdArgs...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -475,7 +515,7 @@ func (c *KubectlClient) CheckStatefulSetExistsByLabel(label string, allNamespace
 func (c *KubectlClient) DeleteStatefulSetByLabel(label string) error {
 
 	cmdArgs := []string{"delete", "statefulset", "-l", label, "--namespace", c.namespace}
-	out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+	out, err := c.command(cmdArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -515,7 +555,7 @@ func (c *KubectlClient) GetDaemonSetsByLabel(label string, allNamespaces bool) (
 	} else {
 		cmdArgs = append(cmdArgs, "--namespace", c.namespace)
 	}
-	cmd := exec.Command(c.cli, cmdArgs...)
+	cmd := c.command(cmdArgs...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -559,7 +599,7 @@ func (c *KubectlClient) CheckDaemonSetExistsByLabel(label string, allNamespaces
 func (c *KubectlClient) DeleteDaemonSetByLabel(label string) error {
 
 	cmdArgs := []string{"delete", "daemonset", "-l", label, "--namespace", c.namespace}
-	out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+	out, err := c.command(cmdArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -599,7 +639,7 @@ func (c *KubectlClient) GetConfigMapsByLabel(label string, allNamespaces bool) (
 	} else {
 		cmdArgs = append(cmdArgs, "--namespace", c.namespace)
 	}
-	cmd := exec.Command(c.cli, cmdArgs...)
+	cmd := c.command(cmdArgs...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -642,7 +682,7 @@ func (c *KubectlClient) CheckConfigMapExistsByLabel(label string, allNamespaces
 func (c *KubectlClient) DeleteConfigMapByLabel(label string) error {
 
 	cmdArgs := []string{"delete", "configmap", "-l", label, "--namespace", c.namespace}
-	out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+	out, err := c.command(cmdArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -658,14 +698,14 @@ func (c *KubectlClient) DeleteConfigMapByLabel(label string) error {
 func (c *KubectlClient) CreateConfigMapFromDirectory(path, name, label string) error {
 
 	cmdArgs := []string{"create", "configmap", name, "--from-file", path, "--namespace", c.namespace}
-	out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+	out, err := c.command(cmdArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
 
 	if label != "" {
 		cmdArgs = []string{"label", "configmap", name, "--namespace", c.namespace, label}
-		out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+		out, err := c.command(cmdArgs...).CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("%s; %v", string(out), err)
 		}
@@ -709,7 +749,7 @@ func (c *KubectlClient) GetPodsByLabel(label string, allNamespaces bool) ([]v1.P
 	} else {
 		cmdArgs = append(cmdArgs, "--namespace", c.namespace)
 	}
-	cmd := exec.Command(c.cli, cmdArgs...)
+	cmd := c.command(cmdArgs...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -752,7 +792,7 @@ func (c *KubectlClient) CheckPodExistsByLabel(label string, allNamespaces bool)
 func (c *KubectlClient) DeletePodByLabel(label string) error {
 
 	cmdArgs := []string{"delete", "pod", "-l", label, "--namespace", c.namespace}
-	out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+	out, err := c.command(cmdArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -770,7 +810,7 @@ func (c *KubectlClient) GetPVC(pvcName string) (*v1.PersistentVolumeClaim, error
 	var pvc v1.PersistentVolumeClaim
 
 	args := []string{"get", "pvc", pvcName, "--namespace", c.namespace, "-o=json"}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -794,7 +834,7 @@ func (c *KubectlClient) GetPVCByLabel(label string, allNamespaces bool) (*v1.Per
 	} else {
 		cmdArgs = append(cmdArgs, "--namespace", c.namespace)
 	}
-	cmd := exec.Command(c.cli, cmdArgs...)
+	cmd := c.command(cmdArgs...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -824,7 +864,7 @@ func (c *KubectlClient) GetPVCByLabel(label string, allNamespaces bool) (*v1.Per
 // It only returns an error if the check failed, not if the PVC doesn't exist.
 func (c *KubectlClient) CheckPVCExists(pvcName string) (bool, error) {
 	args := []string{"get", "pvc", pvcName, "--namespace", c.namespace, "--ignore-not-found"}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return false, fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -848,7 +888,7 @@ func (c *KubectlClient) CheckPVCBound(pvcName string) (bool, error) {
 func (c *KubectlClient) DeletePVCByLabel(label string) error {
 
 	cmdArgs := []string{"delete", "pvc", "-l", label, "--namespace", c.namespace}
-	out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+	out, err := c.command(cmdArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -866,7 +906,7 @@ func (c *KubectlClient) GetPV(pvName string) (*v1.PersistentVolume, error) {
 	var pv v1.PersistentVolume
 
 	args := []string{"get", "pv", pvName, "-o=json"}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -885,7 +925,7 @@ func (c *KubectlClient) GetPVByLabel(label string) (*v1.PersistentVolume, error)
 
 	// Get PV info
 	cmdArgs := []string{"get", "pv", "-l", label, "-o=json"}
-	cmd := exec.Command(c.cli, cmdArgs...)
+	cmd := c.command(cmdArgs...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -915,7 +955,7 @@ func (c *KubectlClient) GetPVByLabel(label string) (*v1.PersistentVolume, error)
 // It only returns an error if the check failed, not if the PV doesn't exist.
 func (c *KubectlClient) CheckPVExists(pvName string) (bool, error) {
 	args := []string{"get", "pv", pvName, "--ignore-not-found"}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return false, fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -925,7 +965,7 @@ func (c *KubectlClient) CheckPVExists(pvName string) (bool, error) {
 func (c *KubectlClient) DeletePVByLabel(label string) error {
 
 	cmdArgs := []string{"delete", "pv", "-l", label}
-	out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+	out, err := c.command(cmdArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -940,7 +980,7 @@ func (c *KubectlClient) GetCRD(crdName string) (*apiextensionv1beta1.CustomResou
 	var crd apiextensionv1beta1.CustomResourceDefinition
 
 	args := []string{"get", "crd", crdName, "-o=json"}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -957,7 +997,7 @@ func (c *KubectlClient) GetCRD(crdName string) (*apiextensionv1beta1.CustomResou
 
 func (c *KubectlClient) CheckCRDExists(crdName string) (bool, error) {
 	args := []string{"get", "crd", crdName, "--ignore-not-found"}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return false, fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -968,7 +1008,7 @@ func (c *KubectlClient) CheckCRDExists(crdName string) (bool, error) {
 // It only returns an error if the check failed, not if the secret doesn't exist.
 func (c *KubectlClient) CheckSecretExists(secretName string) (bool, error) {
 	args := []string{"get", "secret", secretName, "--namespace", c.namespace, "--ignore-not-found"}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return false, fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -1021,7 +1061,7 @@ func (c *KubectlClient) CreateCHAPSecret(secretName, accountName, initiatorSecre
 func (c *KubectlClient) GetSecret(secretName string) (*v1.Secret, error) {
 
 	cmdArgs := []string{"get", "secret", secretName, "--namespace", c.namespace, "-o=json"}
-	cmd := exec.Command(c.cli, cmdArgs...)
+	cmd := c.command(cmdArgs...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -1050,7 +1090,7 @@ func (c *KubectlClient) GetSecretByLabel(label string, allNamespaces bool) (*v1.
 	} else {
 		cmdArgs = append(cmdArgs, "--namespace", c.namespace)
 	}
-	cmd := exec.Command(c.cli, cmdArgs...)
+	cmd := c.command(cmdArgs...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -1080,7 +1120,7 @@ func (c *KubectlClient) GetSecretByLabel(label string, allNamespaces bool) (*v1.
 func (c *KubectlClient) DeleteSecret(secretName string) error {
 
 	cmdArgs := []string{"delete", "secret", secretName, "--namespace", c.namespace}
-	out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+	out, err := c.command(cmdArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -1092,7 +1132,7 @@ func (c *KubectlClient) DeleteSecret(secretName string) error {
 func (c *KubectlClient) DeleteSecretByLabel(label string) error {
 
 	cmdArgs := []string{"delete", "secret", "-l", label, "--namespace", c.namespace}
-	out, err := exec.Command(c.cli, cmdArgs...).CombinedOutput()
+	out, err := c.command(cmdArgs...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -1109,7 +1149,7 @@ func (c *KubectlClient) DeleteSecretByLabel(label string) error {
 // It only returns an error if the check failed, not if the namespace doesn't exist.
 func (c *KubectlClient) CheckNamespaceExists(namespace string) (bool, error) {
 	args := []string{"get", "namespace", namespace, "--ignore-not-found"}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return false, fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -1125,7 +1165,7 @@ func (c *KubectlClient) CreateObjectByFile(filePath string) error {
 		"-f",
 		filePath,
 	}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -1139,7 +1179,7 @@ func (c *KubectlClient) CreateObjectByFile(filePath string) error {
 func (c *KubectlClient) CreateObjectByYAML(yaml string) error {
 
 	args := []string{fmt.Sprintf("--namespace=%s", c.namespace), "create", "-f", "-"}
-	cmd := exec.Command(c.cli, args...)
+	cmd := c.command(args...)
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return err
@@ -1170,7 +1210,7 @@ func (c *KubectlClient) DeleteObjectByFile(filePath string, ignoreNotFound bool)
 		fmt.Sprintf("--namespace=%s", c.namespace),
 		fmt.Sprintf("--ignore-not-found=%t", ignoreNotFound),
 	}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -1190,7 +1230,7 @@ func (c *KubectlClient) DeleteObjectByYAML(yaml string, ignoreNotFound bool) err
 		fmt.Sprintf("--namespace=%s", c.namespace),
 		fmt.Sprintf("--ignore-not-found=%t", ignoreNotFound),
 	}
-	cmd := exec.Command(c.cli, args...)
+	cmd := c.command(args...)
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return err
@@ -1229,7 +1269,7 @@ func (c *KubectlClient) AddTridentUserToOpenShiftSCC(user, scc string) error {
 		"-z",
 		user,
 	}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -1254,7 +1294,7 @@ func (c *KubectlClient) RemoveTridentUserFromOpenShiftSCC(user, scc string) erro
 		"-z",
 		user,
 	}
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -1284,7 +1324,7 @@ RetryLoop:
 
 		log.WithField("cmd", c.cli+" "+strings.Join(args, " ")).Debug("Getting logs.")
 
-		cmd = exec.Command(c.cli, args...)
+		cmd = c.command(args...)
 
 		// Create a pipe that holds stdout
 		stdout, _ := cmd.StdoutPipe()
@@ -1351,7 +1391,7 @@ func (c *KubectlClient) AddFinalizerToCRD(crdName string) error {
 		"--type=merge",
 	}
 
-	out, err := exec.Command(c.cli, args...).CombinedOutput()
+	out, err := c.command(args...).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%s; %v", string(out), err)
 	}
@@ -0,0 +1,86 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package k8sclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKubeConfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: https://127.0.0.1:6443
+users:
+- name: user
+  user:
+    token: token
+contexts:
+- name: ctx-a
+  context:
+    cluster: cluster
+    user: user
+    namespace: alpha
+- name: ctx-b
+  context:
+    cluster: cluster
+    user: user
+    namespace: beta
+current-context: ctx-a
+`
+
+func TestGetKubeContextNamespace(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "trident-kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kubeConfig := filepath.Join(dir, "config")
+	if err = ioutil.WriteFile(kubeConfig, []byte(testKubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	previous, set := os.LookupEnv("KUBECONFIG")
+	os.Setenv("KUBECONFIG", kubeConfig)
+	defer func() {
+		if set {
+			os.Setenv("KUBECONFIG", previous)
+		} else {
+			os.Unsetenv("KUBECONFIG")
+		}
+	}()
+
+	for kubeContext, expected := range map[string]string{
+		"":      "alpha",
+		"ctx-a": "alpha",
+		"ctx-b": "beta",
+	} {
+		namespace, err := GetKubeContextNamespace(kubeContext)
+		if err != nil {
+			t.Errorf("Unexpected error reading namespace of context '%s': %v", kubeContext, err)
+		} else if namespace != expected {
+			t.Errorf("Expected namespace %s for context '%s', got %s", expected, kubeContext, namespace)
+		}
+	}
+
+	if _, err = GetKubeContextNamespace("missing"); err == nil {
+		t.Error("Expected an error for a context missing from the kubeconfig")
+	}
+}
+
+func TestKubeContextArgs(t *testing.T) {
+
+	if args := KubeContextArgs(""); len(args) != 0 {
+		t.Errorf("Expected no arguments without a context, got %v", args)
+	}
+	if args := KubeContextArgs("ctx-b"); len(args) != 2 || args[0] != "--context" || args[1] != "ctx-b" {
+		t.Errorf("Expected --context ctx-b, got %v", args)
+	}
+}
@@ -3,8 +3,22 @@
 package k8sclient
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/netapp/trident/utils"
 )
@@ -36,12 +50,17 @@ metadata:
   name: {NAME}
 `
 
-func GetClusterRoleYAML(flavor OrchestratorFlavor, csi bool) string {
+func GetClusterRoleYAML(flavor OrchestratorFlavor, csi bool, version *utils.Version) string {
 
 	var clusterRoleYAML string
 
 	if csi {
 		clusterRoleYAML = clusterRoleCSIYAMLTemplate
+		if supportsCSIResize(version) {
+			clusterRoleYAML = strings.Replace(clusterRoleYAML, "{RESIZER_RULES}\n", resizerRulesYAML, 1)
+		} else {
+			clusterRoleYAML = strings.Replace(clusterRoleYAML, "{RESIZER_RULES}\n", "", 1)
+		}
 	} else {
 		clusterRoleYAML = clusterRoleYAMLTemplate
 	}
@@ -135,6 +154,26 @@ rules:
   - apiGroups: ["trident.netapp.io"]
     resources: ["tridentversions", "tridentbackends", "tridentstorageclasses", "tridentvolumes","tridentnodes", "tridenttransactions", "tridentsnapshots"]
     verbs: ["get", "list", "watch", "create", "delete", "update", "patch"]
+  - apiGroups: [""]
+    resources: ["configmaps"]
+    verbs: ["get", "list", "watch", "create", "delete", "update", "patch"]
+{RESIZER_RULES}
+`
+
+// resizerRulesYAML lists the rules needed by the external-resizer sidecar.  Some overlap the rules above,
+// but they are listed in full so the resizer keeps working if the other rules are ever narrowed.
+const resizerRulesYAML = `  - apiGroups: [""]
+    resources: ["persistentvolumes"]
+    verbs: ["get", "list", "watch", "update", "patch"]
+  - apiGroups: [""]
+    resources: ["persistentvolumeclaims"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: [""]
+    resources: ["persistentvolumeclaims/status"]
+    verbs: ["update", "patch"]
+  - apiGroups: [""]
+    resources: ["events"]
+    verbs: ["list", "watch", "create", "update", "patch"]
 `
 
 func GetClusterRoleBindingYAML(namespace string, flavor OrchestratorFlavor, csi bool) string {
@@ -190,6 +229,20 @@ roleRef:
   apiGroup: rbac.authorization.k8s.io
 `
 
+// RESTPort is the port of Trident's local HTTP REST interface.  The generated deployments tell
+// Trident to listen on it and point the readiness probe at it, and tridentctl uses it within the
+// Trident pod, so all of them always agree.
+const RESTPort = "8000"
+
+// LivenessPort is the port of the Trident controller's liveness probe.  Unlike the REST
+// interface, it listens where the kubelet can reach it.
+const LivenessPort = "8002"
+
+// TerminationGracePeriod is how many seconds Kubernetes waits for the Trident CSI pods to stop
+// before killing them.  It exceeds the 60 seconds the CSI frontend waits for in-flight operations
+// to finish, leaving time for the rest of the shutdown.
+const TerminationGracePeriod = "90"
+
 func GetDeploymentYAML(tridentImage, label string, debug bool) string {
 
 	var debugLine string
@@ -202,6 +255,8 @@ func GetDeploymentYAML(tridentImage, label string, debug bool) string {
 	deploymentYAML := strings.Replace(deploymentYAMLTemplate, "{TRIDENT_IMAGE}", tridentImage, 1)
 	deploymentYAML = strings.Replace(deploymentYAML, "{DEBUG}", debugLine, 1)
 	deploymentYAML = strings.Replace(deploymentYAML, "{LABEL}", label, -1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{PROBE_PORT}", RESTPort, -1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{LIVENESS_PORT}", LivenessPort, -1)
 	return deploymentYAML
 }
 
@@ -228,24 +283,33 @@ spec:
         args:
         - "--crd_persistence"
         - "--k8s_pod"
+        - "--port={PROBE_PORT}"
+        - "--probe_port={LIVENESS_PORT}"
         {DEBUG}
         livenessProbe:
-          exec:
-            command:
-            - tridentctl
-            - -s
-            - 127.0.0.1:8000
-            - get
-            - backend
-          failureThreshold: 2
+          httpGet:
+            path: /liveness
+            port: {LIVENESS_PORT}
+          failureThreshold: 3
           initialDelaySeconds: 120
-          periodSeconds: 120
-          timeoutSeconds: 90
+          periodSeconds: 30
+          timeoutSeconds: 10
 `
 
+const (
+	// MetricsPortName is the name of the Trident controller's Prometheus metrics port
+	MetricsPortName = "metrics"
+	// MetricsPort is the port on which the Trident controller serves Prometheus metrics
+	MetricsPort = "8001"
+	// MetricsScrapeInterval is how often Prometheus is asked to scrape the Trident controller
+	MetricsScrapeInterval = "1m"
+)
+
 func GetCSIServiceYAML(label string) string {
 
 	serviceYAML := strings.Replace(serviceYAMLTemplate, "{LABEL}", label, -1)
+	serviceYAML = strings.Replace(serviceYAML, "{METRICS_PORT_NAME}", MetricsPortName, 1)
+	serviceYAML = strings.Replace(serviceYAML, "{METRICS_PORT}", MetricsPort, -1)
 	return serviceYAML
 }
 
@@ -260,15 +324,547 @@ spec:
   selector:
     app: {LABEL}
   ports:
-    - protocol: TCP
+    - name: https
+      protocol: TCP
       port: 34571
       targetPort: 8443
+    - name: {METRICS_PORT_NAME}
+      protocol: TCP
+      port: {METRICS_PORT}
+      targetPort: {METRICS_PORT}
+`
+
+// GetServiceMonitorYAML returns a Prometheus Operator ServiceMonitor that scrapes the metrics
+// port of the trident-csi service in the specified namespace.
+func GetServiceMonitorYAML(namespace, label string) string {
+
+	serviceMonitorYAML := strings.Replace(serviceMonitorYAMLTemplate, "{NAMESPACE}", namespace, -1)
+	serviceMonitorYAML = strings.Replace(serviceMonitorYAML, "{LABEL}", label, -1)
+	serviceMonitorYAML = strings.Replace(serviceMonitorYAML, "{METRICS_PORT_NAME}", MetricsPortName, 1)
+	serviceMonitorYAML = strings.Replace(serviceMonitorYAML, "{SCRAPE_INTERVAL}", MetricsScrapeInterval, 1)
+	return serviceMonitorYAML
+}
+
+const serviceMonitorYAMLTemplate = `---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: trident-csi
+  namespace: {NAMESPACE}
+  labels:
+    app: {LABEL}
+spec:
+  jobLabel: app
+  selector:
+    matchLabels:
+      app: {LABEL}
+  namespaceSelector:
+    matchNames:
+    - {NAMESPACE}
+  endpoints:
+  - port: {METRICS_PORT_NAME}
+    path: /metrics
+    interval: {SCRAPE_INTERVAL}
+`
+
+// GetOpenShiftRouteYAML returns an OpenShift Route that exposes the HTTPS REST interface of the
+// trident-csi service in the specified namespace.  TLS is passed through, so clients must still
+// present a certificate trusted by Trident.
+func GetOpenShiftRouteYAML(namespace, label string) string {
+
+	routeYAML := strings.Replace(openShiftRouteYAMLTemplate, "{NAMESPACE}", namespace, 1)
+	routeYAML = strings.Replace(routeYAML, "{LABEL}", label, 1)
+	return routeYAML
+}
+
+const openShiftRouteYAMLTemplate = `---
+apiVersion: route.openshift.io/v1
+kind: Route
+metadata:
+  name: trident-csi
+  namespace: {NAMESPACE}
+  labels:
+    app: {LABEL}
+spec:
+  to:
+    kind: Service
+    name: trident-csi
+  port:
+    targetPort: https
+  tls:
+    termination: passthrough
+`
+
+// NetworkPolicyConfig lists the addresses the Trident CSI controller's NetworkPolicy admits.  The
+// node plugins and the kubelet use the host network, and the Kubernetes API server and backend
+// management interfaces are outside the pod network, so all of them can only be matched by CIDR.
+type NetworkPolicyConfig struct {
+	NodeCIDRs      []string
+	APIServerCIDRs []string
+	BackendCIDRs   []string
+}
+
+// NewNetworkPolicyConfig validates the requested CIDRs and returns a NetworkPolicyConfig, or nil
+// if no network policy was requested.  A policy admits nothing it doesn't list, so the nodes, the
+// API server and the backends must all be listed for the controller to work.
+func NewNetworkPolicyConfig(nodeCIDRs, apiServerCIDRs, backendCIDRs []string) (*NetworkPolicyConfig, error) {
+
+	if len(nodeCIDRs) == 0 && len(apiServerCIDRs) == 0 && len(backendCIDRs) == 0 {
+		return nil, nil
+	}
+
+	for _, cidrs := range []struct {
+		name  string
+		cidrs []string
+	}{
+		{"node", nodeCIDRs},
+		{"API server", apiServerCIDRs},
+		{"backend", backendCIDRs},
+	} {
+		if len(cidrs.cidrs) == 0 {
+			return nil, fmt.Errorf("a network policy requires the %s CIDRs", cidrs.name)
+		}
+		for _, cidr := range cidrs.cidrs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return nil, fmt.Errorf("invalid %s CIDR %s; %v", cidrs.name, cidr, err)
+			}
+		}
+	}
+
+	return &NetworkPolicyConfig{
+		NodeCIDRs:      nodeCIDRs,
+		APIServerCIDRs: apiServerCIDRs,
+		BackendCIDRs:   backendCIDRs,
+	}, nil
+}
+
+// ipBlocksYAML renders CIDRs as NetworkPolicy peers, including the trailing newline.
+func ipBlocksYAML(cidrs []string) string {
+
+	var peers string
+	for _, cidr := range cidrs {
+		peers += fmt.Sprintf("    - ipBlock:\n        cidr: %s\n", cidr)
+	}
+	return peers
+}
+
+// egressRuleYAML renders an egress rule to the CIDRs on the specified TCP ports, including the
+// trailing newline.  A rule without peers would admit every destination, so without CIDRs nothing
+// is rendered.
+func egressRuleYAML(cidrs []string, ports ...int) string {
+
+	if len(cidrs) == 0 {
+		return ""
+	}
+
+	rule := "  - to:\n" + ipBlocksYAML(cidrs) + "    ports:\n"
+	for _, port := range ports {
+		rule += fmt.Sprintf("    - protocol: TCP\n      port: %d\n", port)
+	}
+	return rule
+}
+
+// GetNetworkPolicyYAML returns a NetworkPolicy for the Trident CSI controller pods.  The HTTPS
+// REST interface and the liveness probe admit only the node plugins and the kubelet, which reach
+// them from the node addresses; metrics may be scraped from any namespace.  Egress is limited to
+// the cluster DNS, the Kubernetes API server, and the HTTPS management interfaces of the backends.
+func GetNetworkPolicyYAML(options *InstallOptions) string {
+
+	config := options.NetworkPolicy
+	if config == nil {
+		config = &NetworkPolicyConfig{}
+	}
+
+	networkPolicyYAML := strings.Replace(networkPolicyYAMLTemplate, "{NAMESPACE}", options.Namespace, 1)
+	networkPolicyYAML = strings.Replace(networkPolicyYAML, "{LABEL}", options.Label, -1)
+	networkPolicyYAML = strings.Replace(networkPolicyYAML, "{NODE_LABEL}", options.NodeLabel, 1)
+	networkPolicyYAML = strings.Replace(networkPolicyYAML, "{NODE_PEERS}\n", ipBlocksYAML(config.NodeCIDRs), 1)
+	networkPolicyYAML = strings.Replace(networkPolicyYAML, "{METRICS_PORT}", MetricsPort, 1)
+	networkPolicyYAML = strings.Replace(networkPolicyYAML, "{LIVENESS_PORT}", LivenessPort, 1)
+	networkPolicyYAML = strings.Replace(networkPolicyYAML, "{API_SERVER_EGRESS}\n",
+		egressRuleYAML(config.APIServerCIDRs, 443, 6443), 1)
+	networkPolicyYAML = strings.Replace(networkPolicyYAML, "{BACKEND_EGRESS}\n",
+		egressRuleYAML(config.BackendCIDRs, 443), 1)
+	return networkPolicyYAML
+}
+
+const networkPolicyYAMLTemplate = `---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: trident-csi
+  namespace: {NAMESPACE}
+  labels:
+    app: {LABEL}
+spec:
+  podSelector:
+    matchLabels:
+      app: {LABEL}
+  policyTypes:
+  - Ingress
+  - Egress
+  ingress:
+  - from:
+    - podSelector:
+        matchLabels:
+          app: {NODE_LABEL}
+{NODE_PEERS}
+    ports:
+    - protocol: TCP
+      port: 8443
+    - protocol: TCP
+      port: {LIVENESS_PORT}
+  - from:
+    - namespaceSelector: {}
+    ports:
+    - protocol: TCP
+      port: {METRICS_PORT}
+  egress:
+  - to:
+    - namespaceSelector: {}
+      podSelector:
+        matchLabels:
+          k8s-app: kube-dns
+    ports:
+    - protocol: UDP
+      port: 53
+    - protocol: TCP
+      port: 53
+{API_SERVER_EGRESS}
+{BACKEND_EGRESS}
 `
 
-func GetCSIDeploymentYAML(tridentImage, label string, debug bool, version *utils.Version) string {
+const (
+	DeploymentStrategyRecreate      = "Recreate"
+	DeploymentStrategyRollingUpdate = "RollingUpdate"
+
+	DefaultMaxUnavailable = "0"
+	DefaultMaxSurge       = "1"
+)
+
+var intOrPercentRegex = regexp.MustCompile(`^(0|[1-9][0-9]*)%?$`)
+
+// DeploymentStrategy describes how updates to the Trident CSI controller deployment are rolled out.
+type DeploymentStrategy struct {
+	Type           string
+	MaxUnavailable string
+	MaxSurge       string
+}
+
+// NewDeploymentStrategy validates the requested strategy parameters and returns a DeploymentStrategy.
+// If no strategy type is specified, RollingUpdate is used for multi-replica deployments and Recreate
+// is used for single-replica deployments.
+func NewDeploymentStrategy(replicas int, strategyType, maxUnavailable, maxSurge string) (*DeploymentStrategy, error) {
+
+	if replicas < 1 {
+		return nil, fmt.Errorf("replicas must be at least 1, not %d", replicas)
+	}
+
+	if strategyType == "" {
+		if replicas > 1 {
+			strategyType = DeploymentStrategyRollingUpdate
+		} else {
+			strategyType = DeploymentStrategyRecreate
+		}
+	}
+
+	switch strategyType {
+	case DeploymentStrategyRecreate:
+		if maxUnavailable != "" || maxSurge != "" {
+			return nil, fmt.Errorf("maxUnavailable and maxSurge may only be specified with the %s strategy",
+				DeploymentStrategyRollingUpdate)
+		}
+		return &DeploymentStrategy{Type: DeploymentStrategyRecreate}, nil
+
+	case DeploymentStrategyRollingUpdate:
+		if maxUnavailable == "" {
+			maxUnavailable = DefaultMaxUnavailable
+		}
+		if maxSurge == "" {
+			maxSurge = DefaultMaxSurge
+		}
+		if !intOrPercentRegex.MatchString(maxUnavailable) {
+			return nil, fmt.Errorf("invalid maxUnavailable value '%s'; must be an integer or percentage",
+				maxUnavailable)
+		}
+		if !intOrPercentRegex.MatchString(maxSurge) {
+			return nil, fmt.Errorf("invalid maxSurge value '%s'; must be an integer or percentage", maxSurge)
+		}
+		if isZeroIntOrPercent(maxUnavailable) && isZeroIntOrPercent(maxSurge) {
+			return nil, errors.New("maxUnavailable and maxSurge may not both be zero")
+		}
+		if strings.HasSuffix(maxUnavailable, "%") && !isValidPercent(maxUnavailable) {
+			return nil, fmt.Errorf("invalid maxUnavailable value '%s'; percentage may not exceed 100%%",
+				maxUnavailable)
+		}
+		return &DeploymentStrategy{
+			Type:           DeploymentStrategyRollingUpdate,
+			MaxUnavailable: maxUnavailable,
+			MaxSurge:       maxSurge,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported deployment strategy '%s'; must be %s or %s",
+			strategyType, DeploymentStrategyRecreate, DeploymentStrategyRollingUpdate)
+	}
+}
+
+func isZeroIntOrPercent(value string) bool {
+	return strings.TrimSuffix(value, "%") == "0"
+}
+
+func isValidPercent(value string) bool {
+	percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	return err == nil && percent <= 100
+}
+
+// yaml renders the strategy as the body of a deployment's strategy stanza.
+func (s *DeploymentStrategy) yaml() string {
+
+	if s == nil || s.Type != DeploymentStrategyRollingUpdate {
+		return "    type: " + DeploymentStrategyRecreate
+	}
+
+	return fmt.Sprintf("    type: %s\n    rollingUpdate:\n      maxUnavailable: %s\n      maxSurge: %s",
+		s.Type, quoteIntOrPercent(s.MaxUnavailable), quoteIntOrPercent(s.MaxSurge))
+}
+
+// quoteIntOrPercent quotes percentage values so they are parsed as strings rather than integers.
+func quoteIntOrPercent(value string) string {
+	if strings.HasSuffix(value, "%") {
+		return fmt.Sprintf("\"%s\"", value)
+	}
+	return value
+}
+
+const (
+	DefaultReadinessInitialDelay = 10 * time.Second
+	DefaultReadinessPeriod       = 10 * time.Second
+
+	// readinessFailureThreshold is high enough that a slow response during bootstrap doesn't
+	// toggle a ready controller to unready and back
+	readinessFailureThreshold = 3
+	readinessTimeoutSeconds   = 10
+)
+
+// ReadinessProbe describes how often Kubernetes checks whether the Trident CSI controller has
+// finished bootstrapping and is ready to serve requests.
+type ReadinessProbe struct {
+	InitialDelaySeconds int
+	PeriodSeconds       int
+}
+
+// NewReadinessProbe validates the requested probe timing and returns a ReadinessProbe.
+// Kubernetes probe timing has a granularity of one second, so durations are truncated.
+func NewReadinessProbe(initialDelay, period time.Duration) (*ReadinessProbe, error) {
+
+	if initialDelay < 0 {
+		return nil, fmt.Errorf("readiness initial delay may not be negative, not %v", initialDelay)
+	}
+	if period < time.Second {
+		return nil, fmt.Errorf("readiness period must be at least 1s, not %v", period)
+	}
+
+	return &ReadinessProbe{
+		InitialDelaySeconds: int(initialDelay / time.Second),
+		PeriodSeconds:       int(period / time.Second),
+	}, nil
+}
+
+// yaml renders the probe as a readinessProbe stanza of the trident-main container.  The probe
+// asks the controller for its version, which fails until bootstrapping has completed.
+func (p *ReadinessProbe) yaml() string {
+
+	if p == nil {
+		p = &ReadinessProbe{
+			InitialDelaySeconds: int(DefaultReadinessInitialDelay / time.Second),
+			PeriodSeconds:       int(DefaultReadinessPeriod / time.Second),
+		}
+	}
+
+	return fmt.Sprintf(`        readinessProbe:
+          exec:
+            command:
+            - tridentctl
+            - -s
+            - 127.0.0.1:{PROBE_PORT}
+            - version
+          failureThreshold: %d
+          initialDelaySeconds: %d
+          periodSeconds: %d
+          timeoutSeconds: %d`,
+		readinessFailureThreshold, p.InitialDelaySeconds, p.PeriodSeconds, readinessTimeoutSeconds)
+}
+
+// DefaultRunAsUser is the user ID the Trident CSI controller runs as when it runs as non-root
+const DefaultRunAsUser = 1000
+
+// SecurityContext describes the restricted, non-root security settings applied to the Trident CSI
+// controller pod.  Unlike the node plugin, the controller needs no privileges.
+type SecurityContext struct {
+	RunAsUser int64
+}
+
+// NewSecurityContext validates the requested user ID and returns a SecurityContext.
+func NewSecurityContext(runAsUser int64) (*SecurityContext, error) {
+
+	if runAsUser < 1 {
+		return nil, fmt.Errorf("a non-root user ID must be at least 1, not %d", runAsUser)
+	}
+
+	return &SecurityContext{RunAsUser: runAsUser}, nil
+}
+
+// podYAML renders the context as a pod-level securityContext stanza, including the trailing newline.
+func (c *SecurityContext) podYAML() string {
+
+	if c == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("      securityContext:\n        runAsNonRoot: true\n        runAsUser: %d\n", c.RunAsUser)
+}
+
+// containerYAML renders the context as a container-level securityContext stanza, including the
+// trailing newline.
+func (c *SecurityContext) containerYAML() string {
+
+	if c == nil {
+		return ""
+	}
+
+	return `        securityContext:
+          allowPrivilegeEscalation: false
+          readOnlyRootFilesystem: true
+          capabilities:
+            drop:
+            - ALL
+`
+}
+
+// DefaultNoProxy lists the destinations the Trident CSI controller always reaches directly when a
+// proxy is configured, so that it can still talk to the in-cluster Kubernetes API server.  The
+// API server is also listed by the address in KUBERNETES_SERVICE_HOST, which Kubernetes expands
+// when the container starts, since in-cluster clients connect to that address rather than a name.
+var DefaultNoProxy = []string{
+	"localhost", "127.0.0.1", "$(KUBERNETES_SERVICE_HOST)", "kubernetes.default.svc", ".svc", ".cluster.local",
+}
+
+// ProxyConfig describes the HTTP proxies the Trident CSI controller uses to reach storage backend
+// APIs, such as those of cloud providers, from a restricted network.
+type ProxyConfig struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    []string
+}
+
+// NewProxyConfig validates the requested proxies and returns a ProxyConfig, or nil if no proxy
+// was requested.  The destinations in DefaultNoProxy are always added to noProxy.  Addresses of
+// the pod and service networks aren't known here, so they must be included in noProxy if the
+// controller must reach them directly.
+func NewProxyConfig(httpProxy, httpsProxy string, noProxy []string) (*ProxyConfig, error) {
+
+	if httpProxy == "" && httpsProxy == "" {
+		if len(noProxy) > 0 {
+			return nil, errors.New("no proxy exclusions may be specified without a proxy")
+		}
+		return nil, nil
+	}
+
+	for _, proxy := range []string{httpProxy, httpsProxy} {
+		if proxy == "" {
+			continue
+		}
+		if proxyURL, err := url.Parse(proxy); err != nil || proxyURL.Host == "" ||
+			(proxyURL.Scheme != "http" && proxyURL.Scheme != "https") {
+			return nil, fmt.Errorf("proxy %s must be an http or https URL", proxy)
+		}
+	}
+
+	allNoProxy := make([]string, 0, len(DefaultNoProxy)+len(noProxy))
+	seen := make(map[string]bool)
+	for _, destination := range append(append([]string{}, DefaultNoProxy...), noProxy...) {
+		if destination = strings.TrimSpace(destination); destination != "" && !seen[destination] {
+			seen[destination] = true
+			allNoProxy = append(allNoProxy, destination)
+		}
+	}
+
+	return &ProxyConfig{HTTPProxy: httpProxy, HTTPSProxy: httpsProxy, NoProxy: allNoProxy}, nil
+}
+
+// envYAML renders the proxy settings as env entries of the trident-main container, including the
+// trailing newline.  Unset proxies render nothing.
+func (c *ProxyConfig) envYAML() string {
+
+	if c == nil {
+		return ""
+	}
+
+	var env string
+	for _, variable := range []struct{ name, value string }{
+		{"HTTP_PROXY", c.HTTPProxy},
+		{"HTTPS_PROXY", c.HTTPSProxy},
+		{"NO_PROXY", strings.Join(c.NoProxy, ",")},
+	} {
+		if variable.value != "" {
+			env += fmt.Sprintf("        - name: %s\n          value: %q\n", variable.name, variable.value)
+		}
+	}
+	return env
+}
+
+// NewControllerAntiAffinity returns an affinity that prefers to place each Trident CSI controller
+// replica in a different topology domain, such as a node or zone, than the others.
+func NewControllerAntiAffinity(label, topologyKey string) *v1.Affinity {
+	return &v1.Affinity{
+		PodAntiAffinity: &v1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{{
+				Weight: 100,
+				PodAffinityTerm: v1.PodAffinityTerm{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": label},
+					},
+					TopologyKey: topologyKey,
+				},
+			}},
+		},
+	}
+}
+
+// affinityYAML renders an affinity as a pod-level affinity stanza, including the trailing newline.
+func affinityYAML(affinity *v1.Affinity) string {
+
+	if affinity == nil {
+		return ""
+	}
+
+	affinityBytes, err := yaml.Marshal(struct {
+		Affinity *v1.Affinity `json:"affinity"`
+	}{affinity})
+	if err != nil {
+		return ""
+	}
+
+	var stanza string
+	for _, line := range strings.Split(strings.TrimRight(string(affinityBytes), "\n"), "\n") {
+		stanza += "      " + line + "\n"
+	}
+	return stanza
+}
+
+// GetCSIDeploymentYAML returns the CSI controller Deployment for the specified installation options.
+func GetCSIDeploymentYAML(options *InstallOptions) string {
+	return getCSIDeploymentYAML(options, currentReleaseImages().csiSidecars(options.Version))
+}
+
+// getCSIDeploymentYAML returns the CSI controller Deployment using the specified sidecar images.
+func getCSIDeploymentYAML(options *InstallOptions, sidecars CSISidecarImages) string {
+
+	version := options.Version
 
 	var debugLine string
-	if debug {
+	if options.Debug {
 		debugLine = "- -debug"
 	} else {
 		debugLine = "#- -debug"
@@ -281,12 +877,75 @@ func GetCSIDeploymentYAML(tridentImage, label string, debug bool, version *utils
 		deploymentYAML = csiDeployment114YAMLTemplate
 	}
 
-	deploymentYAML = strings.Replace(deploymentYAML, "{TRIDENT_IMAGE}", tridentImage, 1)
+	replicas := options.Replicas
+	if replicas < 1 {
+		replicas = 1
+	}
+
+	// The resizer sidecar carries its own tokens, so it must be inserted before they are replaced
+	deploymentYAML = strings.Replace(deploymentYAML, "{RESIZER}\n", resizerYAML(version), 1)
+
+	deploymentYAML = strings.Replace(deploymentYAML, "{TRIDENT_IMAGE}", options.TridentImage, 1)
+	deploymentYAML = sidecars.replaceImages(deploymentYAML)
 	deploymentYAML = strings.Replace(deploymentYAML, "{DEBUG}", debugLine, 1)
-	deploymentYAML = strings.Replace(deploymentYAML, "{LABEL}", label, -1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{LABEL}", options.Label, -1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{REPLICAS}", strconv.Itoa(replicas), 1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{STRATEGY}", options.Strategy.yaml(), 1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{READINESS_PROBE}", options.Readiness.yaml(), 1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{PROBE_PORT}", RESTPort, -1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{LIVENESS_PORT}", LivenessPort, -1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{TERMINATION_GRACE_PERIOD}", TerminationGracePeriod, 1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{POD_SECURITY_CONTEXT}\n", options.Security.podYAML(), 1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{AFFINITY}\n", affinityYAML(options.Affinity), 1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{SECURITY_CONTEXT}\n", options.Security.containerYAML(), -1)
+	deploymentYAML = strings.Replace(deploymentYAML, "{PROXY_ENV}\n", options.Proxy.envYAML(), 1)
+
+	// Multiple controller replicas must elect a leader, so that only one of them acts at a time.  The
+	// sidecars don't hold elections of their own; they wait for the CSI socket, which only the
+	// leader's Trident container serves, so they always follow the Trident leader.
+	deploymentYAML = strings.Replace(deploymentYAML, "{TRIDENT_LEADER_ELECTION}\n",
+		leaderElectionYAML(replicas > 1), 1)
 	return deploymentYAML
 }
 
+// supportsCSIResize reports whether Kubernetes supports CSI volume expansion, and hence the
+// external-resizer sidecar.  Kubernetes 1.14 supports it behind the ExpandCSIVolumes feature gate.
+func supportsCSIResize(version *utils.Version) bool {
+	return version != nil && version.AtLeast(utils.MustParseGeneric("1.14"))
+}
+
+// resizerYAML renders the external-resizer sidecar container, or nothing if Kubernetes doesn't
+// support CSI volume expansion.
+func resizerYAML(version *utils.Version) string {
+
+	if !supportsCSIResize(version) {
+		return ""
+	}
+
+	return `      - name: csi-resizer
+        image: {CSI_RESIZER_IMAGE}
+{SECURITY_CONTEXT}
+        args:
+        - "--v=9"
+        - "--csi-address=$(ADDRESS)"
+        env:
+        - name: ADDRESS
+          value: /var/lib/csi/sockets/pluginproxy/csi.sock
+        volumeMounts:
+        - name: socket-dir
+          mountPath: /var/lib/csi/sockets/pluginproxy/
+`
+}
+
+// leaderElectionYAML renders the Trident container arg enabling leader election, if enabled.
+func leaderElectionYAML(enabled bool) string {
+	if !enabled {
+		return ""
+	}
+	return `        - "--csi_leader_election"
+`
+}
+
 const csiDeployment113YAMLTemplate = `---
 apiVersion: extensions/v1beta1
 kind: Deployment
@@ -295,43 +954,50 @@ metadata:
   labels:
     app: {LABEL}
 spec:
-  replicas: 1
+  replicas: {REPLICAS}
   strategy:
-    type: Recreate
+{STRATEGY}
   template:
     metadata:
       labels:
         app: {LABEL}
     spec:
       serviceAccount: trident-csi
+      terminationGracePeriodSeconds: {TERMINATION_GRACE_PERIOD}
+{POD_SECURITY_CONTEXT}
+{AFFINITY}
       containers:
       - name: trident-main
         image: {TRIDENT_IMAGE}
+{SECURITY_CONTEXT}
         ports:
         - containerPort: 8443
+        - containerPort: 8001
+          name: metrics
         command:
         - /usr/local/bin/trident_orchestrator
         args:
         - "--crd_persistence"
         - "--k8s_pod"
+        - "--port={PROBE_PORT}"
+        - "--probe_port={LIVENESS_PORT}"
         - "--https_rest"
         - "--https_port=8443"
         - "--csi_node_name=$(KUBE_NODE_NAME)"
         - "--csi_endpoint=$(CSI_ENDPOINT)"
         - "--csi_role=controller"
+        - "--metrics"
+{TRIDENT_LEADER_ELECTION}
         {DEBUG}
         livenessProbe:
-          exec:
-            command:
-            - tridentctl
-            - -s
-            - 127.0.0.1:8000
-            - get
-            - backend
-          failureThreshold: 2
+          httpGet:
+            path: /liveness
+            port: {LIVENESS_PORT}
+          failureThreshold: 3
           initialDelaySeconds: 120
-          periodSeconds: 120
-          timeoutSeconds: 90
+          periodSeconds: 30
+          timeoutSeconds: 10
+{READINESS_PROBE}
         env:
         - name: KUBE_NODE_NAME
           valueFrom:
@@ -340,6 +1006,7 @@ spec:
               fieldPath: spec.nodeName
         - name: CSI_ENDPOINT
           value: unix://plugin/csi.sock
+{PROXY_ENV}
         volumeMounts:
         - name: socket-dir
           mountPath: /plugin
@@ -347,7 +1014,8 @@ spec:
           mountPath: /certs
           readOnly: true
       - name: csi-provisioner
-        image: quay.io/k8scsi/csi-provisioner:v1.0.1
+        image: {CSI_PROVISIONER_IMAGE}
+{SECURITY_CONTEXT}
         args:
         - "--v=9"
         - "--connection-timeout=24h"
@@ -359,7 +1027,8 @@ spec:
         - name: socket-dir
           mountPath: /var/lib/csi/sockets/pluginproxy/
       - name: csi-attacher
-        image: quay.io/k8scsi/csi-attacher:v1.0.1
+        image: {CSI_ATTACHER_IMAGE}
+{SECURITY_CONTEXT}
         args:
         - "--v=9"
         - "--connection-timeout=24h"
@@ -372,7 +1041,8 @@ spec:
         - name: socket-dir
           mountPath: /var/lib/csi/sockets/pluginproxy/
       - name: csi-snapshotter
-        image: quay.io/k8scsi/csi-snapshotter:v1.0.1
+        image: {CSI_SNAPSHOTTER_IMAGE}
+{SECURITY_CONTEXT}
         args:
         - "--v=9"
         - "--connection-timeout=24h"
@@ -384,7 +1054,8 @@ spec:
         - name: socket-dir
           mountPath: /var/lib/csi/sockets/pluginproxy/
       - name: csi-cluster-driver-registrar
-        image: quay.io/k8scsi/csi-cluster-driver-registrar:v1.0.1
+        image: {CSI_CLUSTER_DRIVER_REGISTRAR_IMAGE}
+{SECURITY_CONTEXT}
         args:
         - "--v=9"
         - "--connection-timeout=24h"
@@ -411,43 +1082,50 @@ metadata:
   labels:
     app: {LABEL}
 spec:
-  replicas: 1
+  replicas: {REPLICAS}
   strategy:
-    type: Recreate
+{STRATEGY}
   template:
     metadata:
       labels:
         app: {LABEL}
     spec:
       serviceAccount: trident-csi
+      terminationGracePeriodSeconds: {TERMINATION_GRACE_PERIOD}
+{POD_SECURITY_CONTEXT}
+{AFFINITY}
       containers:
       - name: trident-main
         image: {TRIDENT_IMAGE}
+{SECURITY_CONTEXT}
         ports:
         - containerPort: 8443
+        - containerPort: 8001
+          name: metrics
         command:
         - /usr/local/bin/trident_orchestrator
         args:
         - "--crd_persistence"
         - "--k8s_pod"
+        - "--port={PROBE_PORT}"
+        - "--probe_port={LIVENESS_PORT}"
         - "--https_rest"
         - "--https_port=8443"
         - "--csi_node_name=$(KUBE_NODE_NAME)"
         - "--csi_endpoint=$(CSI_ENDPOINT)"
         - "--csi_role=controller"
+        - "--metrics"
+{TRIDENT_LEADER_ELECTION}
         {DEBUG}
         livenessProbe:
-          exec:
-            command:
-            - tridentctl
-            - -s
-            - 127.0.0.1:8000
-            - get
-            - backend
-          failureThreshold: 2
+          httpGet:
+            path: /liveness
+            port: {LIVENESS_PORT}
+          failureThreshold: 3
           initialDelaySeconds: 120
-          periodSeconds: 120
-          timeoutSeconds: 90
+          periodSeconds: 30
+          timeoutSeconds: 10
+{READINESS_PROBE}
         env:
         - name: KUBE_NODE_NAME
           valueFrom:
@@ -456,6 +1134,7 @@ spec:
               fieldPath: spec.nodeName
         - name: CSI_ENDPOINT
           value: unix://plugin/csi.sock
+{PROXY_ENV}
         volumeMounts:
         - name: socket-dir
           mountPath: /plugin
@@ -463,7 +1142,8 @@ spec:
           mountPath: /certs
           readOnly: true
       - name: csi-provisioner
-        image: quay.io/k8scsi/csi-provisioner:v1.2.1
+        image: {CSI_PROVISIONER_IMAGE}
+{SECURITY_CONTEXT}
         args:
         - "--v=9"
         - "--timeout=300s"
@@ -475,7 +1155,8 @@ spec:
         - name: socket-dir
           mountPath: /var/lib/csi/sockets/pluginproxy/
       - name: csi-attacher
-        image: quay.io/k8scsi/csi-attacher:v1.1.1
+        image: {CSI_ATTACHER_IMAGE}
+{SECURITY_CONTEXT}
         args:
         - "--v=9"
         - "--timeout=60s"
@@ -487,7 +1168,8 @@ spec:
         - name: socket-dir
           mountPath: /var/lib/csi/sockets/pluginproxy/
       - name: csi-snapshotter
-        image: quay.io/k8scsi/csi-snapshotter:v1.2.0
+        image: {CSI_SNAPSHOTTER_IMAGE}
+{SECURITY_CONTEXT}
         args:
         - "--v=9"
         - "--timeout=60s"
@@ -498,6 +1180,7 @@ spec:
         volumeMounts:
         - name: socket-dir
           mountPath: /var/lib/csi/sockets/pluginproxy/
+{RESIZER}
       volumes:
       - name: socket-dir
         emptyDir:
@@ -506,7 +1189,41 @@ spec:
           secretName: trident-csi
 `
 
-func GetCSIDaemonSetYAML(tridentImage, label string, debug bool, version *utils.Version) string {
+// DefaultKubeletDir is the kubelet's root directory on most Kubernetes distributions.
+const DefaultKubeletDir = "/var/lib/kubelet"
+
+// ValidateKubeletDir returns an error if a kubelet root directory isn't an absolute path.
+func ValidateKubeletDir(kubeletDir string) error {
+	if !path.IsAbs(kubeletDir) {
+		return fmt.Errorf("kubelet directory %s must be an absolute path", kubeletDir)
+	}
+	return nil
+}
+
+// GetCSISocketPath returns the host path of the Trident node plugin's CSI socket, with which the
+// node driver registrar registers Trident with the kubelet.
+func GetCSISocketPath(kubeletDir string) string {
+	if kubeletDir == "" {
+		kubeletDir = DefaultKubeletDir
+	}
+	return path.Join(kubeletDir, "plugins", "csi.trident.netapp.io", "csi.sock")
+}
+
+// GetCSIDaemonSetYAML returns the node plugin DaemonSet.  All of its kubelet host paths, including
+// the CSI socket registered with the kubelet, are relative to kubeletDir, which defaults to
+// DefaultKubeletDir if empty.
+func GetCSIDaemonSetYAML(
+	tridentImage, label string, debug bool, topologyKeys []string, kubeletDir string, version *utils.Version,
+) string {
+	return getCSIDaemonSetYAML(tridentImage, label, debug, topologyKeys, kubeletDir, version,
+		currentReleaseImages().csiSidecars(version))
+}
+
+// getCSIDaemonSetYAML returns the node plugin DaemonSet using the specified sidecar images.
+func getCSIDaemonSetYAML(
+	tridentImage, label string, debug bool, topologyKeys []string, kubeletDir string, version *utils.Version,
+	sidecars CSISidecarImages,
+) string {
 
 	var debugLine string
 
@@ -516,6 +1233,12 @@ func GetCSIDaemonSetYAML(tridentImage, label string, debug bool, version *utils.
 		debugLine = "#- -debug"
 	}
 
+	if kubeletDir == "" {
+		kubeletDir = DefaultKubeletDir
+	} else {
+		kubeletDir = path.Clean(kubeletDir)
+	}
+
 	var daemonSetYAML string
 	if version.MajorVersion() == 1 && version.MinorVersion() == 13 {
 		daemonSetYAML = daemonSet113YAMLTemplate
@@ -524,8 +1247,14 @@ func GetCSIDaemonSetYAML(tridentImage, label string, debug bool, version *utils.
 	}
 
 	daemonSetYAML = strings.Replace(daemonSetYAML, "{TRIDENT_IMAGE}", tridentImage, 1)
+	daemonSetYAML = sidecars.replaceImages(daemonSetYAML)
 	daemonSetYAML = strings.Replace(daemonSetYAML, "{LABEL}", label, -1)
 	daemonSetYAML = strings.Replace(daemonSetYAML, "{DEBUG}", debugLine, 1)
+	daemonSetYAML = strings.Replace(daemonSetYAML, "{TERMINATION_GRACE_PERIOD}", TerminationGracePeriod, 1)
+	daemonSetYAML = strings.Replace(daemonSetYAML, "{TOPOLOGY_ANNOTATIONS}\n",
+		topologyKeysAnnotationYAML(topologyKeys, "      "), 1)
+	daemonSetYAML = strings.Replace(daemonSetYAML, "{CSI_SOCKET_PATH}", GetCSISocketPath(kubeletDir), 1)
+	daemonSetYAML = strings.Replace(daemonSetYAML, "{KUBELET_DIR}", kubeletDir, -1)
 	return daemonSetYAML
 }
 
@@ -544,8 +1273,10 @@ spec:
     metadata:
       labels:
         app: {LABEL}
+{TOPOLOGY_ANNOTATIONS}
     spec:
       serviceAccount: trident-csi
+      terminationGracePeriodSeconds: {TERMINATION_GRACE_PERIOD}
       hostNetwork: true
       hostIPC: true
       dnsPolicy: ClusterFirstWithHostNet
@@ -580,9 +1311,9 @@ spec:
         - name: plugin-dir
           mountPath: /plugin
         - name: plugins-mount-dir
-          mountPath: /var/lib/kubelet/plugins
+          mountPath: {KUBELET_DIR}/plugins
         - name: pods-mount-dir
-          mountPath: /var/lib/kubelet/pods
+          mountPath: {KUBELET_DIR}/pods
           mountPropagation: "Bidirectional"
         - name: dev-dir
           mountPath: /dev
@@ -595,7 +1326,7 @@ spec:
           mountPath: /certs
           readOnly: true
       - name: driver-registrar
-        image: quay.io/k8scsi/csi-node-driver-registrar:v1.0.2
+        image: {CSI_NODE_DRIVER_REGISTRAR_IMAGE}
         args:
         - "--v=9"
         - "--connection-timeout=24h"
@@ -605,7 +1336,7 @@ spec:
         - name: ADDRESS
           value: /plugin/csi.sock
         - name: REGISTRATION_PATH
-          value: "/var/lib/kubelet/plugins/csi.trident.netapp.io/csi.sock"
+          value: "{CSI_SOCKET_PATH}"
         - name: KUBE_NODE_NAME
           valueFrom:
             fieldRef:
@@ -618,19 +1349,19 @@ spec:
       volumes:
       - name: plugin-dir
         hostPath:
-          path: /var/lib/kubelet/plugins/csi.trident.netapp.io/
+          path: {KUBELET_DIR}/plugins/csi.trident.netapp.io/
           type: DirectoryOrCreate
       - name: registration-dir
         hostPath:
-          path: /var/lib/kubelet/plugins_registry/
+          path: {KUBELET_DIR}/plugins_registry/
           type: Directory
       - name: plugins-mount-dir
         hostPath:
-          path: /var/lib/kubelet/plugins
+          path: {KUBELET_DIR}/plugins
           type: DirectoryOrCreate
       - name: pods-mount-dir
         hostPath:
-          path: /var/lib/kubelet/pods
+          path: {KUBELET_DIR}/pods
           type: DirectoryOrCreate
       - name: dev-dir
         hostPath:
@@ -664,8 +1395,10 @@ spec:
     metadata:
       labels:
         app: {LABEL}
+{TOPOLOGY_ANNOTATIONS}
     spec:
       serviceAccount: trident-csi
+      terminationGracePeriodSeconds: {TERMINATION_GRACE_PERIOD}
       hostNetwork: true
       hostIPC: true
       dnsPolicy: ClusterFirstWithHostNet
@@ -700,9 +1433,9 @@ spec:
         - name: plugin-dir
           mountPath: /plugin
         - name: plugins-mount-dir
-          mountPath: /var/lib/kubelet/plugins
+          mountPath: {KUBELET_DIR}/plugins
         - name: pods-mount-dir
-          mountPath: /var/lib/kubelet/pods
+          mountPath: {KUBELET_DIR}/pods
           mountPropagation: "Bidirectional"
         - name: dev-dir
           mountPath: /dev
@@ -715,7 +1448,7 @@ spec:
           mountPath: /certs
           readOnly: true
       - name: driver-registrar
-        image: quay.io/k8scsi/csi-node-driver-registrar:v1.1.0
+        image: {CSI_NODE_DRIVER_REGISTRAR_IMAGE}
         args:
         - "--v=9"
         - "--csi-address=$(ADDRESS)"
@@ -724,7 +1457,7 @@ spec:
         - name: ADDRESS
           value: /plugin/csi.sock
         - name: REGISTRATION_PATH
-          value: "/var/lib/kubelet/plugins/csi.trident.netapp.io/csi.sock"
+          value: "{CSI_SOCKET_PATH}"
         - name: KUBE_NODE_NAME
           valueFrom:
             fieldRef:
@@ -737,19 +1470,19 @@ spec:
       volumes:
       - name: plugin-dir
         hostPath:
-          path: /var/lib/kubelet/plugins/csi.trident.netapp.io/
+          path: {KUBELET_DIR}/plugins/csi.trident.netapp.io/
           type: DirectoryOrCreate
       - name: registration-dir
         hostPath:
-          path: /var/lib/kubelet/plugins_registry/
+          path: {KUBELET_DIR}/plugins_registry/
           type: Directory
       - name: plugins-mount-dir
         hostPath:
-          path: /var/lib/kubelet/plugins
+          path: {KUBELET_DIR}/plugins
           type: DirectoryOrCreate
       - name: pods-mount-dir
         hostPath:
-          path: /var/lib/kubelet/pods
+          path: {KUBELET_DIR}/pods
           type: DirectoryOrCreate
       - name: dev-dir
         hostPath:
@@ -804,7 +1537,7 @@ rules:
     resources: ["deployments", "daemonsets"]
     verbs: ["*"]
   - apiGroups: ["apps"]
-    resources: ["statefulsets", daemonsets", "deployments"]
+    resources: ["statefulsets", "daemonsets", "deployments"]
     verbs: ["*"]
   - apiGroups: ["authorization.openshift.io", "rbac.authorization.k8s.io"]
     resources: ["clusterroles", "clusterrolebindings"]
@@ -991,6 +1724,50 @@ spec:
       name: trident-installer
 `
 
+// GetInstallerJobYAML returns a job that runs the installer with the same container spec as
+// GetInstallerPodYAML.  Unlike a bare pod, the job retries the installer up to backoffLimit times
+// if its pod fails or is evicted, and it reports completion in its status.
+func GetInstallerJobYAML(label, tridentImage string, commandArgs []string, backoffLimit int32) string {
+
+	command := `["` + strings.Join(commandArgs, `", "`) + `"]`
+
+	jobYAML := strings.Replace(installerJobTemplate, "{LABEL}", label, -1)
+	jobYAML = strings.Replace(jobYAML, "{TRIDENT_IMAGE}", tridentImage, 1)
+	jobYAML = strings.Replace(jobYAML, "{COMMAND}", command, 1)
+	jobYAML = strings.Replace(jobYAML, "{BACKOFF_LIMIT}", strconv.Itoa(int(backoffLimit)), 1)
+	return jobYAML
+}
+
+const installerJobTemplate = `---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: trident-installer
+  labels:
+    app: {LABEL}
+spec:
+  backoffLimit: {BACKOFF_LIMIT}
+  template:
+    metadata:
+      labels:
+        app: {LABEL}
+    spec:
+      serviceAccount: trident-installer
+      containers:
+      - name: trident-installer
+        image: {TRIDENT_IMAGE}
+        workingDir: /
+        command: {COMMAND}
+        volumeMounts:
+        - name: setup-dir
+          mountPath: /setup
+      restartPolicy: Never
+      volumes:
+      - name: setup-dir
+        configMap:
+          name: trident-installer
+`
+
 func GetUninstallerPodYAML(label, tridentImage string, commandArgs []string) string {
 
 	command := `["` + strings.Join(commandArgs, `", "`) + `"]`
@@ -1030,14 +1807,51 @@ metadata:
   name: {SCC}
 `
 
+// GetSecretYAML returns a secret with the supplied values written under data.  The values must
+// already be base64-encoded; use GetSecretYAMLEncoded for plaintext values.
 func GetSecretYAML(secretName, namespace, label string, secretData map[string]string) string {
+	return getSecretYAML(secretName, namespace, label, "data", secretData)
+}
+
+// GetSecretYAMLEncoded returns a secret with the supplied plaintext values base64-encoded under
+// data, or written as they are under stringData if stringData is true.
+func GetSecretYAMLEncoded(
+	secretName, namespace, label string, secretData map[string]string, stringData bool,
+) string {
+
+	if stringData {
+		quotedData := make(map[string]string, len(secretData))
+		for key, value := range secretData {
+			// A JSON string is also a valid double-quoted YAML scalar
+			quotedValue, _ := json.Marshal(value)
+			quotedData[key] = string(quotedValue)
+		}
+		return getSecretYAML(secretName, namespace, label, "stringData", quotedData)
+	}
+
+	encodedData := make(map[string]string, len(secretData))
+	for key, value := range secretData {
+		encodedData[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return getSecretYAML(secretName, namespace, label, "data", encodedData)
+}
+
+func getSecretYAML(secretName, namespace, label, dataField string, secretData map[string]string) string {
 
 	secretYAML := strings.Replace(secretYAMLTemplate, "{SECRET_NAME}", secretName, 1)
 	secretYAML = strings.Replace(secretYAML, "{NAMESPACE}", namespace, 1)
 	secretYAML = strings.Replace(secretYAML, "{LABEL}", label, 1)
+	secretYAML += dataField + ":\n"
 
-	for key, value := range secretData {
-		secretYAML += fmt.Sprintf("  %s: %s\n", key, value)
+	// Sort the keys so the same data always renders the same YAML
+	keys := make([]string, 0, len(secretData))
+	for key := range secretData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		secretYAML += fmt.Sprintf("  %s: %s\n", key, secretData[key])
 	}
 
 	return secretYAML
@@ -1051,7 +1865,6 @@ metadata:
   namespace: {NAMESPACE}
   labels:
     app: {LABEL}
-data:
 `
 
 func GetCRDsYAML() string {
@@ -1096,6 +1909,16 @@ spec:
     - name: v1
       served: true
       storage: true
+  validation:
+    openAPIV3Schema:
+      type: object
+      properties:
+        trident_version:
+          type: string
+        trident_store_version:
+          type: string
+        trident_api_version:
+          type: string
   scope: Namespaced
   names:
     plural: tridentversions
@@ -1125,6 +1948,26 @@ spec:
     - name: v1
       served: true
       storage: true
+  validation:
+    openAPIV3Schema:
+      type: object
+      required:
+      - config
+      - backendName
+      - backendUUID
+      properties:
+        config:
+          type: object
+        backendName:
+          type: string
+        backendUUID:
+          type: string
+        version:
+          type: string
+        online:
+          type: boolean
+        state:
+          type: string
   scope: Namespaced
   names:
     plural: tridentbackends
@@ -1159,6 +2002,14 @@ spec:
     - name: v1
       served: true
       storage: true
+  validation:
+    openAPIV3Schema:
+      type: object
+      required:
+      - spec
+      properties:
+        spec:
+          type: object
   scope: Namespaced
   names:
     plural: tridentstorageclasses
@@ -1182,6 +2033,27 @@ spec:
     - name: v1
       served: true
       storage: true
+  validation:
+    openAPIV3Schema:
+      type: object
+      required:
+      - config
+      - backendUUID
+      properties:
+        config:
+          type: object
+        backendUUID:
+          type: string
+        pool:
+          type: string
+        orphaned:
+          type: boolean
+        state:
+          type: string
+        publishedNodes:
+          type: array
+          items:
+            type: string
   scope: Namespaced
   names:
     plural: tridentvolumes
@@ -1240,6 +2112,20 @@ spec:
     - name: v1
       served: true
       storage: true
+  validation:
+    openAPIV3Schema:
+      type: object
+      required:
+      - name
+      properties:
+        name:
+          type: string
+        iqn:
+          type: string
+        ips:
+          type: array
+          items:
+            type: string
   scope: Namespaced
   names:
     plural: tridentnodes
@@ -1262,6 +2148,17 @@ spec:
     - name: v1
       served: true
       storage: true
+  validation:
+    openAPIV3Schema:
+      type: object
+      required:
+      - operation
+      - config
+      properties:
+        operation:
+          type: string
+        config:
+          type: object
   scope: Namespaced
   names:
     plural: tridenttransactions
@@ -1284,6 +2181,21 @@ spec:
     - name: v1
       served: true
       storage: true
+  validation:
+    openAPIV3Schema:
+      type: object
+      required:
+      - spec
+      properties:
+        spec:
+          type: object
+        dateCreated:
+          type: string
+        size:
+          type: integer
+          format: int64
+        state:
+          type: string
   scope: Namespaced
   names:
     plural: tridentsnapshots
@@ -1394,15 +2306,321 @@ spec:
   version: v1alpha1
 `
 
-func GetCSIDriverCRYAML() string {
-	return CSIDriverCRYAML
+// TopologyKeysAnnotation records the installer's topology keys on the CSIDriver object and the node
+// plugin pods, so that both declare the same keys.  It is informational only; Trident does not
+// report accessible topology for the volumes it creates.
+const TopologyKeysAnnotation = "trident.netapp.io/topologyKeys"
+
+// topologyKeysAnnotationYAML renders the topology keys as a metadata annotations stanza at the
+// given indentation, including the trailing newline, or nothing if there are no keys.
+func topologyKeysAnnotationYAML(topologyKeys []string, indent string) string {
+
+	if len(topologyKeys) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%sannotations:\n%s  %s: \"%s\"\n",
+		indent, indent, TopologyKeysAnnotation, strings.Join(topologyKeys, ","))
+}
+
+// GetCSIDriverCRYAML returns the storage.k8s.io/v1beta1 CSIDriver object for Trident, which is only
+// created on Kubernetes 1.14.  Any topology keys are recorded via the TopologyKeysAnnotation.
+func GetCSIDriverCRYAML(podInfoOnMount bool, topologyKeys []string) string {
+
+	csiDriverYAML := strings.Replace(CSIDriverCRYAMLTemplate, "{POD_INFO_ON_MOUNT}",
+		strconv.FormatBool(podInfoOnMount), 1)
+	csiDriverYAML = strings.Replace(csiDriverYAML, "{TOPOLOGY_ANNOTATIONS}\n",
+		topologyKeysAnnotationYAML(topologyKeys, "  "), 1)
+	return csiDriverYAML
 }
 
-const CSIDriverCRYAML = `
+const CSIDriverCRYAMLTemplate = `
 apiVersion: storage.k8s.io/v1beta1
 kind: CSIDriver
 metadata:
   name: csi.trident.netapp.io
+{TOPOLOGY_ANNOTATIONS}
 spec:
   attachRequired: true
+  podInfoOnMount: {POD_INFO_ON_MOUNT}
+`
+
+// GetOpenShiftSCCUserYAML describes how the installer admits Trident's pods on OpenShift.  Rather than
+// creating a security context constraint, the installer adds Trident's service account as a user of
+// a built-in one, so the document holds only comments with the equivalent command.
+func GetOpenShiftSCCUserYAML(scc, user, namespace string) string {
+
+	sccYAML := strings.Replace(openShiftSCCUserYAMLTemplate, "{SCC}", scc, -1)
+	sccYAML = strings.Replace(sccYAML, "{USER}", user, -1)
+	sccYAML = strings.Replace(sccYAML, "{NAMESPACE}", namespace, -1)
+	return sccYAML
+}
+
+const openShiftSCCUserYAMLTemplate = `---
+# Trident does not create a security context constraint on OpenShift.  The installer adds the
+# {USER} service account as a user of the built-in {SCC} security context constraint,
+# which must be done separately when applying these manifests:
+#
+#   oc adm policy add-scc-to-user {SCC} -z {USER} -n {NAMESPACE}
 `
+
+// TridentReleaseVersion identifies a Trident release whose images have been tested together.
+type TridentReleaseVersion string
+
+const (
+	TridentRelease1907 TridentReleaseVersion = "19.07"
+
+	// CurrentTridentRelease is the release whose CSI sidecar images are used when no release is specified
+	CurrentTridentRelease = TridentRelease1907
+)
+
+// CSISidecarImages are the images of the Kubernetes CSI sidecar containers.
+type CSISidecarImages struct {
+	Provisioner            string
+	Attacher               string
+	Snapshotter            string
+	Resizer                string
+	ClusterDriverRegistrar string
+	NodeDriverRegistrar    string
+}
+
+// replaceImages replaces the sidecar image tokens in a manifest template.
+func (s CSISidecarImages) replaceImages(yaml string) string {
+	yaml = strings.Replace(yaml, "{CSI_PROVISIONER_IMAGE}", s.Provisioner, -1)
+	yaml = strings.Replace(yaml, "{CSI_ATTACHER_IMAGE}", s.Attacher, -1)
+	yaml = strings.Replace(yaml, "{CSI_SNAPSHOTTER_IMAGE}", s.Snapshotter, -1)
+	yaml = strings.Replace(yaml, "{CSI_RESIZER_IMAGE}", s.Resizer, -1)
+	yaml = strings.Replace(yaml, "{CSI_CLUSTER_DRIVER_REGISTRAR_IMAGE}", s.ClusterDriverRegistrar, -1)
+	yaml = strings.Replace(yaml, "{CSI_NODE_DRIVER_REGISTRAR_IMAGE}", s.NodeDriverRegistrar, -1)
+	return yaml
+}
+
+// ReleaseImages are the images released and tested together with a Trident release.  Kubernetes 1.13
+// requires older CSI sidecars than the ones used with Kubernetes 1.14 and later.
+type ReleaseImages struct {
+	Trident        string
+	CSISidecars113 CSISidecarImages
+	CSISidecars    CSISidecarImages
+}
+
+// csiSidecars returns the release's CSI sidecar images for a Kubernetes version.
+func (r ReleaseImages) csiSidecars(version *utils.Version) CSISidecarImages {
+	if version != nil && version.MajorVersion() == 1 && version.MinorVersion() == 13 {
+		return r.CSISidecars113
+	}
+	return r.CSISidecars
+}
+
+var releaseImages = map[TridentReleaseVersion]ReleaseImages{
+	TridentRelease1907: {
+		Trident: "netapp/trident:19.07.0",
+		CSISidecars113: CSISidecarImages{
+			Provisioner:            "quay.io/k8scsi/csi-provisioner:v1.0.1",
+			Attacher:               "quay.io/k8scsi/csi-attacher:v1.0.1",
+			Snapshotter:            "quay.io/k8scsi/csi-snapshotter:v1.0.1",
+			ClusterDriverRegistrar: "quay.io/k8scsi/csi-cluster-driver-registrar:v1.0.1",
+			NodeDriverRegistrar:    "quay.io/k8scsi/csi-node-driver-registrar:v1.0.2",
+		},
+		CSISidecars: CSISidecarImages{
+			Provisioner:         "quay.io/k8scsi/csi-provisioner:v1.2.1",
+			Attacher:            "quay.io/k8scsi/csi-attacher:v1.1.1",
+			Snapshotter:         "quay.io/k8scsi/csi-snapshotter:v1.2.0",
+			Resizer:             "quay.io/k8scsi/csi-resizer:v0.1.0",
+			NodeDriverRegistrar: "quay.io/k8scsi/csi-node-driver-registrar:v1.1.0",
+		},
+	},
+}
+
+// GetReleaseImages returns the images released with a Trident release.
+func GetReleaseImages(version TridentReleaseVersion) (ReleaseImages, error) {
+	images, ok := releaseImages[version]
+	if !ok {
+		return ReleaseImages{}, fmt.Errorf("unknown Trident release %s", version)
+	}
+	return images, nil
+}
+
+// currentReleaseImages returns the images released with the current Trident release.
+func currentReleaseImages() ReleaseImages {
+	return releaseImages[CurrentTridentRelease]
+}
+
+// InstallOptions contains the installation choices that affect the content of the generated manifests.
+type InstallOptions struct {
+	Namespace      string
+	TridentImage   string
+	Label          string
+	NodeLabel      string
+	Debug          bool
+	CSI            bool
+	Replicas       int
+	Strategy       *DeploymentStrategy
+	Readiness      *ReadinessProbe
+	Security       *SecurityContext
+	Proxy          *ProxyConfig
+	NetworkPolicy  *NetworkPolicyConfig
+	Affinity       *v1.Affinity
+	PodInfoOnMount bool
+	TopologyKeys   []string
+	KubeletDir     string
+	Flavor         OrchestratorFlavor
+	Version        *utils.Version
+}
+
+// Manifest is a single named YAML document (or set of documents) produced by the factory.
+type Manifest struct {
+	Name string
+	YAML string
+}
+
+// GetInstallManifests returns, in the order they would be applied, all of the manifests an installation
+// with the specified options would create. The Trident secret is intentionally omitted, since it contains
+// certificates generated at installation time.
+func GetInstallManifests(options *InstallOptions) []Manifest {
+	return getInstallManifests(options, currentReleaseImages().csiSidecars(options.Version))
+}
+
+// GetManifestSet returns the manifests an installation of the specified Trident release would create,
+// using the Trident and CSI sidecar images released and tested together with it in place of the image
+// in the options.  An error is returned if the release is unknown.
+func GetManifestSet(version TridentReleaseVersion, options InstallOptions) ([]Manifest, error) {
+
+	images, err := GetReleaseImages(version)
+	if err != nil {
+		return nil, err
+	}
+
+	options.TridentImage = images.Trident
+	return getInstallManifests(&options, images.csiSidecars(options.Version)), nil
+}
+
+// getInstallManifests returns the manifests an installation with the specified options and CSI sidecar
+// images would create.
+func getInstallManifests(options *InstallOptions, sidecars CSISidecarImages) []Manifest {
+
+	manifests := []Manifest{
+		{"namespace", GetNamespaceYAML(options.Namespace)},
+		{"serviceaccount", GetServiceAccountYAML(options.CSI)},
+		{"clusterrole", GetClusterRoleYAML(options.Flavor, options.CSI, options.Version)},
+		{"clusterrolebinding", GetClusterRoleBindingYAML(options.Namespace, options.Flavor, options.CSI)},
+	}
+
+	if options.Flavor == FlavorOpenShift {
+		if options.CSI {
+			manifests = append(manifests, Manifest{"scc",
+				GetOpenShiftSCCUserYAML("privileged", "trident-csi", options.Namespace)})
+		} else {
+			manifests = append(manifests, Manifest{"scc",
+				GetOpenShiftSCCUserYAML("anyuid", "trident", options.Namespace)})
+		}
+	}
+
+	manifests = append(manifests, Manifest{"crds", GetCRDsYAML()})
+
+	if !options.CSI {
+		manifests = append(manifests, Manifest{"deployment",
+			GetDeploymentYAML(options.TridentImage, options.Label, options.Debug)})
+		return manifests
+	}
+
+	if options.Version.MajorVersion() == 1 {
+		switch options.Version.MinorVersion() {
+		case 13:
+			manifests = append(manifests, Manifest{"csidriver-crds",
+				"---" + GetCSIDriverCRDYAML() + "---" + GetCSINodeInfoCRDYAML()})
+		case 14:
+			manifests = append(manifests, Manifest{"csidriver",
+				GetCSIDriverCRYAML(options.PodInfoOnMount, options.TopologyKeys)})
+		}
+	}
+
+	manifests = append(manifests, Manifest{"service", GetCSIServiceYAML(options.Label)})
+
+	if options.Flavor == FlavorOpenShift {
+		manifests = append(manifests, Manifest{"route", GetOpenShiftRouteYAML(options.Namespace, options.Label)})
+	}
+
+	if options.NetworkPolicy != nil {
+		manifests = append(manifests, Manifest{"networkpolicy", GetNetworkPolicyYAML(options)})
+	}
+
+	manifests = append(manifests,
+		Manifest{"deployment", getCSIDeploymentYAML(options, sidecars)},
+		Manifest{"daemonset", getCSIDaemonSetYAML(options.TridentImage, options.NodeLabel, options.Debug,
+			options.TopologyKeys, options.KubeletDir, options.Version, sidecars)},
+	)
+
+	return manifests
+}
+
+// GetInstallManifestFiles returns every object an installation with the specified options would create,
+// one per file, keyed by file name.  Each name is prefixed with the object's position in apply order, with
+// the namespace and CRDs first, so that the files may be applied in name order, such as from a kustomize
+// directory.  Objects from a manifest with several documents are also named for their metadata name.
+func GetInstallManifestFiles(options *InstallOptions) map[string]string {
+
+	manifests := GetInstallManifests(options)
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return manifestApplyPriority(manifests[i].Name) < manifestApplyPriority(manifests[j].Name)
+	})
+
+	separator := regexp.MustCompile(YAMLSeparator)
+	files := make(map[string]string)
+
+	for _, manifest := range manifests {
+		documents := make([]string, 0)
+		for _, document := range separator.Split("\n"+manifest.YAML, -1) {
+			if document = strings.TrimSpace(document); document != "" {
+				documents = append(documents, document)
+			}
+		}
+
+		for i, document := range documents {
+			name := manifest.Name
+			if len(documents) > 1 {
+				var object struct {
+					Metadata struct {
+						Name string `json:"name"`
+					} `json:"metadata"`
+				}
+				if err := yaml.Unmarshal([]byte(document), &object); err == nil && object.Metadata.Name != "" {
+					name += "-" + strings.SplitN(object.Metadata.Name, ".", 2)[0]
+				} else {
+					name += "-" + strconv.Itoa(i+1)
+				}
+			}
+			files[fmt.Sprintf("%02d-%s.yaml", len(files)+1, name)] = "---\n" + document + "\n"
+		}
+	}
+
+	return files
+}
+
+// manifestApplyPriority orders the namespace and then the CRDs ahead of the other manifests, since
+// the other objects are created in the namespace or may be custom resources.
+func manifestApplyPriority(manifestName string) int {
+	switch manifestName {
+	case "namespace":
+		return 0
+	case "crds", "csidriver-crds":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// GetInstallManifestsYAML returns all of the manifests an installation with the specified options would
+// create as a single multi-document YAML string, suitable for applying (or dry-running) out-of-band.
+func GetInstallManifestsYAML(options *InstallOptions) string {
+
+	var documents []string
+	for _, manifest := range GetInstallManifests(options) {
+		for _, document := range regexp.MustCompile(YAMLSeparator).Split("\n"+manifest.YAML, -1) {
+			if document = strings.TrimSpace(document); document != "" {
+				documents = append(documents, document)
+			}
+		}
+	}
+
+	return "---\n" + strings.Join(documents, "\n---\n") + "\n"
+}
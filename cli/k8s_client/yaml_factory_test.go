@@ -3,9 +3,27 @@
 package k8sclient
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ghodss/yaml"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	tridentv1 "github.com/netapp/trident/persistent_store/crd/apis/netapp/v1"
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/utils"
 )
 
 // TestYAML simple validation of the YAML
@@ -19,6 +37,8 @@ func TestYAML(t *testing.T) {
 		clusterRoleBindingKubernetesV1YAMLTemplate,
 		//deploymentYAMLTemplate,
 		serviceYAMLTemplate,
+		serviceMonitorYAMLTemplate,
+		networkPolicyYAMLTemplate,
 		//statefulSet113YAMLTemplate,
 		//statefulSet114YAMLTemplate,
 		//daemonSet113YAMLTemplate,
@@ -46,3 +66,1610 @@ func TestYAML(t *testing.T) {
 		//fmt.Printf("json: %v", string(jsonData))
 	}
 }
+
+func TestGetServiceMonitorYAML(t *testing.T) {
+
+	var serviceMonitor struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Spec struct {
+			Selector struct {
+				MatchLabels map[string]string `json:"matchLabels"`
+			} `json:"selector"`
+			Endpoints []struct {
+				Port     string `json:"port"`
+				Interval string `json:"interval"`
+			} `json:"endpoints"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(GetServiceMonitorYAML("trident", "trident.csi.netapp.io")),
+		&serviceMonitor); err != nil {
+		t.Fatalf("expected service monitor YAML to be valid: %v", err)
+	}
+	if serviceMonitor.Kind != "ServiceMonitor" || serviceMonitor.Metadata.Namespace != "trident" {
+		t.Errorf("unexpected service monitor kind %s or namespace %s", serviceMonitor.Kind,
+			serviceMonitor.Metadata.Namespace)
+	}
+	if len(serviceMonitor.Spec.Endpoints) != 1 ||
+		serviceMonitor.Spec.Endpoints[0].Port != MetricsPortName ||
+		serviceMonitor.Spec.Endpoints[0].Interval != MetricsScrapeInterval {
+		t.Errorf("unexpected service monitor endpoints %v", serviceMonitor.Spec.Endpoints)
+	}
+
+	// The service monitor must select the service, and the service must expose the metrics port
+	var service v1.Service
+	if err := yaml.Unmarshal([]byte(GetCSIServiceYAML("trident.csi.netapp.io")), &service); err != nil {
+		t.Fatalf("expected service YAML to be valid: %v", err)
+	}
+	for key, value := range serviceMonitor.Spec.Selector.MatchLabels {
+		if service.Labels[key] != value {
+			t.Errorf("service monitor selector %s=%s does not match the service", key, value)
+		}
+	}
+	foundMetricsPort := false
+	for _, port := range service.Spec.Ports {
+		if port.Name == MetricsPortName && port.TargetPort.String() == MetricsPort {
+			foundMetricsPort = true
+		}
+	}
+	if !foundMetricsPort {
+		t.Errorf("expected the service to expose the %s port", MetricsPortName)
+	}
+}
+
+func TestGetOpenShiftRouteYAML(t *testing.T) {
+
+	var route struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Namespace string            `json:"namespace"`
+			Labels    map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			To struct {
+				Kind string `json:"kind"`
+				Name string `json:"name"`
+			} `json:"to"`
+			Port struct {
+				TargetPort string `json:"targetPort"`
+			} `json:"port"`
+			TLS struct {
+				Termination string `json:"termination"`
+			} `json:"tls"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(GetOpenShiftRouteYAML("trident", "trident-csi")), &route); err != nil {
+		t.Fatalf("expected route YAML to be valid: %v", err)
+	}
+
+	if route.APIVersion != "route.openshift.io/v1" || route.Kind != "Route" {
+		t.Errorf("expected a route.openshift.io/v1 Route, got %s %s", route.APIVersion, route.Kind)
+	}
+	if route.Metadata.Namespace != "trident" || route.Metadata.Labels["app"] != "trident-csi" {
+		t.Errorf("expected route in namespace trident with label trident-csi, got %v", route.Metadata)
+	}
+	if route.Spec.TLS.Termination != "passthrough" {
+		t.Errorf("expected TLS passthrough, got %s", route.Spec.TLS.Termination)
+	}
+
+	// The route must target the HTTPS port of the trident-csi service
+	var service v1.Service
+	if err := yaml.Unmarshal([]byte(GetCSIServiceYAML("trident-csi")), &service); err != nil {
+		t.Fatalf("expected service YAML to be valid: %v", err)
+	}
+	if route.Spec.To.Kind != "Service" || route.Spec.To.Name != service.Name {
+		t.Errorf("expected route to target service %s, got %s %s", service.Name, route.Spec.To.Kind,
+			route.Spec.To.Name)
+	}
+	found := false
+	for _, port := range service.Spec.Ports {
+		if port.Name == route.Spec.Port.TargetPort {
+			found = true
+			if port.TargetPort.IntValue() != 8443 {
+				t.Errorf("expected route port %s to reach the HTTPS REST port, got %s", port.Name,
+					port.TargetPort.String())
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected route port %s to be a port of service %s", route.Spec.Port.TargetPort, service.Name)
+	}
+}
+
+func TestGetNetworkPolicyYAML(t *testing.T) {
+
+	policyYAML := GetNetworkPolicyYAML(&InstallOptions{
+		Namespace: "trident",
+		Label:     "trident.csi.netapp.io",
+		NodeLabel: "node.csi.trident.netapp.io",
+		NetworkPolicy: &NetworkPolicyConfig{
+			NodeCIDRs:      []string{"10.0.0.0/24"},
+			APIServerCIDRs: []string{"10.96.0.1/32"},
+			BackendCIDRs:   []string{"192.168.0.0/24"},
+		},
+	})
+
+	var policy networkingv1.NetworkPolicy
+	if err := yaml.Unmarshal([]byte(policyYAML), &policy); err != nil {
+		t.Fatalf("expected network policy YAML to be valid: %v", err)
+	}
+	if policy.Namespace != "trident" {
+		t.Errorf("expected namespace trident, got %s", policy.Namespace)
+	}
+	if policy.Spec.PodSelector.MatchLabels["app"] != "trident.csi.netapp.io" {
+		t.Errorf("expected the policy to select the trident app label, got %v", policy.Spec.PodSelector.MatchLabels)
+	}
+	if len(policy.Spec.PolicyTypes) != 2 {
+		t.Errorf("expected ingress and egress policy types, got %v", policy.Spec.PolicyTypes)
+	}
+
+	foundHTTPSIngress, foundLivenessIngress := false, false
+	for _, rule := range policy.Spec.Ingress {
+		for _, port := range rule.Ports {
+			if port.Port != nil && port.Port.IntValue() == 8443 {
+				foundHTTPSIngress = true
+			}
+			if port.Port != nil && port.Port.String() == LivenessPort {
+				foundLivenessIngress = true
+			}
+		}
+	}
+	if !foundHTTPSIngress {
+		t.Error("expected the policy to allow ingress to the HTTPS port")
+	}
+	if !foundLivenessIngress {
+		t.Error("expected the policy to allow ingress to the liveness probe port")
+	}
+
+	// The HTTPS and liveness ports admit only the node plugin pods and the node addresses
+	for _, rule := range policy.Spec.Ingress {
+		if len(rule.Ports) == 1 && rule.Ports[0].Port != nil && rule.Ports[0].Port.String() == MetricsPort {
+			continue
+		}
+		if len(rule.From) == 0 {
+			t.Errorf("expected ingress rule %v to restrict its peers", rule.Ports)
+		}
+		for _, peer := range rule.From {
+			if peer.PodSelector != nil && peer.PodSelector.MatchLabels["app"] != "node.csi.trident.netapp.io" {
+				t.Errorf("expected ingress only from the node plugin pods, got %v", peer.PodSelector.MatchLabels)
+			}
+			if peer.IPBlock != nil && peer.IPBlock.CIDR != "10.0.0.0/24" {
+				t.Errorf("expected ingress only from the node CIDRs, got %s", peer.IPBlock.CIDR)
+			}
+		}
+	}
+
+	// Every egress rule names its peers, so none of them allows all traffic
+	if len(policy.Spec.Egress) != 3 {
+		t.Errorf("expected DNS, API server and backend egress rules, got %d", len(policy.Spec.Egress))
+	}
+	for _, rule := range policy.Spec.Egress {
+		if len(rule.To) == 0 {
+			t.Errorf("expected egress rule %v to restrict its peers", rule.Ports)
+		}
+		for _, peer := range rule.To {
+			if peer.IPBlock != nil && peer.IPBlock.CIDR == "0.0.0.0/0" {
+				t.Errorf("expected egress rule %v not to allow all addresses", rule.Ports)
+			}
+		}
+	}
+}
+
+func TestNewNetworkPolicyConfig(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		nodeCIDRs      []string
+		apiServerCIDRs []string
+		backendCIDRs   []string
+		valid          bool
+		expectPolicy   bool
+	}{
+		{"none", nil, nil, nil, true, false},
+		{"all", []string{"10.0.0.0/24"}, []string{"10.96.0.1/32"}, []string{"192.168.0.0/24"}, true, true},
+		{"no backends", []string{"10.0.0.0/24"}, []string{"10.96.0.1/32"}, nil, false, false},
+		{"no nodes", nil, []string{"10.96.0.1/32"}, []string{"192.168.0.0/24"}, false, false},
+		{"invalid CIDR", []string{"10.0.0.0"}, []string{"10.96.0.1/32"}, []string{"192.168.0.0/24"}, false, false},
+	}
+
+	for _, test := range tests {
+		config, err := NewNetworkPolicyConfig(test.nodeCIDRs, test.apiServerCIDRs, test.backendCIDRs)
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+		if (config != nil) != test.expectPolicy {
+			t.Errorf("%s: unexpected network policy %v", test.name, config)
+		}
+	}
+}
+
+func TestGetCSIDeploymentYAMLRollingUpdate(t *testing.T) {
+
+	strategy, err := NewDeploymentStrategy(2, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error creating deployment strategy: %v", err)
+	}
+
+	for _, version := range []string{"v1.13.0", "v1.14.0", "v1.16.0"} {
+		deploymentYAML := GetCSIDeploymentYAML(&InstallOptions{
+			TridentImage: "trident:test",
+			Label:        "trident-csi",
+			Replicas:     2,
+			Strategy:     strategy,
+			Version:      utils.MustParseSemantic(version),
+		})
+
+		var deployment v1beta1.Deployment
+		if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+			t.Fatalf("expected deployment YAML for %s to be valid: %v", version, err)
+		}
+		if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 2 {
+			t.Errorf("expected 2 replicas for %s", version)
+		}
+		if deployment.Spec.Strategy.Type != v1beta1.RollingUpdateDeploymentStrategyType {
+			t.Errorf("expected RollingUpdate strategy for %s, got %s", version, deployment.Spec.Strategy.Type)
+		}
+		rollingUpdate := deployment.Spec.Strategy.RollingUpdate
+		if rollingUpdate == nil || rollingUpdate.MaxUnavailable.String() != DefaultMaxUnavailable ||
+			rollingUpdate.MaxSurge.String() != DefaultMaxSurge {
+			t.Errorf("expected default rolling update parameters for %s", version)
+		}
+	}
+}
+
+func TestGetCSIDeploymentYAMLRecreate(t *testing.T) {
+
+	strategy, err := NewDeploymentStrategy(1, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error creating deployment strategy: %v", err)
+	}
+
+	deploymentYAML := GetCSIDeploymentYAML(&InstallOptions{
+		TridentImage: "trident:test",
+		Label:        "trident-csi",
+		Replicas:     1,
+		Strategy:     strategy,
+		Version:      utils.MustParseSemantic("v1.14.0"),
+	})
+
+	var deployment v1beta1.Deployment
+	if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+		t.Fatalf("expected deployment YAML to be valid: %v", err)
+	}
+	if deployment.Spec.Strategy.Type != v1beta1.RecreateDeploymentStrategyType {
+		t.Errorf("expected Recreate strategy, got %s", deployment.Spec.Strategy.Type)
+	}
+}
+
+func TestGetCSIDeploymentYAMLReadinessProbe(t *testing.T) {
+
+	strategy, err := NewDeploymentStrategy(1, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error creating deployment strategy: %v", err)
+	}
+	readiness, err := NewReadinessProbe(30*time.Second, 15*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error creating readiness probe: %v", err)
+	}
+
+	for _, version := range []string{"v1.13.0", "v1.14.0", "v1.16.0"} {
+		deploymentYAML := GetCSIDeploymentYAML(&InstallOptions{
+			TridentImage: "trident:test",
+			Label:        "trident-csi",
+			Replicas:     1,
+			Strategy:     strategy,
+			Readiness:    readiness,
+			Version:      utils.MustParseSemantic(version),
+		})
+
+		var deployment v1beta1.Deployment
+		if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+			t.Fatalf("expected deployment YAML for %s to be valid: %v", version, err)
+		}
+		probe := deployment.Spec.Template.Spec.Containers[0].ReadinessProbe
+		if probe == nil || probe.Exec == nil {
+			t.Fatalf("expected an exec readiness probe on trident-main for %s", version)
+		}
+		if probe.InitialDelaySeconds != 30 || probe.PeriodSeconds != 15 {
+			t.Errorf("expected readiness timing 30s/15s for %s, got %ds/%ds", version,
+				probe.InitialDelaySeconds, probe.PeriodSeconds)
+		}
+		if probe.FailureThreshold < 2 {
+			t.Errorf("expected a readiness failure threshold that tolerates bootstrap for %s", version)
+		}
+	}
+
+	// A nil probe should use the defaults
+	deploymentYAML := GetCSIDeploymentYAML(&InstallOptions{
+		TridentImage: "trident:test",
+		Label:        "trident-csi",
+		Replicas:     1,
+		Strategy:     strategy,
+		Version:      utils.MustParseSemantic("v1.14.0"),
+	})
+	var deployment v1beta1.Deployment
+	if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+		t.Fatalf("expected deployment YAML to be valid: %v", err)
+	}
+	probe := deployment.Spec.Template.Spec.Containers[0].ReadinessProbe
+	if probe == nil || probe.PeriodSeconds != int32(DefaultReadinessPeriod/time.Second) {
+		t.Error("expected a readiness probe with the default period")
+	}
+}
+
+func TestGetCSIDeploymentYAMLSecurityContext(t *testing.T) {
+
+	strategy, err := NewDeploymentStrategy(1, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error creating deployment strategy: %v", err)
+	}
+	securityContext, err := NewSecurityContext(2000)
+	if err != nil {
+		t.Fatalf("unexpected error creating security context: %v", err)
+	}
+
+	for _, version := range []string{"v1.13.0", "v1.14.0", "v1.16.0"} {
+		k8sVersion := utils.MustParseSemantic(version)
+
+		var deployment v1beta1.Deployment
+		deploymentYAML := GetCSIDeploymentYAML(&InstallOptions{
+			TridentImage: "trident:test",
+			Label:        "trident-csi",
+			Replicas:     1,
+			Strategy:     strategy,
+			Security:     securityContext,
+			Version:      k8sVersion,
+		})
+		if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+			t.Fatalf("expected deployment YAML for %s to be valid: %v", version, err)
+		}
+		podContext := deployment.Spec.Template.Spec.SecurityContext
+		if podContext == nil || podContext.RunAsNonRoot == nil || !*podContext.RunAsNonRoot ||
+			podContext.RunAsUser == nil || *podContext.RunAsUser != 2000 {
+			t.Errorf("expected a non-root pod security context for %s", version)
+		}
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			containerContext := container.SecurityContext
+			if containerContext == nil || containerContext.ReadOnlyRootFilesystem == nil ||
+				!*containerContext.ReadOnlyRootFilesystem || containerContext.Capabilities == nil ||
+				len(containerContext.Capabilities.Drop) != 1 || containerContext.Capabilities.Drop[0] != "ALL" {
+				t.Errorf("expected a restricted security context on container %s for %s", container.Name, version)
+			}
+		}
+
+		// Without a security context, none should be rendered
+		deploymentYAML = GetCSIDeploymentYAML(&InstallOptions{
+			TridentImage: "trident:test",
+			Label:        "trident-csi",
+			Replicas:     1,
+			Strategy:     strategy,
+			Version:      k8sVersion,
+		})
+		deployment = v1beta1.Deployment{}
+		if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+			t.Fatalf("expected deployment YAML for %s to be valid: %v", version, err)
+		}
+		if deployment.Spec.Template.Spec.SecurityContext != nil {
+			t.Errorf("expected no pod security context for %s", version)
+		}
+
+		// The node plugin needs privileges, so it must never run as non-root
+		var daemonSet v1beta1.DaemonSet
+		if err := yaml.Unmarshal([]byte(GetCSIDaemonSetYAML("trident:test", "trident-node", false, nil,
+			"", k8sVersion)),
+			&daemonSet); err != nil {
+			t.Fatalf("expected daemonset YAML for %s to be valid: %v", version, err)
+		}
+		if podContext := daemonSet.Spec.Template.Spec.SecurityContext; podContext != nil &&
+			podContext.RunAsNonRoot != nil && *podContext.RunAsNonRoot {
+			t.Errorf("expected the node plugin not to run as non-root for %s", version)
+		}
+	}
+
+	if _, err := NewSecurityContext(0); err == nil {
+		t.Error("expected an error for a root user ID")
+	}
+}
+
+func TestCSIYAMLTerminationGracePeriod(t *testing.T) {
+
+	// The pods must outlast the CSI frontend's wait for in-flight operations when they stop
+	for _, version := range []string{"v1.13.0", "v1.14.0"} {
+		k8sVersion := utils.MustParseSemantic(version)
+
+		var deployment v1beta1.Deployment
+		if err := yaml.Unmarshal([]byte(GetCSIDeploymentYAML(&InstallOptions{
+			TridentImage: "trident:test",
+			Label:        "trident-csi",
+			Version:      k8sVersion,
+		})), &deployment); err != nil {
+			t.Fatalf("expected deployment YAML for %s to be valid: %v", version, err)
+		}
+		if period := deployment.Spec.Template.Spec.TerminationGracePeriodSeconds; period == nil || *period != 90 {
+			t.Errorf("expected a deployment termination grace period of 90 seconds for %s, got %v",
+				version, period)
+		}
+
+		var daemonSet v1beta1.DaemonSet
+		if err := yaml.Unmarshal([]byte(GetCSIDaemonSetYAML("trident:test", "trident-node", false, nil,
+			"", k8sVersion)), &daemonSet); err != nil {
+			t.Fatalf("expected daemonset YAML for %s to be valid: %v", version, err)
+		}
+		if period := daemonSet.Spec.Template.Spec.TerminationGracePeriodSeconds; period == nil || *period != 90 {
+			t.Errorf("expected a daemonset termination grace period of 90 seconds for %s, got %v",
+				version, period)
+		}
+	}
+}
+
+func TestCSIDaemonSetYAMLKubeletDir(t *testing.T) {
+
+	const kubeletDir = "/var/data/kubelet"
+
+	if socketPath := GetCSISocketPath(""); socketPath !=
+		"/var/lib/kubelet/plugins/csi.trident.netapp.io/csi.sock" {
+		t.Errorf("expected the default CSI socket path, got %s", socketPath)
+	}
+
+	for _, version := range []string{"v1.13.0", "v1.14.0"} {
+
+		daemonSetYAML := GetCSIDaemonSetYAML("trident:test", "trident-node", false, nil, kubeletDir+"/",
+			utils.MustParseSemantic(version))
+		if strings.Contains(daemonSetYAML, DefaultKubeletDir) {
+			t.Errorf("expected no default kubelet paths for %s", version)
+		}
+
+		var daemonSet v1beta1.DaemonSet
+		if err := yaml.Unmarshal([]byte(daemonSetYAML), &daemonSet); err != nil {
+			t.Fatalf("expected daemonset YAML for %s to be valid: %v", version, err)
+		}
+
+		kubeletVolumes := make(map[string]bool)
+		for _, volume := range daemonSet.Spec.Template.Spec.Volumes {
+			if volume.HostPath == nil {
+				continue
+			}
+			switch volume.Name {
+			case "plugin-dir", "registration-dir", "plugins-mount-dir", "pods-mount-dir":
+				if !strings.HasPrefix(volume.HostPath.Path, kubeletDir+"/") {
+					t.Errorf("expected volume %s under %s for %s, got %s", volume.Name, kubeletDir, version,
+						volume.HostPath.Path)
+				}
+				kubeletVolumes[volume.Name] = true
+			}
+		}
+		if len(kubeletVolumes) != 4 {
+			t.Errorf("expected 4 kubelet host paths for %s, got %v", version, kubeletVolumes)
+		}
+
+		var registrationPath string
+		for _, container := range daemonSet.Spec.Template.Spec.Containers {
+			for _, mount := range container.VolumeMounts {
+				if mount.Name == "plugins-mount-dir" || mount.Name == "pods-mount-dir" {
+					if !strings.HasPrefix(mount.MountPath, kubeletDir+"/") {
+						t.Errorf("expected mount %s under %s for %s, got %s", mount.Name, kubeletDir, version,
+							mount.MountPath)
+					}
+				}
+			}
+			for _, env := range container.Env {
+				if env.Name == "REGISTRATION_PATH" {
+					registrationPath = env.Value
+				}
+			}
+		}
+		if registrationPath != GetCSISocketPath(kubeletDir) ||
+			registrationPath != kubeletDir+"/plugins/csi.trident.netapp.io/csi.sock" {
+			t.Errorf("expected registration path under %s for %s, got %s", kubeletDir, version, registrationPath)
+		}
+	}
+
+	if err := ValidateKubeletDir("var/lib/kubelet"); err == nil {
+		t.Error("expected an error for a relative kubelet directory")
+	}
+}
+
+func TestCSIDeploymentYAMLProxy(t *testing.T) {
+
+	proxy, err := NewProxyConfig("http://proxy.example.com:3128", "https://proxy.example.com:3129",
+		[]string{"10.244.0.0/16", ".svc"})
+	if err != nil {
+		t.Fatalf("unexpected error creating proxy config: %v", err)
+	}
+
+	expected := map[string]string{
+		"HTTP_PROXY":  "http://proxy.example.com:3128",
+		"HTTPS_PROXY": "https://proxy.example.com:3129",
+		"NO_PROXY":    "localhost,127.0.0.1,$(KUBERNETES_SERVICE_HOST),kubernetes.default.svc,.svc,.cluster.local,10.244.0.0/16",
+	}
+
+	// mainEnv returns the proxy variables of the trident-main container
+	mainEnv := func(proxy *ProxyConfig, version string) map[string]string {
+		var deployment v1beta1.Deployment
+		deploymentYAML := GetCSIDeploymentYAML(&InstallOptions{
+			TridentImage: "trident:test",
+			Label:        "trident-csi",
+			Replicas:     1,
+			Proxy:        proxy,
+			Version:      utils.MustParseSemantic(version),
+		})
+		if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+			t.Fatalf("expected deployment YAML for %s to be valid: %v", version, err)
+		}
+		env := make(map[string]string)
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			if container.Name != "trident-main" {
+				continue
+			}
+			for _, variable := range container.Env {
+				if _, ok := expected[variable.Name]; ok {
+					env[variable.Name] = variable.Value
+				}
+			}
+		}
+		return env
+	}
+
+	for _, version := range []string{"v1.13.0", "v1.14.0"} {
+		if env := mainEnv(proxy, version); !reflect.DeepEqual(env, expected) {
+			t.Errorf("expected proxy env %v for %s, got %v", expected, version, env)
+		}
+		if env := mainEnv(nil, version); len(env) != 0 {
+			t.Errorf("expected no proxy env for %s, got %v", version, env)
+		}
+	}
+
+	// A proxy may be set for just one scheme
+	httpsOnly, err := NewProxyConfig("", "https://proxy.example.com:3129", nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating proxy config: %v", err)
+	}
+	if env := mainEnv(httpsOnly, "v1.14.0"); env["HTTP_PROXY"] != "" || env["HTTPS_PROXY"] == "" ||
+		env["NO_PROXY"] != strings.Join(DefaultNoProxy, ",") {
+		t.Errorf("expected only HTTPS_PROXY and the default NO_PROXY, got %v", env)
+	}
+
+	if proxy, err = NewProxyConfig("", "", nil); err != nil || proxy != nil {
+		t.Errorf("expected no proxy config without proxies, got %v: %v", proxy, err)
+	}
+	for _, invalid := range [][]string{{"proxy.example.com:3128", ""}, {"", "ftp://proxy.example.com"}} {
+		if _, err = NewProxyConfig(invalid[0], invalid[1], nil); err == nil {
+			t.Errorf("expected an error for proxies %v", invalid)
+		}
+	}
+	if _, err = NewProxyConfig("", "", []string{"10.0.0.0/8"}); err == nil {
+		t.Error("expected an error for proxy exclusions without a proxy")
+	}
+}
+
+func TestCSIDeploymentYAMLAffinity(t *testing.T) {
+
+	affinity := NewControllerAntiAffinity("trident-csi", "topology.kubernetes.io/zone")
+	affinity.NodeAffinity = &v1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{{
+				MatchExpressions: []v1.NodeSelectorRequirement{{
+					Key:      "node-role.kubernetes.io/infra",
+					Operator: v1.NodeSelectorOpExists,
+				}},
+			}},
+		},
+	}
+
+	for _, version := range []string{"v1.13.0", "v1.14.0"} {
+
+		deploymentYAML := GetCSIDeploymentYAML(&InstallOptions{
+			TridentImage: "trident:test",
+			Label:        "trident-csi",
+			Replicas:     2,
+			Affinity:     affinity,
+			Version:      utils.MustParseSemantic(version),
+		})
+		if strings.Contains(deploymentYAML, "{") {
+			t.Errorf("expected all tokens to be replaced in deployment YAML for %s", version)
+		}
+		var deployment v1beta1.Deployment
+		if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+			t.Fatalf("expected deployment YAML for %s to be valid: %v", version, err)
+		}
+		if !reflect.DeepEqual(deployment.Spec.Template.Spec.Affinity, affinity) {
+			t.Errorf("expected affinity %v for %s, got %v", affinity, version, deployment.Spec.Template.Spec.Affinity)
+		}
+		terms := deployment.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		if len(terms) != 1 || terms[0].PodAffinityTerm.TopologyKey != "topology.kubernetes.io/zone" ||
+			terms[0].PodAffinityTerm.LabelSelector.MatchLabels["app"] != "trident-csi" {
+			t.Errorf("expected a zone anti-affinity term for %s, got %v", version, terms)
+		}
+
+		deploymentYAML = GetCSIDeploymentYAML(&InstallOptions{
+			TridentImage: "trident:test",
+			Label:        "trident-csi",
+			Replicas:     2,
+			Version:      utils.MustParseSemantic(version),
+		})
+		deployment = v1beta1.Deployment{}
+		if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+			t.Fatalf("expected deployment YAML for %s to be valid: %v", version, err)
+		}
+		if deployment.Spec.Template.Spec.Affinity != nil {
+			t.Errorf("expected no affinity for %s", version)
+		}
+	}
+}
+
+func TestDeploymentYAMLProbePort(t *testing.T) {
+
+	strategy, err := NewDeploymentStrategy(1, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error creating deployment strategy: %v", err)
+	}
+
+	deploymentYAMLs := map[string]string{
+		"legacy": GetDeploymentYAML("trident:test", "trident", false),
+		"csi-1.13": GetCSIDeploymentYAML(&InstallOptions{
+			TridentImage: "trident:test",
+			Label:        "trident-csi",
+			Replicas:     1,
+			Strategy:     strategy,
+			Version:      utils.MustParseSemantic("v1.13.0"),
+		}),
+		"csi-1.14": GetCSIDeploymentYAML(&InstallOptions{
+			TridentImage: "trident:test",
+			Label:        "trident-csi",
+			Replicas:     1,
+			Strategy:     strategy,
+			Version:      utils.MustParseSemantic("v1.14.0"),
+		}),
+	}
+
+	for name, deploymentYAML := range deploymentYAMLs {
+
+		var deployment v1beta1.Deployment
+		if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+			t.Fatalf("expected %s deployment YAML to be valid: %v", name, err)
+		}
+		container := deployment.Spec.Template.Spec.Containers[0]
+
+		foundPortArg := false
+		for _, arg := range container.Args {
+			if arg == "--port="+RESTPort {
+				foundPortArg = true
+			}
+		}
+		if !foundPortArg {
+			t.Errorf("expected the %s deployment to set the REST port, got args %v", name, container.Args)
+		}
+
+		if name != "legacy" {
+			probe := container.ReadinessProbe
+			if probe == nil || probe.Exec == nil || !strings.Contains(strings.Join(probe.Exec.Command, " "),
+				"127.0.0.1:"+RESTPort) {
+				t.Errorf("expected the %s deployment readiness probe to use the REST port", name)
+			}
+		}
+
+		foundProbePortArg := false
+		for _, arg := range container.Args {
+			if arg == "--probe_port="+LivenessPort {
+				foundProbePortArg = true
+			}
+		}
+		if !foundProbePortArg {
+			t.Errorf("expected the %s deployment to set the liveness probe port, got args %v", name, container.Args)
+		}
+		probe := container.LivenessProbe
+		if probe == nil || probe.HTTPGet == nil || probe.HTTPGet.Path != "/liveness" ||
+			probe.HTTPGet.Port.String() != LivenessPort {
+			t.Errorf("expected the %s deployment liveness probe to get /liveness on port %s", name, LivenessPort)
+		}
+		if strings.Contains(deploymentYAML, "{PROBE_PORT}") || strings.Contains(deploymentYAML, "{LIVENESS_PORT}") {
+			t.Errorf("expected no unreplaced probe port tokens in the %s deployment", name)
+		}
+	}
+}
+
+func TestGetCSIDriverCRYAML(t *testing.T) {
+
+	type csiDriver struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Spec       struct {
+			AttachRequired bool `json:"attachRequired"`
+			PodInfoOnMount bool `json:"podInfoOnMount"`
+		} `json:"spec"`
+	}
+
+	for _, podInfoOnMount := range []bool{false, true} {
+		var driver csiDriver
+		if err := yaml.Unmarshal([]byte(GetCSIDriverCRYAML(podInfoOnMount, nil)), &driver); err != nil {
+			t.Fatalf("expected CSIDriver YAML to be valid: %v", err)
+		}
+		if driver.Kind != "CSIDriver" || driver.APIVersion != "storage.k8s.io/v1beta1" {
+			t.Errorf("expected a storage.k8s.io/v1beta1 CSIDriver, got %s %s", driver.Kind, driver.APIVersion)
+		}
+		if !driver.Spec.AttachRequired {
+			t.Error("expected attachRequired")
+		}
+		if driver.Spec.PodInfoOnMount != podInfoOnMount {
+			t.Errorf("expected podInfoOnMount %v, got %v", podInfoOnMount, driver.Spec.PodInfoOnMount)
+		}
+	}
+}
+
+func TestTopologyKeysRoundTrip(t *testing.T) {
+
+	topologyKeys := []string{"topology.kubernetes.io/region", "topology.kubernetes.io/zone"}
+
+	k8sVersion := utils.MustParseSemantic("v1.14.0")
+
+	var driver struct {
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(GetCSIDriverCRYAML(false, topologyKeys)), &driver); err != nil {
+		t.Fatalf("expected CSIDriver YAML to be valid: %v", err)
+	}
+	driverKeys := strings.Split(driver.Metadata.Annotations[TopologyKeysAnnotation], ",")
+	if !reflect.DeepEqual(driverKeys, topologyKeys) {
+		t.Errorf("expected CSIDriver topology keys %v, got %v", topologyKeys, driverKeys)
+	}
+
+	var daemonSet v1beta1.DaemonSet
+	if err := yaml.Unmarshal([]byte(GetCSIDaemonSetYAML("trident:test", "trident-node", false, topologyKeys,
+		"", k8sVersion)), &daemonSet); err != nil {
+		t.Fatalf("expected daemonset YAML to be valid: %v", err)
+	}
+	nodeKeys := strings.Split(daemonSet.Spec.Template.Annotations[TopologyKeysAnnotation], ",")
+	if !reflect.DeepEqual(nodeKeys, driverKeys) {
+		t.Errorf("expected node plugin topology keys %v, got %v", driverKeys, nodeKeys)
+	}
+
+	// Without topology keys, neither object is annotated
+	if strings.Contains(GetCSIDriverCRYAML(false, nil), "annotations") {
+		t.Error("expected no CSIDriver annotations without topology keys")
+	}
+}
+
+func TestGetCSIDeploymentYAMLLeaderElection(t *testing.T) {
+
+	isLeaderElectionArg := func(arg string) bool {
+		return strings.Contains(arg, "leader-election") || strings.Contains(arg, "leader_election")
+	}
+
+	for _, version := range []string{"v1.13.0", "v1.14.0", "v1.16.0"} {
+		for _, replicas := range []int{1, 2, 3} {
+
+			strategy, err := NewDeploymentStrategy(replicas, "", "", "")
+			if err != nil {
+				t.Fatalf("unexpected error creating deployment strategy: %v", err)
+			}
+
+			var deployment v1beta1.Deployment
+			deploymentYAML := GetCSIDeploymentYAML(&InstallOptions{
+				TridentImage: "trident:test",
+				Label:        "trident-csi",
+				Replicas:     replicas,
+				Strategy:     strategy,
+				Version:      utils.MustParseSemantic(version),
+			})
+			if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+				t.Fatalf("expected deployment YAML for %s to be valid: %v", version, err)
+			}
+
+			// Only Trident elects a leader; the sidecars follow it
+			for _, container := range deployment.Spec.Template.Spec.Containers {
+				expected := container.Name == "trident-main" && replicas > 1
+				found := false
+				for _, containerArg := range container.Args {
+					if isLeaderElectionArg(containerArg) {
+						found = true
+					}
+				}
+				if found != expected {
+					t.Errorf("expected %s leader election %v for %d replicas on %s", container.Name, expected,
+						replicas, version)
+				}
+			}
+		}
+	}
+}
+
+func TestResizerRBACAndSidecar(t *testing.T) {
+
+	resizerRule := func(clusterRole *rbacv1.ClusterRole) bool {
+		for _, rule := range clusterRole.Rules {
+			for _, resource := range rule.Resources {
+				if resource == "persistentvolumeclaims/status" {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for _, version := range []string{"v1.13.0", "v1.14.0", "v1.16.0"} {
+		k8sVersion := utils.MustParseSemantic(version)
+		expected := supportsCSIResize(k8sVersion)
+
+		var clusterRole rbacv1.ClusterRole
+		if err := yaml.Unmarshal([]byte(GetClusterRoleYAML(FlavorKubernetes, true, k8sVersion)),
+			&clusterRole); err != nil {
+			t.Fatalf("expected cluster role YAML for %s to be valid: %v", version, err)
+		}
+		if resizerRule(&clusterRole) != expected {
+			t.Errorf("expected resizer RBAC %v on %s", expected, version)
+		}
+
+		var deployment v1beta1.Deployment
+		deploymentYAML := GetCSIDeploymentYAML(&InstallOptions{
+			TridentImage: "trident:test",
+			Label:        "trident-csi",
+			Replicas:     1,
+			Security:     &SecurityContext{RunAsUser: 1000},
+			Version:      k8sVersion,
+		})
+		if strings.Contains(deploymentYAML, "{") {
+			t.Errorf("expected all tokens to be replaced in deployment YAML for %s", version)
+		}
+		if err := yaml.Unmarshal([]byte(deploymentYAML), &deployment); err != nil {
+			t.Fatalf("expected deployment YAML for %s to be valid: %v", version, err)
+		}
+		found := false
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			if container.Name == "csi-resizer" {
+				found = true
+				if container.SecurityContext == nil {
+					t.Errorf("expected csi-resizer security context on %s", version)
+				}
+			}
+		}
+		if found != expected {
+			t.Errorf("expected csi-resizer sidecar %v on %s", expected, version)
+		}
+	}
+
+	// The node plugin doesn't need resizer permissions
+	var clusterRole rbacv1.ClusterRole
+	if err := yaml.Unmarshal([]byte(GetClusterRoleYAML(FlavorKubernetes, false, utils.MustParseSemantic("v1.16.0"))),
+		&clusterRole); err != nil {
+		t.Fatalf("expected cluster role YAML to be valid: %v", err)
+	}
+	if resizerRule(&clusterRole) {
+		t.Error("expected no resizer RBAC in the non-CSI cluster role")
+	}
+}
+
+// crdSchema mirrors the parts of a CRD's OpenAPI v3 schema used by Trident.
+type crdSchema struct {
+	Type                  string                `json:"type,omitempty"`
+	Format                string                `json:"format,omitempty"`
+	Required              []string              `json:"required,omitempty"`
+	Properties            map[string]*crdSchema `json:"properties,omitempty"`
+	Items                 *crdSchema            `json:"items,omitempty"`
+	PreserveUnknownFields bool                  `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+}
+
+// validate checks a decoded JSON value against the schema much as the API server would, returning
+// a description of each violation.  Fields the schema doesn't declare are also reported, so that the
+// schema stays a complete description of what Trident writes.
+func (s *crdSchema) validate(value interface{}, path string) []string {
+
+	var problems []string
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s must be an object", path)}
+		}
+		for _, field := range s.Required {
+			if _, ok := object[field]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s is required", path, field))
+			}
+		}
+		for field, fieldValue := range object {
+			if fieldSchema, ok := s.Properties[field]; ok {
+				problems = append(problems, fieldSchema.validate(fieldValue, path+"."+field)...)
+			} else if !s.PreserveUnknownFields && !(path == "" && (field == "apiVersion" ||
+				field == "kind" || field == "metadata")) {
+				problems = append(problems, fmt.Sprintf("%s.%s is not declared", path, field))
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s must be an array", path)}
+		}
+		for i, item := range array {
+			problems = append(problems, s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			problems = append(problems, fmt.Sprintf("%s must be a string", path))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s must be a boolean", path))
+		}
+	case "integer":
+		if number, ok := value.(float64); !ok || number != float64(int64(number)) {
+			problems = append(problems, fmt.Sprintf("%s must be an integer", path))
+		}
+	}
+
+	return problems
+}
+
+func TestCRDSchemas(t *testing.T) {
+
+	separator := regexp.MustCompile(YAMLSeparator)
+
+	// Collect the schemas of the CRDs, which the apiextensions v1beta1 API keeps in the spec
+	schemas := make(map[string]*crdSchema)
+	for _, document := range separator.Split("\n"+GetCRDsYAML(), -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+		var crd struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				Validation struct {
+					OpenAPIV3Schema *crdSchema `json:"openAPIV3Schema"`
+				} `json:"validation"`
+			} `json:"spec"`
+		}
+		if err := yaml.Unmarshal([]byte(document), &crd); err != nil {
+			t.Fatalf("expected CRD YAML to be valid: %v", err)
+		}
+		if crd.Spec.Validation.OpenAPIV3Schema == nil {
+			t.Fatalf("expected CRD %s to have a schema", crd.Metadata.Name)
+		}
+		schemas[crd.Metadata.Name] = crd.Spec.Validation.OpenAPIV3Schema
+	}
+
+	volume, err := tridentv1.NewTridentVolume(&storage.VolumeExternal{
+		Config:      &storage.VolumeConfig{Name: "vol1", Size: "1073741824", StorageClass: "gold"},
+		BackendUUID: "ed6cf2d6-3c2c-4a5e-a7f7-3b8a3ac8a55e",
+		Pool:        "aggr1",
+		State:       storage.VolumeStateOnline,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend, err := tridentv1.NewTridentBackend(&storage.BackendPersistent{
+		Version:     "1",
+		Name:        "nas",
+		BackendUUID: "ed6cf2d6-3c2c-4a5e-a7f7-3b8a3ac8a55e",
+		Online:      true,
+		State:       storage.Online,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := tridentv1.NewTridentSnapshot(storage.NewSnapshot(
+		&storage.SnapshotConfig{Name: "snap1", VolumeName: "vol1"}, "2019-06-01T00:00:00Z", 1073741824,
+	).ConstructPersistent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objects := []struct {
+		crd      string
+		object   interface{}
+		required []string
+	}{
+		{"tridentvolumes.trident.netapp.io", volume, []string{"config", "backendUUID"}},
+		{"tridentbackends.trident.netapp.io", backend, []string{"config", "backendName", "backendUUID"}},
+		{"tridentsnapshots.trident.netapp.io", snapshot, []string{"spec"}},
+	}
+
+	for _, o := range objects {
+		schema, ok := schemas[o.crd]
+		if !ok {
+			t.Fatalf("expected a CRD named %s", o.crd)
+		}
+
+		objectJSON, err := json.Marshal(o.object)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decode := func() map[string]interface{} {
+			var object map[string]interface{}
+			if err := json.Unmarshal(objectJSON, &object); err != nil {
+				t.Fatal(err)
+			}
+			return object
+		}
+
+		// Objects written by Trident must be accepted without losing any fields
+		if problems := schema.validate(decode(), ""); len(problems) != 0 {
+			t.Errorf("expected a valid %s to be accepted, got %v", o.crd, problems)
+		}
+
+		// Objects missing a required field must be rejected
+		for _, field := range o.required {
+			object := decode()
+			delete(object, field)
+			if problems := schema.validate(object, ""); len(problems) == 0 {
+				t.Errorf("expected a %s without %s to be rejected", o.crd, field)
+			}
+		}
+
+		// Objects with a field of the wrong type must be rejected
+		object := decode()
+		object[o.required[len(o.required)-1]] = 42
+		if problems := schema.validate(object, ""); len(problems) == 0 {
+			t.Errorf("expected a %s with a mistyped field to be rejected", o.crd)
+		}
+	}
+}
+
+func TestNewReadinessProbeValidation(t *testing.T) {
+
+	for _, timing := range [][]time.Duration{
+		{-time.Second, 10 * time.Second},
+		{10 * time.Second, 0},
+		{10 * time.Second, 500 * time.Millisecond},
+	} {
+		if _, err := NewReadinessProbe(timing[0], timing[1]); err == nil {
+			t.Errorf("expected an error for readiness timing %v", timing)
+		}
+	}
+}
+
+func TestNewDeploymentStrategyValidation(t *testing.T) {
+
+	invalid := []struct {
+		replicas       int
+		strategyType   string
+		maxUnavailable string
+		maxSurge       string
+	}{
+		{0, "", "", ""},
+		{2, "Canary", "", ""},
+		{1, DeploymentStrategyRecreate, "1", ""},
+		{2, DeploymentStrategyRollingUpdate, "0", "0"},
+		{2, DeploymentStrategyRollingUpdate, "0%", "0%"},
+		{2, DeploymentStrategyRollingUpdate, "-1", "1"},
+		{2, DeploymentStrategyRollingUpdate, "150%", "1"},
+		{2, DeploymentStrategyRollingUpdate, "1", "one"},
+	}
+	for _, c := range invalid {
+		if _, err := NewDeploymentStrategy(c.replicas, c.strategyType, c.maxUnavailable, c.maxSurge); err == nil {
+			t.Errorf("expected error for %+v", c)
+		}
+	}
+
+	strategy, err := NewDeploymentStrategy(3, DeploymentStrategyRollingUpdate, "25%", "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strategy.MaxUnavailable != "25%" || strategy.MaxSurge != "1" {
+		t.Errorf("unexpected rolling update parameters: %+v", strategy)
+	}
+}
+
+func TestGetInstallManifests(t *testing.T) {
+
+	strategy, err := NewDeploymentStrategy(1, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error creating deployment strategy: %v", err)
+	}
+
+	separator := regexp.MustCompile(YAMLSeparator)
+
+	for _, flavor := range []OrchestratorFlavor{FlavorKubernetes, FlavorOpenShift} {
+		for _, version := range []string{"v1.11.0", "v1.13.0", "v1.14.0"} {
+
+			options := &InstallOptions{
+				Namespace:    "trident",
+				TridentImage: "trident:test",
+				Label:        "trident-csi",
+				NodeLabel:    "trident-node",
+				CSI:          version != "v1.11.0",
+				Replicas:     1,
+				Strategy:     strategy,
+				Flavor:       flavor,
+				Version:      utils.MustParseSemantic(version),
+			}
+
+			names := make(map[string]bool)
+			for _, manifest := range GetInstallManifests(options) {
+				names[manifest.Name] = true
+				for _, document := range separator.Split(manifest.YAML, -1) {
+					if strings.TrimSpace(document) == "" || manifest.Name == "scc" {
+						continue
+					}
+					object := make(map[string]interface{})
+					if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+						t.Fatalf("expected %s manifest for %s/%s to be valid YAML: %v",
+							manifest.Name, flavor, version, err)
+					}
+					if object["kind"] == nil || object["apiVersion"] == nil {
+						t.Errorf("expected %s manifest for %s/%s to have a kind and apiVersion",
+							manifest.Name, flavor, version)
+					}
+				}
+			}
+
+			for _, name := range []string{"namespace", "serviceaccount", "clusterrole", "clusterrolebinding",
+				"crds", "deployment"} {
+				if !names[name] {
+					t.Errorf("expected %s manifest for %s/%s", name, flavor, version)
+				}
+			}
+			if options.CSI && (!names["service"] || !names["daemonset"]) {
+				t.Errorf("expected CSI service and daemonset manifests for %s/%s", flavor, version)
+			}
+			if names["scc"] != (flavor == FlavorOpenShift) {
+				t.Errorf("expected SCC manifest only for OpenShift, flavor %s", flavor)
+			}
+			if names["route"] != (flavor == FlavorOpenShift && options.CSI) {
+				t.Errorf("expected route manifest only for OpenShift CSI, flavor %s/%s", flavor, version)
+			}
+		}
+	}
+}
+
+func TestGetOpenShiftSCCUserManifest(t *testing.T) {
+
+	for _, c := range []struct {
+		csi     bool
+		command string
+	}{
+		{false, "oc adm policy add-scc-to-user anyuid -z trident -n trident"},
+		{true, "oc adm policy add-scc-to-user privileged -z trident-csi -n trident"},
+	} {
+		options := &InstallOptions{
+			Namespace: "trident",
+			CSI:       c.csi,
+			Flavor:    FlavorOpenShift,
+			Version:   utils.MustParseSemantic("v1.14.0"),
+		}
+
+		var sccYAML string
+		for _, manifest := range GetInstallManifests(options) {
+			if manifest.Name == "scc" {
+				sccYAML = manifest.YAML
+			}
+		}
+
+		// The installer only adds a user to a built-in SCC, so there must be no object to apply
+		object := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(strings.TrimPrefix(sccYAML, "---\n")), &object); err != nil {
+			t.Fatalf("expected SCC manifest to be valid YAML: %v", err)
+		}
+		if len(object) != 0 {
+			t.Errorf("expected SCC manifest to contain no objects, got %v", object)
+		}
+		if !strings.Contains(sccYAML, c.command) {
+			t.Errorf("expected SCC manifest to describe %q, got %s", c.command, sccYAML)
+		}
+	}
+}
+
+func TestGetManifestSet(t *testing.T) {
+
+	imageRegex := regexp.MustCompile(`(?m)^\s*image: (\S+)$`)
+
+	for _, c := range []struct {
+		version        string
+		expectedImages []string
+	}{
+		{"v1.13.0", []string{
+			"netapp/trident:19.07.0",
+			"quay.io/k8scsi/csi-provisioner:v1.0.1",
+			"quay.io/k8scsi/csi-attacher:v1.0.1",
+			"quay.io/k8scsi/csi-snapshotter:v1.0.1",
+			"quay.io/k8scsi/csi-cluster-driver-registrar:v1.0.1",
+			"quay.io/k8scsi/csi-node-driver-registrar:v1.0.2",
+		}},
+		{"v1.16.0", []string{
+			"netapp/trident:19.07.0",
+			"quay.io/k8scsi/csi-provisioner:v1.2.1",
+			"quay.io/k8scsi/csi-attacher:v1.1.1",
+			"quay.io/k8scsi/csi-snapshotter:v1.2.0",
+			"quay.io/k8scsi/csi-resizer:v0.1.0",
+			"quay.io/k8scsi/csi-node-driver-registrar:v1.1.0",
+		}},
+	} {
+		manifests, err := GetManifestSet(TridentRelease1907, InstallOptions{
+			Namespace:    "trident",
+			TridentImage: "trident:ignored",
+			Label:        "trident-csi",
+			NodeLabel:    "trident-node",
+			CSI:          true,
+			Replicas:     1,
+			Flavor:       FlavorKubernetes,
+			Version:      utils.MustParseSemantic(c.version),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error getting the manifest set for %s: %v", c.version, err)
+		}
+
+		images := make(map[string]bool)
+		for _, manifest := range manifests {
+			if strings.Contains(manifest.YAML, "{CSI_") {
+				t.Errorf("expected no unreplaced image tokens in the %s manifest for %s", manifest.Name, c.version)
+			}
+			for _, match := range imageRegex.FindAllStringSubmatch(manifest.YAML, -1) {
+				images[match[1]] = true
+			}
+		}
+
+		expected := make(map[string]bool)
+		for _, image := range c.expectedImages {
+			expected[image] = true
+		}
+		if !reflect.DeepEqual(images, expected) {
+			t.Errorf("expected images %v for %s, got %v", c.expectedImages, c.version, images)
+		}
+	}
+
+	if _, err := GetManifestSet(TridentReleaseVersion("18.01"), InstallOptions{CSI: true}); err == nil {
+		t.Error("expected an error for an unknown Trident release")
+	}
+}
+
+func TestGetInstallManifestFiles(t *testing.T) {
+
+	strategy, err := NewDeploymentStrategy(1, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error creating deployment strategy: %v", err)
+	}
+
+	files := GetInstallManifestFiles(&InstallOptions{
+		Namespace:    "trident",
+		TridentImage: "trident:test",
+		Label:        "trident-csi",
+		NodeLabel:    "trident-node",
+		CSI:          true,
+		Replicas:     1,
+		Strategy:     strategy,
+		Flavor:       FlavorKubernetes,
+		Version:      utils.MustParseSemantic("v1.14.0"),
+	})
+
+	expected := []struct {
+		name string
+		kind string
+	}{
+		{"01-namespace.yaml", "Namespace"},
+		{"02-crds-tridentversions.yaml", "CustomResourceDefinition"},
+		{"03-crds-tridentbackends.yaml", "CustomResourceDefinition"},
+		{"04-crds-tridentstorageclasses.yaml", "CustomResourceDefinition"},
+		{"05-crds-tridentvolumes.yaml", "CustomResourceDefinition"},
+		{"06-crds-tridentnodes.yaml", "CustomResourceDefinition"},
+		{"07-crds-tridenttransactions.yaml", "CustomResourceDefinition"},
+		{"08-crds-tridentsnapshots.yaml", "CustomResourceDefinition"},
+		{"09-serviceaccount.yaml", "ServiceAccount"},
+		{"10-clusterrole.yaml", "ClusterRole"},
+		{"11-clusterrolebinding.yaml", "ClusterRoleBinding"},
+		{"12-csidriver.yaml", "CSIDriver"},
+		{"13-service.yaml", "Service"},
+		{"14-deployment.yaml", "Deployment"},
+		{"15-daemonset.yaml", "DaemonSet"},
+	}
+
+	if len(files) != len(expected) {
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		t.Errorf("expected %d files, got %v", len(expected), names)
+	}
+
+	for _, e := range expected {
+		document, ok := files[e.name]
+		if !ok {
+			t.Errorf("expected a file named %s", e.name)
+			continue
+		}
+		if len(regexp.MustCompile(YAMLSeparator).Split("\n"+document, -1)) != 2 {
+			t.Errorf("expected %s to contain a single document", e.name)
+		}
+		var object struct {
+			Kind string `json:"kind"`
+		}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			t.Errorf("expected %s to be valid YAML: %v", e.name, err)
+		} else if object.Kind != e.kind {
+			t.Errorf("expected %s to contain a %s, got %s", e.name, e.kind, object.Kind)
+		}
+	}
+}
+
+func TestGetInstallManifestsYAML(t *testing.T) {
+
+	strategy, err := NewDeploymentStrategy(1, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error creating deployment strategy: %v", err)
+	}
+
+	options := &InstallOptions{
+		Namespace:    "trident",
+		TridentImage: "trident:test",
+		Label:        "trident-csi",
+		NodeLabel:    "trident-node",
+		CSI:          true,
+		Replicas:     1,
+		Strategy:     strategy,
+		Flavor:       FlavorKubernetes,
+		Version:      utils.MustParseSemantic("v1.14.0"),
+	}
+
+	allYAML := GetInstallManifestsYAML(options)
+	if !strings.HasPrefix(allYAML, "---\n") {
+		t.Error("expected the manifests to start with a document separator")
+	}
+
+	kinds := make(map[string]int)
+	for _, document := range regexp.MustCompile(YAMLSeparator).Split(allYAML, -1) {
+		if strings.TrimSpace(document) == "" || strings.TrimSpace(document) == "---" {
+			continue
+		}
+		object := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			t.Fatalf("expected each manifest document to be valid YAML: %v", err)
+		}
+		kind, _ := object["kind"].(string)
+		if kind == "" {
+			t.Errorf("expected each manifest document to have a kind, got %s", document)
+		}
+		kinds[kind]++
+	}
+
+	for _, kind := range []string{"Namespace", "ServiceAccount", "ClusterRole", "ClusterRoleBinding",
+		"CustomResourceDefinition", "CSIDriver", "Service", "Deployment", "DaemonSet"} {
+		if kinds[kind] == 0 {
+			t.Errorf("expected a %s in the install manifests", kind)
+		}
+	}
+	if kinds["CustomResourceDefinition"] < 2 {
+		t.Errorf("expected all Trident CRDs in the install manifests, got %d", kinds["CustomResourceDefinition"])
+	}
+}
+
+// decodeYAMLStrictly decodes a single YAML document into a typed object, failing on any field the
+// object does not define, so that misindented or misspelled fields are caught.
+func decodeYAMLStrictly(document string, object interface{}) error {
+
+	jsonData, err := yaml.YAMLToJSON([]byte(document))
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(object)
+}
+
+func TestGeneratedYAMLDecodes(t *testing.T) {
+
+	strategy, err := NewDeploymentStrategy(2, "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error creating deployment strategy: %v", err)
+	}
+	securityContext, err := NewSecurityContext(DefaultRunAsUser)
+	if err != nil {
+		t.Fatalf("unexpected error creating security context: %v", err)
+	}
+	commandArgs := []string{"tridentctl", "install", "--debug"}
+	secretData := map[string]string{"caKeyFile": "a2V5"}
+
+	type generatedYAML struct {
+		name   string
+		yaml   string
+		object func() interface{}
+	}
+
+	newNamespace := func() interface{} { return &v1.Namespace{} }
+	newServiceAccount := func() interface{} { return &v1.ServiceAccount{} }
+	newService := func() interface{} { return &v1.Service{} }
+	newSecret := func() interface{} { return &v1.Secret{} }
+	newPod := func() interface{} { return &v1.Pod{} }
+	newJob := func() interface{} { return &batchv1.Job{} }
+	newClusterRole := func() interface{} { return &rbacv1.ClusterRole{} }
+	newClusterRoleBinding := func() interface{} { return &rbacv1.ClusterRoleBinding{} }
+	newDeployment := func() interface{} { return &v1beta1.Deployment{} }
+	newDaemonSet := func() interface{} { return &v1beta1.DaemonSet{} }
+	newNetworkPolicy := func() interface{} { return &networkingv1.NetworkPolicy{} }
+	newCRD := func() interface{} { return &apiextensionv1beta1.CustomResourceDefinition{} }
+
+	generated := []generatedYAML{
+		{"namespace", GetNamespaceYAML("trident"), newNamespace},
+		{"service", GetCSIServiceYAML("trident-csi"), newService},
+		{"networkpolicy", GetNetworkPolicyYAML(&InstallOptions{
+			Namespace: "trident", Label: "trident-csi", NodeLabel: "trident-csi-node",
+			NetworkPolicy: &NetworkPolicyConfig{NodeCIDRs: []string{"10.0.0.0/24"},
+				APIServerCIDRs: []string{"10.96.0.1/32"}, BackendCIDRs: []string{"192.168.0.0/24"}},
+		}), newNetworkPolicy},
+		{"deployment", GetDeploymentYAML("trident:test", "trident", true), newDeployment},
+		{"installer serviceaccount", GetInstallerServiceAccountYAML(), newServiceAccount},
+		{"migrator pod", GetMigratorPodYAML("trident", "trident:test", "etcd:test", "trident-migrator", true,
+			commandArgs), newPod},
+		{"installer pod", GetInstallerPodYAML("trident-installer", "trident:test", commandArgs), newPod},
+		{"installer job", GetInstallerJobYAML("trident-installer", "trident:test", commandArgs, 3), newJob},
+		{"uninstaller pod", GetUninstallerPodYAML("trident-installer", "trident:test", commandArgs), newPod},
+		{"secret", GetSecretYAML("trident-csi", "trident", "trident-csi", secretData), newSecret},
+		{"encoded secret", GetSecretYAMLEncoded("trident-csi", "trident", "trident-csi", secretData, false),
+			newSecret},
+		{"stringData secret", GetSecretYAMLEncoded("trident-csi", "trident", "trident-csi", secretData, true),
+			newSecret},
+		{"crds", GetCRDsYAML(), newCRD},
+		{"csidriver crd", GetCSIDriverCRDYAML(), newCRD},
+		{"csinodeinfo crd", GetCSINodeInfoCRDYAML(), newCRD},
+		// The following kinds have no typed objects available here, so they are only checked for valid YAML
+		{"servicemonitor", GetServiceMonitorYAML("trident", "trident-csi"), nil},
+		{"csidriver", GetCSIDriverCRYAML(true, []string{"topology.kubernetes.io/zone"}), nil},
+		{"scc query", GetOpenShiftSCCQueryYAML("trident"), nil},
+		{"route", GetOpenShiftRouteYAML("trident", "trident-csi"), nil},
+	}
+
+	for _, flavor := range []OrchestratorFlavor{FlavorKubernetes, FlavorOpenShift} {
+		generated = append(generated,
+			generatedYAML{"installer clusterrole " + string(flavor), GetInstallerClusterRoleYAML(flavor),
+				newClusterRole},
+			generatedYAML{"installer clusterrolebinding " + string(flavor),
+				GetInstallerClusterRoleBindingYAML("trident", flavor), newClusterRoleBinding},
+		)
+		for _, csi := range []bool{false, true} {
+			generated = append(generated,
+				generatedYAML{"serviceaccount", GetServiceAccountYAML(csi), newServiceAccount},
+				generatedYAML{"clusterrole " + string(flavor), GetClusterRoleYAML(flavor, csi,
+					utils.MustParseSemantic("v1.16.0")), newClusterRole},
+				generatedYAML{"clusterrolebinding " + string(flavor),
+					GetClusterRoleBindingYAML("trident", flavor, csi), newClusterRoleBinding},
+			)
+		}
+	}
+
+	for _, version := range []string{"v1.13.0", "v1.14.0", "v1.16.0"} {
+		k8sVersion := utils.MustParseSemantic(version)
+		generated = append(generated,
+			generatedYAML{"csi deployment " + version, GetCSIDeploymentYAML(&InstallOptions{
+				TridentImage: "trident:test",
+				Label:        "trident-csi",
+				Debug:        true,
+				Replicas:     2,
+				Strategy:     strategy,
+				Security:     securityContext,
+				Version:      k8sVersion,
+			}), newDeployment},
+			generatedYAML{"csi daemonset " + version, GetCSIDaemonSetYAML("trident:test", "trident-node", true,
+				[]string{"topology.kubernetes.io/zone"}, "/var/data/kubelet", k8sVersion), newDaemonSet},
+		)
+	}
+
+	separator := regexp.MustCompile(YAMLSeparator)
+
+	for _, g := range generated {
+		for _, document := range separator.Split("\n"+g.yaml, -1) {
+			if strings.TrimSpace(document) == "" {
+				continue
+			}
+
+			var typeMeta struct {
+				APIVersion string `json:"apiVersion"`
+				Kind       string `json:"kind"`
+			}
+			if err := yaml.Unmarshal([]byte(document), &typeMeta); err != nil {
+				t.Errorf("expected %s YAML to be valid: %v", g.name, err)
+				continue
+			}
+			if typeMeta.APIVersion == "" || typeMeta.Kind == "" {
+				t.Errorf("expected %s YAML to have an apiVersion and kind", g.name)
+			}
+
+			if g.object == nil {
+				continue
+			}
+			object := g.object()
+			if err := decodeYAMLStrictly(document, object); err != nil {
+				t.Errorf("expected %s YAML to decode as %T: %v", g.name, object, err)
+				continue
+			}
+
+			// Quoting mistakes in flow sequences still parse, so look for stray quotes in RBAC rules
+			if clusterRole, ok := object.(*rbacv1.ClusterRole); ok {
+				for _, rule := range clusterRole.Rules {
+					for _, value := range append(append(rule.APIGroups, rule.Resources...), rule.Verbs...) {
+						if strings.ContainsAny(value, `"'`) {
+							t.Errorf("expected %s rule value %s to contain no quotes", g.name, value)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestGetSecretYAMLEncoded(t *testing.T) {
+
+	secretData := map[string]string{
+		"username": "admin",
+		"password": "p@ss: word\n",
+		"cert":     "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+	}
+
+	var secret v1.Secret
+	encodedYAML := GetSecretYAMLEncoded("trident-csi", "trident", "trident-csi", secretData, false)
+	if err := yaml.Unmarshal([]byte(encodedYAML), &secret); err != nil {
+		t.Fatalf("expected encoded secret YAML to be valid: %v", err)
+	}
+	if len(secret.Data) != len(secretData) || len(secret.StringData) != 0 {
+		t.Errorf("expected %d data values and no stringData, got %v", len(secretData), secret)
+	}
+	for key, value := range secretData {
+		if string(secret.Data[key]) != value {
+			t.Errorf("expected data %s to decode to %q, got %q", key, value, string(secret.Data[key]))
+		}
+	}
+
+	secret = v1.Secret{}
+	stringDataYAML := GetSecretYAMLEncoded("trident-csi", "trident", "trident-csi", secretData, true)
+	if err := yaml.Unmarshal([]byte(stringDataYAML), &secret); err != nil {
+		t.Fatalf("expected stringData secret YAML to be valid: %v", err)
+	}
+	if len(secret.StringData) != len(secretData) || len(secret.Data) != 0 {
+		t.Errorf("expected %d stringData values and no data, got %v", len(secretData), secret)
+	}
+	for key, value := range secretData {
+		if secret.StringData[key] != value {
+			t.Errorf("expected stringData %s to be %q, got %q", key, value, secret.StringData[key])
+		}
+	}
+
+	// Keys are always rendered in the same order
+	if encodedYAML != GetSecretYAMLEncoded("trident-csi", "trident", "trident-csi", secretData, false) {
+		t.Error("expected the encoded secret YAML to be deterministic")
+	}
+	if strings.Index(encodedYAML, "cert:") > strings.Index(encodedYAML, "password:") ||
+		strings.Index(encodedYAML, "password:") > strings.Index(encodedYAML, "username:") {
+		t.Errorf("expected secret keys in sorted order, got %s", encodedYAML)
+	}
+}
+
+func TestGetInstallerJobYAML(t *testing.T) {
+
+	commandArgs := []string{"tridentctl", "install", "--debug"}
+
+	var pod v1.Pod
+	if err := decodeYAMLStrictly(GetInstallerPodYAML("trident-installer", "trident:test", commandArgs),
+		&pod); err != nil {
+		t.Fatalf("could not decode installer pod: %v", err)
+	}
+
+	var job batchv1.Job
+	if err := decodeYAMLStrictly(GetInstallerJobYAML("trident-installer", "trident:test", commandArgs, 3),
+		&job); err != nil {
+		t.Fatalf("could not decode installer job: %v", err)
+	}
+
+	if job.Name != pod.Name {
+		t.Errorf("expected job name %s, got %s", pod.Name, job.Name)
+	}
+	if job.Labels["app"] != "trident-installer" || job.Spec.Template.Labels["app"] != "trident-installer" {
+		t.Errorf("expected job and pod template labels app=trident-installer, got %v and %v",
+			job.Labels, job.Spec.Template.Labels)
+	}
+	if job.Spec.BackoffLimit == nil || *job.Spec.BackoffLimit != 3 {
+		t.Errorf("expected backoffLimit 3, got %v", job.Spec.BackoffLimit)
+	}
+	if job.Spec.TTLSecondsAfterFinished != nil {
+		t.Errorf("expected no ttlSecondsAfterFinished, which is alpha in Kubernetes 1.14, got %d",
+			*job.Spec.TTLSecondsAfterFinished)
+	}
+	if !reflect.DeepEqual(job.Spec.Template.Spec, pod.Spec) {
+		t.Errorf("expected job pod spec %+v to match installer pod spec %+v", job.Spec.Template.Spec, pod.Spec)
+	}
+}
@@ -12,6 +12,7 @@ func main() {
 	cmd.ExitCode = cmd.ExitCodeSuccess
 
 	if err := cmd.RootCmd.Execute(); err != nil {
+		cmd.WriteError(os.Stderr, err)
 		cmd.SetExitCodeFromError(err)
 	}
 
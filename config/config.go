@@ -44,10 +44,12 @@ const (
 	ProtocolAny Protocol = ""
 
 	/* Access mode constants */
-	ReadWriteOnce AccessMode = "ReadWriteOnce"
-	ReadOnlyMany  AccessMode = "ReadOnlyMany"
-	ReadWriteMany AccessMode = "ReadWriteMany"
-	ModeAny       AccessMode = ""
+	ReadWriteOnce    AccessMode = "ReadWriteOnce"
+	ReadWriteOncePod AccessMode = "ReadWriteOncePod"
+	ReadOnlyOnce     AccessMode = "ReadOnlyOnce"
+	ReadOnlyMany     AccessMode = "ReadOnlyMany"
+	ReadWriteMany    AccessMode = "ReadWriteMany"
+	ModeAny          AccessMode = ""
 
 	/* Volume type constants */
 	OntapNFS          VolumeType = "ONTAP_NFS"
@@ -63,11 +65,19 @@ const (
 	SANResizeDelta           = 50000000 // 50mb
 
 	/* REST frontend constants */
-	MaxRESTRequestSize = 10240
+	MaxRESTRequestSize      = 10240
+	MaxRESTStateRequestSize = 64 * 1024 * 1024
 
 	/* Kubernetes deployment constants */
-	ContainerTrident = "trident-main"
-	ContainerEtcd    = "etcd"
+	ContainerTrident        = "trident-main"
+	ContainerEtcd           = "etcd"
+	ContainerCSIProvisioner = "csi-provisioner"
+	ContainerCSIAttacher    = "csi-attacher"
+	ContainerCSISnapshotter = "csi-snapshotter"
+
+	/* Node topology constants */
+	TopologyRegionLabel = "topology.kubernetes.io/region"
+	TopologyZoneLabel   = "topology.kubernetes.io/zone"
 
 	ContextDocker     DriverContext = "docker"
 	ContextKubernetes DriverContext = "kubernetes"
@@ -131,7 +141,10 @@ var (
 	StorageClassURL = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/storageclass"
 	NodeURL         = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/node"
 	SnapshotURL     = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/snapshot"
+	StateURL        = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/state"
+	EventURL        = "/" + OrchestratorName + "/v" + OrchestratorAPIVersion + "/event"
 	StoreURL        = "/" + OrchestratorName + "/store"
+	StoreLayoutURL  = "/" + OrchestratorName + "/layout"
 
 	UsingPassthroughStore bool
 	CurrentDriverContext  DriverContext
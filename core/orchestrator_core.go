@@ -3,9 +3,13 @@
 package core
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand"
+	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -18,12 +22,26 @@ import (
 	persistentstore "github.com/netapp/trident/persistent_store"
 	"github.com/netapp/trident/storage"
 	"github.com/netapp/trident/storage/factory"
+	sa "github.com/netapp/trident/storage_attribute"
 	storageclass "github.com/netapp/trident/storage_class"
 	drivers "github.com/netapp/trident/storage_drivers"
 	"github.com/netapp/trident/storage_drivers/fake"
 	"github.com/netapp/trident/utils"
 )
 
+const (
+	// DefaultTransactionReconcilePeriod is how often outstanding volume transactions are reconciled.
+	DefaultTransactionReconcilePeriod = 5 * time.Minute
+
+	// DefaultTransactionMaxAge is how long a volume transaction that can't be reconciled is kept
+	// before it is deleted.
+	DefaultTransactionMaxAge = 24 * time.Hour
+
+	// DefaultSnapshotPrunePeriod is how often snapshots exceeding their storage class's retention
+	// policy are pruned.
+	DefaultSnapshotPrunePeriod = time.Hour
+)
+
 type TridentOrchestrator struct {
 	backends       map[string]*storage.Backend // key is UUID, not name
 	volumes        map[string]*storage.Volume
@@ -35,6 +53,11 @@ type TridentOrchestrator struct {
 	storeClient    persistentstore.Client
 	bootstrapped   bool
 	bootstrapError error
+
+	txnFirstSeen      map[string]time.Time // key is from volumeTransactionKey
+	txnReconcilerDone chan struct{}
+
+	snapshotPrunerDone chan struct{}
 }
 
 // NewTridentOrchestrator returns a storage orchestrator instance
@@ -50,6 +73,7 @@ func NewTridentOrchestrator(client persistentstore.Client) *TridentOrchestrator
 		storeClient:    client,
 		bootstrapped:   false,
 		bootstrapError: notReadyError(),
+		txnFirstSeen:   make(map[string]time.Time),
 	}
 }
 
@@ -191,8 +215,8 @@ func (o *TridentOrchestrator) bootstrapBackends() error {
 			if backendErr != nil {
 				newBackend.State = storage.Failed
 			} else {
-				if b.State == storage.Deleting {
-					newBackend.State = storage.Deleting
+				if b.State == storage.Deleting || b.State == storage.Offline {
+					newBackend.State = b.State
 				}
 			}
 			log.WithFields(log.Fields{
@@ -247,6 +271,7 @@ func (o *TridentOrchestrator) bootstrapVolumes() error {
 		}
 
 		vol := storage.NewVolume(v.Config, backend.BackendUUID, v.Pool, v.Orphaned)
+		vol.PublishedNodes = v.PublishedNodes
 		backend.Volumes[vol.Config.Name] = vol
 		o.volumes[vol.Config.Name] = vol
 
@@ -401,7 +426,7 @@ func (o *TridentOrchestrator) handleFailedTransaction(v *persistentstore.VolumeT
 			// If the volume was added to etcd, we will have loaded the
 			// volume into memory, and we can just delete it normally.
 			// Handles case 3)
-			err := o.deleteVolume(v.Config.Name)
+			err := o.deleteVolume(v.Config.Name, false)
 			if err != nil {
 				return fmt.Errorf("unable to clean up volume %s: %v", v.Config.Name, err)
 			}
@@ -441,7 +466,7 @@ func (o *TridentOrchestrator) handleFailedTransaction(v *persistentstore.VolumeT
 		// volume should have been loaded into memory when we bootstrapped.
 		if _, ok := o.volumes[v.Config.Name]; ok {
 
-			err := o.deleteVolume(v.Config.Name)
+			err := o.deleteVolume(v.Config.Name, false)
 			if err != nil {
 				log.WithFields(log.Fields{
 					"volume": v.Config.Name,
@@ -479,7 +504,7 @@ func (o *TridentOrchestrator) handleFailedTransaction(v *persistentstore.VolumeT
 			// Handles case 2)
 			for _, backend := range o.backends {
 				// Skip backends that aren't ready to accept a snapshot delete operation
-				if !backend.State.IsOnline() && !backend.State.IsDeleting() {
+				if !backend.State.IsOnline() && !backend.State.IsOffline() && !backend.State.IsDeleting() {
 					continue
 				}
 				// Snapshot deletion is an idempotent operation, so it's safe to
@@ -590,6 +615,243 @@ func (o *TridentOrchestrator) handleFailedTransaction(v *persistentstore.VolumeT
 	return nil
 }
 
+// volumeTransactionKey identifies a volume transaction for the transaction reconciler.
+func volumeTransactionKey(v *persistentstore.VolumeTransaction) string {
+	switch v.Op {
+	case persistentstore.AddSnapshot, persistentstore.DeleteSnapshot:
+		return string(v.Op) + "/" + v.SnapshotConfig.ID()
+	default:
+		return string(v.Op) + "/" + v.Config.Name
+	}
+}
+
+// ReconcileVolumeTransactions completes or rolls back any volume transactions left in the
+// persistent store.  Every operation that records a transaction holds the orchestrator lock until
+// the transaction is deleted, so any transaction found here was stranded by a failed operation.
+// A transaction that can't be reconciled is retried on later calls, and once it has been seen
+// for longer than maxAge it is deleted so that it no longer blocks operations on its volume.
+func (o *TridentOrchestrator) ReconcileVolumeTransactions(maxAge time.Duration) error {
+
+	if o.bootstrapError != nil {
+		return o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	volTxns, err := o.storeClient.GetVolumeTransactions()
+	if err != nil {
+		return fmt.Errorf("could not read volume transactions; %v", err)
+	}
+
+	now := time.Now()
+	outstanding := make(map[string]bool)
+	for _, v := range volTxns {
+
+		key := volumeTransactionKey(v)
+		if err = o.handleFailedTransaction(v); err == nil {
+			log.WithField("transaction", key).Info("Reconciled stranded volume transaction.")
+			delete(o.txnFirstSeen, key)
+			continue
+		}
+
+		firstSeen, ok := o.txnFirstSeen[key]
+		if !ok {
+			firstSeen = now
+			o.txnFirstSeen[key] = firstSeen
+		}
+
+		logFields := log.Fields{"transaction": key, "firstSeen": firstSeen, "error": err}
+
+		if now.Sub(firstSeen) < maxAge {
+			log.WithFields(logFields).Warning("Could not reconcile volume transaction, will retry.")
+			outstanding[key] = true
+			continue
+		}
+
+		if err = o.deleteVolumeTransaction(v); err != nil {
+			log.WithFields(logFields).Errorf("Could not delete stale volume transaction; %v", err)
+			outstanding[key] = true
+			continue
+		}
+		log.WithFields(logFields).Warning("Deleted stale volume transaction that could not be reconciled.")
+	}
+
+	// Forget any transactions that have since been cleaned up
+	for key := range o.txnFirstSeen {
+		if !outstanding[key] {
+			delete(o.txnFirstSeen, key)
+		}
+	}
+
+	return nil
+}
+
+// StartTransactionReconciler reconciles outstanding volume transactions immediately and then
+// once per period until StopTransactionReconciler is called.
+func (o *TridentOrchestrator) StartTransactionReconciler(period, maxAge time.Duration) {
+
+	if o.txnReconcilerDone != nil {
+		return
+	}
+	o.txnReconcilerDone = make(chan struct{})
+
+	reconcile := func() {
+		if err := o.ReconcileVolumeTransactions(maxAge); err != nil {
+			log.Errorf("Volume transaction reconciliation failed; %v", err)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"period": period,
+		"maxAge": maxAge,
+	}).Info("Starting volume transaction reconciler.")
+
+	ticker := time.NewTicker(period)
+	go func(done chan struct{}) {
+		defer ticker.Stop()
+		reconcile()
+		for {
+			select {
+			case <-ticker.C:
+				reconcile()
+			case <-done:
+				return
+			}
+		}
+	}(o.txnReconcilerDone)
+}
+
+// StopTransactionReconciler stops the periodic reconciliation of volume transactions.
+func (o *TridentOrchestrator) StopTransactionReconciler() {
+	if o.txnReconcilerDone != nil {
+		close(o.txnReconcilerDone)
+		o.txnReconcilerDone = nil
+	}
+}
+
+// PruneSnapshots deletes the snapshots of each volume that exceed the snapshot retention policy
+// of the volume's storage class.  Snapshots from which volumes were cloned, and snapshots created
+// through CSI, are never pruned.
+func (o *TridentOrchestrator) PruneSnapshots() error {
+	return o.pruneSnapshots(time.Now())
+}
+
+// pruneSnapshots prunes snapshots as of the specified time.
+func (o *TridentOrchestrator) pruneSnapshots(now time.Time) error {
+
+	if o.bootstrapError != nil {
+		return o.bootstrapError
+	}
+
+	// Choose the snapshots to prune while locked, then delete each as a separate operation.  The
+	// in-memory snapshots mirror the store, so no store reads are needed while locked.
+	o.mutex.Lock()
+	snapshotsByVolume := make(map[string][]*storage.Snapshot)
+	for _, snapshot := range o.snapshots {
+		volumeName := snapshot.Config.VolumeName
+		snapshotsByVolume[volumeName] = append(snapshotsByVolume[volumeName], snapshot)
+	}
+	prune := make([]*storage.SnapshotConfig, 0)
+	for volumeName, snapshots := range snapshotsByVolume {
+
+		volume, ok := o.volumes[volumeName]
+		if !ok {
+			continue
+		}
+		sc, ok := o.storageClasses[volume.Config.StorageClass]
+		if !ok || sc.GetSnapshotRetention() == nil {
+			continue
+		}
+
+		for _, snapshot := range sc.GetSnapshotRetention().SnapshotsToPrune(snapshots, now) {
+			if dependents := o.getSnapshotDependents(volume.Config.Name, snapshot.Config.Name); len(dependents) > 0 {
+				log.WithFields(log.Fields{
+					"volume":     volume.Config.Name,
+					"snapshot":   snapshot.Config.Name,
+					"dependents": dependents,
+				}).Debug("Not pruning snapshot with dependent clones.")
+				continue
+			}
+			prune = append(prune, snapshot.Config)
+		}
+	}
+	o.mutex.Unlock()
+
+	errList := make([]string, 0)
+	for _, snapshotConfig := range prune {
+		logFields := log.Fields{"volume": snapshotConfig.VolumeName, "snapshot": snapshotConfig.Name}
+
+		// A clone may have been created since the snapshot was chosen, in which case it is kept
+		err := o.DeleteSnapshot(snapshotConfig.VolumeName, snapshotConfig.Name)
+		if IsSnapshotInUseError(err) || IsNotFoundError(err) {
+			log.WithFields(logFields).Debugf("Not pruning snapshot; %v", err)
+		} else if err != nil {
+			errList = append(errList, fmt.Sprintf("could not prune snapshot %s; %v", snapshotConfig.ID(), err))
+		} else {
+			log.WithFields(logFields).Info("Pruned snapshot exceeding its storage class retention policy.")
+		}
+	}
+
+	if len(errList) > 0 {
+		return fmt.Errorf(strings.Join(errList, ", "))
+	}
+	return nil
+}
+
+// StartSnapshotPruner prunes snapshots immediately and then once per period until
+// StopSnapshotPruner is called.
+func (o *TridentOrchestrator) StartSnapshotPruner(period time.Duration) {
+
+	if o.snapshotPrunerDone != nil {
+		return
+	}
+	o.snapshotPrunerDone = make(chan struct{})
+
+	prune := func() {
+		if err := o.PruneSnapshots(); err != nil {
+			log.Errorf("Snapshot pruning failed; %v", err)
+		}
+	}
+
+	log.WithField("period", period).Info("Starting snapshot pruner.")
+
+	ticker := time.NewTicker(period)
+	go func(done chan struct{}) {
+		defer ticker.Stop()
+		prune()
+		for {
+			select {
+			case <-ticker.C:
+				prune()
+			case <-done:
+				return
+			}
+		}
+	}(o.snapshotPrunerDone)
+}
+
+// StopSnapshotPruner stops the periodic pruning of snapshots.
+func (o *TridentOrchestrator) StopSnapshotPruner() {
+	if o.snapshotPrunerDone != nil {
+		close(o.snapshotPrunerDone)
+		o.snapshotPrunerDone = nil
+	}
+}
+
+// ListVolumeTransactions returns the volume transactions outstanding in the persistent store.
+func (o *TridentOrchestrator) ListVolumeTransactions() ([]*persistentstore.VolumeTransaction, error) {
+
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return o.storeClient.GetVolumeTransactions()
+}
+
 func (o *TridentOrchestrator) AddFrontend(f frontend.Plugin) {
 	name := f.GetName()
 	if _, ok := o.frontends[name]; ok {
@@ -626,6 +888,72 @@ func (o *TridentOrchestrator) GetVersion() (string, error) {
 	return config.OrchestratorVersion.String(), o.bootstrapError
 }
 
+// Ping checks that the orchestrator is healthy and can reach its persistent store.  An
+// orchestrator that is still bootstrapping is healthy, but one whose bootstrap failed is not.
+// Ping doesn't wait for the orchestrator's lock, so it responds even while a long-running
+// operation is in progress.
+func (o *TridentOrchestrator) Ping(ctx context.Context) error {
+	if o.bootstrapError != nil && !IsNotReadyError(o.bootstrapError) {
+		return o.bootstrapError
+	}
+	return o.storeClient.Ping(ctx)
+}
+
+// ExportState returns a copy of every object in the persistent store, suitable for restoring
+// into another Trident instance via ImportState.
+func (o *TridentOrchestrator) ExportState() (*persistentstore.State, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return persistentstore.ExportState(o.storeClient)
+}
+
+// ImportState restores exported objects into the persistent store, skipping any that already
+// exist, and then loads the imported backends, storage classes, volumes, snapshots, and nodes.
+// Because the imported volumes must be reconciled with their backends, state may only be
+// imported while Trident is managing no backends or volumes.  Imported volume transactions
+// are not processed until Trident next bootstraps.
+func (o *TridentOrchestrator) ImportState(
+	state *persistentstore.State,
+) (*persistentstore.ImportStateResult, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if len(o.backends) > 0 || len(o.volumes) > 0 {
+		return nil, unsupportedError(fmt.Sprintf("state may only be imported while %s has no backends or volumes",
+			config.OrchestratorName))
+	}
+
+	result, err := persistentstore.ImportState(o.storeClient, state)
+	if err != nil {
+		return result, err
+	}
+
+	type bootstrapFunc func() error
+	for _, f := range []bootstrapFunc{
+		o.bootstrapBackends, o.bootstrapStorageClasses, o.bootstrapVolumes,
+		o.bootstrapSnapshots, o.bootstrapNodes} {
+		if err = f(); err != nil && !persistentstore.MatchKeyNotFoundErr(err) {
+			return result, fmt.Errorf("imported state could not be loaded: %v", err)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"imported": result.Imported,
+		"skipped":  result.Skipped,
+	}).Info("Imported persistent state.")
+
+	return result, nil
+}
+
 // AddBackend handles creation of a new storage backend
 func (o *TridentOrchestrator) AddBackend(configJSON string) (*storage.BackendExternal, error) {
 	if o.bootstrapError != nil {
@@ -799,6 +1127,11 @@ func (o *TridentOrchestrator) updateBackendByBackendUUID(backendName, configJSON
 	if err = o.validateBackendUpdate(originalBackend, backend); err != nil {
 		return nil, err
 	}
+
+	// An offline backend stays offline across configuration updates
+	if originalBackend.State.IsOffline() {
+		backend.State = storage.Offline
+	}
 	log.WithFields(log.Fields{
 		"originalBackend.Name":        originalBackend.Name,
 		"originalBackend.BackendUUID": originalBackend.BackendUUID,
@@ -967,19 +1300,131 @@ func (o *TridentOrchestrator) updateBackendState(backendName, backendState strin
 
 	newBackendState := storage.BackendState(backendState)
 
-	// Limit the command to Failed
-	if !newBackendState.IsFailed() {
+	switch {
+	case newBackendState.IsFailed():
+		backend.Terminate()
+
+	case newBackendState.IsOffline():
+		// An offline backend is withdrawn from its storage classes, so it isn't chosen
+		// for new volumes, but it continues to serve the volumes it already holds.
+		if !backend.State.IsOnline() && !backend.State.IsOffline() {
+			return nil, fmt.Errorf("cannot take backend %s offline from state %s", backendName, backend.State)
+		}
+		for _, sc := range o.storageClasses {
+			sc.RemovePoolsForBackend(backend)
+		}
+		for _, storagePool := range backend.Storage {
+			storagePool.StorageClasses = []string{}
+		}
+
+	case newBackendState.IsOnline():
+		if !backend.State.IsOnline() && !backend.State.IsOffline() {
+			return nil, fmt.Errorf("cannot bring backend %s online from state %s", backendName, backend.State)
+		}
+
+	default:
 		return nil, fmt.Errorf("unsupported backend state: %s", newBackendState)
 	}
 
-	if !newBackendState.IsOnline() {
-		backend.Terminate()
-	}
+	oldBackendState := backend.State
 	backend.State = newBackendState
 
+	if newBackendState.IsOnline() && !oldBackendState.IsOnline() {
+		for _, sc := range o.storageClasses {
+			sc.CheckAndAddBackend(backend)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"backend":  backendName,
+		"oldState": oldBackendState,
+		"newState": newBackendState,
+	}).Info("Orchestrator changed the backend's state.")
+
 	return backend.ConstructExternal(), o.storeClient.UpdateBackend(backend)
 }
 
+// UpdateBackendCredentials replaces the credentials in an existing backend's configuration, leaving
+// the rest of its configuration untouched.  An empty username keeps the current one.  The backend is
+// reinitialized with the new credentials, so they are validated before being persisted.
+func (o *TridentOrchestrator) UpdateBackendCredentials(backendName, username, password string) (
+	backendExternal *storage.BackendExternal, err error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return o.updateBackendCredentials(backendName, username, password)
+}
+
+// updateBackendCredentials replaces an existing backend's credentials. It assumes the mutex lock is already held.
+func (o *TridentOrchestrator) updateBackendCredentials(backendName, username, password string) (
+	backendExternal *storage.BackendExternal, err error) {
+
+	log.WithField("backendName", backendName).Debug("UpdateBackendCredentials")
+
+	if password == "" {
+		return nil, fmt.Errorf("no password was specified for backend %s", backendName)
+	}
+
+	backend, err := o.getBackendByBackendName(backendName)
+	if err != nil {
+		return nil, err
+	}
+
+	configJSON, err := backend.ConstructPersistent().MarshalConfig()
+	if err != nil {
+		return nil, err
+	}
+	configJSON, err = setBackendConfigCredentials(configJSON, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("could not update credentials for backend %s; %v", backendName, err)
+	}
+
+	return o.updateBackendByBackendUUID(backend.Name, configJSON, backend.BackendUUID)
+}
+
+// setBackendConfigCredentials replaces the credentials in a backend's configuration JSON.  Drivers keep
+// their credentials either in username and password fields or, like SolidFire, in the endpoint URL.
+func setBackendConfigCredentials(configJSON, username, password string) (string, error) {
+
+	// Numbers are kept verbatim, so that the rest of the configuration round-trips unchanged
+	decoder := json.NewDecoder(strings.NewReader(configJSON))
+	decoder.UseNumber()
+
+	var backendConfig map[string]interface{}
+	if err := decoder.Decode(&backendConfig); err != nil {
+		return "", fmt.Errorf("could not parse backend config; %v", err)
+	}
+
+	if _, ok := backendConfig["password"]; ok {
+		if username != "" {
+			backendConfig["username"] = username
+		}
+		backendConfig["password"] = password
+	} else if endpoint, ok := backendConfig["EndPoint"].(string); ok {
+		endpointURL, err := url.Parse(endpoint)
+		if err != nil {
+			return "", fmt.Errorf("could not parse backend endpoint; %v", err)
+		}
+		if username == "" && endpointURL.User != nil {
+			username = endpointURL.User.Username()
+		}
+		endpointURL.User = url.UserPassword(username, password)
+		backendConfig["EndPoint"] = endpointURL.String()
+	} else {
+		return "", fmt.Errorf("backend does not use username and password credentials")
+	}
+
+	configBytes, err := json.Marshal(backendConfig)
+	if err != nil {
+		return "", err
+	}
+	return string(configBytes), nil
+}
+
 func (o *TridentOrchestrator) getBackendUUIDByBackendName(backendName string) (string, error) {
 	backendUUID := ""
 	for _, b := range o.backends {
@@ -1035,6 +1480,51 @@ func (o *TridentOrchestrator) GetBackend(backendName string) (*storage.BackendEx
 	return backendExternal, nil
 }
 
+// GetBackendPools returns the storage pools of a backend, including the capacity of each pool
+// as reported by the backend's storage driver.
+func (o *TridentOrchestrator) GetBackendPools(backendName string) ([]*storage.PoolCapacityExternal, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	backendUUID, err := o.getBackendUUIDByBackendName(backendName)
+	if err != nil {
+		return nil, err
+	}
+	backend, found := o.backends[backendUUID]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("backend %v was not found", backendName))
+	}
+
+	return backend.GetPoolCapacities()
+}
+
+// GetBackendWithSecrets returns a backend like GetBackend, except that its config is returned in
+// full, including any credentials.
+func (o *TridentOrchestrator) GetBackendWithSecrets(backendName string) (*storage.BackendExternal, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	backendUUID, err := o.getBackendUUIDByBackendName(backendName)
+	if err != nil {
+		return nil, err
+	}
+	backend, found := o.backends[backendUUID]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("backend %v was not found", backendName))
+	}
+
+	log.WithField("backend", backendName).Info("Returning backend config with secrets.")
+	return backend.ConstructExternalWithSecrets()
+}
+
 func (o *TridentOrchestrator) GetBackendByBackendUUID(backendUUID string) (*storage.BackendExternal, error) {
 	if o.bootstrapError != nil {
 		return nil, o.bootstrapError
@@ -1255,14 +1745,90 @@ func (o *TridentOrchestrator) AddVolume(volumeConfig *storage.VolumeConfig) (
 	return nil, err
 }
 
-func (o *TridentOrchestrator) CloneVolume(volumeConfig *storage.VolumeConfig) (
-	externalVol *storage.VolumeExternal, err error) {
+// PlanVolume runs the same backend and storage pool selection as AddVolume, returning where the
+// volume would be created without creating it or recording anything in the persistent store.
+// Pools are tried in random order, as they are by AddVolume, so a real create may choose a
+// different pool that is also suitable.
+func (o *TridentOrchestrator) PlanVolume(volumeConfig *storage.VolumeConfig) (*storage.VolumePlacement, error) {
 
 	if o.bootstrapError != nil {
 		return nil, o.bootstrapError
 	}
 
-	var (
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if _, ok := o.volumes[volumeConfig.Name]; ok {
+		return nil, fmt.Errorf("volume %s already exists", volumeConfig.Name)
+	}
+
+	// Get the protocol based on the specified access mode & protocol
+	protocol, err := o.getProtocol(volumeConfig.AccessMode, volumeConfig.Protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	sc, ok := o.storageClasses[volumeConfig.StorageClass]
+	if !ok {
+		return nil, fmt.Errorf("unknown storage class: %s", volumeConfig.StorageClass)
+	}
+	pools := sc.GetStoragePoolsForProtocol(protocol)
+	if len(pools) == 0 {
+		return nil, fmt.Errorf("no available backends for storage class %s",
+			volumeConfig.StorageClass)
+	}
+
+	rand.Seed(time.Now().UnixNano())
+
+	errorMessages := make([]string, 0)
+
+	for _, num := range rand.Perm(len(pools)) {
+
+		// Plan against a copy, since the backend fills in the internal name
+		backend := pools[num].Backend
+		plannedConfig := volumeConfig.ConstructClone()
+		capacityChecked, err := backend.PlanVolume(plannedConfig, pools[num])
+		if err != nil {
+			errorMessages = append(errorMessages,
+				fmt.Sprintf("[Cannot create volume %s on storage pool %s from backend %s: %s]",
+					volumeConfig.Name, pools[num].Name, backend.Name, err.Error()))
+			continue
+		}
+
+		plannedProtocol := plannedConfig.Protocol
+		if plannedProtocol == config.ProtocolAny {
+			plannedProtocol = backend.GetProtocol()
+		}
+
+		log.WithFields(log.Fields{
+			"volume":       volumeConfig.Name,
+			"backend":      backend.Name,
+			"pool":         pools[num].Name,
+			"internalName": plannedConfig.InternalName,
+		}).Debug("Planned volume placement.")
+
+		return &storage.VolumePlacement{
+			Backend:         backend.Name,
+			BackendUUID:     backend.BackendUUID,
+			Pool:            pools[num].Name,
+			InternalName:    plannedConfig.InternalName,
+			Protocol:        plannedProtocol,
+			CapacityChecked: capacityChecked,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no suitable %s backend with \"%s\" storage class and %s of free space was found: %s",
+		protocol, volumeConfig.StorageClass, volumeConfig.Size, strings.Join(errorMessages, ", "))
+}
+
+func (o *TridentOrchestrator) CloneVolume(volumeConfig *storage.VolumeConfig) (
+	externalVol *storage.VolumeExternal, err error) {
+
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	var (
 		found   bool
 		backend *storage.Backend
 		vol     *storage.Volume
@@ -1295,12 +1861,22 @@ func (o *TridentOrchestrator) CloneVolume(volumeConfig *storage.VolumeConfig) (
 	// Copy a few attributes from the request that will affect clone creation
 	cloneConfig.Name = volumeConfig.Name
 	cloneConfig.InternalName = ""
+	cloneConfig.InternalNameBase = volumeConfig.InternalNameBase
 	cloneConfig.SplitOnClone = volumeConfig.SplitOnClone
 	cloneConfig.CloneSourceVolume = volumeConfig.CloneSourceVolume
 	cloneConfig.CloneSourceVolumeInternal = sourceVolume.Config.InternalName
 	cloneConfig.CloneSourceSnapshot = volumeConfig.CloneSourceSnapshot
 	cloneConfig.QoS = volumeConfig.QoS
 	cloneConfig.QoSType = volumeConfig.QoSType
+	cloneConfig.DeletionProtection = volumeConfig.DeletionProtection
+
+	// The clone may be placed in a different storage class if the source volume's pool satisfies it
+	if volumeConfig.StorageClass != "" && volumeConfig.StorageClass != sourceVolume.Config.StorageClass {
+		if err = o.validateCloneStorageClass(sourceVolume, volumeConfig.StorageClass); err != nil {
+			return nil, err
+		}
+		cloneConfig.StorageClass = volumeConfig.StorageClass
+	}
 
 	// Add transaction in case the operation must be rolled back later
 	volTxn := &persistentstore.VolumeTransaction{
@@ -1339,6 +1915,40 @@ func (o *TridentOrchestrator) CloneVolume(volumeConfig *storage.VolumeConfig) (
 	return vol.ConstructExternal(), nil
 }
 
+// validateCloneStorageClass ensures that a storage class requested for a clone is satisfied by the
+// storage pool of the source volume, since a clone is always created alongside its source.
+func (o *TridentOrchestrator) validateCloneStorageClass(sourceVolume *storage.Volume, scName string) error {
+
+	sc, ok := o.storageClasses[scName]
+	if !ok {
+		return fmt.Errorf("unknown storage class: %s", scName)
+	}
+
+	backend, ok := o.backends[sourceVolume.BackendUUID]
+	if !ok {
+		return notFoundError(fmt.Sprintf("backend %s for the source volume not found: %s",
+			sourceVolume.BackendUUID, sourceVolume.Config.Name))
+	}
+
+	// Volumes whose pool isn't known, such as clones and imports, are checked against the whole backend
+	pool, ok := backend.Storage[sourceVolume.Pool]
+	if !ok {
+		if sc.IsAddedToBackend(backend, scName) {
+			return nil
+		}
+		return fmt.Errorf("storage class %s does not match any storage pools for backend %s of source volume %s",
+			scName, backend.Name, sourceVolume.Config.Name)
+	}
+
+	for _, poolSCName := range pool.StorageClasses {
+		if poolSCName == scName {
+			return nil
+		}
+	}
+	return fmt.Errorf("storage class %s is not satisfied by storage pool %s on backend %s of source volume %s",
+		scName, pool.Name, backend.Name, sourceVolume.Config.Name)
+}
+
 // This func is used by volume import so it doesn't check core's o.volumes to see if the
 // volume exists or not. Instead it asks the driver if the volume exists before requesting
 // the volume size. Returns the VolumeExternal representation of the volume.
@@ -1676,6 +2286,106 @@ func (o *TridentOrchestrator) GetVolume(volume string) (*storage.VolumeExternal,
 	return vol.ConstructExternal(), nil
 }
 
+// GetVolumeUsage returns how much of a volume's space is in use, as reported by the volume's backend.
+// The backend is queried on every call.  If the backend's storage driver doesn't report volume usage,
+// nil is returned.
+func (o *TridentOrchestrator) GetVolumeUsage(volumeName string) (*storage.VolumeUsage, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	volume, found := o.volumes[volumeName]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("volume %v was not found", volumeName))
+	}
+	backend, found := o.backends[volume.BackendUUID]
+	if !found {
+		// Should never get here but just to be safe
+		return nil, notFoundError(fmt.Sprintf("backend %s for volume %s not found", volume.BackendUUID,
+			volumeName))
+	}
+
+	return backend.GetVolumeUsage(volume.Config)
+}
+
+// AddVolumeEvent records an event for a volume in the persistent store, where it outlives any
+// container orchestrator event.  Only the most recent events are kept.  The volume need not
+// exist, so that provisioning failures are recorded too.
+func (o *TridentOrchestrator) AddVolumeEvent(volumeName string, event *storage.VolumeEvent) error {
+	if o.bootstrapError != nil {
+		return o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	events, err := o.storeClient.GetVolumeEvents(volumeName)
+	if err != nil {
+		return fmt.Errorf("could not read events for volume %s; %v", volumeName, err)
+	}
+	events = storage.PrependVolumeEvent(events, event, storage.MaxVolumeEvents)
+
+	if err = o.storeClient.UpdateVolumeEvents(volumeName, events); err != nil {
+		return fmt.Errorf("could not record event for volume %s; %v", volumeName, err)
+	}
+	return nil
+}
+
+// GetVolumeEvents returns the recent events recorded for a volume, newest first.
+func (o *TridentOrchestrator) GetVolumeEvents(volumeName string) ([]*storage.VolumeEvent, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return o.storeClient.GetVolumeEvents(volumeName)
+}
+
+// GetVolumeByInternalName returns the volume whose name on its storage system matches the
+// specified internal name.  This maps a volume seen on a storage system back to Trident.
+func (o *TridentOrchestrator) GetVolumeByInternalName(volumeInternal string) (*storage.VolumeExternal, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return getVolumeByInternalName(o.volumes, volumeInternal)
+}
+
+// getVolumeByInternalName searches a set of volumes for the one with the specified internal name.
+// Internal names are only unique within a backend, so a name found on several backends is an error.
+func getVolumeByInternalName(
+	volumes map[string]*storage.Volume, volumeInternal string,
+) (*storage.VolumeExternal, error) {
+
+	var match *storage.Volume
+	for _, vol := range volumes {
+		if vol.Config.InternalName != volumeInternal {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("volumes %s and %s both have internal name %s",
+				match.Config.Name, vol.Config.Name, volumeInternal)
+		}
+		match = vol
+	}
+	if match == nil {
+		return nil, notFoundError(fmt.Sprintf("volume with internal name %s was not found", volumeInternal))
+	}
+	return match.ConstructExternal(), nil
+}
+
 func (o *TridentOrchestrator) GetDriverTypeForVolume(vol *storage.VolumeExternal) (string, error) {
 	if o.bootstrapError != nil {
 		return config.UnknownDriver, o.bootstrapError
@@ -1746,6 +2456,22 @@ func (o *TridentOrchestrator) ListVolumes() ([]*storage.VolumeExternal, error) {
 	return volumes, nil
 }
 
+// ListVolumesPaged returns up to maxEntries volumes, ordered by name, starting with the volume
+// identified by startingToken, along with the token identifying the next page.  The token is
+// empty after the last page.  Pages are read from the persistent store, so listing a very large
+// number of volumes doesn't require building the whole list at once.
+func (o *TridentOrchestrator) ListVolumesPaged(
+	startingToken string, maxEntries int,
+) ([]*storage.VolumeExternal, string, error) {
+	if o.bootstrapError != nil {
+		return nil, "", o.bootstrapError
+	}
+
+	// The store is read directly and is safe to read concurrently, so the page is read without
+	// holding the orchestrator lock and doesn't stall other operations
+	return o.storeClient.GetVolumesPaged(startingToken, maxEntries)
+}
+
 // volumeSnapshots returns any Snapshots for the specified volume
 func (o *TridentOrchestrator) volumeSnapshots(volumeName string) ([]*storage.Snapshot, error) {
 	volume, volumeFound := o.volumes[volumeName]
@@ -1765,8 +2491,9 @@ func (o *TridentOrchestrator) volumeSnapshots(volumeName string) ([]*storage.Sna
 // deleteVolume does the necessary work to delete a volume entirely.  It does
 // not construct a transaction, nor does it take locks; it assumes that the
 // caller will take care of both of these.  It also assumes that the volume
-// exists in memory.
-func (o *TridentOrchestrator) deleteVolume(volumeName string) error {
+// exists in memory.  If force is set, the volume is removed from Trident even
+// if the backend fails to delete it.
+func (o *TridentOrchestrator) deleteVolume(volumeName string, force bool) error {
 	volume := o.volumes[volumeName]
 	volumeBackend := o.backends[volume.BackendUUID]
 
@@ -1797,12 +2524,23 @@ func (o *TridentOrchestrator) deleteVolume(volumeName string) error {
 	// fails to delete the volume.  If the volume does not exist on the backend,
 	// the driver will not return an error.  Thus, we're fine.
 	if err := volumeBackend.RemoveVolume(volume); err != nil {
+		if !force {
+			log.WithFields(log.Fields{
+				"volume":      volumeName,
+				"backendUUID": volume.BackendUUID,
+				"error":       err,
+			}).Error("Unable to delete volume from backend.")
+			return err
+		}
 		log.WithFields(log.Fields{
-			"volume":      volumeName,
-			"backendUUID": volume.BackendUUID,
-			"error":       err,
-		}).Error("Unable to delete volume from backend.")
-		return err
+			"volume":       volumeName,
+			"internalName": volume.Config.InternalName,
+			"backend":      volumeBackend.Name,
+			"backendUUID":  volume.BackendUUID,
+			"error":        err,
+		}).Warning("FORCED DELETE: Unable to delete volume from backend, removing it from Trident anyway.  " +
+			"The volume may be orphaned on the storage system and must be deleted there manually.")
+		volumeBackend.RemoveCachedVolume(volumeName)
 	}
 	if err := o.deleteVolumeFromPersistentStoreIgnoreError(volume); err != nil {
 		return err
@@ -1836,13 +2574,29 @@ func (o *TridentOrchestrator) deleteVolumeFromPersistentStoreIgnoreError(volume
 		}).Error("Unable to delete volume from persistent store.")
 		return err
 	}
+	if err := o.storeClient.DeleteVolumeEvents(volume.Config.Name); err != nil {
+		log.WithFields(log.Fields{
+			"volume": volume.Config.Name,
+		}).Warningf("Unable to delete volume events from persistent store; %v", err)
+	}
 	return nil
 }
 
 // DeleteVolume does the necessary set up to delete a volume during the course
 // of normal operation, verifying that the volume is present in Trident and
 // creating a transaction to ensure that the delete eventually completes.
-func (o *TridentOrchestrator) DeleteVolume(volumeName string) (err error) {
+func (o *TridentOrchestrator) DeleteVolume(volumeName string) error {
+	return o.deleteVolumeWithTransaction(volumeName, false)
+}
+
+// ForceDeleteVolume deletes a volume like DeleteVolume, except that the volume is removed
+// from Trident even if its backend cannot delete it, such as when the storage system is
+// unreachable.  Any volume left on the storage system must be cleaned up manually.
+func (o *TridentOrchestrator) ForceDeleteVolume(volumeName string) error {
+	return o.deleteVolumeWithTransaction(volumeName, true)
+}
+
+func (o *TridentOrchestrator) deleteVolumeWithTransaction(volumeName string, force bool) (err error) {
 	if o.bootstrapError != nil {
 		return o.bootstrapError
 	}
@@ -1854,6 +2608,10 @@ func (o *TridentOrchestrator) DeleteVolume(volumeName string) (err error) {
 	if !ok {
 		return notFoundError(fmt.Sprintf("volume %s not found", volumeName))
 	}
+	if volume.Config.DeletionProtection {
+		return volumeProtectedError(fmt.Sprintf(
+			"volume %s is protected from deletion; clear its deletion protection first", volumeName))
+	}
 	if volume.Orphaned {
 		log.WithFields(log.Fields{
 			"volume":      volumeName,
@@ -1891,7 +2649,7 @@ func (o *TridentOrchestrator) DeleteVolume(volumeName string) (err error) {
 	}()
 
 	// Delete the volume
-	return o.deleteVolume(volumeName)
+	return o.deleteVolume(volumeName, force)
 }
 
 func (o *TridentOrchestrator) ListVolumesByPlugin(pluginName string) ([]*storage.VolumeExternal, error) {
@@ -1914,6 +2672,31 @@ func (o *TridentOrchestrator) ListVolumesByPlugin(pluginName string) ([]*storage
 	return volumes, nil
 }
 
+// ListVolumesForBackend returns the volumes on the named backend.
+func (o *TridentOrchestrator) ListVolumesForBackend(backendName string) ([]*storage.VolumeExternal, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	backendUUID, err := o.getBackendUUIDByBackendName(backendName)
+	if err != nil {
+		return nil, err
+	}
+	backend, found := o.backends[backendUUID]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("backend %v was not found", backendName))
+	}
+
+	volumes := make([]*storage.VolumeExternal, 0, len(backend.Volumes))
+	for _, vol := range backend.Volumes {
+		volumes = append(volumes, vol.ConstructExternal())
+	}
+	return volumes, nil
+}
+
 func (o *TridentOrchestrator) PublishVolume(
 	volumeName string, publishInfo *utils.VolumePublishInfo,
 ) error {
@@ -1932,7 +2715,71 @@ func (o *TridentOrchestrator) PublishVolume(
 		return volumeDeletingError(fmt.Sprintf("volume %s is deleting", volumeName))
 	}
 
-	return o.backends[volume.BackendUUID].Driver.Publish(volume.Config.InternalName, publishInfo)
+	if err := o.backends[volume.BackendUUID].Driver.Publish(volume.Config.InternalName, publishInfo); err != nil {
+		return err
+	}
+
+	// Record the publication, so that it survives a restart.  If it can't be saved, the CO will
+	// retry, and publishing again is harmless.
+	if publishInfo.HostName != "" && volume.AddPublishedNode(publishInfo.HostName) {
+		if err := o.storeClient.UpdateVolume(volume); err != nil {
+			volume.RemovePublishedNode(publishInfo.HostName)
+			return err
+		}
+	}
+	return nil
+}
+
+// UnpublishVolume records that a volume is no longer published to a node.  Trident doesn't revoke
+// a node's access to individual volumes, so nothing changes on the backend.
+func (o *TridentOrchestrator) UnpublishVolume(volumeName, nodeName string) error {
+	if o.bootstrapError != nil {
+		return o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	volume, ok := o.volumes[volumeName]
+	if !ok {
+		return notFoundError(fmt.Sprintf("volume %s not found", volumeName))
+	}
+
+	if volume.RemovePublishedNode(nodeName) {
+		if err := o.storeClient.UpdateVolume(volume); err != nil {
+			volume.AddPublishedNode(nodeName)
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPublishedNodesForVolume returns the nodes a volume is published to, as recorded in the
+// persistent store.  A node that has since been removed from Trident is still returned, by name
+// only, since the volume may remain attached to it.
+func (o *TridentOrchestrator) GetPublishedNodesForVolume(volumeName string) ([]*utils.Node, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	volume, ok := o.volumes[volumeName]
+	if !ok {
+		return nil, notFoundError(fmt.Sprintf("volume %s not found", volumeName))
+	}
+
+	nodes := make([]*utils.Node, 0, len(volume.PublishedNodes))
+	for _, nodeName := range volume.PublishedNodes {
+		if node, ok := o.nodes[nodeName]; ok {
+			nodes = append(nodes, node)
+		} else {
+			nodes = append(nodes, &utils.Node{Name: nodeName})
+		}
+	}
+
+	return nodes, nil
 }
 
 // AttachVolume mounts a volume to the local host.  This method is currently only used by Docker,
@@ -2032,6 +2879,105 @@ func (o *TridentOrchestrator) DetachVolume(volumeName, mountpoint string) error
 // CreateSnapshot creates a snapshot of the given volume
 func (o *TridentOrchestrator) CreateSnapshot(
 	snapshotConfig *storage.SnapshotConfig,
+) (*storage.SnapshotExternal, error) {
+
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return o.createSnapshot(snapshotConfig)
+}
+
+// CreateSnapshotGroup creates a snapshot with the given name of each of the listed volumes,
+// recording the snapshots as a group.  Trident has no way to ask several backends to snapshot
+// their volumes at the same instant, so the snapshots are taken one after another while the
+// orchestrator is locked.  If any snapshot can't be created, those already taken are deleted.
+func (o *TridentOrchestrator) CreateSnapshotGroup(
+	volumeNames []string, groupName string,
+) ([]*storage.SnapshotExternal, error) {
+
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	if groupName == "" {
+		return nil, fmt.Errorf("a snapshot group name is required")
+	}
+	if len(volumeNames) == 0 {
+		return nil, fmt.Errorf("snapshot group %s must include at least one volume", groupName)
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	// Check the whole group before creating anything
+	groupVolumes := make(map[string]bool)
+	for _, volumeName := range volumeNames {
+		if groupVolumes[volumeName] {
+			return nil, fmt.Errorf("volume %s is listed more than once", volumeName)
+		}
+		groupVolumes[volumeName] = true
+
+		volume, ok := o.volumes[volumeName]
+		if !ok {
+			return nil, notFoundError(fmt.Sprintf("volume %s not found", volumeName))
+		}
+		if volume.State.IsDeleting() {
+			return nil, volumeDeletingError(fmt.Sprintf("volume %s is deleting", volumeName))
+		}
+		if _, ok := o.snapshots[storage.MakeSnapshotID(volumeName, groupName)]; ok {
+			return nil, fmt.Errorf("snapshot %s already exists",
+				storage.MakeSnapshotID(volumeName, groupName))
+		}
+	}
+
+	snapshots := make([]*storage.SnapshotExternal, 0, len(volumeNames))
+	for _, volumeName := range volumeNames {
+		snapshotConfig := &storage.SnapshotConfig{
+			Version:    config.OrchestratorAPIVersion,
+			Name:       groupName,
+			VolumeName: volumeName,
+			GroupName:  groupName,
+		}
+		snapshot, err := o.createSnapshot(snapshotConfig)
+		if err != nil {
+			o.deleteSnapshotGroupMembers(snapshots)
+			return nil, fmt.Errorf("failed to create snapshot group %s; %v", groupName, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	log.WithFields(log.Fields{
+		"group":   groupName,
+		"volumes": volumeNames,
+	}).Info("Created snapshot group.")
+
+	return snapshots, nil
+}
+
+// deleteSnapshotGroupMembers rolls back a partially created snapshot group.  Failures are logged
+// rather than returned so that the error that caused the rollback is the one reported.  The
+// caller must hold the orchestrator lock.
+func (o *TridentOrchestrator) deleteSnapshotGroupMembers(snapshots []*storage.SnapshotExternal) {
+	for _, snapshot := range snapshots {
+		if err := o.deleteSnapshot(snapshot.Config); err != nil {
+			log.WithFields(log.Fields{
+				"snapshot": snapshot.Config.Name,
+				"volume":   snapshot.Config.VolumeName,
+				"group":    snapshot.Config.GroupName,
+				"error":    err,
+			}).Error("Unable to roll back snapshot group member.")
+		}
+	}
+}
+
+// createSnapshot creates a snapshot of the given volume.  The caller must hold the
+// orchestrator lock.
+func (o *TridentOrchestrator) createSnapshot(
+	snapshotConfig *storage.SnapshotConfig,
 ) (externalSnapshot *storage.SnapshotExternal, err error) {
 
 	var (
@@ -2041,13 +2987,6 @@ func (o *TridentOrchestrator) CreateSnapshot(
 		snapshot *storage.Snapshot
 	)
 
-	if o.bootstrapError != nil {
-		return nil, o.bootstrapError
-	}
-
-	o.mutex.Lock()
-	defer o.mutex.Unlock()
-
 	// Check if the snapshot already exists
 	if _, ok := o.snapshots[snapshotConfig.ID()]; ok {
 		return nil, fmt.Errorf("snapshot %s already exists", snapshotConfig.ID())
@@ -2072,6 +3011,23 @@ func (o *TridentOrchestrator) CreateSnapshot(
 	// Complete the snapshot config
 	snapshotConfig.VolumeInternalName = volume.Config.InternalName
 
+	// Ensure the volume may have another snapshot, whether it is created or imported
+	if backend.LimitSnapshotsPerVolume > 0 {
+		snapshots, err := o.storeClient.GetSnapshotsForVolume(snapshotConfig.VolumeName)
+		if err != nil {
+			return nil, err
+		}
+		if len(snapshots) >= backend.LimitSnapshotsPerVolume {
+			return nil, snapshotLimitError(fmt.Sprintf("volume %s has %d snapshots, which is the limit "+
+				"for backend %s", snapshotConfig.VolumeName, len(snapshots), backend.Name))
+		}
+	}
+
+	// Adopt an existing snapshot on the storage system rather than creating one
+	if snapshotConfig.ImportOriginalName != "" {
+		return o.importSnapshot(snapshotConfig, backend)
+	}
+
 	// Add transaction in case the operation must be rolled back later
 	txn := &persistentstore.VolumeTransaction{
 		Config:         volume.Config,
@@ -2094,6 +3050,15 @@ func (o *TridentOrchestrator) CreateSnapshot(
 			snapshotConfig.Name, snapshotConfig.VolumeName, backend.Name, err)
 	}
 
+	// Backends that create snapshots asynchronously report them as creating until they are ready
+	if !snapshot.State.IsReady() {
+		log.WithFields(log.Fields{
+			"snapshot": snapshotConfig.Name,
+			"volume":   snapshotConfig.VolumeName,
+			"backend":  backend.Name,
+		}).Info("Snapshot is still being created.")
+	}
+
 	// Save references to new snapshot
 	if err = o.storeClient.AddSnapshot(snapshot); err != nil {
 		return nil, err
@@ -2103,6 +3068,48 @@ func (o *TridentOrchestrator) CreateSnapshot(
 	return snapshot.ConstructExternal(), nil
 }
 
+// importSnapshot records a pre-existing backend snapshot in Trident, much like a pre-provisioned
+// VolumeSnapshotContent, without asking the backend to create anything.  Because nothing is
+// created, there is nothing to roll back and no transaction is needed.  The caller must hold
+// the orchestrator lock.
+func (o *TridentOrchestrator) importSnapshot(
+	snapshotConfig *storage.SnapshotConfig, backend *storage.Backend,
+) (*storage.SnapshotExternal, error) {
+
+	if !backend.State.IsOnline() {
+		return nil, fmt.Errorf("backend %s is not online", backend.Name)
+	}
+
+	// Ensure the snapshot exists on the source volume
+	snapshotConfig.InternalName = snapshotConfig.ImportOriginalName
+	existingSnapshot, err := backend.Driver.GetSnapshot(snapshotConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for snapshot %s of volume %s on backend %s: %v",
+			snapshotConfig.ImportOriginalName, snapshotConfig.VolumeName, backend.Name, err)
+	} else if existingSnapshot == nil {
+		return nil, notFoundError(fmt.Sprintf("snapshot %s of volume %s not found on backend %s",
+			snapshotConfig.ImportOriginalName, snapshotConfig.VolumeName, backend.Name))
+	}
+
+	snapshot := storage.NewSnapshot(snapshotConfig, existingSnapshot.Created, existingSnapshot.SizeBytes)
+	if !existingSnapshot.State.IsReady() {
+		snapshot.State = storage.SnapshotStateCreating
+	}
+	if err = o.storeClient.AddSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+	o.snapshots[snapshotConfig.ID()] = snapshot
+
+	log.WithFields(log.Fields{
+		"snapshot":     snapshotConfig.Name,
+		"internalName": snapshotConfig.InternalName,
+		"volume":       snapshotConfig.VolumeName,
+		"backend":      backend.Name,
+	}).Info("Imported snapshot.")
+
+	return snapshot.ConstructExternal(), nil
+}
+
 // addSnapshotCleanup is used as a deferred method from the snapshot create method
 // to clean up in case anything goes wrong during the operation.
 func (o *TridentOrchestrator) addSnapshotCleanup(
@@ -2159,10 +3166,12 @@ func (o *TridentOrchestrator) GetSnapshot(volumeName, snapshotName string) (*sto
 		return nil, o.bootstrapError
 	}
 
+	snapshotID := storage.MakeSnapshotID(volumeName, snapshotName)
+	o.refreshPendingSnapshots(func(s *storage.Snapshot) bool { return s.ID() == snapshotID })
+
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
 
-	snapshotID := storage.MakeSnapshotID(volumeName, snapshotName)
 	snapshot, found := o.snapshots[snapshotID]
 	if !found {
 		return nil, notFoundError(fmt.Sprintf("snapshot %v was not found", snapshotName))
@@ -2170,6 +3179,71 @@ func (o *TridentOrchestrator) GetSnapshot(volumeName, snapshotName string) (*sto
 	return snapshot.ConstructExternal(), nil
 }
 
+// refreshPendingSnapshots asks the backends whether any of the matching snapshots that were still
+// being created have become ready to use.  The backends are queried without holding the orchestrator
+// lock, so the caller must not hold it.  A snapshot that has become ready is persisted with its new
+// state; if that fails, it stays pending and is checked again next time.
+func (o *TridentOrchestrator) refreshPendingSnapshots(matches func(*storage.Snapshot) bool) {
+
+	type pendingSnapshot struct {
+		config  *storage.SnapshotConfig
+		backend *storage.Backend
+	}
+
+	o.mutex.Lock()
+	pending := make([]pendingSnapshot, 0)
+	for _, snapshot := range o.snapshots {
+		if snapshot.State.IsReady() || !matches(snapshot) {
+			continue
+		}
+		logFields := log.Fields{"snapshot": snapshot.Config.Name, "volume": snapshot.Config.VolumeName}
+		volume, ok := o.volumes[snapshot.Config.VolumeName]
+		if !ok {
+			log.WithFields(logFields).Warning("Source volume of pending snapshot not found.")
+			continue
+		}
+		backend, ok := o.backends[volume.BackendUUID]
+		if !ok {
+			log.WithFields(logFields).Warning("Backend of pending snapshot not found.")
+			continue
+		}
+		pending = append(pending, pendingSnapshot{snapshot.ConstructClone().Config, backend})
+	}
+	o.mutex.Unlock()
+
+	for _, p := range pending {
+
+		logFields := log.Fields{"snapshot": p.config.Name, "volume": p.config.VolumeName}
+
+		current, err := p.backend.GetSnapshot(p.config)
+		if err != nil {
+			log.WithFields(logFields).Warningf("Could not check the state of pending snapshot: %v", err)
+			continue
+		} else if !current.State.IsReady() {
+			log.WithFields(logFields).Debug("Snapshot is not yet ready.")
+			continue
+		}
+
+		// The snapshot may have been deleted or recreated while the lock wasn't held
+		o.mutex.Lock()
+		if snapshot, ok := o.snapshots[p.config.ID()]; ok && !snapshot.State.IsReady() {
+			update := snapshot.ConstructClone()
+			update.State = storage.SnapshotStateOnline
+			if current.SizeBytes != 0 {
+				update.SizeBytes = current.SizeBytes
+			}
+			if err = o.storeClient.UpdateSnapshot(update); err != nil {
+				log.WithFields(logFields).Warningf("Could not persist the state of ready snapshot: %v", err)
+			} else {
+				snapshot.State = update.State
+				snapshot.SizeBytes = update.SizeBytes
+				log.WithFields(logFields).Info("Snapshot is ready.")
+			}
+		}
+		o.mutex.Unlock()
+	}
+}
+
 // deleteSnapshot does the necessary work to delete a snapshot entirely.  It does
 // not construct a transaction, nor does it take locks; it assumes that the caller will
 // take care of both of these.
@@ -2221,7 +3295,7 @@ func (o *TridentOrchestrator) deleteSnapshot(snapshotConfig *storage.SnapshotCon
 			"backendUUID":               volume.BackendUUID,
 			"volume.State":              volume.State,
 		}).Debug("Hard deleting volume.")
-		return o.deleteVolume(snapshotConfig.VolumeName)
+		return o.deleteVolume(snapshotConfig.VolumeName, false)
 	}
 
 	return nil
@@ -2242,8 +3316,38 @@ func (o *TridentOrchestrator) deleteSnapshotFromPersistentStoreIgnoreError(snaps
 	return nil
 }
 
-// DeleteSnapshot deletes a snapshot of the given volume
-func (o *TridentOrchestrator) DeleteSnapshot(volumeName, snapshotName string) (err error) {
+// DeleteSnapshot deletes a snapshot of the given volume, unless a volume was cloned from it.
+func (o *TridentOrchestrator) DeleteSnapshot(volumeName, snapshotName string) error {
+	return o.deleteSnapshotWithTransaction(volumeName, snapshotName, false)
+}
+
+// ForceDeleteSnapshot deletes a snapshot like DeleteSnapshot, except that the snapshot is
+// deleted even if volumes were cloned from it.  The backend may still refuse to delete a
+// snapshot on which a clone depends.
+func (o *TridentOrchestrator) ForceDeleteSnapshot(volumeName, snapshotName string) error {
+	return o.deleteSnapshotWithTransaction(volumeName, snapshotName, true)
+}
+
+// getSnapshotDependents returns the sorted names of the volumes cloned from a snapshot that still
+// depend on it.  Clones their backends report as independent, such as split clones, are omitted.
+func (o *TridentOrchestrator) getSnapshotDependents(volumeName, snapshotName string) []string {
+	dependents := make([]string, 0)
+	for _, volume := range o.volumes {
+		if volume.Config.CloneSourceVolume != volumeName || volume.Config.CloneSourceSnapshot != snapshotName {
+			continue
+		}
+		if backend, ok := o.backends[volume.BackendUUID]; ok && backend.IsCloneIndependent(volume.Config) {
+			continue
+		}
+		dependents = append(dependents, volume.Config.Name)
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+func (o *TridentOrchestrator) deleteSnapshotWithTransaction(
+	volumeName, snapshotName string, force bool,
+) (err error) {
 	if o.bootstrapError != nil {
 		return o.bootstrapError
 	}
@@ -2267,6 +3371,18 @@ func (o *TridentOrchestrator) DeleteSnapshot(volumeName, snapshotName string) (e
 		return notFoundError(fmt.Sprintf("snapshot %s not found on volume %s", snapshotName, volumeName))
 	}
 
+	if dependents := o.getSnapshotDependents(volumeName, snapshotName); len(dependents) > 0 {
+		if !force {
+			return snapshotInUseError(fmt.Sprintf("snapshot %s of volume %s has dependent clone(s): %s",
+				snapshotName, volumeName, strings.Join(dependents, ", ")))
+		}
+		log.WithFields(log.Fields{
+			"volume":     volumeName,
+			"snapshot":   snapshotName,
+			"dependents": dependents,
+		}).Warning("Force deleting a snapshot with dependent clones.")
+	}
+
 	// TODO: Is this needed?
 	if volume.Orphaned {
 		log.WithFields(log.Fields{
@@ -2317,6 +3433,8 @@ func (o *TridentOrchestrator) ListSnapshots() ([]*storage.SnapshotExternal, erro
 		return nil, o.bootstrapError
 	}
 
+	o.refreshPendingSnapshots(func(*storage.Snapshot) bool { return true })
+
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
 
@@ -2332,6 +3450,8 @@ func (o *TridentOrchestrator) ListSnapshotsByName(snapshotName string) ([]*stora
 		return nil, o.bootstrapError
 	}
 
+	o.refreshPendingSnapshots(func(s *storage.Snapshot) bool { return s.Config.Name == snapshotName })
+
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
 
@@ -2349,6 +3469,8 @@ func (o *TridentOrchestrator) ListSnapshotsForVolume(volumeName string) ([]*stor
 		return nil, o.bootstrapError
 	}
 
+	o.refreshPendingSnapshots(func(s *storage.Snapshot) bool { return s.Config.VolumeName == volumeName })
+
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
 
@@ -2552,14 +3674,127 @@ func (o *TridentOrchestrator) resizeVolumeCleanup(
 	return err
 }
 
+// ChangeVolumeAccessMode changes the access mode of an existing volume, provided the volume's backend
+// and protocol support the requested mode.  Only the new mode is persisted; the volume's access rules
+// (export policies, igroups) are applied per node as the volume is next published.
+func (o *TridentOrchestrator) ChangeVolumeAccessMode(
+	volumeName string, accessMode config.AccessMode,
+) (*storage.VolumeExternal, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	volume, found := o.volumes[volumeName]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("volume %s not found", volumeName))
+	}
+	if volume.State.IsDeleting() {
+		return nil, volumeDeletingError(fmt.Sprintf("volume %s is deleting", volumeName))
+	}
+
+	switch accessMode {
+	case config.ReadWriteOnce, config.ReadWriteOncePod, config.ReadOnlyOnce, config.ReadOnlyMany,
+		config.ReadWriteMany:
+		break
+	default:
+		return nil, fmt.Errorf("invalid access mode: %s", accessMode)
+	}
+
+	backend, found := o.backends[volume.BackendUUID]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("backend %s for volume %s not found",
+			volume.BackendUUID, volumeName))
+	}
+
+	// Ensure the volume's protocol (or its backend's, if unspecified) supports the new access mode
+	protocol := volume.Config.Protocol
+	if protocol == config.ProtocolAny {
+		protocol = backend.GetProtocol()
+	}
+	if _, err := o.getProtocol(accessMode, protocol); err != nil {
+		return nil, unsupportedError(fmt.Sprintf("cannot change access mode of volume %s to %s: %v",
+			volumeName, accessMode, err))
+	}
+
+	if volume.Config.AccessMode == accessMode {
+		return volume.ConstructExternal(), nil
+	}
+
+	oldAccessMode := volume.Config.AccessMode
+	volume.Config.AccessMode = accessMode
+	if err := o.updateVolumeOnPersistentStore(volume); err != nil {
+		volume.Config.AccessMode = oldAccessMode
+		log.WithFields(log.Fields{
+			"volume": volumeName,
+			"error":  err,
+		}).Error("Unable to update the volume's access mode in persistent store.")
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"volume":        volumeName,
+		"oldAccessMode": oldAccessMode,
+		"newAccessMode": accessMode,
+	}).Info("Orchestrator changed the volume's access mode.")
+
+	return volume.ConstructExternal(), nil
+}
+
+// SetVolumeDeletionProtection sets or clears a volume's deletion protection.  While a volume is
+// protected, DeleteVolume and ForceDeleteVolume refuse to delete it.
+func (o *TridentOrchestrator) SetVolumeDeletionProtection(
+	volumeName string, protected bool,
+) (*storage.VolumeExternal, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	volume, found := o.volumes[volumeName]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("volume %s not found", volumeName))
+	}
+	if volume.State.IsDeleting() {
+		return nil, volumeDeletingError(fmt.Sprintf("volume %s is deleting", volumeName))
+	}
+
+	if volume.Config.DeletionProtection == protected {
+		return volume.ConstructExternal(), nil
+	}
+
+	volume.Config.DeletionProtection = protected
+	if err := o.updateVolumeOnPersistentStore(volume); err != nil {
+		volume.Config.DeletionProtection = !protected
+		log.WithFields(log.Fields{
+			"volume": volumeName,
+			"error":  err,
+		}).Error("Unable to update the volume's deletion protection in persistent store.")
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"volume":             volumeName,
+		"deletionProtection": protected,
+	}).Info("Orchestrator changed the volume's deletion protection.")
+
+	return volume.ConstructExternal(), nil
+}
+
 // getProtocol returns the appropriate protocol based on a specified volume access mode and protocol, or
 // an error if the two settings are incompatible.
 //
 // Generally, the access mode maps to a protocol as follows:
 //
-//  ReadWriteOnce -> Any (File + Block)
-//  ReadOnlyMany  -> Any (File + Block)
-//  ReadWriteMany -> File
+//  ReadWriteOnce    -> Any (File + Block)
+//  ReadWriteOncePod -> Any (File + Block)
+//  ReadOnlyOnce     -> Any (File + Block)
+//  ReadOnlyMany     -> Any (File + Block)
+//  ReadWriteMany    -> File
 //
 // But if the protocol is explicitly set to File or Block, then it may override ProtocolAny or generate a conflict.
 // The truth table below yields two special cases (RWX/Block) and (RWX/Any); all other rows simply echo the protocol.
@@ -2599,6 +3834,10 @@ func (o *TridentOrchestrator) AddStorageClass(scConfig *storageclass.Config) (*s
 		return nil, o.bootstrapError
 	}
 
+	if err := scConfig.SnapshotRetention.Validate(); err != nil {
+		return nil, err
+	}
+
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
 	sc := storageclass.New(scConfig)
@@ -2689,6 +3928,17 @@ func (o *TridentOrchestrator) AddNode(node *utils.Node) error {
 
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
+
+	if otherNode := o.findOtherNodeWithIQN(node); otherNode != nil {
+		log.WithFields(log.Fields{
+			"node":      node.Name,
+			"otherNode": otherNode.Name,
+			"IQN":       node.IQN,
+		}).Warning("Node has the same IQN as another node.")
+		return foundError(fmt.Sprintf("node %s has the same IQN %s as node %s, which must be removed first",
+			node.Name, node.IQN, otherNode.Name))
+	}
+
 	if err := o.storeClient.AddOrUpdateNode(node); err != nil {
 		return err
 	}
@@ -2696,6 +3946,23 @@ func (o *TridentOrchestrator) AddNode(node *utils.Node) error {
 	return nil
 }
 
+// findOtherNodeWithIQN returns any other registered node that has the same iSCSI initiator name as
+// the specified node, or nil if there is none.  That happens when a node is re-created under a new
+// name, and the old record must be removed before the new node may register, since Trident can't
+// tell which of the two still owns the IQN.
+func (o *TridentOrchestrator) findOtherNodeWithIQN(node *utils.Node) *utils.Node {
+
+	if node.IQN == "" {
+		return nil
+	}
+	for _, otherNode := range o.nodes {
+		if otherNode.Name != node.Name && otherNode.IQN == node.IQN {
+			return otherNode
+		}
+	}
+	return nil
+}
+
 func (o *TridentOrchestrator) GetNode(nName string) (*utils.Node, error) {
 	if o.bootstrapError != nil {
 		return nil, o.bootstrapError
@@ -2726,6 +3993,66 @@ func (o *TridentOrchestrator) ListNodes() ([]*utils.Node, error) {
 	return nodes, nil
 }
 
+// GetReachableNodesForVolume returns the registered nodes whose topology labels (region, zone)
+// match those of the storage pool on which the specified volume resides.  If the pool does not
+// advertise a region or zone, every node is considered able to reach the volume.
+func (o *TridentOrchestrator) GetReachableNodesForVolume(volumeName string) ([]*utils.Node, error) {
+	if o.bootstrapError != nil {
+		return nil, o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	volume, found := o.volumes[volumeName]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("volume %s was not found", volumeName))
+	}
+
+	backend, found := o.backends[volume.BackendUUID]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("backend %s for volume %s was not found",
+			volume.BackendUUID, volumeName))
+	}
+
+	// Determine the pool's failure domain, if any
+	var regionOffer, zoneOffer sa.Offer
+	if pool, ok := backend.Storage[volume.Pool]; ok {
+		regionOffer = pool.Attributes[sa.Region]
+		zoneOffer = pool.Attributes[sa.Zone]
+	}
+
+	nodes := make([]*utils.Node, 0, len(o.nodes))
+	for _, node := range o.nodes {
+		if topologyOfferMatches(regionOffer, node.TopologyLabels[config.TopologyRegionLabel]) &&
+			topologyOfferMatches(zoneOffer, node.TopologyLabels[config.TopologyZoneLabel]) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"volume":    volumeName,
+		"backend":   backend.Name,
+		"pool":      volume.Pool,
+		"reachable": len(nodes),
+		"nodes":     len(o.nodes),
+	}).Debug("Found nodes that can reach volume.")
+
+	return nodes, nil
+}
+
+// topologyOfferMatches returns true if a pool topology offer (region or zone) is satisfied by
+// the corresponding node label.  A pool with no such offer is reachable from any node.
+func topologyOfferMatches(offer sa.Offer, label string) bool {
+	if offer == nil {
+		return true
+	}
+	if label == "" {
+		return false
+	}
+	return offer.Matches(sa.NewStringRequest(label))
+}
+
 func (o *TridentOrchestrator) DeleteNode(nName string) error {
 	if o.bootstrapError != nil {
 		return o.bootstrapError
@@ -2745,6 +4072,56 @@ func (o *TridentOrchestrator) DeleteNode(nName string) error {
 	return nil
 }
 
+// CleanupNode removes a decommissioned node from Trident.  The node's access to every backend is
+// revoked before its record is deleted, so a failed cleanup may simply be repeated.  Cleaning up a
+// node that Trident does not know is not an error.
+func (o *TridentOrchestrator) CleanupNode(nName string) error {
+	if o.bootstrapError != nil {
+		return o.bootstrapError
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	node, found := o.nodes[nName]
+	if !found {
+		log.WithField("node", nName).Debug("Node not found, nothing to clean up.")
+		return nil
+	}
+
+	errList := make([]string, 0)
+	for _, backend := range o.backends {
+		if err := backend.RemoveNodeAccess(node); err != nil {
+			log.WithFields(log.Fields{
+				"node":    nName,
+				"backend": backend.Name,
+				"error":   err,
+			}).Error("Unable to remove node access from backend.")
+			errList = append(errList, fmt.Sprintf("backend %s: %v", backend.Name, err))
+		}
+	}
+	if len(errList) > 0 {
+		return fmt.Errorf("could not remove access for node %s; %s", nName, strings.Join(errList, "; "))
+	}
+
+	if err := o.storeClient.DeleteNode(node); err != nil {
+		return err
+	}
+	delete(o.nodes, nName)
+
+	// The node's access is gone, so none of its publications remain
+	for _, volume := range o.volumes {
+		if volume.RemovePublishedNode(nName) {
+			if err := o.storeClient.UpdateVolume(volume); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.WithField("node", nName).Info("Cleaned up node.")
+	return nil
+}
+
 func (o *TridentOrchestrator) updateBackendOnPersistentStore(
 	backend *storage.Backend, newBackend bool,
 ) error {
@@ -2841,3 +4218,47 @@ func unsupportedError(message string) error {
 func volumeDeletingError(message string) error {
 	return &VolumeDeletingError{message}
 }
+
+func IsVolumeDeletingError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*VolumeDeletingError)
+	return ok
+}
+
+func volumeProtectedError(message string) error {
+	return &VolumeProtectedError{message}
+}
+
+func IsVolumeProtectedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*VolumeProtectedError)
+	return ok
+}
+
+func snapshotInUseError(message string) error {
+	return &SnapshotInUseError{message}
+}
+
+func IsSnapshotInUseError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*SnapshotInUseError)
+	return ok
+}
+
+func snapshotLimitError(message string) error {
+	return &SnapshotLimitError{message}
+}
+
+func IsSnapshotLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*SnapshotLimitError)
+	return ok
+}
@@ -3,9 +3,11 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -16,6 +18,7 @@ import (
 
 	"github.com/netapp/trident/config"
 	"github.com/netapp/trident/frontend"
+	persistentstore "github.com/netapp/trident/persistent_store"
 	"github.com/netapp/trident/storage"
 	"github.com/netapp/trident/storage/factory"
 	storageclass "github.com/netapp/trident/storage_class"
@@ -58,7 +61,9 @@ type MockOrchestrator struct {
 	mockBackendsByUUID map[string]*mockBackend
 	storageClasses     map[string]*storageclass.StorageClass
 	volumes            map[string]*storage.Volume
+	snapshots          map[string]*storage.Snapshot
 	nodes              map[string]*utils.Node
+	volumeEvents       map[string][]*storage.VolumeEvent
 	mutex              *sync.Mutex
 }
 
@@ -78,6 +83,44 @@ func (m *MockOrchestrator) GetVersion() (string, error) {
 	return config.OrchestratorVersion.String(), nil
 }
 
+func (m *MockOrchestrator) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *MockOrchestrator) ExportState() (*persistentstore.State, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state := &persistentstore.State{SchemaVersion: persistentstore.StateSchemaVersion}
+	for _, backend := range m.backendsByUUID {
+		state.Backends = append(state.Backends, backend.ConstructPersistent())
+	}
+	for _, sc := range m.storageClasses {
+		state.StorageClasses = append(state.StorageClasses, sc.ConstructPersistent())
+	}
+	for _, volume := range m.volumes {
+		state.Volumes = append(state.Volumes, volume.ConstructExternal())
+	}
+	for _, node := range m.nodes {
+		state.Nodes = append(state.Nodes, node)
+	}
+	return state, nil
+}
+
+// ImportState only validates the state document, since the mock has no persistent store.
+func (m *MockOrchestrator) ImportState(
+	state *persistentstore.State,
+) (*persistentstore.ImportStateResult, error) {
+	if state == nil || state.SchemaVersion != persistentstore.StateSchemaVersion {
+		return nil, fmt.Errorf("unsupported state document")
+	}
+	return &persistentstore.ImportStateResult{}, nil
+}
+
+func (m *MockOrchestrator) ListVolumeTransactions() ([]*persistentstore.VolumeTransaction, error) {
+	return make([]*persistentstore.VolumeTransaction, 0), nil
+}
+
 // TODO:  Add extra methods to add backends without needing to provide a valid,
 // stringified JSON config.
 func (m *MockOrchestrator) AddBackend(configJSON string) (*storage.BackendExternal, error) {
@@ -237,6 +280,13 @@ func (m *MockOrchestrator) UpdateBackendState(backendName, backendState string)
 	return nil, fmt.Errorf("operation not currently supported")
 }
 
+// UpdateBackendCredentials updates an existing backend's credentials
+func (m *MockOrchestrator) UpdateBackendCredentials(backendName, username, password string) (
+	storageBackendExternal *storage.BackendExternal, err error) {
+	//TODO
+	return nil, fmt.Errorf("operation not currently supported")
+}
+
 func (m *MockOrchestrator) dumpKnownBackends() {
 	log.Debug(">>>MockOrchestrator#dumpKnownBackends")
 	defer log.Debug("<<<MockOrchestrator#dumpKnownBackends")
@@ -289,6 +339,30 @@ func (m *MockOrchestrator) GetBackend(backendName string) (*storage.BackendExter
 	return b.ConstructExternal(), nil
 }
 
+func (m *MockOrchestrator) GetBackendWithSecrets(backendName string) (*storage.BackendExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	b, err := m.getBackendByName(backendName)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.ConstructExternalWithSecrets()
+}
+
+func (m *MockOrchestrator) GetBackendPools(backendName string) ([]*storage.PoolCapacityExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	b, err := m.getBackendByName(backendName)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.GetPoolCapacities()
+}
+
 func (m *MockOrchestrator) GetBackendByBackendUUID(backendUUID string) (*storage.BackendExternal, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -389,6 +463,39 @@ func (m *MockOrchestrator) AddVolume(volumeConfig *storage.VolumeConfig) (*stora
 	return volume.ConstructExternal(), nil
 }
 
+// PlanVolume chooses a mock backend the way AddVolume does, without adding the volume.
+func (m *MockOrchestrator) PlanVolume(volumeConfig *storage.VolumeConfig) (*storage.VolumePlacement, error) {
+
+	if _, ok := m.storageClasses[volumeConfig.StorageClass]; !ok {
+		return nil, fmt.Errorf("storage class %s not found for volume %s",
+			volumeConfig.StorageClass, volumeConfig.Name)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.volumes[volumeConfig.Name]; ok {
+		return nil, fmt.Errorf("volume %s already exists", volumeConfig.Name)
+	}
+	for _, b := range m.mockBackendsByUUID {
+		if volumeConfig.Protocol != config.ProtocolAny && b.protocol != volumeConfig.Protocol {
+			continue
+		}
+		backend, err := m.getBackendByName(b.name)
+		if err != nil {
+			return nil, err
+		}
+		return &storage.VolumePlacement{
+			Backend:      backend.Name,
+			BackendUUID:  backend.BackendUUID,
+			Pool:         "fake",
+			InternalName: GetFakeInternalName(volumeConfig.Name),
+			Protocol:     b.protocol,
+		}, nil
+	}
+	return nil, fmt.Errorf("no mock backends available for protocol %s", volumeConfig.Protocol)
+}
+
 func (m *MockOrchestrator) CloneVolume(volumeConfig *storage.VolumeConfig) (*storage.VolumeExternal, error) {
 	// TODO: write this method to enable CloneVolume unit tests
 	return nil, nil
@@ -439,6 +546,53 @@ func (m *MockOrchestrator) GetVolume(volume string) (*storage.VolumeExternal, er
 	return vol.ConstructExternal(), nil
 }
 
+func (m *MockOrchestrator) GetVolumeByInternalName(volumeInternal string) (*storage.VolumeExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return getVolumeByInternalName(m.volumes, volumeInternal)
+}
+
+func (m *MockOrchestrator) GetVolumeUsage(volumeName string) (*storage.VolumeUsage, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	volume, found := m.volumes[volumeName]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("volume %v was not found", volumeName))
+	}
+	backend, found := m.backendsByUUID[volume.BackendUUID]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("backend %s for volume %s not found", volume.BackendUUID,
+			volumeName))
+	}
+
+	return backend.GetVolumeUsage(volume.Config)
+}
+
+func (m *MockOrchestrator) AddVolumeEvent(volumeName string, event *storage.VolumeEvent) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	m.volumeEvents[volumeName] = storage.PrependVolumeEvent(m.volumeEvents[volumeName], event,
+		storage.MaxVolumeEvents)
+	return nil
+}
+
+func (m *MockOrchestrator) GetVolumeEvents(volumeName string) ([]*storage.VolumeEvent, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	events, ok := m.volumeEvents[volumeName]
+	if !ok {
+		return make([]*storage.VolumeEvent, 0), nil
+	}
+	return events, nil
+}
+
 // Copied verbatim from TridentOrchestrator
 func (m *MockOrchestrator) GetDriverTypeForVolume(
 	vol *storage.VolumeExternal,
@@ -486,6 +640,28 @@ func (m *MockOrchestrator) ListVolumes() ([]*storage.VolumeExternal, error) {
 	return volumes, nil
 }
 
+// ListVolumesPaged pages the mock's volumes by name, like the persistent stores do.
+func (m *MockOrchestrator) ListVolumesPaged(
+	startingToken string, maxEntries int,
+) ([]*storage.VolumeExternal, string, error) {
+	if maxEntries <= 0 {
+		return nil, "", fmt.Errorf("invalid page size %d", maxEntries)
+	}
+
+	volumes, err := m.ListVolumes()
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Config.Name < volumes[j].Config.Name })
+
+	start := sort.Search(len(volumes), func(i int) bool { return volumes[i].Config.Name >= startingToken })
+	end := start + maxEntries
+	if end >= len(volumes) {
+		return volumes[start:], "", nil
+	}
+	return volumes[start:end], volumes[end].Config.Name, nil
+}
+
 func (m *MockOrchestrator) DeleteVolume(volumeName string) error {
 
 	m.mutex.Lock()
@@ -496,6 +672,10 @@ func (m *MockOrchestrator) DeleteVolume(volumeName string) error {
 	if !ok {
 		return notFoundError(fmt.Sprintf("volume %s not found", volumeName))
 	}
+	if volume.Config.DeletionProtection {
+		return volumeProtectedError(fmt.Sprintf(
+			"volume %s is protected from deletion; clear its deletion protection first", volumeName))
+	}
 
 	//delete(m.mockBackends[volume.BackendUUID].volumes, volume.Config.Name)
 	delete(m.mockBackendsByUUID[volume.BackendUUID].volumes, volume.Config.Name)
@@ -503,12 +683,35 @@ func (m *MockOrchestrator) DeleteVolume(volumeName string) error {
 	return nil
 }
 
+// ForceDeleteVolume behaves like DeleteVolume, since mock backends never fail to delete a volume.
+func (m *MockOrchestrator) ForceDeleteVolume(volumeName string) error {
+	return m.DeleteVolume(volumeName)
+}
+
 func (m *MockOrchestrator) ListVolumesByPlugin(pluginName string) ([]*storage.VolumeExternal, error) {
 	// Currently returns nil, since this is backend agnostic.  Change this
 	// if we ever have non-apiserver functionality depend on this function.
 	return nil, nil
 }
 
+func (m *MockOrchestrator) ListVolumesForBackend(backendName string) ([]*storage.VolumeExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	backend, err := m.getBackendByName(backendName)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := make([]*storage.VolumeExternal, 0)
+	for _, vol := range m.volumes {
+		if vol.BackendUUID == backend.BackendUUID {
+			volumes = append(volumes, vol.ConstructExternal())
+		}
+	}
+	return volumes, nil
+}
+
 func (m *MockOrchestrator) AttachVolume(volumeName, mountpoint string, publishInfo *utils.VolumePublishInfo) error {
 	return nil
 }
@@ -519,15 +722,71 @@ func (m *MockOrchestrator) DetachVolume(volumeName, mountpoint string) error {
 
 func (m *MockOrchestrator) PublishVolume(
 	volumeName string, publishInfo *utils.VolumePublishInfo) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Like the NAS drivers, report the backend's mount options
+	if volume, ok := m.volumes[volumeName]; ok {
+		publishInfo.MountOptions = volume.Config.AccessInfo.MountOptions
+		if publishInfo.HostName != "" {
+			volume.AddPublishedNode(publishInfo.HostName)
+		}
+	}
+	return nil
+}
+
+func (m *MockOrchestrator) UnpublishVolume(volumeName, nodeName string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if volume, ok := m.volumes[volumeName]; ok {
+		volume.RemovePublishedNode(nodeName)
+	}
 	return nil
 }
 
 func (m *MockOrchestrator) CreateSnapshot(snapshotConfig *storage.SnapshotConfig) (*storage.SnapshotExternal, error) {
-	return nil, nil
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshotConfig.InternalName = snapshotConfig.Name
+	snapshotConfig.VolumeInternalName = GetFakeInternalName(snapshotConfig.VolumeName)
+	snapshot := storage.NewSnapshot(snapshotConfig, time.Now().UTC().Format(time.RFC3339), 0)
+	m.snapshots[snapshot.ID()] = snapshot
+	return snapshot.ConstructExternal(), nil
+}
+
+func (m *MockOrchestrator) CreateSnapshotGroup(
+	volumeNames []string, groupName string,
+) ([]*storage.SnapshotExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshots := make([]*storage.SnapshotExternal, 0, len(volumeNames))
+	for _, volumeName := range volumeNames {
+		snapshotConfig := &storage.SnapshotConfig{
+			Name:               groupName,
+			InternalName:       groupName,
+			VolumeName:         volumeName,
+			VolumeInternalName: GetFakeInternalName(volumeName),
+			GroupName:          groupName,
+		}
+		snapshot := storage.NewSnapshot(snapshotConfig, time.Now().UTC().Format(time.RFC3339), 0)
+		m.snapshots[snapshot.ID()] = snapshot
+		snapshots = append(snapshots, snapshot.ConstructExternal())
+	}
+	return snapshots, nil
 }
 
 func (m *MockOrchestrator) GetSnapshot(volumeName, snapshotName string) (*storage.SnapshotExternal, error) {
-	return nil, nil
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshot, found := m.snapshots[storage.MakeSnapshotID(volumeName, snapshotName)]
+	if !found {
+		return nil, notFoundError("not found")
+	}
+	return snapshot.ConstructExternal(), nil
 }
 
 func (m *MockOrchestrator) ListSnapshots() ([]*storage.SnapshotExternal, error) {
@@ -535,7 +794,16 @@ func (m *MockOrchestrator) ListSnapshots() ([]*storage.SnapshotExternal, error)
 }
 
 func (m *MockOrchestrator) ListSnapshotsByName(snapshotName string) ([]*storage.SnapshotExternal, error) {
-	return make([]*storage.SnapshotExternal, 0), nil
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshots := make([]*storage.SnapshotExternal, 0)
+	for _, snapshot := range m.snapshots {
+		if snapshot.Config.Name == snapshotName {
+			snapshots = append(snapshots, snapshot.ConstructExternal())
+		}
+	}
+	return snapshots, nil
 }
 
 func (m *MockOrchestrator) ListSnapshotsForVolume(volumeName string) ([]*storage.SnapshotExternal, error) {
@@ -547,6 +815,30 @@ func (m *MockOrchestrator) ReadSnapshotsForVolume(volumeName string) ([]*storage
 }
 
 func (m *MockOrchestrator) DeleteSnapshot(volumeName, snapshotName string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	dependents := make([]string, 0)
+	for _, volume := range m.volumes {
+		if volume.Config.CloneSourceVolume == volumeName && volume.Config.CloneSourceSnapshot == snapshotName {
+			dependents = append(dependents, volume.Config.Name)
+		}
+	}
+	if len(dependents) > 0 {
+		sort.Strings(dependents)
+		return snapshotInUseError(fmt.Sprintf("snapshot %s of volume %s has dependent clone(s): %s",
+			snapshotName, volumeName, strings.Join(dependents, ", ")))
+	}
+
+	delete(m.snapshots, storage.MakeSnapshotID(volumeName, snapshotName))
+	return nil
+}
+
+func (m *MockOrchestrator) ForceDeleteSnapshot(volumeName, snapshotName string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.snapshots, storage.MakeSnapshotID(volumeName, snapshotName))
 	return nil
 }
 
@@ -555,9 +847,45 @@ func (m *MockOrchestrator) ReloadVolumes() error {
 }
 
 func (m *MockOrchestrator) ResizeVolume(volumeName, newSize string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	volume, found := m.volumes[volumeName]
+	if !found {
+		return notFoundError(fmt.Sprintf("volume %s not found", volumeName))
+	}
+	volume.Config.Size = newSize
 	return nil
 }
 
+func (m *MockOrchestrator) ChangeVolumeAccessMode(
+	volumeName string, accessMode config.AccessMode,
+) (*storage.VolumeExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	volume, found := m.volumes[volumeName]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("volume %s not found", volumeName))
+	}
+	volume.Config.AccessMode = accessMode
+	return volume.ConstructExternal(), nil
+}
+
+func (m *MockOrchestrator) SetVolumeDeletionProtection(
+	volumeName string, protected bool,
+) (*storage.VolumeExternal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	volume, found := m.volumes[volumeName]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("volume %s not found", volumeName))
+	}
+	volume.Config.DeletionProtection = protected
+	return volume.ConstructExternal(), nil
+}
+
 func NewMockOrchestrator() *MockOrchestrator {
 	return &MockOrchestrator{
 		backendsByUUID:     make(map[string]*storage.Backend),
@@ -566,6 +894,8 @@ func NewMockOrchestrator() *MockOrchestrator {
 		// mockBackends:   make(map[string]*mockBackend),
 		storageClasses: make(map[string]*storageclass.StorageClass),
 		volumes:        make(map[string]*storage.Volume),
+		snapshots:      make(map[string]*storage.Snapshot),
+		volumeEvents:   make(map[string][]*storage.VolumeEvent),
 		mutex:          &sync.Mutex{},
 	}
 }
@@ -632,6 +962,45 @@ func (m *MockOrchestrator) ListNodes() ([]*utils.Node, error) {
 	return ret, nil
 }
 
+// GetReachableNodesForVolume returns all known nodes, since the mock does not model topology.
+func (m *MockOrchestrator) GetReachableNodesForVolume(volumeName string) ([]*utils.Node, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, found := m.volumes[volumeName]; !found {
+		return nil, notFoundError(fmt.Sprintf("volume %s not found", volumeName))
+	}
+	ret := make([]*utils.Node, 0, len(m.nodes))
+	for _, node := range m.nodes {
+		ret = append(ret, node)
+	}
+	return ret, nil
+}
+
+func (m *MockOrchestrator) GetPublishedNodesForVolume(volumeName string) ([]*utils.Node, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	volume, found := m.volumes[volumeName]
+	if !found {
+		return nil, notFoundError(fmt.Sprintf("volume %s not found", volumeName))
+	}
+	nodes := make([]*utils.Node, 0, len(volume.PublishedNodes))
+	for _, nodeName := range volume.PublishedNodes {
+		if node, ok := m.nodes[nodeName]; ok {
+			nodes = append(nodes, node)
+		} else {
+			nodes = append(nodes, &utils.Node{Name: nodeName})
+		}
+	}
+	return nodes, nil
+}
+
+func (m *MockOrchestrator) CleanupNode(nName string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.nodes, nName)
+	return nil
+}
+
 func (m *MockOrchestrator) DeleteNode(nName string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
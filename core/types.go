@@ -3,8 +3,11 @@
 package core
 
 import (
+	"context"
+
 	"github.com/netapp/trident/config"
 	"github.com/netapp/trident/frontend"
+	persistentstore "github.com/netapp/trident/persistent_store"
 	"github.com/netapp/trident/storage"
 	storageclass "github.com/netapp/trident/storage_class"
 	"github.com/netapp/trident/utils"
@@ -15,38 +18,58 @@ type Orchestrator interface {
 	AddFrontend(f frontend.Plugin)
 	GetFrontend(name string) (frontend.Plugin, error)
 	GetVersion() (string, error)
+	Ping(ctx context.Context) error
+	ExportState() (*persistentstore.State, error)
+	ImportState(state *persistentstore.State) (*persistentstore.ImportStateResult, error)
+	ListVolumeTransactions() ([]*persistentstore.VolumeTransaction, error)
 
 	AddBackend(configJSON string) (*storage.BackendExternal, error)
 	DeleteBackend(backend string) error
 	DeleteBackendByBackendUUID(backendName, backendUUID string) error
 	GetBackend(backend string) (*storage.BackendExternal, error)
+	GetBackendWithSecrets(backend string) (*storage.BackendExternal, error)
 	GetBackendByBackendUUID(backendUUID string) (*storage.BackendExternal, error)
+	GetBackendPools(backend string) ([]*storage.PoolCapacityExternal, error)
 	ListBackends() ([]*storage.BackendExternal, error)
 	UpdateBackend(backendName, configJSON string) (storageBackendExternal *storage.BackendExternal, err error)
 	UpdateBackendByBackendUUID(backendName, configJSON, backendUUID string) (storageBackendExternal *storage.BackendExternal, err error)
 	UpdateBackendState(backendName, backendState string) (storageBackendExternal *storage.BackendExternal, err error)
+	UpdateBackendCredentials(backendName, username, password string) (storageBackendExternal *storage.BackendExternal, err error)
 
 	AddVolume(volumeConfig *storage.VolumeConfig) (*storage.VolumeExternal, error)
+	PlanVolume(volumeConfig *storage.VolumeConfig) (*storage.VolumePlacement, error)
 	AttachVolume(volumeName, mountpoint string, publishInfo *utils.VolumePublishInfo) error
 	CloneVolume(volumeConfig *storage.VolumeConfig) (*storage.VolumeExternal, error)
 	DetachVolume(volumeName, mountpoint string) error
 	DeleteVolume(volume string) error
+	ForceDeleteVolume(volume string) error
 	GetVolume(volume string) (*storage.VolumeExternal, error)
+	GetVolumeByInternalName(volumeInternal string) (*storage.VolumeExternal, error)
+	GetVolumeUsage(volume string) (*storage.VolumeUsage, error)
+	AddVolumeEvent(volume string, event *storage.VolumeEvent) error
+	GetVolumeEvents(volume string) ([]*storage.VolumeEvent, error)
 	GetVolumeExternal(volumeName string, backendName string) (*storage.VolumeExternal, error)
 	GetVolumeType(vol *storage.VolumeExternal) (config.VolumeType, error)
 	ImportVolume(volumeConfig *storage.VolumeConfig, backendName string, notManaged bool, createPVandPVC VolumeCallback) (*storage.VolumeExternal, error)
 	ListVolumes() ([]*storage.VolumeExternal, error)
+	ListVolumesPaged(startingToken string, maxEntries int) ([]*storage.VolumeExternal, string, error)
 	ListVolumesByPlugin(pluginName string) ([]*storage.VolumeExternal, error)
+	ListVolumesForBackend(backendName string) ([]*storage.VolumeExternal, error)
 	PublishVolume(volumeName string, publishInfo *utils.VolumePublishInfo) error
+	UnpublishVolume(volumeName, nodeName string) error
 	ResizeVolume(volumeName, newSize string) error
+	ChangeVolumeAccessMode(volumeName string, accessMode config.AccessMode) (*storage.VolumeExternal, error)
+	SetVolumeDeletionProtection(volumeName string, protected bool) (*storage.VolumeExternal, error)
 
 	CreateSnapshot(snapshotConfig *storage.SnapshotConfig) (*storage.SnapshotExternal, error)
+	CreateSnapshotGroup(volumeNames []string, groupName string) ([]*storage.SnapshotExternal, error)
 	GetSnapshot(volumeName, snapshotName string) (*storage.SnapshotExternal, error)
 	ListSnapshots() ([]*storage.SnapshotExternal, error)
 	ListSnapshotsByName(snapshotName string) ([]*storage.SnapshotExternal, error)
 	ListSnapshotsForVolume(volumeName string) ([]*storage.SnapshotExternal, error)
 	ReadSnapshotsForVolume(volumeName string) ([]*storage.SnapshotExternal, error)
 	DeleteSnapshot(volumeName, snapshotName string) error
+	ForceDeleteSnapshot(volumeName, snapshotName string) error
 
 	GetDriverTypeForVolume(vol *storage.VolumeExternal) (string, error)
 	ReloadVolumes() error
@@ -59,7 +82,10 @@ type Orchestrator interface {
 	AddNode(node *utils.Node) error
 	GetNode(nName string) (*utils.Node, error)
 	ListNodes() ([]*utils.Node, error)
+	GetReachableNodesForVolume(volumeName string) ([]*utils.Node, error)
+	GetPublishedNodesForVolume(volumeName string) ([]*utils.Node, error)
 	DeleteNode(nName string) error
+	CleanupNode(nName string) error
 }
 
 type NotReadyError struct {
@@ -98,4 +124,22 @@ type VolumeDeletingError struct {
 
 func (e *VolumeDeletingError) Error() string { return e.message }
 
+type VolumeProtectedError struct {
+	message string
+}
+
+func (e *VolumeProtectedError) Error() string { return e.message }
+
+type SnapshotLimitError struct {
+	message string
+}
+
+func (e *SnapshotLimitError) Error() string { return e.message }
+
+type SnapshotInUseError struct {
+	message string
+}
+
+func (e *SnapshotInUseError) Error() string { return e.message }
+
 type VolumeCallback func(*storage.VolumeExternal, string) error
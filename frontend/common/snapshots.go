@@ -0,0 +1,110 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/utils"
+)
+
+const (
+	// SnapshotImportOriginalName names an existing backend snapshot to import
+	// rather than creating a new one.
+	SnapshotImportOriginalName = "importOriginalName"
+
+	// SnapshotReserve is the percentage of the source volume to reserve for its snapshots.
+	SnapshotReserve = "snapshotReserve"
+
+	// SnapshotSize is how much space to reserve in the source volume for the snapshot.
+	SnapshotSize = "size"
+
+	// reservedParameterPrefix marks parameters that belong to the container
+	// orchestrator (e.g. secret references) and are never passed to Trident.
+	reservedParameterPrefix = "csi.storage.k8s.io/"
+)
+
+// supportedSnapshotParameters lists the snapshot class parameters understood by Trident.
+var supportedSnapshotParameters = map[string]bool{
+	SnapshotImportOriginalName: true,
+	SnapshotReserve:            true,
+	SnapshotSize:               true,
+}
+
+type InvalidParameterError struct {
+	message string
+}
+
+func (e *InvalidParameterError) Error() string { return e.message }
+
+func IsInvalidParameterError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*InvalidParameterError)
+	return ok
+}
+
+// unsupportedParameters returns the sorted keys of any parameters that are neither supported
+// nor reserved for the container orchestrator.
+func unsupportedParameters(opts map[string]string, isSupported func(string) bool) []string {
+
+	unknown := make([]string, 0)
+	for k := range opts {
+		if !isSupported(k) && !strings.HasPrefix(k, reservedParameterPrefix) {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// GetSnapshotConfig accepts a set of parameters describing a snapshot creation request
+// and returns a snapshot config structure suitable for passing to the orchestrator core.
+// Any parameter Trident does not recognize results in an InvalidParameterError.
+func GetSnapshotConfig(volumeName, snapshotName string, opts map[string]string) (*storage.SnapshotConfig, error) {
+
+	unknown := unsupportedParameters(opts, func(k string) bool { return supportedSnapshotParameters[k] })
+	if len(unknown) > 0 {
+		return nil, &InvalidParameterError{
+			fmt.Sprintf("unsupported snapshot parameter(s): %s", strings.Join(unknown, ", ")),
+		}
+	}
+
+	snapshotReserve := opts[SnapshotReserve]
+	if snapshotReserve != "" {
+		percent, err := strconv.Atoi(snapshotReserve)
+		if err != nil || percent < 0 || percent > storage.MaxSnapshotReserve {
+			return nil, &InvalidParameterError{fmt.Sprintf("invalid snapshot parameter %s=%s; expected "+
+				"a percentage from 0 to %d", SnapshotReserve, snapshotReserve, storage.MaxSnapshotReserve)}
+		}
+	}
+
+	requestedSize := opts[SnapshotSize]
+	if requestedSize != "" {
+		sizeBytes, err := utils.ConvertSizeToBytes(requestedSize)
+		if err == nil {
+			_, err = strconv.ParseUint(sizeBytes, 10, 64)
+		}
+		if err != nil {
+			return nil, &InvalidParameterError{
+				fmt.Sprintf("invalid snapshot parameter %s=%s; %v", SnapshotSize, requestedSize, err),
+			}
+		}
+		requestedSize = sizeBytes
+	}
+
+	return &storage.SnapshotConfig{
+		Version:            config.OrchestratorAPIVersion,
+		Name:               snapshotName,
+		VolumeName:         volumeName,
+		ImportOriginalName: opts[SnapshotImportOriginalName],
+		SnapshotReserve:    snapshotReserve,
+		RequestedSize:      requestedSize,
+	}, nil
+}
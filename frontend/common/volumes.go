@@ -4,6 +4,8 @@ package common
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	hash "github.com/mitchellh/hashstructure"
 	log "github.com/sirupsen/logrus"
@@ -18,8 +20,87 @@ import (
 
 const (
 	autoStorageClassPrefix = "auto_sc_%d"
+
+	// PassthroughParameterPrefix marks volume parameters that Trident accepts without recognizing them,
+	// so that storage classes may carry options intended for other or newer consumers.
+	PassthroughParameterPrefix = "passthrough/"
+
+	// VolumeNameTemplate is the volume parameter holding a template for a volume's name on its
+	// storage system.  The template may contain the tokens below.
+	VolumeNameTemplate = "nameTemplate"
+
+	// VolumeDryRun is the volume parameter that, when true, asks for the backend and storage pool a
+	// volume would be created in without creating it.
+	VolumeDryRun = "dryRun"
+
+	// VolumeDeletionProtection is the volume parameter that, when true, creates a volume that
+	// Trident refuses to delete until its deletion protection is cleared.
+	VolumeDeletionProtection = "deletionProtection"
+
+	// VolumeFSGroupChangePolicy is the volume parameter recording whether a pod's fsGroup ownership
+	// is meant to always be applied to a volume, or only if the volume's root doesn't already match.
+	// It is passed to the node plugin, but ownership changes are made by the kubelet.  Its values
+	// mirror the Kubernetes pod-level fsGroupChangePolicy.
+	VolumeFSGroupChangePolicy = "fsGroupChangePolicy"
+
+	FSGroupChangePolicyAlways         = "Always"
+	FSGroupChangePolicyOnRootMismatch = "OnRootMismatch"
+
+	NameTemplateTokenPVC       = "{pvc}"
+	NameTemplateTokenNamespace = "{namespace}"
+	NameTemplateTokenUID       = "{uid}"
 )
 
+var nameTemplateTokenRegex = regexp.MustCompile(`\{[^{}]*\}`)
+
+// supportedVolumeParameters lists the volume creation parameters understood by Trident, other
+// than storage attributes.
+var supportedVolumeParameters = map[string]bool{
+	sa.StoragePools:             true,
+	sa.AdditionalStoragePools:   true,
+	sa.ExcludeStoragePools:      true,
+	sa.RequiredStorage:          true,
+	"fsType":                    true,
+	"fstype":                    true,
+	"fileSystemType":            true,
+	"spaceReserve":              true,
+	"securityStyle":             true,
+	"splitOnClone":              true,
+	"snapshotPolicy":            true,
+	"snapshotReserve":           true,
+	"snapshotDir":               true,
+	"exportPolicy":              true,
+	"unixPermissions":           true,
+	"blocksize":                 true,
+	"qos":                       true,
+	"type":                      true,
+	"from":                      true,
+	"fromSnapshot":              true,
+	"serviceLevel":              true,
+	VolumeNameTemplate:          true,
+	VolumeDryRun:                true,
+	VolumeDeletionProtection:    true,
+	VolumeFSGroupChangePolicy:   true,
+	"snapshotRetentionMaxAge":   true,
+	"snapshotRetentionMaxCount": true,
+}
+
+// ValidateVolumeParameters returns an InvalidParameterError naming any volume creation parameters
+// Trident does not recognize, so that a mistyped parameter doesn't silently yield an unexpected volume.
+func ValidateVolumeParameters(opts map[string]string) error {
+
+	unknown := unsupportedParameters(opts, func(k string) bool {
+		return supportedVolumeParameters[k] || sa.IsStorageAttribute(k) ||
+			strings.HasPrefix(k, PassthroughParameterPrefix)
+	})
+	if len(unknown) > 0 {
+		return &InvalidParameterError{
+			fmt.Sprintf("unsupported volume parameter(s): %s", strings.Join(unknown, ", ")),
+		}
+	}
+	return nil
+}
+
 // getStorageClass accepts a list of volume creation options and returns a
 // matching storage class.  If the orchestrator already has a matching
 // storage class, that is returned; otherwise a new one is created and
@@ -140,3 +221,46 @@ func GetVolumeConfig(
 		ServiceLevel:        utils.GetV(opts, "serviceLevel", ""),
 	}, nil
 }
+
+// ValidateFSGroupChangePolicy returns an InvalidParameterError if the policy is neither empty nor
+// one of the supported fsGroupChangePolicy values.
+func ValidateFSGroupChangePolicy(policy string) error {
+	switch policy {
+	case "", FSGroupChangePolicyAlways, FSGroupChangePolicyOnRootMismatch:
+		return nil
+	default:
+		return &InvalidParameterError{
+			fmt.Sprintf("%s must be %s or %s, not %s", VolumeFSGroupChangePolicy,
+				FSGroupChangePolicyAlways, FSGroupChangePolicyOnRootMismatch, policy),
+		}
+	}
+}
+
+// ExpandVolumeNameTemplate substitutes the values of a volume's tokens into a name template.  A
+// template must contain {uid}, or both {namespace} and {pvc}, so that no two volumes can expand
+// to the same name.  Templates that could collide or contain unknown tokens are rejected with
+// an InvalidParameterError.
+func ExpandVolumeNameTemplate(template string, values map[string]string) (string, error) {
+
+	for _, token := range nameTemplateTokenRegex.FindAllString(template, -1) {
+		if _, ok := values[token]; !ok {
+			return "", &InvalidParameterError{
+				fmt.Sprintf("unknown token %s in volume name template %s", token, template),
+			}
+		}
+	}
+
+	if !strings.Contains(template, NameTemplateTokenUID) &&
+		!(strings.Contains(template, NameTemplateTokenNamespace) && strings.Contains(template, NameTemplateTokenPVC)) {
+		return "", &InvalidParameterError{
+			fmt.Sprintf("volume name template %s must contain %s, or both %s and %s", template,
+				NameTemplateTokenUID, NameTemplateTokenNamespace, NameTemplateTokenPVC),
+		}
+	}
+
+	name := template
+	for token, value := range values {
+		name = strings.Replace(name, token, value, -1)
+	}
+	return name, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package common
+
+import (
+	"testing"
+)
+
+func TestValidateVolumeParameters(t *testing.T) {
+
+	// Known volume parameters, storage attributes, and orchestrator parameters are accepted
+	valid := map[string]string{
+		"fsType":       "ext4",
+		"snapshotDir":  "true",
+		"exportPolicy": "default",
+		"backendType":  "ontap-nas",
+		"snapshots":    "true",
+		"IOPS":         "1000",
+		"storagePools": "backend1:pool1",
+		"csi.storage.k8s.io/provisioner-secret-name": "secret",
+		"snapshotRetentionMaxAge":                    "720h",
+		"snapshotRetentionMaxCount":                  "10",
+	}
+	if err := ValidateVolumeParameters(valid); err != nil {
+		t.Errorf("Expected valid parameters to be accepted, got %v", err)
+	}
+
+	// Unknown parameters are rejected by name
+	err := ValidateVolumeParameters(map[string]string{"snapshotDir": "true", "snapshotDirectory": "true"})
+	if !IsInvalidParameterError(err) {
+		t.Fatalf("Expected InvalidParameterError for unknown parameter, got %v", err)
+	}
+	if err.Error() != "unsupported volume parameter(s): snapshotDirectory" {
+		t.Errorf("Expected only the unknown key in error, got %v", err)
+	}
+
+	// Passthrough parameters are accepted without being recognized
+	passthrough := map[string]string{PassthroughParameterPrefix + "futureOption": "value"}
+	if err := ValidateVolumeParameters(passthrough); err != nil {
+		t.Errorf("Expected passthrough parameter to be accepted, got %v", err)
+	}
+}
+
+func TestExpandVolumeNameTemplate(t *testing.T) {
+
+	values := map[string]string{
+		NameTemplateTokenPVC:       "data",
+		NameTemplateTokenNamespace: "team-a",
+		NameTemplateTokenUID:       "1234-5678",
+	}
+
+	tests := []struct {
+		template string
+		expected string
+		valid    bool
+	}{
+		{"{namespace}-{pvc}", "team-a-data", true},
+		{"prod-{uid}", "prod-1234-5678", true},
+		{"{namespace}_{pvc}_{uid}", "team-a_data_1234-5678", true},
+		{"{pvc}", "", false},
+		{"{namespace}", "", false},
+		{"static", "", false},
+		{"{namespace}-{pvc}-{cluster}", "", false},
+	}
+
+	for _, test := range tests {
+		name, err := ExpandVolumeNameTemplate(test.template, values)
+		if !test.valid {
+			if !IsInvalidParameterError(err) {
+				t.Errorf("Expected InvalidParameterError for template %s, got %v", test.template, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error expanding template %s: %v", test.template, err)
+		} else if name != test.expected {
+			t.Errorf("Expected template %s to expand to %s, got %s", test.template, test.expected, name)
+		}
+	}
+}
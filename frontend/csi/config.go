@@ -2,8 +2,26 @@
 
 package csi
 
+import "time"
+
 const (
 	Version           = "1.1"
 	Provisioner       = "csi.trident.netapp.io"
 	LegacyProvisioner = "netapp.io/trident"
+
+	// ForceDeleteSecret is the DeleteVolume secret that, when "true", removes a volume from
+	// Trident even if its backend fails to delete it.  As a DeleteSnapshot secret, it deletes a
+	// snapshot even if volumes were cloned from it.
+	ForceDeleteSecret = "force"
+
+	// ShutdownTimeout bounds how long deactivating the CSI frontend waits for in-flight operations.
+	// The installer gives the CSI pods a longer termination grace period, so keep them in step.
+	ShutdownTimeout = 60 * time.Second
+
+	// PublishRetryTimeout bounds how long ControllerPublishVolume retries transient backend failures
+	// while updating export rules or igroups, if the request deadline doesn't end the retries first.
+	PublishRetryTimeout = 30 * time.Second
 )
+
+// publishRetryInterval is the initial wait between attempts to publish a volume on its backend.
+var publishRetryInterval = 1 * time.Second
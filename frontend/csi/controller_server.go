@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cenkalti/backoff"
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	log "github.com/sirupsen/logrus"
@@ -18,6 +19,7 @@ import (
 
 	tridentconfig "github.com/netapp/trident/config"
 	"github.com/netapp/trident/core"
+	frontendcommon "github.com/netapp/trident/frontend/common"
 	"github.com/netapp/trident/frontend/csi/helpers"
 	"github.com/netapp/trident/storage"
 	"github.com/netapp/trident/utils"
@@ -27,17 +29,16 @@ func (p *Plugin) CreateVolume(
 	ctx context.Context, req *csi.CreateVolumeRequest,
 ) (*csi.CreateVolumeResponse, error) {
 
-	fields := log.Fields{"Method": "CreateVolume", "Type": "CSI_Controller", "name": req.Name}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "CreateVolume", "Type": "CSI_Controller", "name": req.Name, "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> CreateVolume")
 	defer log.WithFields(fields).Debug("<<<< CreateVolume")
 
-	if _, ok := p.opCache[req.Name]; ok {
-		log.WithFields(fields).Debug("Create already in progress, returning DeadlineExceeded.")
-		return nil, status.Error(codes.DeadlineExceeded, "create already in progress")
-	} else {
-		p.opCache[req.Name] = true
-		defer delete(p.opCache, req.Name)
+	if err := p.startOperation(req.Name); err != nil {
+		log.WithFields(fields).Debugf("Could not start create; %v", err)
+		return nil, err
 	}
+	defer p.finishOperation(req.Name)
 
 	// Check arguments
 	if len(req.GetName()) == 0 {
@@ -46,6 +47,29 @@ func (p *Plugin) CreateVolume(
 	if req.GetVolumeCapabilities() == nil {
 		return nil, status.Error(codes.InvalidArgument, "volume capabilities missing in request")
 	}
+	if err := frontendcommon.ValidateVolumeParameters(req.GetParameters()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	dryRun := false
+	if value, ok := req.GetParameters()[frontendcommon.VolumeDryRun]; ok {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			return nil, status.Error(codes.InvalidArgument,
+				fmt.Sprintf("invalid %s parameter %s", frontendcommon.VolumeDryRun, value))
+		}
+	}
+	deletionProtection := false
+	if value, ok := req.GetParameters()[frontendcommon.VolumeDeletionProtection]; ok {
+		var err error
+		if deletionProtection, err = strconv.ParseBool(value); err != nil {
+			return nil, status.Error(codes.InvalidArgument,
+				fmt.Sprintf("invalid %s parameter %s", frontendcommon.VolumeDeletionProtection, value))
+		}
+	}
+	fsGroupChangePolicy := req.GetParameters()[frontendcommon.VolumeFSGroupChangePolicy]
+	if err := frontendcommon.ValidateFSGroupChangePolicy(fsGroupChangePolicy); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
 	// Check for pre-existing volume with the same name
 	existingVolume, err := p.orchestrator.GetVolume(req.Name)
@@ -74,7 +98,7 @@ func (p *Plugin) CreateVolume(
 	}
 
 	// Check for matching volume capabilities
-	log.Debugf("Volume capabilities (%d): %v", len(req.GetVolumeCapabilities()), req.GetVolumeCapabilities())
+	log.WithFields(fields).Debugf("Volume capabilities (%d): %v", len(req.GetVolumeCapabilities()), req.GetVolumeCapabilities())
 	protocol := tridentconfig.ProtocolAny
 	accessMode := tridentconfig.ModeAny
 	fsType := ""
@@ -91,8 +115,8 @@ func (p *Plugin) CreateVolume(
 			// See if we have a backend for the specified access mode
 			accessMode = p.getAccessForCSIAccessMode(capability.GetAccessMode().Mode)
 			protocol = p.getProtocolForCSIAccessMode(capability.GetAccessMode().Mode)
-			if !p.hasBackendForProtocol(protocol) {
-				return nil, status.Error(codes.InvalidArgument, "no available storage for access mode")
+			if err := p.checkBackendForAccessMode(accessMode, protocol); err != nil {
+				return nil, err
 			}
 
 			// See if fsType was specified
@@ -109,13 +133,26 @@ func (p *Plugin) CreateVolume(
 	}
 
 	// Convert volume creation options into a Trident volume config
-	volConfig, err := p.helper.GetVolumeConfig(req.Name, sizeBytes, req.Parameters, protocol, accessMode, fsType)
+	volConfig, err := p.helper.GetVolumeConfig(ctx, req.Name, sizeBytes, req.Parameters, protocol, accessMode, fsType)
 	if err != nil {
-		p.helper.RecordVolumeEvent(req.Name, helpers.EventTypeNormal, "ProvisioningFailed", err.Error())
+		if !dryRun {
+			p.recordVolumeEvent(ctx, req.Name, helpers.EventTypeNormal, "ProvisioningFailed", err.Error())
+		}
 		return nil, p.getCSIErrorForOrchestratorError(err)
 	}
 
-	// Check if CSI asked for a clone (overrides trident.netapp.io/cloneFromPVC PVC annotation, if present)
+	// The volume config may override the protocol, so check the resolved values as well
+	if err = p.checkBackendForAccessMode(volConfig.AccessMode, volConfig.Protocol); err != nil {
+		if !dryRun {
+			p.recordVolumeEvent(ctx, req.Name, helpers.EventTypeNormal, "ProvisioningFailed", err.Error())
+		}
+		return nil, err
+	}
+	volConfig.FSGroupChangePolicy = fsGroupChangePolicy
+	volConfig.DeletionProtection = deletionProtection
+
+	// Check if CSI asked for a clone (overrides the trident.netapp.io/cloneFromPVC and cloneFromSnapshot
+	// PVC annotations, if present)
 	if req.VolumeContentSource != nil {
 		switch contentSource := req.VolumeContentSource.Type.(type) {
 
@@ -125,6 +162,7 @@ func (p *Plugin) CreateVolume(
 				return nil, status.Error(codes.InvalidArgument, "content source volume ID missing in request")
 			}
 			volConfig.CloneSourceVolume = volumeID
+			volConfig.CloneSourceSnapshot = ""
 
 		case *csi.VolumeContentSource_Snapshot:
 			snapshotID := contentSource.Snapshot.SnapshotId
@@ -135,6 +173,7 @@ func (p *Plugin) CreateVolume(
 				log.WithFields(log.Fields{
 					"volumeName": req.Name,
 					"snapshotID": contentSource.Snapshot.SnapshotId,
+					"requestID":  GetRequestID(ctx),
 				}).Error("Cannot create clone, invalid snapshot ID.")
 				return nil, status.Error(codes.InvalidArgument, "invalid snapshot ID")
 			} else {
@@ -144,6 +183,14 @@ func (p *Plugin) CreateVolume(
 		}
 	}
 
+	// A dry run reports where the volume would land without creating it
+	if dryRun {
+		if volConfig.CloneSourceVolume != "" {
+			return nil, status.Error(codes.InvalidArgument, "dry run is not supported when cloning a volume")
+		}
+		return p.planVolume(ctx, volConfig)
+	}
+
 	// Invoke the orchestrator to create or clone the new volume
 	var newVolume *storage.VolumeExternal
 	if volConfig.CloneSourceVolume == "" {
@@ -153,10 +200,10 @@ func (p *Plugin) CreateVolume(
 	}
 
 	if err != nil {
-		p.helper.RecordVolumeEvent(req.Name, helpers.EventTypeNormal, "ProvisioningFailed", err.Error())
+		p.recordVolumeEvent(ctx, req.Name, helpers.EventTypeNormal, "ProvisioningFailed", err.Error())
 		return nil, p.getCSIErrorForOrchestratorError(err)
 	} else {
-		p.helper.RecordVolumeEvent(req.Name, v1.EventTypeNormal, "ProvisioningSuccess", "provisioned a volume")
+		p.recordVolumeEvent(ctx, req.Name, v1.EventTypeNormal, "ProvisioningSuccess", "provisioned a volume")
 	}
 
 	csiVolume, err := p.getCSIVolumeFromTridentVolume(newVolume)
@@ -167,11 +214,44 @@ func (p *Plugin) CreateVolume(
 	return &csi.CreateVolumeResponse{Volume: csiVolume}, nil
 }
 
+// planVolume answers a dry-run CreateVolume request.  No volume is created, so the request fails
+// with FailedPrecondition, and the error message describes where the volume would be created.
+// Container orchestrators report that message against the volume claim, and the claim stays
+// unbound instead of referring to a volume that doesn't exist.
+func (p *Plugin) planVolume(
+	ctx context.Context, volConfig *storage.VolumeConfig,
+) (*csi.CreateVolumeResponse, error) {
+
+	placement, err := p.orchestrator.PlanVolume(volConfig)
+	if err != nil {
+		return nil, p.getCSIErrorForOrchestratorError(err)
+	}
+
+	log.WithFields(log.Fields{
+		"volume":          volConfig.Name,
+		"backend":         placement.Backend,
+		"pool":            placement.Pool,
+		"internalName":    placement.InternalName,
+		"capacityChecked": placement.CapacityChecked,
+		"requestID":       GetRequestID(ctx),
+	}).Info("Planned volume for a dry run.")
+
+	capacity := "fits in the storage pool"
+	if !placement.CapacityChecked {
+		capacity = "was not checked against the storage pool's capacity"
+	}
+
+	return nil, status.Errorf(codes.FailedPrecondition,
+		"dry run: volume %s would be created as %s volume %s in storage pool %s of backend %s, and its size %s",
+		volConfig.Name, placement.Protocol, placement.InternalName, placement.Pool, placement.Backend, capacity)
+}
+
 func (p *Plugin) DeleteVolume(
 	ctx context.Context, req *csi.DeleteVolumeRequest,
 ) (*csi.DeleteVolumeResponse, error) {
 
-	fields := log.Fields{"Method": "DeleteVolume", "Type": "CSI_Controller"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "DeleteVolume", "Type": "CSI_Controller", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> DeleteVolume")
 	defer log.WithFields(fields).Debug("<<<< DeleteVolume")
 
@@ -179,11 +259,18 @@ func (p *Plugin) DeleteVolume(
 		return nil, status.Error(codes.InvalidArgument, "no volume ID provided")
 	}
 
-	if err := p.orchestrator.DeleteVolume(req.VolumeId); err != nil {
+	deleteVolume := p.orchestrator.DeleteVolume
+	if force, _ := strconv.ParseBool(req.GetSecrets()[ForceDeleteSecret]); force {
+		log.WithFields(fields).Warningf("Force deleting volume %s.", req.VolumeId)
+		deleteVolume = p.orchestrator.ForceDeleteVolume
+	}
+
+	if err := deleteVolume(req.VolumeId); err != nil {
 
 		log.WithFields(log.Fields{
 			"volumeName": req.VolumeId,
 			"error":      err,
+			"requestID":  GetRequestID(ctx),
 		}).Debugf("Could not delete volume.")
 
 		// In CSI, delete is idempotent, so don't return an error if the volume doesn't exist
@@ -210,7 +297,8 @@ func (p *Plugin) ControllerPublishVolume(
 	ctx context.Context, req *csi.ControllerPublishVolumeRequest,
 ) (*csi.ControllerPublishVolumeResponse, error) {
 
-	fields := log.Fields{"Method": "ControllerPublishVolume", "Type": "CSI_Controller"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "ControllerPublishVolume", "Type": "CSI_Controller", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> ControllerPublishVolume")
 	defer log.WithFields(fields).Debug("<<<< ControllerPublishVolume")
 
@@ -237,7 +325,7 @@ func (p *Plugin) ControllerPublishVolume(
 	// Get node attributes from the node ID
 	nodeInfo, err := p.orchestrator.GetNode(nodeID)
 	if err != nil {
-		log.WithField("node", nodeID).Error("Node info not found.")
+		log.WithFields(log.Fields{"node": nodeID, "requestID": GetRequestID(ctx)}).Error("Node info not found.")
 		return nil, status.Error(codes.NotFound, err.Error())
 	}
 
@@ -250,14 +338,19 @@ func (p *Plugin) ControllerPublishVolume(
 	}
 
 	// Update NFS export rules (?), add node IQN to igroup, etc.
-	err = p.orchestrator.PublishVolume(volume.Config.Name, volumePublishInfo)
+	err = p.publishVolumeWithRetry(ctx, volume.Config.Name, volumePublishInfo)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	// Explicit mount options always override the backend's mount options, and the plugin's default
+	// NFS mount options apply only if the backend sets none
 	mount := req.VolumeCapability.GetMount()
-	if len(mount.MountFlags) > 0 {
-		volumePublishInfo.MountOptions = strings.Join(mount.MountFlags, ",")
+	if len(mount.GetMountFlags()) > 0 {
+		volumePublishInfo.MountOptions = strings.Join(mount.GetMountFlags(), ",")
+	} else if volume.Config.Protocol == tridentconfig.File && volumePublishInfo.MountOptions == "" &&
+		p.nfsMountOptions != "" {
+		volumePublishInfo.MountOptions = p.nfsMountOptions
 	}
 
 	// Build CSI controller publish info from volume publish info
@@ -266,9 +359,17 @@ func (p *Plugin) ControllerPublishVolume(
 	}
 
 	publishInfo["mountOptions"] = volumePublishInfo.MountOptions
+
+	// Preserve any read-only intent, so the node plugin mounts the volume read-only
+	readOnly := req.GetReadonly() || isReadOnlyCSIAccessMode(req.VolumeCapability.GetAccessMode().GetMode())
+	publishInfo["readOnly"] = strconv.FormatBool(readOnly)
+
 	if volume.Config.Protocol == tridentconfig.File {
 		publishInfo["nfsServerIp"] = volume.Config.AccessInfo.NfsServerIP
 		publishInfo["nfsPath"] = volume.Config.AccessInfo.NfsPath
+		if volume.Config.FSGroupChangePolicy != "" {
+			publishInfo["fsGroupChangePolicy"] = volume.Config.FSGroupChangePolicy
+		}
 	} else if volume.Config.Protocol == tridentconfig.Block {
 		stashIscsiTargetPortals(publishInfo, volume.Config.AccessInfo)
 		publishInfo["iscsiTargetIqn"] = volume.Config.AccessInfo.IscsiTargetIQN
@@ -286,11 +387,43 @@ func (p *Plugin) ControllerPublishVolume(
 	return &csi.ControllerPublishVolumeResponse{PublishContext: publishInfo}, nil
 }
 
+// publishVolumeWithRetry publishes a volume on its backend, retrying with an exponential backoff so
+// that a transient failure updating export rules or igroups doesn't fail the whole attach.  Publishing
+// is idempotent, so a retry is safe.  Errors that retrying can't fix are returned immediately.
+func (p *Plugin) publishVolumeWithRetry(
+	ctx context.Context, volumeName string, publishInfo *utils.VolumePublishInfo,
+) error {
+
+	publish := func() error {
+		err := p.orchestrator.PublishVolume(volumeName, publishInfo)
+		if core.IsNotReadyError(err) || core.IsBootstrapError(err) || core.IsNotFoundError(err) ||
+			core.IsVolumeDeletingError(err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+	publishNotify := func(err error, duration time.Duration) {
+		log.WithFields(log.Fields{
+			"volume":    volumeName,
+			"increment": duration,
+			"requestID": GetRequestID(ctx),
+		}).Warningf("Could not publish volume, will retry; %v", err)
+	}
+	publishBackoff := backoff.NewExponentialBackOff()
+	publishBackoff.InitialInterval = publishRetryInterval
+	publishBackoff.Multiplier = 2
+	publishBackoff.RandomizationFactor = 0.1
+	publishBackoff.MaxElapsedTime = PublishRetryTimeout
+
+	return backoff.RetryNotify(publish, backoff.WithContext(publishBackoff, ctx), publishNotify)
+}
+
 func (p *Plugin) ControllerUnpublishVolume(
 	ctx context.Context, req *csi.ControllerUnpublishVolumeRequest,
 ) (*csi.ControllerUnpublishVolumeResponse, error) {
 
-	fields := log.Fields{"Method": "ControllerUnpublishVolume", "Type": "CSI_Controller"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "ControllerUnpublishVolume", "Type": "CSI_Controller", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> ControllerUnpublishVolume")
 	defer log.WithFields(fields).Debug("<<<< ControllerUnpublishVolume")
 
@@ -304,7 +437,12 @@ func (p *Plugin) ControllerUnpublishVolume(
 		return nil, p.getCSIErrorForOrchestratorError(err)
 	}
 
-	// Apart from validation, Trident has nothing to do for this entry point
+	// Apart from validation, Trident only has to forget the publication for this entry point
+	if nodeID := req.GetNodeId(); nodeID != "" {
+		if err := p.orchestrator.UnpublishVolume(volumeID, nodeID); err != nil {
+			return nil, p.getCSIErrorForOrchestratorError(err)
+		}
+	}
 	return &csi.ControllerUnpublishVolumeResponse{}, nil
 }
 
@@ -329,7 +467,7 @@ func (p *Plugin) ValidateVolumeCapabilities(
 	resp := &csi.ValidateVolumeCapabilitiesResponse{}
 
 	for _, v := range req.GetVolumeCapabilities() {
-		if volume.Config.AccessMode != p.getAccessForCSIAccessMode(v.GetAccessMode().Mode) {
+		if !volumeAccessModeAllows(volume.Config.AccessMode, p.getAccessForCSIAccessMode(v.GetAccessMode().GetMode())) {
 			resp.Message = "Could not satisfy one or more access modes."
 			return resp, nil
 		}
@@ -346,6 +484,11 @@ func (p *Plugin) ValidateVolumeCapabilities(
 		}
 	}
 
+	if message := p.validateVolumeParameters(volume, req); message != "" {
+		resp.Message = message
+		return resp, nil
+	}
+
 	confirmed := &csi.ValidateVolumeCapabilitiesResponse_Confirmed{}
 	confirmed.VolumeCapabilities = req.GetVolumeCapabilities()
 
@@ -354,28 +497,86 @@ func (p *Plugin) ValidateVolumeCapabilities(
 	return resp, nil
 }
 
+// validateVolumeParameters checks the file system type, parameters and volume context of a
+// ValidateVolumeCapabilities request against how the volume was provisioned.  It returns a
+// message describing the first mismatch, or an empty string if there is none.
+func (p *Plugin) validateVolumeParameters(
+	volume *storage.VolumeExternal, req *csi.ValidateVolumeCapabilitiesRequest,
+) string {
+
+	fsTypeMatches := func(fsType string) bool {
+		return fsType == "" || volume.Config.FileSystem == "" || strings.EqualFold(fsType, volume.Config.FileSystem)
+	}
+
+	for _, v := range req.GetVolumeCapabilities() {
+		if mount := v.GetMount(); mount != nil && !fsTypeMatches(mount.GetFsType()) {
+			return fmt.Sprintf("Could not satisfy file system type %s; volume has %s.",
+				mount.GetFsType(), volume.Config.FileSystem)
+		}
+	}
+
+	for _, key := range []string{"fsType", "fstype", "fileSystemType"} {
+		if fsType := req.GetParameters()[key]; !fsTypeMatches(fsType) {
+			return fmt.Sprintf("Could not satisfy parameter %s=%s; volume has file system type %s.",
+				key, fsType, volume.Config.FileSystem)
+		}
+	}
+
+	// Only the context Trident itself reports for the volume is compared, since the CO may add its own keys
+	csiVolume, err := p.getCSIVolumeFromTridentVolume(volume)
+	if err != nil {
+		return "Could not determine the volume context."
+	}
+	for key, value := range req.GetVolumeContext() {
+		if expected, ok := csiVolume.VolumeContext[key]; ok && expected != value {
+			return fmt.Sprintf("Could not satisfy volume context %s=%s; volume has %s.", key, value, expected)
+		}
+	}
+
+	return ""
+}
+
+// listVolumesPageSize is the most volumes ListVolumes returns when the CO doesn't limit the
+// number of entries.
+const listVolumesPageSize = 500
+
 func (p *Plugin) ListVolumes(
 	ctx context.Context, req *csi.ListVolumesRequest,
 ) (*csi.ListVolumesResponse, error) {
 
-	fields := log.Fields{"Method": "ListVolumes", "Type": "CSI_Controller"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "ListVolumes", "Type": "CSI_Controller", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> ListVolumes")
 	defer log.WithFields(fields).Debug("<<<< ListVolumes")
 
-	volumes, err := p.orchestrator.ListVolumes()
+	if req.GetMaxEntries() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max entries may not be negative")
+	}
+
+	// A CO that doesn't limit the entries still gets a bounded page, and follows the next token
+	// for the rest, as the CSI spec allows
+	maxEntries := int(req.GetMaxEntries())
+	if maxEntries == 0 {
+		maxEntries = listVolumesPageSize
+	}
+
+	token := req.GetStartingToken()
+	volumes, nextToken, err := p.orchestrator.ListVolumesPaged(token, maxEntries)
 	if err != nil {
+		if token != "" && !core.IsNotReadyError(err) {
+			return nil, status.Errorf(codes.Aborted, "invalid starting token %s; %v", token, err)
+		}
 		return nil, p.getCSIErrorForOrchestratorError(err)
 	}
 
 	entries := make([]*csi.ListVolumesResponse_Entry, 0)
-
 	for _, volume := range volumes {
 		if csiVolume, err := p.getCSIVolumeFromTridentVolume(volume); err == nil {
 			entries = append(entries, &csi.ListVolumesResponse_Entry{Volume: csiVolume})
 		}
 	}
 
-	return &csi.ListVolumesResponse{Entries: entries}, nil
+	return &csi.ListVolumesResponse{Entries: entries, NextToken: nextToken}, nil
 }
 
 func (p *Plugin) GetCapacity(
@@ -390,7 +591,8 @@ func (p *Plugin) ControllerGetCapabilities(
 	ctx context.Context, req *csi.ControllerGetCapabilitiesRequest,
 ) (*csi.ControllerGetCapabilitiesResponse, error) {
 
-	fields := log.Fields{"Method": "ControllerGetCapabilities", "Type": "CSI_Controller"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "ControllerGetCapabilities", "Type": "CSI_Controller", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> ControllerGetCapabilities")
 	defer log.WithFields(fields).Debug("<<<< ControllerGetCapabilities")
 
@@ -401,7 +603,8 @@ func (p *Plugin) CreateSnapshot(
 	ctx context.Context, req *csi.CreateSnapshotRequest,
 ) (*csi.CreateSnapshotResponse, error) {
 
-	fields := log.Fields{"Method": "CreateSnapshot", "Type": "CSI_Controller"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "CreateSnapshot", "Type": "CSI_Controller", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> CreateSnapshot")
 	defer log.WithFields(fields).Debug("<<<< CreateSnapshot")
 
@@ -434,27 +637,38 @@ func (p *Plugin) CreateSnapshot(
 	if existingSnapshots, err := p.orchestrator.ListSnapshotsByName(snapshotName); err != nil {
 		return nil, p.getCSIErrorForOrchestratorError(err)
 	} else if len(existingSnapshots) > 0 {
+		volumeNames := make([]string, 0, len(existingSnapshots))
 		for _, s := range existingSnapshots {
-			log.Errorf("Found existing snapshot %s in another volume %s.", s.Config.Name, s.Config.VolumeName)
+			log.WithFields(fields).Debugf("Found existing snapshot %s in another volume %s.", s.Config.Name, s.Config.VolumeName)
+			volumeNames = append(volumeNames, s.Config.VolumeName)
 		}
 		// We already handled the same name / same volume case, so getting here has to mean a different volume
-		return nil, status.Error(codes.AlreadyExists, "snapshot exists on a different volume")
+		return nil, status.Errorf(codes.AlreadyExists, "snapshot %s exists on a different volume %s",
+			snapshotName, strings.Join(volumeNames, ","))
 	} else {
-		log.Debugf("Found no existing snapshot %s in other volumes.", snapshotName)
+		log.WithFields(fields).Debugf("Found no existing snapshot %s in other volumes.", snapshotName)
 	}
 
 	// Convert snapshot creation options into a Trident snapshot config
-	snapshotConfig, err := p.helper.GetSnapshotConfig(volumeName, snapshotName)
+	snapshotConfig, err := p.helper.GetSnapshotConfig(volumeName, snapshotName, req.GetParameters())
 	if err != nil {
-		p.helper.RecordVolumeEvent(req.Name, helpers.EventTypeNormal, "ProvisioningFailed", err.Error())
+		p.recordVolumeEvent(ctx, req.Name, helpers.EventTypeNormal, "ProvisioningFailed", err.Error())
+		if frontendcommon.IsInvalidParameterError(err) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, p.getCSIErrorForOrchestratorError(err)
 	}
 
+	// The snapshot backs a container orchestrator object, so only the orchestrator may delete it
+	snapshotConfig.CSIManaged = true
+
 	// Create the snapshot
 	newSnapshot, err := p.orchestrator.CreateSnapshot(snapshotConfig)
 	if err != nil {
 		if core.IsNotFoundError(err) {
 			return nil, status.Error(codes.NotFound, err.Error())
+		} else if core.IsSnapshotLimitError(err) {
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
 		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -470,7 +684,8 @@ func (p *Plugin) DeleteSnapshot(
 	ctx context.Context, req *csi.DeleteSnapshotRequest,
 ) (*csi.DeleteSnapshotResponse, error) {
 
-	fields := log.Fields{"Method": "DeleteSnapshot", "Type": "CSI_Controller"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "DeleteSnapshot", "Type": "CSI_Controller", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> DeleteSnapshot")
 	defer log.WithFields(fields).Debug("<<<< DeleteSnapshot")
 
@@ -482,17 +697,24 @@ func (p *Plugin) DeleteSnapshot(
 	volumeName, snapshotName, err := storage.ParseSnapshotID(snapshotID)
 	if err != nil {
 		// An invalid ID is treated an a non-existent snapshot, so we log the error and return success
-		log.Error(err)
+		log.WithFields(fields).Error(err)
 		return &csi.DeleteSnapshotResponse{}, nil
 	}
 
+	deleteSnapshot := p.orchestrator.DeleteSnapshot
+	if force, _ := strconv.ParseBool(req.GetSecrets()[ForceDeleteSecret]); force {
+		log.WithFields(fields).Warningf("Force deleting snapshot %s.", snapshotID)
+		deleteSnapshot = p.orchestrator.ForceDeleteSnapshot
+	}
+
 	// Delete the snapshot
-	if err = p.orchestrator.DeleteSnapshot(volumeName, snapshotName); err != nil {
+	if err = deleteSnapshot(volumeName, snapshotName); err != nil {
 
 		log.WithFields(log.Fields{
 			"volumeName":   volumeName,
 			"snapshotName": snapshotName,
 			"error":        err,
+			"requestID":    GetRequestID(ctx),
 		}).Debugf("Could not delete snapshot.")
 
 		// In CSI, delete is idempotent, so don't return an error if the snapshot doesn't exist
@@ -516,8 +738,51 @@ func (p *Plugin) ControllerExpandVolume(
 	ctx context.Context, in *csi.ControllerExpandVolumeRequest,
 ) (*csi.ControllerExpandVolumeResponse, error) {
 
-	// Trident doesn't support expansion via CSI
-	return nil, status.Error(codes.Unimplemented, "")
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "ControllerExpandVolume", "Type": "CSI_Controller", "requestID": GetRequestID(ctx)}
+	log.WithFields(fields).Debug(">>>> ControllerExpandVolume")
+	defer log.WithFields(fields).Debug("<<<< ControllerExpandVolume")
+
+	if in.GetVolumeId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "no volume ID provided")
+	}
+
+	volume, err := p.orchestrator.GetVolume(in.GetVolumeId())
+	if err != nil {
+		return nil, p.getCSIErrorForOrchestratorError(err)
+	}
+
+	requiredBytes := in.GetCapacityRange().GetRequiredBytes()
+	if requiredBytes <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "no required capacity provided")
+	}
+	limitBytes := in.GetCapacityRange().GetLimitBytes()
+	if limitBytes > 0 && limitBytes < requiredBytes {
+		return nil, status.Errorf(codes.OutOfRange, "required capacity %d exceeds the limit %d",
+			requiredBytes, limitBytes)
+	}
+
+	// Only NFS volumes may be expanded, since Trident has no node-side step to grow a file system
+	if volume.Config.Protocol != tridentconfig.File {
+		return nil, status.Errorf(codes.FailedPrecondition, "can't expand non-NFS volume %s",
+			volume.Config.Name)
+	}
+
+	// Volumes are never shrunk, so a volume at least as large as requested is already expanded
+	currentBytes, err := strconv.ParseInt(volume.Config.Size, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not parse size %s of volume %s; %v",
+			volume.Config.Size, volume.Config.Name, err)
+	}
+	if currentBytes >= requiredBytes {
+		return &csi.ControllerExpandVolumeResponse{CapacityBytes: currentBytes}, nil
+	}
+
+	if err = p.orchestrator.ResizeVolume(volume.Config.Name, strconv.FormatInt(requiredBytes, 10)); err != nil {
+		return nil, p.getCSIErrorForOrchestratorError(err)
+	}
+
+	return &csi.ControllerExpandVolumeResponse{CapacityBytes: requiredBytes, NodeExpansionRequired: false}, nil
 }
 
 func (p *Plugin) getCSIVolumeFromTridentVolume(volume *storage.VolumeExternal) (*csi.Volume, error) {
@@ -531,12 +796,23 @@ func (p *Plugin) getCSIVolumeFromTridentVolume(volume *storage.VolumeExternal) (
 		capacity = 0
 	}
 
+	// The volume context is visible to the CO and the node plugin, so it must never carry secrets
+	// such as CHAP credentials; those are only passed in the publish context.
 	attributes := map[string]string{
 		"backendUUID":  volume.BackendUUID,
 		"name":         volume.Config.Name,
 		"internalName": volume.Config.InternalName,
 		"protocol":     string(volume.Config.Protocol),
 	}
+	if volume.Config.FileSystem != "" {
+		attributes["fsType"] = volume.Config.FileSystem
+	}
+	if volume.Config.AccessMode != "" {
+		attributes["accessMode"] = string(volume.Config.AccessMode)
+	}
+	if volume.Config.AccessInfo.MountOptions != "" {
+		attributes["mountOptions"] = volume.Config.AccessInfo.MountOptions
+	}
 
 	return &csi.Volume{
 		CapacityBytes: capacity,
@@ -558,16 +834,32 @@ func (p *Plugin) getCSISnapshotFromTridentSnapshot(snapshot *storage.SnapshotExt
 		SnapshotId:     storage.MakeSnapshotID(snapshot.Config.VolumeName, snapshot.Config.Name),
 		SourceVolumeId: snapshot.Config.VolumeName,
 		CreationTime:   &timestamp.Timestamp{Seconds: createdSeconds.Unix()},
-		ReadyToUse:     true,
+		ReadyToUse:     snapshot.State.IsReady(),
 	}, nil
 }
 
+// CSI 1.5 split SINGLE_NODE_WRITER into single-node single- and multi-writer access modes, which
+// Kubernetes 1.22+ uses for ReadWriteOncePod and ReadWriteOnce volumes respectively once a driver
+// advertises the SINGLE_NODE_MULTI_WRITER capabilities.  The vendored CSI spec predates them, so
+// their values are defined here.
+const (
+	accessModeSingleNodeSingleWriter = csi.VolumeCapability_AccessMode_Mode(6)
+	accessModeSingleNodeMultiWriter  = csi.VolumeCapability_AccessMode_Mode(7)
+
+	controllerCapabilitySingleNodeMultiWriter = csi.ControllerServiceCapability_RPC_Type(13)
+	nodeCapabilitySingleNodeMultiWriter       = csi.NodeServiceCapability_RPC_Type(5)
+)
+
 func (p *Plugin) getAccessForCSIAccessMode(accessMode csi.VolumeCapability_AccessMode_Mode) tridentconfig.AccessMode {
 	switch accessMode {
 	case csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER:
 		return tridentconfig.ReadWriteOnce
-	case csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY:
+	case accessModeSingleNodeSingleWriter:
+		return tridentconfig.ReadWriteOncePod
+	case accessModeSingleNodeMultiWriter:
 		return tridentconfig.ReadWriteOnce
+	case csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY:
+		return tridentconfig.ReadOnlyOnce
 	case csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY:
 		return tridentconfig.ReadOnlyMany
 	case csi.VolumeCapability_AccessMode_MULTI_NODE_SINGLE_WRITER:
@@ -579,10 +871,35 @@ func (p *Plugin) getAccessForCSIAccessMode(accessMode csi.VolumeCapability_Acces
 	}
 }
 
+// volumeAccessModeAllows reports whether a volume created with one access mode may be used with
+// another.  A ReadWriteOnce volume may be further restricted to a single pod, but a ReadWriteOncePod
+// volume may never be shared among pods.
+func volumeAccessModeAllows(volumeMode, requestedMode tridentconfig.AccessMode) bool {
+	if volumeMode == tridentconfig.ReadWriteOnce && requestedMode == tridentconfig.ReadWriteOncePod {
+		return true
+	}
+	return volumeMode == requestedMode
+}
+
+// isReadOnlyCSIAccessMode reports whether a CSI access mode only permits reading.
+func isReadOnlyCSIAccessMode(accessMode csi.VolumeCapability_AccessMode_Mode) bool {
+	switch accessMode {
+	case csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY,
+		csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY:
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *Plugin) getProtocolForCSIAccessMode(accessMode csi.VolumeCapability_AccessMode_Mode) tridentconfig.Protocol {
 	switch accessMode {
 	case csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER: // block or file OK
 		return tridentconfig.ProtocolAny
+	case accessModeSingleNodeSingleWriter: // block or file OK
+		return tridentconfig.ProtocolAny
+	case accessModeSingleNodeMultiWriter: // block or file OK
+		return tridentconfig.ProtocolAny
 	case csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY: // block or file OK
 		return tridentconfig.ProtocolAny
 	case csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY: // block or file OK
@@ -596,6 +913,31 @@ func (p *Plugin) getProtocolForCSIAccessMode(accessMode csi.VolumeCapability_Acc
 	}
 }
 
+// checkBackendForAccessMode returns an InvalidArgument error unless at least one backend can provision
+// a volume with the specified access mode and protocol.  ReadWriteMany requires file storage, no matter
+// which CSI access mode it was derived from.
+func (p *Plugin) checkBackendForAccessMode(
+	accessMode tridentconfig.AccessMode, protocol tridentconfig.Protocol,
+) error {
+
+	if accessMode == tridentconfig.ReadWriteMany {
+		if protocol == tridentconfig.Block {
+			return status.Errorf(codes.InvalidArgument, "access mode %s is not supported by protocol %s",
+				accessMode, protocol)
+		}
+		protocol = tridentconfig.File
+	}
+
+	if !p.hasBackendForProtocol(protocol) {
+		if accessMode == tridentconfig.ModeAny {
+			return status.Error(codes.InvalidArgument, "no available storage for access mode")
+		}
+		return status.Errorf(codes.InvalidArgument, "no available storage for access mode %s", accessMode)
+	}
+
+	return nil
+}
+
 func (p *Plugin) hasBackendForProtocol(protocol tridentconfig.Protocol) bool {
 
 	backends, err := p.orchestrator.ListBackends()
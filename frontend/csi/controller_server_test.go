@@ -0,0 +1,1147 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package csi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tridentconfig "github.com/netapp/trident/config"
+	"github.com/netapp/trident/core"
+	frontendcommon "github.com/netapp/trident/frontend/common"
+	"github.com/netapp/trident/storage"
+	storageclass "github.com/netapp/trident/storage_class"
+	"github.com/netapp/trident/utils"
+)
+
+// testHelper is a minimal HybridPlugin that mirrors the plain CSI helper.
+type testHelper struct{}
+
+func (h *testHelper) GetVolumeConfig(
+	ctx context.Context, name string, sizeBytes int64, parameters map[string]string,
+	protocol tridentconfig.Protocol, accessMode tridentconfig.AccessMode, fsType string,
+) (*storage.VolumeConfig, error) {
+	return &storage.VolumeConfig{Name: name}, nil
+}
+
+func (h *testHelper) GetSnapshotConfig(
+	volumeName, snapshotName string, parameters map[string]string,
+) (*storage.SnapshotConfig, error) {
+	return frontendcommon.GetSnapshotConfig(volumeName, snapshotName, parameters)
+}
+
+func (h *testHelper) RecordVolumeEvent(name, eventType, reason, message string) {}
+
+func (h *testHelper) GetNodeTopologyLabels(nodeName string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (h *testHelper) Version() string {
+	return "test"
+}
+
+func newTestControllerPlugin() *Plugin {
+	return &Plugin{
+		orchestrator: core.NewMockOrchestrator(),
+		helper:       &testHelper{},
+		opCache:      make(map[string]bool),
+	}
+}
+
+func TestCreateSnapshotNameCollisions(t *testing.T) {
+
+	p := newTestControllerPlugin()
+	ctx := context.Background()
+
+	// New name creates a snapshot
+	resp, err := p.CreateSnapshot(ctx, &csi.CreateSnapshotRequest{SourceVolumeId: "vol1", Name: "snap1"})
+	if err != nil {
+		t.Fatalf("Unexpected error creating snapshot: %v", err)
+	}
+	expectedID := storage.MakeSnapshotID("vol1", "snap1")
+	if resp.Snapshot.SnapshotId != expectedID {
+		t.Errorf("Expected snapshot ID %s, got %s", expectedID, resp.Snapshot.SnapshotId)
+	}
+	if resp.Snapshot.SourceVolumeId != "vol1" {
+		t.Errorf("Expected source volume vol1, got %s", resp.Snapshot.SourceVolumeId)
+	}
+
+	// Same name and same volume returns the existing snapshot
+	existing, err := p.CreateSnapshot(ctx, &csi.CreateSnapshotRequest{SourceVolumeId: "vol1", Name: "snap1"})
+	if err != nil {
+		t.Fatalf("Unexpected error repeating snapshot request: %v", err)
+	}
+	if existing.Snapshot.SnapshotId != resp.Snapshot.SnapshotId {
+		t.Errorf("Expected existing snapshot %s, got %s", resp.Snapshot.SnapshotId, existing.Snapshot.SnapshotId)
+	}
+	if existing.Snapshot.CreationTime.Seconds != resp.Snapshot.CreationTime.Seconds {
+		t.Error("Expected existing snapshot to keep its creation time")
+	}
+
+	// Same name on a different volume is rejected
+	_, err = p.CreateSnapshot(ctx, &csi.CreateSnapshotRequest{SourceVolumeId: "vol2", Name: "snap1"})
+	if err == nil {
+		t.Fatal("Expected error for snapshot name on a different volume")
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("Expected a gRPC status error, got %v", err)
+	}
+	if st.Code() != codes.AlreadyExists {
+		t.Errorf("Expected AlreadyExists, got %v", st.Code())
+	}
+	if !strings.Contains(st.Message(), "vol1") {
+		t.Errorf("Expected conflicting volume name in message, got %s", st.Message())
+	}
+
+	// The rejected request must not have created a snapshot
+	snapshots, err := p.orchestrator.ListSnapshotsByName("snap1")
+	if err != nil {
+		t.Fatalf("Unexpected error listing snapshots: %v", err)
+	}
+	if len(snapshots) != 1 {
+		t.Errorf("Expected 1 snapshot named snap1, got %d", len(snapshots))
+	}
+}
+
+func TestCreateSnapshotParameters(t *testing.T) {
+
+	p := newTestControllerPlugin()
+	ctx := context.Background()
+
+	// Unrecognized parameter is rejected before anything is created
+	_, err := p.CreateSnapshot(ctx, &csi.CreateSnapshotRequest{
+		SourceVolumeId: "vol1",
+		Name:           "snap1",
+		Parameters:     map[string]string{"snapshotTemplate": "daily-%d"},
+	})
+	if err == nil {
+		t.Fatal("Expected error for unrecognized snapshot parameter")
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("Expected a gRPC status error, got %v", err)
+	}
+	if st.Code() != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", st.Code())
+	}
+	if !strings.Contains(st.Message(), "snapshotTemplate") {
+		t.Errorf("Expected unrecognized key in message, got %s", st.Message())
+	}
+	if _, err = p.orchestrator.GetSnapshot("vol1", "snap1"); !core.IsNotFoundError(err) {
+		t.Errorf("Expected no snapshot to be created, got %v", err)
+	}
+
+	// Recognized parameter reaches the orchestrator
+	_, err = p.CreateSnapshot(ctx, &csi.CreateSnapshotRequest{
+		SourceVolumeId: "vol1",
+		Name:           "snap1",
+		Parameters:     map[string]string{frontendcommon.SnapshotImportOriginalName: "hourly.0"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating snapshot: %v", err)
+	}
+	snapshot, err := p.orchestrator.GetSnapshot("vol1", "snap1")
+	if err != nil {
+		t.Fatalf("Unexpected error getting snapshot: %v", err)
+	}
+	if snapshot.Config.ImportOriginalName != "hourly.0" {
+		t.Errorf("Expected importOriginalName hourly.0, got %s", snapshot.Config.ImportOriginalName)
+	}
+
+	// Snapshot space parameters reach the orchestrator, with the size in bytes
+	_, err = p.CreateSnapshot(ctx, &csi.CreateSnapshotRequest{
+		SourceVolumeId: "vol1",
+		Name:           "snap2",
+		Parameters: map[string]string{
+			frontendcommon.SnapshotReserve: "20",
+			frontendcommon.SnapshotSize:    "1Gi",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating snapshot: %v", err)
+	}
+	snapshot, err = p.orchestrator.GetSnapshot("vol1", "snap2")
+	if err != nil {
+		t.Fatalf("Unexpected error getting snapshot: %v", err)
+	}
+	if snapshot.Config.SnapshotReserve != "20" || snapshot.Config.RequestedSize != "1073741824" {
+		t.Errorf("Expected snapshot reserve 20 and size 1073741824, got %s and %s",
+			snapshot.Config.SnapshotReserve, snapshot.Config.RequestedSize)
+	}
+
+	// Invalid values of recognized parameters are rejected too
+	for key, value := range map[string]string{
+		frontendcommon.SnapshotReserve: "95",
+		frontendcommon.SnapshotSize:    "-1Gi",
+	} {
+		_, err = p.CreateSnapshot(ctx, &csi.CreateSnapshotRequest{
+			SourceVolumeId: "vol1",
+			Name:           "snap3",
+			Parameters:     map[string]string{key: value},
+		})
+		if st, ok := status.FromError(err); !ok || st.Code() != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %s=%s, got %v", key, value, err)
+		}
+	}
+}
+
+func TestGetAccessForCSIAccessMode(t *testing.T) {
+
+	p := newTestControllerPlugin()
+
+	tests := []struct {
+		mode     csi.VolumeCapability_AccessMode_Mode
+		access   tridentconfig.AccessMode
+		protocol tridentconfig.Protocol
+		readOnly bool
+	}{
+		{csi.VolumeCapability_AccessMode_UNKNOWN, tridentconfig.ModeAny, tridentconfig.ProtocolAny, false},
+		{csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER, tridentconfig.ReadWriteOnce,
+			tridentconfig.ProtocolAny, false},
+		{csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY, tridentconfig.ReadOnlyOnce,
+			tridentconfig.ProtocolAny, true},
+		{csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY, tridentconfig.ReadOnlyMany,
+			tridentconfig.ProtocolAny, true},
+		{csi.VolumeCapability_AccessMode_MULTI_NODE_SINGLE_WRITER, tridentconfig.ReadWriteMany,
+			tridentconfig.ProtocolAny, false},
+		{csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER, tridentconfig.ReadWriteMany,
+			tridentconfig.File, false},
+		{accessModeSingleNodeSingleWriter, tridentconfig.ReadWriteOncePod, tridentconfig.ProtocolAny, false},
+		{accessModeSingleNodeMultiWriter, tridentconfig.ReadWriteOnce, tridentconfig.ProtocolAny, false},
+	}
+
+	for _, test := range tests {
+		if access := p.getAccessForCSIAccessMode(test.mode); access != test.access {
+			t.Errorf("Expected %s to map to access mode %q, got %q", test.mode, test.access, access)
+		}
+		if protocol := p.getProtocolForCSIAccessMode(test.mode); protocol != test.protocol {
+			t.Errorf("Expected %s to map to protocol %q, got %q", test.mode, test.protocol, protocol)
+		}
+		if readOnly := isReadOnlyCSIAccessMode(test.mode); readOnly != test.readOnly {
+			t.Errorf("Expected %s read-only to be %v, got %v", test.mode, test.readOnly, readOnly)
+		}
+	}
+}
+
+func TestListVolumesPaging(t *testing.T) {
+
+	p := newTestControllerPlugin()
+	ctx := context.Background()
+
+	orchestrator := p.orchestrator.(*core.MockOrchestrator)
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+	expected := make([]string, 0)
+	for i := 7; i > 0; i-- {
+		name := fmt.Sprintf("vol%d", i)
+		if _, err := orchestrator.AddVolume(&storage.VolumeConfig{
+			Name:         name,
+			StorageClass: "sc",
+			Protocol:     tridentconfig.File,
+		}); err != nil {
+			t.Fatalf("Unexpected error adding volume: %v", err)
+		}
+		expected = append([]string{name}, expected...)
+	}
+
+	// Listing a page at a time returns every volume once, in order
+	listed := make([]string, 0)
+	token, pages := "", 0
+	for {
+		resp, err := p.ListVolumes(ctx, &csi.ListVolumesRequest{MaxEntries: 3, StartingToken: token})
+		if err != nil {
+			t.Fatalf("Unexpected error listing volumes: %v", err)
+		}
+		if len(resp.Entries) > 3 {
+			t.Errorf("Expected at most 3 entries, got %d", len(resp.Entries))
+		}
+		for _, entry := range resp.Entries {
+			listed = append(listed, entry.Volume.VolumeId)
+		}
+		pages++
+		if token = resp.NextToken; token == "" {
+			break
+		}
+		if pages > len(expected) {
+			t.Fatal("Listing volumes did not finish")
+		}
+	}
+	if pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", pages)
+	}
+	if !reflect.DeepEqual(listed, expected) {
+		t.Errorf("Expected volumes %v, got %v", expected, listed)
+	}
+
+	// Without a limit, a page of up to listVolumesPageSize volumes is returned
+	resp, err := p.ListVolumes(ctx, &csi.ListVolumesRequest{StartingToken: "vol3"})
+	if err != nil {
+		t.Fatalf("Unexpected error listing volumes: %v", err)
+	}
+	if len(resp.Entries) != 5 || resp.Entries[0].Volume.VolumeId != "vol3" || resp.NextToken != "" {
+		t.Errorf("Expected volumes vol3 through vol7 and no next token, got %v, %s", resp.Entries, resp.NextToken)
+	}
+
+	if _, err = p.ListVolumes(ctx, &csi.ListVolumesRequest{MaxEntries: -1}); statusCode(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for negative max entries, got %v", err)
+	}
+}
+
+func TestValidateVolumeCapabilitiesAccessModes(t *testing.T) {
+
+	p := newTestControllerPlugin()
+
+	orchestrator := p.orchestrator.(*core.MockOrchestrator)
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+	for name, accessMode := range map[string]tridentconfig.AccessMode{
+		"rwovol":  tridentconfig.ReadWriteOnce,
+		"rwopvol": tridentconfig.ReadWriteOncePod,
+	} {
+		if _, err := orchestrator.AddVolume(&storage.VolumeConfig{
+			Name:         name,
+			StorageClass: "sc",
+			Protocol:     tridentconfig.File,
+			AccessMode:   accessMode,
+		}); err != nil {
+			t.Fatalf("Unexpected error adding volume: %v", err)
+		}
+	}
+
+	tests := []struct {
+		volume    string
+		mode      csi.VolumeCapability_AccessMode_Mode
+		confirmed bool
+	}{
+		{"rwovol", csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER, true},
+		{"rwovol", accessModeSingleNodeSingleWriter, true},
+		{"rwovol", accessModeSingleNodeMultiWriter, true},
+		{"rwovol", csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER, false},
+		{"rwopvol", accessModeSingleNodeSingleWriter, true},
+		{"rwopvol", accessModeSingleNodeMultiWriter, false},
+		{"rwopvol", csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER, false},
+		{"rwopvol", csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER, false},
+	}
+
+	for _, test := range tests {
+		resp, err := p.ValidateVolumeCapabilities(context.Background(), &csi.ValidateVolumeCapabilitiesRequest{
+			VolumeId: test.volume,
+			VolumeCapabilities: []*csi.VolumeCapability{{
+				AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+				AccessMode: &csi.VolumeCapability_AccessMode{Mode: test.mode},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error validating %s for %s: %v", test.mode, test.volume, err)
+		}
+		if confirmed := resp.Confirmed != nil; confirmed != test.confirmed {
+			t.Errorf("Expected %s for %s confirmed to be %v, got %v", test.mode, test.volume, test.confirmed,
+				confirmed)
+		}
+	}
+}
+
+func TestValidateVolumeCapabilitiesFsType(t *testing.T) {
+
+	p := newTestControllerPlugin()
+
+	orchestrator := p.orchestrator.(*core.MockOrchestrator)
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+	if _, err := orchestrator.AddVolume(&storage.VolumeConfig{
+		Name:         "ext4vol",
+		StorageClass: "sc",
+		Protocol:     tridentconfig.File,
+		AccessMode:   tridentconfig.ReadWriteOnce,
+		FileSystem:   "ext4",
+	}); err != nil {
+		t.Fatalf("Unexpected error adding volume: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		fsType        string
+		parameters    map[string]string
+		volumeContext map[string]string
+		confirmed     bool
+	}{
+		{"matching fsType", "ext4", nil, nil, true},
+		{"unspecified fsType", "", nil, nil, true},
+		{"mismatched fsType", "xfs", nil, nil, false},
+		{"matching fsType parameter", "", map[string]string{"fsType": "EXT4"}, nil, true},
+		{"mismatched fsType parameter", "", map[string]string{"fsType": "xfs"}, nil, false},
+		{"mismatched fstype parameter", "ext4", map[string]string{"fstype": "xfs"}, nil, false},
+		{"matching volume context", "", nil, map[string]string{"protocol": "file", "name": "ext4vol"}, true},
+		{"mismatched volume context", "", nil, map[string]string{"protocol": "block"}, false},
+		{"foreign volume context", "", nil, map[string]string{"storage.kubernetes.io/owner": "k8s"}, true},
+	}
+
+	for _, test := range tests {
+		capabilities := []*csi.VolumeCapability{{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{FsType: test.fsType}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		}}
+		resp, err := p.ValidateVolumeCapabilities(context.Background(), &csi.ValidateVolumeCapabilitiesRequest{
+			VolumeId:           "ext4vol",
+			VolumeCapabilities: capabilities,
+			Parameters:         test.parameters,
+			VolumeContext:      test.volumeContext,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error validating %s: %v", test.name, err)
+		}
+		if confirmed := resp.Confirmed != nil; confirmed != test.confirmed {
+			t.Errorf("Expected %s confirmed to be %v, got %v (%s)", test.name, test.confirmed, confirmed, resp.Message)
+			continue
+		}
+		if test.confirmed && len(resp.Confirmed.VolumeCapabilities) != 1 {
+			t.Errorf("Expected %s to echo the volume capabilities, got %v", test.name, resp.Confirmed)
+		}
+		if !test.confirmed && resp.Message == "" {
+			t.Errorf("Expected a message explaining why %s was not confirmed", test.name)
+		}
+	}
+}
+
+func TestCreateVolumeUnknownParameter(t *testing.T) {
+
+	p := newTestControllerPlugin()
+
+	_, err := p.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+		Name:               "vol1",
+		VolumeCapabilities: []*csi.VolumeCapability{{}},
+		Parameters:         map[string]string{"backendTyp": "ontap-nas"},
+	})
+	if err == nil {
+		t.Fatal("Expected error for unknown volume parameter")
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("Expected a gRPC status error, got %v", err)
+	}
+	if st.Code() != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", st.Code())
+	}
+	if !strings.Contains(st.Message(), "backendTyp") {
+		t.Errorf("Expected unknown key in message, got %s", st.Message())
+	}
+}
+
+func TestCreateVolumeRequestID(t *testing.T) {
+
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	logLevel := log.GetLevel()
+	log.SetLevel(log.DebugLevel)
+	defer log.SetLevel(logLevel)
+
+	p := newTestControllerPlugin()
+
+	// The exit log is deferred, so a request rejected during validation logs both lines
+	if _, err := p.CreateVolume(context.Background(), &csi.CreateVolumeRequest{Name: "vol1"}); err == nil {
+		t.Fatal("Expected error for missing volume capabilities")
+	}
+
+	var entryID, exitID interface{}
+	for _, entry := range hook.AllEntries() {
+		switch entry.Message {
+		case ">>>> CreateVolume":
+			entryID = entry.Data["requestID"]
+		case "<<<< CreateVolume":
+			exitID = entry.Data["requestID"]
+		}
+	}
+	if entryID == nil || entryID == "" {
+		t.Fatal("Expected a request ID on the CreateVolume entry log")
+	}
+	if exitID != entryID {
+		t.Errorf("Expected request ID %v on the CreateVolume exit log, got %v", entryID, exitID)
+	}
+}
+
+// accessModeHelper is a testHelper that carries the requested protocol and access mode into the volume config.
+type accessModeHelper struct {
+	testHelper
+	protocol tridentconfig.Protocol
+}
+
+func (h *accessModeHelper) GetVolumeConfig(
+	ctx context.Context, name string, sizeBytes int64, parameters map[string]string,
+	protocol tridentconfig.Protocol, accessMode tridentconfig.AccessMode, fsType string,
+) (*storage.VolumeConfig, error) {
+	if h.protocol != tridentconfig.ProtocolAny {
+		protocol = h.protocol
+	}
+	return &storage.VolumeConfig{
+		Name:         name,
+		Size:         "1073741824",
+		StorageClass: "sc",
+		Protocol:     protocol,
+		AccessMode:   accessMode,
+	}, nil
+}
+
+func TestCreateVolumeAccessModeForBackendProtocol(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		backend  tridentconfig.Protocol
+		protocol tridentconfig.Protocol
+		mode     csi.VolumeCapability_AccessMode_Mode
+		code     codes.Code
+	}{
+		{"RWX on NFS", tridentconfig.File, tridentconfig.ProtocolAny,
+			csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER, codes.OK},
+		{"RWX on iSCSI", tridentconfig.Block, tridentconfig.ProtocolAny,
+			csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER, codes.InvalidArgument},
+		{"single-writer RWX on iSCSI", tridentconfig.Block, tridentconfig.ProtocolAny,
+			csi.VolumeCapability_AccessMode_MULTI_NODE_SINGLE_WRITER, codes.InvalidArgument},
+		{"RWX with block protocol", tridentconfig.File, tridentconfig.Block,
+			csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER, codes.InvalidArgument},
+		{"RWO on NFS", tridentconfig.File, tridentconfig.ProtocolAny,
+			csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER, codes.OK},
+		{"RWO on iSCSI", tridentconfig.Block, tridentconfig.ProtocolAny,
+			csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER, codes.OK},
+	}
+
+	for _, test := range tests {
+		p := newTestControllerPlugin()
+		p.helper = &accessModeHelper{protocol: test.protocol}
+
+		orchestrator := p.orchestrator.(*core.MockOrchestrator)
+		if test.backend == tridentconfig.File {
+			orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+		} else {
+			orchestrator.AddMockONTAPSANBackend("san", "10.0.0.2")
+		}
+		orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+
+		_, err := p.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+			Name: "vol1",
+			VolumeCapabilities: []*csi.VolumeCapability{{
+				AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+				AccessMode: &csi.VolumeCapability_AccessMode{Mode: test.mode},
+			}},
+		})
+		if code := statusCode(err); code != test.code {
+			t.Errorf("%s: expected %v, got %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestCreateVolumeDryRun(t *testing.T) {
+
+	p := newTestControllerPlugin()
+	p.helper = &accessModeHelper{protocol: tridentconfig.ProtocolAny}
+
+	orchestrator := p.orchestrator.(*core.MockOrchestrator)
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+
+	request := &csi.CreateVolumeRequest{
+		Name: "vol1",
+		VolumeCapabilities: []*csi.VolumeCapability{{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		}},
+		Parameters: map[string]string{frontendcommon.VolumeDryRun: "true"},
+	}
+
+	// A dry run never reports success, since no volume exists, but it describes the placement
+	resp, err := p.CreateVolume(context.Background(), request)
+	if resp != nil || statusCode(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition for a dry run, got %v, %v", resp, err)
+	}
+	message := status.Convert(err).Message()
+	for _, expected := range []string{"backend nas", core.GetFakeInternalName("vol1")} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("Expected dry run message to contain %q, got %q", expected, message)
+		}
+	}
+
+	// The volume must not have been created
+	if _, err = orchestrator.GetVolume("vol1"); !core.IsNotFoundError(err) {
+		t.Errorf("Expected no volume after a dry run, got %v", err)
+	}
+
+	request.Parameters[frontendcommon.VolumeDryRun] = "maybe"
+	if _, err = p.CreateVolume(context.Background(), request); statusCode(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an invalid dryRun value, got %v", err)
+	}
+}
+
+// slowHelper is a testHelper whose GetVolumeConfig blocks until released.
+type slowHelper struct {
+	testHelper
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *slowHelper) GetVolumeConfig(
+	ctx context.Context, name string, sizeBytes int64, parameters map[string]string,
+	protocol tridentconfig.Protocol, accessMode tridentconfig.AccessMode, fsType string,
+) (*storage.VolumeConfig, error) {
+	close(h.started)
+	<-h.release
+	return h.testHelper.GetVolumeConfig(ctx, name, sizeBytes, parameters, protocol, accessMode, fsType)
+}
+
+func TestShutdownWaitsForCreateVolume(t *testing.T) {
+
+	p := newTestControllerPlugin()
+	helper := &slowHelper{started: make(chan struct{}), release: make(chan struct{})}
+	p.helper = helper
+
+	orchestrator := p.orchestrator.(*core.MockOrchestrator)
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+
+	request := &csi.CreateVolumeRequest{
+		Name: "vol1",
+		VolumeCapabilities: []*csi.VolumeCapability{{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+			},
+		}},
+	}
+
+	created := make(chan struct{})
+	go func() {
+		p.CreateVolume(context.Background(), request)
+		close(created)
+	}()
+	<-helper.started
+
+	shutdown := make(chan struct{})
+	go func() {
+		p.shutdown(5 * time.Second)
+		close(shutdown)
+	}()
+
+	// New operations are refused while shutting down
+	for i := 0; i < 100; i++ {
+		p.opLock.Lock()
+		stopping := p.stopping
+		p.opLock.Unlock()
+		if stopping {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	_, err := p.CreateVolume(context.Background(), &csi.CreateVolumeRequest{Name: "vol2"})
+	if st, _ := status.FromError(err); st.Code() != codes.Unavailable {
+		t.Errorf("Expected Unavailable while shutting down, got %v", err)
+	}
+
+	// Shutdown waits for the in-flight create
+	select {
+	case <-shutdown:
+		t.Fatal("Expected shutdown to wait for the in-flight CreateVolume")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(helper.release)
+	<-created
+
+	select {
+	case <-shutdown:
+	case <-time.After(time.Second):
+		t.Error("Expected shutdown to finish after the in-flight CreateVolume")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+
+	p := newTestControllerPlugin()
+	if err := p.startOperation("vol1"); err != nil {
+		t.Fatalf("Unexpected error starting operation: %v", err)
+	}
+	defer p.finishOperation("vol1")
+
+	// An operation that never finishes doesn't block shutdown past the timeout
+	start := time.Now()
+	p.shutdown(50 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected shutdown to give up after its timeout, took %v", elapsed)
+	}
+}
+
+func TestGetCSIVolumeFromTridentVolumeContext(t *testing.T) {
+
+	p := newTestControllerPlugin()
+
+	volume := &storage.VolumeExternal{
+		Config: &storage.VolumeConfig{
+			Name:         "vol1",
+			InternalName: "trident_vol1",
+			Size:         "1073741824",
+			Protocol:     tridentconfig.Block,
+			AccessMode:   tridentconfig.ReadWriteOnce,
+			FileSystem:   "xfs",
+			AccessInfo: utils.VolumeAccessInfo{
+				MountOptions: "discard",
+				IscsiAccessInfo: utils.IscsiAccessInfo{
+					IscsiTargetIQN:       "iqn.1992-08.com.netapp:sn.1",
+					IscsiUsername:        "user",
+					IscsiInitiatorSecret: "initiatorSecret",
+					IscsiTargetSecret:    "targetSecret",
+				},
+			},
+		},
+		BackendUUID: "uuid1",
+	}
+
+	csiVolume, err := p.getCSIVolumeFromTridentVolume(volume)
+	if err != nil {
+		t.Fatalf("Unexpected error converting volume: %v", err)
+	}
+
+	expected := map[string]string{
+		"backendUUID":  "uuid1",
+		"name":         "vol1",
+		"internalName": "trident_vol1",
+		"protocol":     "block",
+		"fsType":       "xfs",
+		"accessMode":   "ReadWriteOnce",
+		"mountOptions": "discard",
+	}
+	if !reflect.DeepEqual(csiVolume.VolumeContext, expected) {
+		t.Errorf("Expected volume context %v, got %v", expected, csiVolume.VolumeContext)
+	}
+
+	for key, value := range csiVolume.VolumeContext {
+		lowerKey := strings.ToLower(key)
+		for _, secretLike := range []string{"secret", "password", "username", "chap"} {
+			if strings.Contains(lowerKey, secretLike) {
+				t.Errorf("Volume context must not contain secret-like key %s", key)
+			}
+		}
+		if value == "user" || strings.Contains(value, "Secret") {
+			t.Errorf("Volume context key %s must not carry a credential", key)
+		}
+	}
+}
+
+func TestGetCSISnapshotReadyToUse(t *testing.T) {
+
+	p := newTestControllerPlugin()
+
+	tests := []struct {
+		state storage.SnapshotState
+		ready bool
+	}{
+		{storage.SnapshotStateOnline, true},
+		{storage.SnapshotStateCreating, false},
+		{"", true},
+	}
+
+	for _, test := range tests {
+		snapshot := storage.NewSnapshot(&storage.SnapshotConfig{Name: "snap1", VolumeName: "vol1"},
+			time.Now().UTC().Format(time.RFC3339), 0)
+		snapshot.State = test.state
+
+		csiSnapshot, err := p.getCSISnapshotFromTridentSnapshot(snapshot.ConstructExternal())
+		if err != nil {
+			t.Fatalf("Unexpected error converting snapshot: %v", err)
+		}
+		if csiSnapshot.ReadyToUse != test.ready {
+			t.Errorf("Expected ReadyToUse %v for state %q, got %v", test.ready, test.state, csiSnapshot.ReadyToUse)
+		}
+	}
+}
+
+func TestControllerPublishVolumeNFSMountOptions(t *testing.T) {
+
+	p := newTestControllerPlugin()
+
+	orchestrator := p.orchestrator.(*core.MockOrchestrator)
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddMockONTAPSANBackend("san", "10.0.0.2")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+	orchestrator.AddNode(&utils.Node{Name: "node1", IQN: "iqn.1993-08.org.debian:01:node1"})
+
+	for _, volumeConfig := range []*storage.VolumeConfig{
+		{Name: "nfsvol", StorageClass: "sc", Protocol: tridentconfig.File},
+		{Name: "sanvol", StorageClass: "sc", Protocol: tridentconfig.Block},
+		{Name: "backendvol", StorageClass: "sc", Protocol: tridentconfig.File,
+			AccessInfo: utils.VolumeAccessInfo{MountOptions: "vers=3"}},
+	} {
+		if _, err := orchestrator.AddVolume(volumeConfig); err != nil {
+			t.Fatalf("Unexpected error adding volume %s: %v", volumeConfig.Name, err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		volume     string
+		defaults   string
+		mountFlags []string
+		expected   string
+	}{
+		{"default applied", "nfsvol", "vers=4.1,nconnect=4", nil, "vers=4.1,nconnect=4"},
+		{"default overridden", "nfsvol", "vers=4.1,nconnect=4", []string{"vers=3", "nolock"}, "vers=3,nolock"},
+		{"block ignores default", "sanvol", "vers=4.1,nconnect=4", nil, ""},
+		{"backend overrides default", "backendvol", "vers=4.1,nconnect=4", nil, "vers=3"},
+		{"no default keeps backend", "backendvol", "", nil, "vers=3"},
+	}
+
+	for _, test := range tests {
+		p.nfsMountOptions = test.defaults
+		resp, err := p.ControllerPublishVolume(context.Background(), &csi.ControllerPublishVolumeRequest{
+			VolumeId: test.volume,
+			NodeId:   "node1",
+			VolumeCapability: &csi.VolumeCapability{
+				AccessType: &csi.VolumeCapability_Mount{
+					Mount: &csi.VolumeCapability_MountVolume{MountFlags: test.mountFlags},
+				},
+				AccessMode: &csi.VolumeCapability_AccessMode{
+					Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error publishing volume: %v", test.name, err)
+		}
+		if mountOptions := resp.PublishContext["mountOptions"]; mountOptions != test.expected {
+			t.Errorf("%s: expected mount options %q, got %q", test.name, test.expected, mountOptions)
+		}
+	}
+}
+
+func TestControllerPublishVolumeFSGroupChangePolicy(t *testing.T) {
+
+	p := newTestControllerPlugin()
+
+	orchestrator := p.orchestrator.(*core.MockOrchestrator)
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddMockONTAPSANBackend("san", "10.0.0.2")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+	orchestrator.AddNode(&utils.Node{Name: "node1", IQN: "iqn.1993-08.org.debian:01:node1"})
+
+	capability := &csi.VolumeCapability{
+		AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+		AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+	}
+
+	tests := []struct {
+		name     string
+		protocol tridentconfig.Protocol
+		policy   string
+		expected string
+	}{
+		{"nfs-rootmismatch", tridentconfig.File, frontendcommon.FSGroupChangePolicyOnRootMismatch,
+			frontendcommon.FSGroupChangePolicyOnRootMismatch},
+		{"nfs-always", tridentconfig.File, frontendcommon.FSGroupChangePolicyAlways,
+			frontendcommon.FSGroupChangePolicyAlways},
+		{"nfs-default", tridentconfig.File, "", ""},
+		{"san-rootmismatch", tridentconfig.Block, frontendcommon.FSGroupChangePolicyOnRootMismatch, ""},
+	}
+
+	for _, test := range tests {
+		p.helper = &accessModeHelper{protocol: test.protocol}
+
+		parameters := map[string]string{}
+		if test.policy != "" {
+			parameters[frontendcommon.VolumeFSGroupChangePolicy] = test.policy
+		}
+		if _, err := p.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+			Name:               test.name,
+			VolumeCapabilities: []*csi.VolumeCapability{capability},
+			Parameters:         parameters,
+		}); err != nil {
+			t.Fatalf("%s: unexpected error creating volume: %v", test.name, err)
+		}
+
+		resp, err := p.ControllerPublishVolume(context.Background(), &csi.ControllerPublishVolumeRequest{
+			VolumeId:         test.name,
+			NodeId:           "node1",
+			VolumeCapability: capability,
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error publishing volume: %v", test.name, err)
+		}
+		policy, ok := resp.PublishContext["fsGroupChangePolicy"]
+		if policy != test.expected || ok != (test.expected != "") {
+			t.Errorf("%s: expected fsGroupChangePolicy %q, got %q", test.name, test.expected, policy)
+		}
+	}
+
+	p.helper = &accessModeHelper{protocol: tridentconfig.File}
+	_, err := p.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+		Name:               "nfs-invalid",
+		VolumeCapabilities: []*csi.VolumeCapability{capability},
+		Parameters:         map[string]string{frontendcommon.VolumeFSGroupChangePolicy: "Never"},
+	})
+	if statusCode(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an invalid fsGroupChangePolicy, got %v", err)
+	}
+}
+
+// flakyPublishOrchestrator is a mock orchestrator whose PublishVolume fails a set number of times
+// before succeeding.
+type flakyPublishOrchestrator struct {
+	*core.MockOrchestrator
+	failures int
+	err      error
+	calls    int
+}
+
+func (o *flakyPublishOrchestrator) PublishVolume(volumeName string, publishInfo *utils.VolumePublishInfo) error {
+	o.calls++
+	if o.calls <= o.failures {
+		return o.err
+	}
+	return o.MockOrchestrator.PublishVolume(volumeName, publishInfo)
+}
+
+func TestControllerPublishVolumeRetry(t *testing.T) {
+
+	interval := publishRetryInterval
+	publishRetryInterval = time.Millisecond
+	defer func() { publishRetryInterval = interval }()
+
+	p := newTestControllerPlugin()
+	orchestrator := &flakyPublishOrchestrator{
+		MockOrchestrator: p.orchestrator.(*core.MockOrchestrator),
+		failures:         1,
+		err:              fmt.Errorf("export policy rule update failed"),
+	}
+	p.orchestrator = orchestrator
+
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+	orchestrator.AddNode(&utils.Node{Name: "node1"})
+	if _, err := orchestrator.AddVolume(&storage.VolumeConfig{
+		Name: "vol1", StorageClass: "sc", Protocol: tridentconfig.File,
+	}); err != nil {
+		t.Fatalf("Unexpected error adding volume: %v", err)
+	}
+
+	request := &csi.ControllerPublishVolumeRequest{
+		VolumeId: "vol1",
+		NodeId:   "node1",
+		VolumeCapability: &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+			},
+		},
+	}
+
+	// A transient failure is retried within the same request
+	resp, err := p.ControllerPublishVolume(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error publishing volume: %v", err)
+	}
+	if resp.PublishContext["nfsServerIp"] == "" {
+		t.Errorf("Expected NFS publish info, got %v", resp.PublishContext)
+	}
+	if orchestrator.calls != 2 {
+		t.Errorf("Expected 2 publish attempts, got %d", orchestrator.calls)
+	}
+
+	// A permanent failure is returned without retrying
+	_, orchestrator.err = core.NewMockOrchestrator().GetNode("missing")
+	orchestrator.calls = 0
+	if _, err = p.ControllerPublishVolume(context.Background(), request); err == nil {
+		t.Error("Expected an error publishing volume")
+	}
+	if orchestrator.calls != 1 {
+		t.Errorf("Expected 1 publish attempt, got %d", orchestrator.calls)
+	}
+}
+
+func TestControllerExpandVolume(t *testing.T) {
+
+	p := newTestControllerPlugin()
+	allowVolumeExpansion := true
+
+	orchestrator := p.orchestrator.(*core.MockOrchestrator)
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "gold", AllowVolumeExpansion: &allowVolumeExpansion})
+	for _, config := range []*storage.VolumeConfig{
+		{Name: "nfsvol", StorageClass: "gold", Protocol: tridentconfig.File, Size: "1073741824"},
+		{Name: "blockvol", StorageClass: "gold", Protocol: tridentconfig.Block, Size: "1073741824"},
+	} {
+		if _, err := orchestrator.AddVolume(config); err != nil {
+			t.Fatalf("Unexpected error adding volume: %v", err)
+		}
+	}
+
+	tests := []struct {
+		volume        string
+		requiredBytes int64
+		limitBytes    int64
+		code          codes.Code
+		capacity      int64
+	}{
+		{"nfsvol", 2147483648, 0, codes.OK, 2147483648},
+		{"nfsvol", 1073741824, 0, codes.OK, 2147483648},
+		{"nfsvol", 0, 0, codes.InvalidArgument, 0},
+		{"nfsvol", 4294967296, 3221225472, codes.OutOfRange, 0},
+		{"blockvol", 2147483648, 0, codes.FailedPrecondition, 0},
+	}
+
+	for _, test := range tests {
+		response, err := p.ControllerExpandVolume(context.Background(), &csi.ControllerExpandVolumeRequest{
+			VolumeId:      test.volume,
+			CapacityRange: &csi.CapacityRange{RequiredBytes: test.requiredBytes, LimitBytes: test.limitBytes},
+		})
+		if st, _ := status.FromError(err); st.Code() != test.code {
+			t.Errorf("Expected %v expanding %s to %d, got %v", test.code, test.volume, test.requiredBytes, err)
+			continue
+		}
+		if err == nil && response.CapacityBytes != test.capacity {
+			t.Errorf("Expected %s to have capacity %d, got %d", test.volume, test.capacity, response.CapacityBytes)
+		}
+	}
+
+	volume, err := orchestrator.GetVolume("nfsvol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if volume.Config.Size != "2147483648" {
+		t.Errorf("Expected the volume to be resized to 2147483648, got %s", volume.Config.Size)
+	}
+
+	// An unknown volume is not found
+	_, err = p.ControllerExpandVolume(context.Background(), &csi.ControllerExpandVolumeRequest{VolumeId: "vol2"})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown volume, got %v", err)
+	}
+}
+
+func TestDeleteVolumeProtection(t *testing.T) {
+
+	p := newTestControllerPlugin()
+	p.helper = &accessModeHelper{protocol: tridentconfig.File}
+
+	orchestrator := p.orchestrator.(*core.MockOrchestrator)
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+
+	capability := &csi.VolumeCapability{
+		AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+		AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+	}
+
+	for name, parameters := range map[string]map[string]string{
+		"protected":   {frontendcommon.VolumeDeletionProtection: "true"},
+		"unprotected": {},
+	} {
+		if _, err := p.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+			Name:               name,
+			VolumeCapabilities: []*csi.VolumeCapability{capability},
+			Parameters:         parameters,
+		}); err != nil {
+			t.Fatalf("%s: unexpected error creating volume: %v", name, err)
+		}
+	}
+
+	// A protected volume is not deleted
+	_, err := p.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{VolumeId: "protected"})
+	if code := statusCode(err); code != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition deleting a protected volume, got %v", err)
+	}
+	if _, err = orchestrator.GetVolume("protected"); err != nil {
+		t.Errorf("Expected the protected volume to remain, got %v", err)
+	}
+
+	// An unprotected volume is deleted
+	if _, err = p.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{VolumeId: "unprotected"}); err != nil {
+		t.Errorf("Unexpected error deleting an unprotected volume: %v", err)
+	}
+	if _, err = orchestrator.GetVolume("unprotected"); !core.IsNotFoundError(err) {
+		t.Errorf("Expected the unprotected volume to be deleted, got %v", err)
+	}
+
+	// Once its protection is cleared, the formerly protected volume is deleted
+	if _, err = orchestrator.SetVolumeDeletionProtection("protected", false); err != nil {
+		t.Fatalf("Unexpected error clearing deletion protection: %v", err)
+	}
+	if _, err = p.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{VolumeId: "protected"}); err != nil {
+		t.Errorf("Unexpected error deleting a formerly protected volume: %v", err)
+	}
+
+	// An invalid protection parameter is rejected
+	_, err = p.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+		Name:               "invalid",
+		VolumeCapabilities: []*csi.VolumeCapability{capability},
+		Parameters:         map[string]string{frontendcommon.VolumeDeletionProtection: "sometimes"},
+	})
+	if code := statusCode(err); code != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an invalid deletionProtection parameter, got %v", err)
+	}
+}
+
+func TestDeleteSnapshotDependents(t *testing.T) {
+
+	p := newTestControllerPlugin()
+
+	orchestrator := p.orchestrator.(*core.MockOrchestrator)
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+
+	for _, volumeConfig := range []*storage.VolumeConfig{
+		{Name: "vol1", Size: "1073741824", StorageClass: "sc", Protocol: tridentconfig.File},
+		{Name: "clone1", Size: "1073741824", StorageClass: "sc", Protocol: tridentconfig.File,
+			CloneSourceVolume: "vol1", CloneSourceSnapshot: "snap1"},
+	} {
+		if _, err := orchestrator.AddVolume(volumeConfig); err != nil {
+			t.Fatalf("Unexpected error adding volume %s: %v", volumeConfig.Name, err)
+		}
+	}
+	for _, snapshotName := range []string{"snap1", "snap2"} {
+		if _, err := orchestrator.CreateSnapshot(&storage.SnapshotConfig{
+			Name:       snapshotName,
+			VolumeName: "vol1",
+		}); err != nil {
+			t.Fatalf("Unexpected error creating snapshot %s: %v", snapshotName, err)
+		}
+	}
+
+	// A snapshot without dependents is deleted
+	_, err := p.DeleteSnapshot(context.Background(), &csi.DeleteSnapshotRequest{
+		SnapshotId: storage.MakeSnapshotID("vol1", "snap2"),
+	})
+	if err != nil {
+		t.Errorf("Unexpected error deleting a snapshot without dependents: %v", err)
+	}
+	if _, err = orchestrator.GetSnapshot("vol1", "snap2"); !core.IsNotFoundError(err) {
+		t.Errorf("Expected the snapshot without dependents to be deleted, got %v", err)
+	}
+
+	// A snapshot with dependents is not deleted
+	_, err = p.DeleteSnapshot(context.Background(), &csi.DeleteSnapshotRequest{
+		SnapshotId: storage.MakeSnapshotID("vol1", "snap1"),
+	})
+	if code := statusCode(err); code != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition deleting a snapshot with dependents, got %v", err)
+	} else if !strings.Contains(err.Error(), "clone1") {
+		t.Errorf("Expected the dependent clone to be listed, got %v", err)
+	}
+	if _, err = orchestrator.GetSnapshot("vol1", "snap1"); err != nil {
+		t.Errorf("Expected the snapshot with dependents to remain, got %v", err)
+	}
+
+	// A forced delete ignores the dependents
+	_, err = p.DeleteSnapshot(context.Background(), &csi.DeleteSnapshotRequest{
+		SnapshotId: storage.MakeSnapshotID("vol1", "snap1"),
+		Secrets:    map[string]string{ForceDeleteSecret: "true"},
+	})
+	if err != nil {
+		t.Errorf("Unexpected error force deleting a snapshot with dependents: %v", err)
+	}
+}
@@ -75,7 +75,7 @@ func (s *nonBlockingGRPCServer) serve(endpoint string, ids csi.IdentityServer, c
 	}
 
 	opts := []grpc.ServerOption{
-		grpc.UnaryInterceptor(logGRPC),
+		grpc.UnaryInterceptor(chainUnaryInterceptors(metricsGRPC, logGRPC)),
 	}
 	server := grpc.NewServer(opts...)
 	s.server = server
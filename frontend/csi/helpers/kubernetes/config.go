@@ -19,15 +19,31 @@ const (
 	CacheBackoffMultiplier          = 1.414
 	CacheBackoffMaxInterval         = 5 * time.Second
 
+	// Leader election timing for multiple CSI controller replicas
+	LeaderElectionLockName      = "trident-csi"
+	LeaderElectionLeaseDuration = 15 * time.Second
+	LeaderElectionRenewDeadline = 10 * time.Second
+	LeaderElectionRetryPeriod   = 2 * time.Second
+
 	// Kubernetes-defined storage class parameters
 	K8sFsType = "fsType"
 
+	// Orchestrator-defined storage class parameters
+	SnapshotRetentionMaxAge   = "snapshotRetentionMaxAge"
+	SnapshotRetentionMaxCount = "snapshotRetentionMaxCount"
+
 	// Kubernetes-defined annotations
 	// (Based on kubernetes/pkg/controller/volume/persistentvolume/controller.go)
 	AnnClass                  = "volume.beta.kubernetes.io/storage-class"
 	AnnDynamicallyProvisioned = "pv.kubernetes.io/provisioned-by"
 	AnnStorageProvisioner     = "volume.beta.kubernetes.io/storage-provisioner"
 
+	// Kubernetes-defined node labels
+	K8sTopologyRegionLabel     = config.TopologyRegionLabel
+	K8sTopologyZoneLabel       = config.TopologyZoneLabel
+	K8sTopologyRegionBetaLabel = "failure-domain.beta.kubernetes.io/region"
+	K8sTopologyZoneBetaLabel   = "failure-domain.beta.kubernetes.io/zone"
+
 	// Orchestrator-defined annotations
 	annPrefix          = config.OrchestratorName + ".netapp.io"
 	AnnProtocol        = annPrefix + "/protocol"
@@ -39,6 +55,15 @@ const (
 	AnnBlockSize       = annPrefix + "/blockSize"
 	AnnFileSystem      = annPrefix + "/fileSystem"
 	AnnCloneFromPVC    = annPrefix + "/cloneFromPVC"
+	AnnCloneFromSnap   = annPrefix + "/cloneFromSnapshot"
 	AnnSplitOnClone    = annPrefix + "/splitOnClone"
 	AnnNotManaged      = annPrefix + "/notManaged"
 )
+
+// CacheBackoffConfig tunes the exponential backoff used while waiting for objects to appear in the
+// Kubernetes caches.  Zero-valued fields are replaced by the CacheBackoff* defaults.
+type CacheBackoffConfig struct {
+	InitialInterval time.Duration
+	Multiplier      float64
+	MaxInterval     time.Duration
+}
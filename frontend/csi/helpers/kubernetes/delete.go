@@ -49,6 +49,12 @@ func (p *Plugin) updateLegacyPV(oldObj, newObj interface{}) {
 		return
 	}
 
+	// Leave the PV and its volume alone if legacy PV deletion is disabled
+	if p.skipLegacyPVDeletion {
+		log.WithField("PV", pv.Name).Info("K8S helper skipped deleting a released legacy PV and its backing volume.")
+		return
+	}
+
 	// Delete the volume on the backend
 	if err := p.orchestrator.DeleteVolume(pv.Name); err != nil && !core.IsNotFoundError(err) {
 		// Updating the PV's phase to "VolumeFailed", so that a storage admin can take action.
@@ -2,13 +2,16 @@ package kubernetes
 
 import (
 	"fmt"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
 	k8sstoragev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/netapp/trident/config"
+	frontendcommon "github.com/netapp/trident/frontend/common"
 	"github.com/netapp/trident/frontend/csi"
 	"github.com/netapp/trident/frontend/csi/helpers"
 	"github.com/netapp/trident/storage"
@@ -25,19 +28,24 @@ import (
 // retrieved from the K8S API server, and returns a VolumeConfig structure
 // as needed by Trident to create a new volume.
 func (p *Plugin) GetVolumeConfig(
-	name string, sizeBytes int64, parameters map[string]string,
+	ctx context.Context, name string, sizeBytes int64, parameters map[string]string,
 	protocol config.Protocol, accessMode config.AccessMode, fsType string,
 ) (*storage.VolumeConfig, error) {
 
 	// Kubernetes CSI passes us the name of what will become a new PV
 	pvName := name
 
-	fields := log.Fields{"Method": "GetVolumeConfig", "Type": "K8S helper", "name": pvName}
+	fields := log.Fields{
+		"Method":    "GetVolumeConfig",
+		"Type":      "K8S helper",
+		"name":      pvName,
+		"requestID": csi.GetRequestID(ctx),
+	}
 	log.WithFields(fields).Debug(">>>> GetVolumeConfig")
 	defer log.WithFields(fields).Debug("<<<< GetVolumeConfig")
 
 	// Get the PVC corresponding to the new PV being provisioned
-	pvc, err := p.getPVCForCSIVolume(pvName)
+	pvc, err := p.getPVCForCSIVolume(ctx, pvName)
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +55,7 @@ func (p *Plugin) GetVolumeConfig(
 		"UID":          pvc.UID,
 		"size":         pvc.Spec.Resources.Requests[v1.ResourceStorage],
 		"storageClass": getStorageClassForPVC(pvc),
+		"requestID":    csi.GetRequestID(ctx),
 	}).Infof("Found PVC for requested volume %s.", pvName)
 
 	// Validate the PVC
@@ -70,33 +79,68 @@ func (p *Plugin) GetVolumeConfig(
 	if err != nil {
 		return nil, err
 	}
-	log.WithField("name", sc.Name).Infof("Found storage class for requested volume %s.", pvName)
+	log.WithFields(log.Fields{
+		"name":      sc.Name,
+		"requestID": csi.GetRequestID(ctx),
+	}).Infof("Found storage class for requested volume %s.", pvName)
 
 	// Validate the storage class
-	if sc.Provisioner != csi.Provisioner {
-		return nil, fmt.Errorf("the provisioner for storage class %s is not %s", sc.Name, csi.Provisioner)
+	if !p.handlesProvisioner(sc.Provisioner) {
+		return nil, fmt.Errorf("the provisioner for storage class %s is not one of %s", sc.Name,
+			strings.Join(getProvisioners(p.provisioners), ", "))
 	}
 
 	// Create the volume config
+	fsType = p.getFsTypeForStorageClass(fsType, scName)
 	volumeConfig := getVolumeConfig(pvc.Spec.AccessModes, pvName, pvcSize, processPVCAnnotations(pvc, fsType), scName)
 
+	// Name the volume on its storage system according to the storage class's template, if any
+	if nameTemplate := parameters[frontendcommon.VolumeNameTemplate]; nameTemplate != "" {
+		internalNameBase, err := frontendcommon.ExpandVolumeNameTemplate(nameTemplate, map[string]string{
+			frontendcommon.NameTemplateTokenPVC:       pvc.Name,
+			frontendcommon.NameTemplateTokenNamespace: pvc.Namespace,
+			frontendcommon.NameTemplateTokenUID:       string(pvc.UID),
+		})
+		if err != nil {
+			return nil, err
+		}
+		volumeConfig.InternalNameBase = internalNameBase
+	}
+
 	// Check if we're cloning a PVC, and if so, do some further validation
-	if cloneSourcePVName, err := p.getCloneSourceInfo(pvc); err != nil {
+	if cloneSourceVolume, cloneSourceSnapshot, err := p.getCloneSourceInfo(pvc); err != nil {
 		return nil, err
-	} else if cloneSourcePVName != "" {
-		volumeConfig.CloneSourceVolume = cloneSourcePVName
+	} else if cloneSourceVolume != "" {
+		volumeConfig.CloneSourceVolume = cloneSourceVolume
+		volumeConfig.CloneSourceSnapshot = cloneSourceSnapshot
 	}
 
 	return volumeConfig, nil
 }
 
+// getFsTypeForStorageClass returns the file system type requested for a volume, or if none was
+// requested, the default file system type of the volume's Trident storage class, if any.
+func (p *Plugin) getFsTypeForStorageClass(fsType, scName string) string {
+
+	if fsType != "" {
+		return fsType
+	}
+
+	sc, err := p.orchestrator.GetStorageClass(scName)
+	if err != nil {
+		log.WithField("storageClass", scName).Debugf("Could not get storage class default fsType; %v", err)
+		return ""
+	}
+	return sc.Config.FileSystem
+}
+
 // getPVCForCSIVolume accepts the name of a volume being requested by the CSI provisioner,
 // extracts the PVC name from the volume name, and returns the PVC object as read from the
 // Kubernetes API server.  The method waits for the object to appear in cache, resyncs the
 // cache if not found after an initial wait, and waits again after the resync.  This strategy
 // is necessary because CSI only provides us with the PVC's UID, and the Kubernetes API does
 // not support querying objects by UID.
-func (p *Plugin) getPVCForCSIVolume(name string) (*v1.PersistentVolumeClaim, error) {
+func (p *Plugin) getPVCForCSIVolume(ctx context.Context, name string) (*v1.PersistentVolumeClaim, error) {
 
 	// Get the PVC UID from the volume name.  The CSI provisioner sidecar creates
 	// volume names of the form "pvc-<PVC_UID>".
@@ -106,17 +150,19 @@ func (p *Plugin) getPVCForCSIVolume(name string) (*v1.PersistentVolumeClaim, err
 	}
 
 	// Get the cached PVC that started this workflow
-	pvc, err := p.waitForCachedPVCByUID(pvcUID, PreSyncCacheWaitPeriod)
+	logFields := log.Fields{"uid": pvcUID, "requestID": csi.GetRequestID(ctx)}
+
+	pvc, err := p.waitForCachedPVCByUID(ctx, pvcUID, PreSyncCacheWaitPeriod)
 	if err != nil {
-		log.WithField("uid", pvcUID).Warningf("PVC not found in local cache: %v", err)
+		log.WithFields(logFields).Warningf("PVC not found in local cache: %v", err)
 
 		// Not found immediately, so re-sync and try again
 		if err = p.pvcIndexer.Resync(); err != nil {
 			return nil, fmt.Errorf("could not refresh local PVC cache: %v", err)
 		}
 
-		if pvc, err = p.waitForCachedPVCByUID(pvcUID, PostSyncCacheWaitPeriod); err != nil {
-			log.WithField("uid", pvcUID).Errorf("PVC not found in local cache after resync: %v", err)
+		if pvc, err = p.waitForCachedPVCByUID(ctx, pvcUID, PostSyncCacheWaitPeriod); err != nil {
+			log.WithFields(logFields).Errorf("PVC not found in local cache after resync: %v", err)
 			return nil, fmt.Errorf("could not find PVC with UID %s: %v", pvcUID, err)
 		}
 	}
@@ -170,26 +216,41 @@ func (p *Plugin) getStorageClass(name string) (*k8sstoragev1.StorageClass, error
 // getCloneSourceInfo accepts the PVC of a volume being provisioned by CSI and inspects it
 // for the annotations indicating a clone operation (of which CSI is unaware). If a clone is
 // being created, the method completes several checks on the source PVC/PV and returns the
-// name of the source PV as needed by Trident to clone a volume as well as an optional
-// snapshot name (also potentially unknown to CSI).  Note that these legacy clone annotations
-// will be overridden if the VolumeContentSource is set in the CSI CreateVolume request.
-func (p *Plugin) getCloneSourceInfo(clonePVC *v1.PersistentVolumeClaim) (string, error) {
+// name of the source Trident volume as needed by Trident to clone a volume as well as an
+// optional snapshot name (also potentially unknown to CSI).  Note that these legacy clone
+// annotations will be overridden if the VolumeContentSource is set in the CSI CreateVolume request.
+func (p *Plugin) getCloneSourceInfo(clonePVC *v1.PersistentVolumeClaim) (string, string, error) {
 
 	// Check if this is a clone operation
 	annotations := processPVCAnnotations(clonePVC, "")
 	sourcePVCName := getAnnotation(annotations, AnnCloneFromPVC)
+	sourceSnapshotName := getAnnotation(annotations, AnnCloneFromSnap)
 	if sourcePVCName == "" {
-		return "", nil
+		if sourceSnapshotName != "" {
+			return "", "", fmt.Errorf("the %s annotation requires the %s annotation", AnnCloneFromSnap,
+				AnnCloneFromPVC)
+		}
+		return "", "", nil
+	}
+
+	// Refuse a source PVC in another namespace, which would let one namespace read another's data
+	if strings.Contains(sourcePVCName, "/") {
+		log.WithFields(log.Fields{
+			"sourcePVCName": sourcePVCName,
+			"namespace":     clonePVC.Namespace,
+		}).Error("Cloning from a PVC requires both PVCs be in the same namespace.")
+		return "", "", fmt.Errorf("cloning from a PVC requires both PVCs be in the same namespace")
 	}
 
-	// Check that the source PVC is in the same namespace.
+	// Check that the source PVC exists in the same namespace.
 	sourcePVC, err := p.waitForCachedPVCByName(sourcePVCName, clonePVC.Namespace, PreSyncCacheWaitPeriod)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"sourcePVCName": sourcePVCName,
 			"namespace":     clonePVC.Namespace,
 		}).Errorf("Clone source PVC not found in local cache: %v", err)
-		return "", fmt.Errorf("cloning from a PVC requires both PVCs be in the same namespace: %v", err)
+		return "", "", fmt.Errorf("clone source PVC %s not found in namespace %s: %v", sourcePVCName,
+			clonePVC.Namespace, err)
 	}
 
 	// Check that both source and clone PVCs have the same storage class
@@ -202,7 +263,7 @@ func (p *Plugin) getCloneSourceInfo(clonePVC *v1.PersistentVolumeClaim) (string,
 			"sourcePVCNamespace":    sourcePVC.Namespace,
 			"sourcePVCStorageClass": getStorageClassForPVC(sourcePVC),
 		}).Error("Cloning from a PVC requires both PVCs have the same storage class.")
-		return "", fmt.Errorf("cloning from a PVC requires both PVCs have the same storage class")
+		return "", "", fmt.Errorf("cloning from a PVC requires both PVCs have the same storage class")
 	}
 
 	// Check that the source PVC has an associated PV
@@ -212,7 +273,22 @@ func (p *Plugin) getCloneSourceInfo(clonePVC *v1.PersistentVolumeClaim) (string,
 			"sourcePVCName":      sourcePVC.Name,
 			"sourcePVCNamespace": sourcePVC.Namespace,
 		}).Error("Cloning from a PVC requires the source to be bound to a PV.")
-		return "", fmt.Errorf("cloning from a PVC requires the source to be bound to a PV")
+		return "", "", fmt.Errorf("cloning from a PVC requires the source to be bound to a PV")
+	}
+
+	// Resolve the source PV to its Trident volume
+	sourceVolumeName, err := p.getVolumeNameForPV(sourcePVName)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err = p.orchestrator.GetVolume(sourceVolumeName); err != nil {
+		log.WithFields(log.Fields{
+			"sourcePVCName": sourcePVC.Name,
+			"sourcePVName":  sourcePVName,
+			"sourceVolume":  sourceVolumeName,
+		}).Errorf("Clone source volume not found: %v", err)
+		return "", "", fmt.Errorf("clone source volume %s for PVC %s not found: %v", sourceVolumeName,
+			sourcePVC.Name, err)
 	}
 
 	// Check that the clone size is <= the source size
@@ -226,20 +302,53 @@ func (p *Plugin) getCloneSourceInfo(clonePVC *v1.PersistentVolumeClaim) (string,
 			"sourcePVCSize": sourcePVCSize,
 			"clonePVCSize":  clonePVCSize,
 		}).Error("requested PVC size is too large for the clone source")
-		return "", fmt.Errorf("requested PVC size is too large for the clone source")
+		return "", "", fmt.Errorf("requested PVC size is too large for the clone source")
 	}
 
-	return sourcePVName, nil
+	// Check that the source snapshot, if any, exists
+	if sourceSnapshotName != "" {
+		if _, err = p.orchestrator.GetSnapshot(sourceVolumeName, sourceSnapshotName); err != nil {
+			log.WithFields(log.Fields{
+				"sourceVolume":   sourceVolumeName,
+				"sourceSnapshot": sourceSnapshotName,
+			}).Errorf("Clone source snapshot not found: %v", err)
+			return "", "", fmt.Errorf("clone source snapshot %s of PVC %s not found: %v", sourceSnapshotName,
+				sourcePVC.Name, err)
+		}
+	}
+
+	return sourceVolumeName, sourceSnapshotName, nil
+}
+
+// getVolumeNameForPV returns the name of the Trident volume backing a PV from the PV cache.
+// A CSI volume is named by its volume handle, while a volume provisioned before CSI shares
+// its PV's name.
+func (p *Plugin) getVolumeNameForPV(pvName string) (string, error) {
+
+	item, exists, err := p.pvIndexer.GetByKey(pvName)
+	if err != nil {
+		return "", fmt.Errorf("could not search cache for PV %s: %v", pvName, err)
+	} else if !exists {
+		log.WithField("name", pvName).Error("Clone source PV not found in local cache.")
+		return "", fmt.Errorf("clone source PV %s not found", pvName)
+	}
+	pv, ok := item.(*v1.PersistentVolume)
+	if !ok {
+		return "", fmt.Errorf("non-PV object %s found in cache", pvName)
+	}
+
+	if pv.Spec.CSI != nil && pv.Spec.CSI.VolumeHandle != "" {
+		return pv.Spec.CSI.VolumeHandle, nil
+	}
+	return pv.Name, nil
 }
 
 // GetSnapshotConfig accepts the attributes of a snapshot being requested by the CSI
 // provisioner and returns a SnapshotConfig structure as needed by Trident to create a new snapshot.
-func (p *Plugin) GetSnapshotConfig(volumeName, snapshotName string) (*storage.SnapshotConfig, error) {
-	return &storage.SnapshotConfig{
-		Version:    config.OrchestratorAPIVersion,
-		Name:       snapshotName,
-		VolumeName: volumeName,
-	}, nil
+func (p *Plugin) GetSnapshotConfig(
+	volumeName, snapshotName string, parameters map[string]string,
+) (*storage.SnapshotConfig, error) {
+	return frontendcommon.GetSnapshotConfig(volumeName, snapshotName, parameters)
 }
 
 // RecordVolumeEvent accepts the name of a CSI volume (i.e. a PV name), finds the associated
@@ -253,13 +362,36 @@ func (p *Plugin) RecordVolumeEvent(name, eventType, reason, message string) {
 		"message":   message,
 	}).Debug("Volume event.")
 
-	if pvc, err := p.getPVCForCSIVolume(name); err != nil {
+	if pvc, err := p.getPVCForCSIVolume(context.Background(), name); err != nil {
 		log.WithField("error", err).Debug("Failed to find PVC for event.")
 	} else {
 		p.eventRecorder.Event(pvc, mapEventType(eventType), reason, message)
 	}
 }
 
+// GetNodeTopologyLabels accepts the name of a node (i.e. a CSI node ID), finds the corresponding
+// Kubernetes node, and returns the node's region and zone topology labels, if any.  The labels are
+// keyed by the topology.kubernetes.io names, even if the node only has the older failure-domain ones.
+func (p *Plugin) GetNodeTopologyLabels(nodeName string) (map[string]string, error) {
+
+	node, err := p.waitForCachedNodeByName(nodeName, PreSyncCacheWaitPeriod)
+	if err != nil {
+		log.WithField("name", nodeName).Warningf("Node not found in local cache: %v", err)
+
+		// Not found immediately, so re-sync and try again
+		if err = p.nodeIndexer.Resync(); err != nil {
+			return nil, fmt.Errorf("could not refresh local node cache: %v", err)
+		}
+
+		if node, err = p.waitForCachedNodeByName(nodeName, PostSyncCacheWaitPeriod); err != nil {
+			log.WithField("name", nodeName).Errorf("Node not found in local cache after resync: %v", err)
+			return nil, fmt.Errorf("could not find node %s: %v", nodeName, err)
+		}
+	}
+
+	return getNodeTopologyLabels(node), nil
+}
+
 // mapEventType maps between K8S API event types and Trident CSI helper event types.  The
 // two sets of types may be identical, but the CSI helper interface should not be tightly
 // coupled to Kubernetes.
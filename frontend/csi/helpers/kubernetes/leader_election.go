@@ -0,0 +1,64 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package kubernetes
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// WaitForLeadership blocks until this Trident instance is elected leader among the CSI controller
+// replicas in its namespace, using a ConfigMap as the lock because Lease objects aren't served by
+// every supported Kubernetes version.  Leadership is renewed in the background
+// for as long as the process runs; if it is ever lost, onLost is invoked, which should stop this instance
+// from serving requests (typically by exiting so the pod restarts as a standby).
+func (p *Plugin) WaitForLeadership(identity string, onLost func()) {
+
+	lock := &resourcelock.ConfigMapLock{
+		ConfigMapMeta: metav1.ObjectMeta{
+			Name:      LeaderElectionLockName,
+			Namespace: p.namespace,
+		},
+		Client: p.kubeClient.CoreV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+
+	logFields := log.Fields{
+		"identity":  identity,
+		"lock":      LeaderElectionLockName,
+		"namespace": p.namespace,
+	}
+
+	elected := make(chan struct{})
+
+	go leaderelection.RunOrDie(context.Background(), leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: LeaderElectionLeaseDuration,
+		RenewDeadline: LeaderElectionRenewDeadline,
+		RetryPeriod:   LeaderElectionRetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.WithFields(logFields).Info("Elected leader.")
+				close(elected)
+			},
+			OnStoppedLeading: func() {
+				log.WithFields(logFields).Error("Lost leadership.")
+				onLost()
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					log.WithFields(logFields).WithField("leader", leader).Info("Waiting as a standby replica.")
+				}
+			},
+		},
+	})
+
+	log.WithFields(logFields).Info("Waiting to be elected leader.")
+	<-elected
+}
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
 	k8sstoragev1 "k8s.io/api/storage/v1"
 	k8sstoragev1beta "k8s.io/api/storage/v1beta1"
@@ -55,6 +59,17 @@ type Plugin struct {
 	namespace     string
 	eventRecorder record.EventRecorder
 
+	cacheSyncPeriod  time.Duration
+	resizeSyncPeriod time.Duration
+	cacheBackoff     CacheBackoffConfig
+
+	// provisioners are the storage class provisioner names handled by Trident; if empty, only
+	// the CSI provisioner is handled
+	provisioners []string
+
+	// skipLegacyPVDeletion leaves released legacy PVs and their volumes in place, only logging them
+	skipLegacyPVDeletion bool
+
 	pvcIndexer            cache.Indexer
 	pvcController         cache.SharedIndexInformer
 	pvcControllerStopChan chan struct{}
@@ -69,10 +84,23 @@ type Plugin struct {
 	scController         cache.SharedIndexInformer
 	scControllerStopChan chan struct{}
 	scSource             cache.ListerWatcher
+
+	nodeIndexer            cache.Indexer
+	nodeController         cache.SharedIndexInformer
+	nodeControllerStopChan chan struct{}
+	nodeSource             cache.ListerWatcher
 }
 
-// NewPlugin instantiates this plugin when running outside a pod.
-func NewPlugin(o core.Orchestrator, apiServerIP, kubeConfigPath string) (*Plugin, error) {
+// NewPlugin instantiates this plugin when running outside a pod.  Zero-valued sync periods
+// are replaced by CacheSyncPeriod and ResizeSyncPeriod, respectively, and zero-valued cache
+// backoff parameters by their defaults.  Storage classes naming any of the provisioners are
+// handled, or only those naming the CSI provisioner if none are specified.  If
+// skipLegacyPVDeletion is set, released legacy PVs are logged rather than deleted.  Events are
+// attributed to eventComponent, or to the CSI provisioner if it is empty.
+func NewPlugin(
+	o core.Orchestrator, apiServerIP, kubeConfigPath string, cacheSyncPeriod, resizeSyncPeriod time.Duration,
+	cacheBackoff CacheBackoffConfig, provisioners []string, skipLegacyPVDeletion bool, eventComponent string,
+) (*Plugin, error) {
 
 	kubeConfig, err := clientcmd.BuildConfigFromFlags(apiServerIP, kubeConfigPath)
 	if err != nil {
@@ -80,17 +108,26 @@ func NewPlugin(o core.Orchestrator, apiServerIP, kubeConfigPath string) (*Plugin
 	}
 
 	// Create the CLI-based Kubernetes client
-	client, err := clik8sclient.NewKubectlClient("")
+	client, err := clik8sclient.NewKubectlClient("", "")
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize Kubernetes client: %v", err)
 	}
 
 	// When running in binary mode, we use the current namespace as determined by the CLI client
-	return newKubernetesPlugin(o, kubeConfig, client.Namespace())
+	return newKubernetesPlugin(o, kubeConfig, client.Namespace(), cacheSyncPeriod, resizeSyncPeriod,
+		cacheBackoff, provisioners, skipLegacyPVDeletion, eventComponent)
 }
 
-// NewPluginInCluster instantiates this plugin when running inside a pod.
-func NewPluginInCluster(o core.Orchestrator) (*Plugin, error) {
+// NewPluginInCluster instantiates this plugin when running inside a pod.  Zero-valued sync
+// periods are replaced by CacheSyncPeriod and ResizeSyncPeriod, respectively, and zero-valued
+// cache backoff parameters by their defaults.  Storage classes naming any of the provisioners
+// are handled, or only those naming the CSI provisioner if none are specified.  If
+// skipLegacyPVDeletion is set, released legacy PVs are logged rather than deleted.  Events are
+// attributed to eventComponent, or to the CSI provisioner if it is empty.
+func NewPluginInCluster(
+	o core.Orchestrator, cacheSyncPeriod, resizeSyncPeriod time.Duration, cacheBackoff CacheBackoffConfig,
+	provisioners []string, skipLegacyPVDeletion bool, eventComponent string,
+) (*Plugin, error) {
 
 	kubeConfig, err := rest.InClusterConfig()
 	if err != nil {
@@ -107,15 +144,126 @@ func NewPluginInCluster(o core.Orchestrator) (*Plugin, error) {
 		return nil, err
 	}
 
-	return newKubernetesPlugin(o, kubeConfig, string(namespaceBytes))
+	return newKubernetesPlugin(o, kubeConfig, string(namespaceBytes), cacheSyncPeriod, resizeSyncPeriod,
+		cacheBackoff, provisioners, skipLegacyPVDeletion, eventComponent)
+}
+
+// getSyncPeriods applies the defaults to any unset informer resync periods and ensures the
+// resize handler does not resync more often than the caches it is layered upon.
+func getSyncPeriods(cacheSyncPeriod, resizeSyncPeriod time.Duration) (time.Duration, time.Duration, error) {
+
+	if cacheSyncPeriod == 0 {
+		cacheSyncPeriod = CacheSyncPeriod
+	}
+	if resizeSyncPeriod == 0 {
+		resizeSyncPeriod = ResizeSyncPeriod
+	}
+
+	if cacheSyncPeriod < 0 {
+		return 0, 0, fmt.Errorf("cache sync period may not be negative: %v", cacheSyncPeriod)
+	}
+	if resizeSyncPeriod < cacheSyncPeriod {
+		return 0, 0, fmt.Errorf("resize sync period (%v) may not be shorter than the cache sync period (%v)",
+			resizeSyncPeriod, cacheSyncPeriod)
+	}
+
+	return cacheSyncPeriod, resizeSyncPeriod, nil
+}
+
+// withDefaults returns a copy of the backoff configuration with any unset fields replaced by
+// the defaults.
+func (c CacheBackoffConfig) withDefaults() CacheBackoffConfig {
+	if c.InitialInterval == 0 {
+		c.InitialInterval = CacheBackoffInitialInterval
+	}
+	if c.Multiplier == 0 {
+		c.Multiplier = CacheBackoffMultiplier
+	}
+	if c.MaxInterval == 0 {
+		c.MaxInterval = CacheBackoffMaxInterval
+	}
+	return c
+}
+
+// getCacheBackoffConfig applies the defaults to any unset cache backoff parameters and ensures
+// the resulting backoff is well formed.
+func getCacheBackoffConfig(c CacheBackoffConfig) (CacheBackoffConfig, error) {
+
+	c = c.withDefaults()
+
+	if c.InitialInterval < 0 {
+		return c, fmt.Errorf("cache backoff initial interval may not be negative: %v", c.InitialInterval)
+	}
+	if c.Multiplier < 1 {
+		return c, fmt.Errorf("cache backoff multiplier may not be less than 1: %v", c.Multiplier)
+	}
+	if c.MaxInterval < c.InitialInterval {
+		return c, fmt.Errorf("cache backoff max interval (%v) may not be shorter than the initial interval (%v)",
+			c.MaxInterval, c.InitialInterval)
+	}
+
+	return c, nil
+}
+
+// getProvisioners returns the distinct, non-empty provisioner names in a list, defaulting to
+// the CSI provisioner if there are none.
+func getProvisioners(provisioners []string) []string {
+
+	result := make([]string, 0, len(provisioners))
+	seen := make(map[string]bool)
+	for _, provisioner := range provisioners {
+		provisioner = strings.TrimSpace(provisioner)
+		if provisioner == "" || seen[provisioner] {
+			continue
+		}
+		seen[provisioner] = true
+		result = append(result, provisioner)
+	}
+
+	if len(result) == 0 {
+		result = append(result, csi.Provisioner)
+	}
+	return result
+}
+
+// newEventRecorder returns a recorder for events attributed to a component, defaulting to the
+// CSI provisioner, so that events from different Trident deployments can be told apart.
+func newEventRecorder(broadcaster record.EventBroadcaster, component string) record.EventRecorder {
+
+	if component = strings.TrimSpace(component); component == "" {
+		component = csi.Provisioner
+	}
+	return broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: component})
+}
+
+// handlesProvisioner reports whether Trident handles storage classes naming a provisioner.
+func (p *Plugin) handlesProvisioner(provisioner string) bool {
+	for _, handled := range getProvisioners(p.provisioners) {
+		if provisioner == handled {
+			return true
+		}
+	}
+	return false
 }
 
 // newKubernetesPlugin initializes this plugin, checks the K8S verison, and sets up the watchers for
 // various Kubernetes objects.
-func newKubernetesPlugin(orchestrator core.Orchestrator, kubeConfig *rest.Config, namespace string) (*Plugin, error) {
+func newKubernetesPlugin(
+	orchestrator core.Orchestrator, kubeConfig *rest.Config, namespace string,
+	cacheSyncPeriod, resizeSyncPeriod time.Duration, cacheBackoff CacheBackoffConfig, provisioners []string,
+	skipLegacyPVDeletion bool, eventComponent string,
+) (*Plugin, error) {
 
 	log.WithField("namespace", namespace).Info("Initializing K8S helper frontend.")
 
+	cacheSyncPeriod, resizeSyncPeriod, err := getSyncPeriods(cacheSyncPeriod, resizeSyncPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("K8S helper frontend has an invalid configuration: %v", err)
+	}
+	if cacheBackoff, err = getCacheBackoffConfig(cacheBackoff); err != nil {
+		return nil, fmt.Errorf("K8S helper frontend has an invalid configuration: %v", err)
+	}
+
 	// Create the Kubernetes client
 	kubeClient, err := kubernetes.NewForConfig(kubeConfig)
 	if err != nil {
@@ -129,14 +277,20 @@ func newKubernetesPlugin(orchestrator core.Orchestrator, kubeConfig *rest.Config
 	}
 
 	p := &Plugin{
-		orchestrator:          orchestrator,
-		kubeConfig:            *kubeConfig,
-		kubeClient:            kubeClient,
-		kubeVersion:           kubeVersion,
-		pvcControllerStopChan: make(chan struct{}),
-		pvControllerStopChan:  make(chan struct{}),
-		scControllerStopChan:  make(chan struct{}),
-		namespace:             namespace,
+		orchestrator:           orchestrator,
+		kubeConfig:             *kubeConfig,
+		kubeClient:             kubeClient,
+		kubeVersion:            kubeVersion,
+		pvcControllerStopChan:  make(chan struct{}),
+		pvControllerStopChan:   make(chan struct{}),
+		scControllerStopChan:   make(chan struct{}),
+		nodeControllerStopChan: make(chan struct{}),
+		namespace:              namespace,
+		cacheSyncPeriod:        cacheSyncPeriod,
+		resizeSyncPeriod:       resizeSyncPeriod,
+		cacheBackoff:           cacheBackoff,
+		provisioners:           getProvisioners(provisioners),
+		skipLegacyPVDeletion:   skipLegacyPVDeletion,
 	}
 
 	log.WithFields(log.Fields{
@@ -151,7 +305,7 @@ func newKubernetesPlugin(orchestrator core.Orchestrator, kubeConfig *rest.Config
 	// Set up event broadcaster
 	broadcaster := record.NewBroadcaster()
 	broadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
-	p.eventRecorder = broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: csi.Provisioner})
+	p.eventRecorder = newEventRecorder(broadcaster, eventComponent)
 
 	// Set up a watch for PVCs
 	p.pvcSource = &cache.ListWatch{
@@ -167,7 +321,7 @@ func newKubernetesPlugin(orchestrator core.Orchestrator, kubeConfig *rest.Config
 	p.pvcController = cache.NewSharedIndexInformer(
 		p.pvcSource,
 		&v1.PersistentVolumeClaim{},
-		CacheSyncPeriod,
+		p.cacheSyncPeriod,
 		cache.Indexers{uidIndex: MetaUIDKeyFunc},
 	)
 	p.pvcIndexer = p.pvcController.GetIndexer()
@@ -184,7 +338,7 @@ func newKubernetesPlugin(orchestrator core.Orchestrator, kubeConfig *rest.Config
 		cache.ResourceEventHandlerFuncs{
 			UpdateFunc: p.updatePVCResize,
 		},
-		ResizeSyncPeriod,
+		p.resizeSyncPeriod,
 	)
 
 	// Set up a watch for PVs
@@ -201,7 +355,7 @@ func newKubernetesPlugin(orchestrator core.Orchestrator, kubeConfig *rest.Config
 	p.pvController = cache.NewSharedIndexInformer(
 		p.pvSource,
 		&v1.PersistentVolume{},
-		CacheSyncPeriod,
+		p.cacheSyncPeriod,
 		cache.Indexers{uidIndex: MetaUIDKeyFunc},
 	)
 	p.pvIndexer = p.pvController.GetIndexer()
@@ -227,7 +381,7 @@ func newKubernetesPlugin(orchestrator core.Orchestrator, kubeConfig *rest.Config
 	p.scController = cache.NewSharedIndexInformer(
 		p.scSource,
 		&k8sstoragev1.StorageClass{},
-		CacheSyncPeriod,
+		p.cacheSyncPeriod,
 		cache.Indexers{uidIndex: MetaUIDKeyFunc},
 	)
 	p.scIndexer = p.scController.GetIndexer()
@@ -250,6 +404,34 @@ func newKubernetesPlugin(orchestrator core.Orchestrator, kubeConfig *rest.Config
 		},
 	)
 
+	// Set up a watch for nodes
+	p.nodeSource = &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.CoreV1().Nodes().List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.CoreV1().Nodes().Watch(options)
+		},
+	}
+
+	// Set up the node indexing controller
+	p.nodeController = cache.NewSharedIndexInformer(
+		p.nodeSource,
+		&v1.Node{},
+		p.cacheSyncPeriod,
+		cache.Indexers{uidIndex: MetaUIDKeyFunc},
+	)
+	p.nodeIndexer = p.nodeController.GetIndexer()
+
+	// Add handlers for nodes
+	p.nodeController.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    p.addNode,
+			UpdateFunc: p.updateNode,
+			DeleteFunc: p.deleteNode,
+		},
+	)
+
 	return p, nil
 }
 
@@ -271,10 +453,14 @@ func MetaUIDKeyFunc(obj interface{}) ([]string, error) {
 
 // Activate starts this Trident frontend.
 func (p *Plugin) Activate() error {
-	log.Info("Activating K8S helper frontend.")
+	log.WithFields(log.Fields{
+		"cacheSyncPeriod":  p.cacheSyncPeriod,
+		"resizeSyncPeriod": p.resizeSyncPeriod,
+	}).Info("Activating K8S helper frontend.")
 	go p.pvcController.Run(p.pvcControllerStopChan)
 	go p.pvController.Run(p.pvControllerStopChan)
 	go p.scController.Run(p.scControllerStopChan)
+	go p.nodeController.Run(p.nodeControllerStopChan)
 
 	// Configure telemetry
 	config.OrchestratorTelemetry.Platform = string(config.PlatformKubernetes)
@@ -289,6 +475,7 @@ func (p *Plugin) Deactivate() error {
 	close(p.pvcControllerStopChan)
 	close(p.pvControllerStopChan)
 	close(p.scControllerStopChan)
+	close(p.nodeControllerStopChan)
 	return nil
 }
 
@@ -384,6 +571,19 @@ func (p *Plugin) getCachedPVCByName(name, namespace string) (*v1.PersistentVolum
 	}
 }
 
+// newCacheBackoff returns the backoff used while waiting for an object to appear in one of the
+// caches, giving up after the specified duration.
+func (p *Plugin) newCacheBackoff(maxElapsedTime time.Duration) *backoff.ExponentialBackOff {
+	backoffConfig := p.cacheBackoff.withDefaults()
+	cacheBackoff := backoff.NewExponentialBackOff()
+	cacheBackoff.InitialInterval = backoffConfig.InitialInterval
+	cacheBackoff.RandomizationFactor = CacheBackoffRandomizationFactor
+	cacheBackoff.Multiplier = backoffConfig.Multiplier
+	cacheBackoff.MaxInterval = backoffConfig.MaxInterval
+	cacheBackoff.MaxElapsedTime = maxElapsedTime
+	return cacheBackoff
+}
+
 // waitForCachedPVCByUID returns a PVC (identified by namespace/name) from the client's cache, waiting in a
 // backoff loop for the specified duration for the PVC to become available.
 func (p *Plugin) waitForCachedPVCByName(
@@ -404,12 +604,7 @@ func (p *Plugin) waitForCachedPVCByName(
 			"increment": duration,
 		}).Debugf("PVC not yet in cache, waiting.")
 	}
-	pvcBackoff := backoff.NewExponentialBackOff()
-	pvcBackoff.InitialInterval = CacheBackoffInitialInterval
-	pvcBackoff.RandomizationFactor = CacheBackoffRandomizationFactor
-	pvcBackoff.Multiplier = CacheBackoffMultiplier
-	pvcBackoff.MaxInterval = CacheBackoffMaxInterval
-	pvcBackoff.MaxElapsedTime = maxElapsedTime
+	pvcBackoff := p.newCacheBackoff(maxElapsedTime)
 
 	if err := backoff.RetryNotify(checkForCachedPVC, pvcBackoff, pvcNotify); err != nil {
 		return nil, fmt.Errorf("PVC %s/%s was not cache after %3.2f seconds",
@@ -448,7 +643,9 @@ func (p *Plugin) getCachedPVCByUID(uid string) (*v1.PersistentVolumeClaim, error
 
 // waitForCachedPVCByUID returns a PVC (identified by UID) from the client's cache, waiting in a
 // backoff loop for the specified duration for the PVC to become available.
-func (p *Plugin) waitForCachedPVCByUID(uid string, maxElapsedTime time.Duration) (*v1.PersistentVolumeClaim, error) {
+func (p *Plugin) waitForCachedPVCByUID(
+	ctx context.Context, uid string, maxElapsedTime time.Duration,
+) (*v1.PersistentVolumeClaim, error) {
 
 	var pvc *v1.PersistentVolumeClaim
 
@@ -461,14 +658,10 @@ func (p *Plugin) waitForCachedPVCByUID(uid string, maxElapsedTime time.Duration)
 		log.WithFields(log.Fields{
 			"uid":       uid,
 			"increment": duration,
+			"requestID": csi.GetRequestID(ctx),
 		}).Debugf("PVC not yet in cache, waiting.")
 	}
-	pvcBackoff := backoff.NewExponentialBackOff()
-	pvcBackoff.InitialInterval = CacheBackoffInitialInterval
-	pvcBackoff.RandomizationFactor = CacheBackoffRandomizationFactor
-	pvcBackoff.Multiplier = CacheBackoffMultiplier
-	pvcBackoff.MaxInterval = CacheBackoffMaxInterval
-	pvcBackoff.MaxElapsedTime = maxElapsedTime
+	pvcBackoff := p.newCacheBackoff(maxElapsedTime)
 
 	if err := backoff.RetryNotify(checkForCachedPVC, pvcBackoff, pvcNotify); err != nil {
 		return nil, fmt.Errorf("PVC %s was not cache after %3.2f seconds", uid, maxElapsedTime.Seconds())
@@ -517,7 +710,7 @@ func (p *Plugin) deleteStorageClass(obj interface{}) {
 func (p *Plugin) processStorageClass(sc *k8sstoragev1.StorageClass, eventType string) {
 
 	// Validate the storage class
-	if sc.Provisioner != csi.Provisioner {
+	if !p.handlesProvisioner(sc.Provisioner) {
 		return
 	}
 
@@ -538,6 +731,14 @@ func (p *Plugin) processStorageClass(sc *k8sstoragev1.StorageClass, eventType st
 			log.WithFields(logFields).Warn("K8S helper has no record of the updated " +
 				"storage class; instead it will try to create it.")
 			p.processAddedStorageClass(sc)
+		} else if storageClassPoliciesChanged(sc, storageClass.Config) {
+			// Only these fields of a storage class may change, so replace Trident's record of it
+			log.WithFields(logFields).Debug("K8S helper is updating the storage class policies.")
+			if err := p.orchestrator.DeleteStorageClass(sc.Name); err != nil {
+				log.WithFields(logFields).Errorf("K8S helper could not update the storage class; %v", err)
+				return
+			}
+			p.processAddedStorageClass(sc)
 		}
 	case eventDelete:
 		log.WithFields(logFields).Debug("Storage class deleted from cache.")
@@ -545,18 +746,66 @@ func (p *Plugin) processStorageClass(sc *k8sstoragev1.StorageClass, eventType st
 	}
 }
 
+// storageClassPoliciesChanged reports whether the reclaim policy or volume expansion setting of a
+// storage class differ from those Trident recorded.
+func storageClassPoliciesChanged(sc *k8sstoragev1.StorageClass, scConfig *storageclass.Config) bool {
+
+	reclaimPolicy := ""
+	if sc.ReclaimPolicy != nil {
+		reclaimPolicy = string(*sc.ReclaimPolicy)
+	}
+	if reclaimPolicy != scConfig.ReclaimPolicy {
+		return true
+	}
+	if (sc.AllowVolumeExpansion == nil) != (scConfig.AllowVolumeExpansion == nil) {
+		return true
+	}
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion != *scConfig.AllowVolumeExpansion
+}
+
 // processAddedStorageClass informs the orchestrator of a new storage class.
 func (p *Plugin) processAddedStorageClass(sc *k8sstoragev1.StorageClass) {
 
 	scConfig := new(storageclass.Config)
 	scConfig.Name = sc.Name
 	scConfig.Attributes = make(map[string]storageattribute.Request)
+	scConfig.AllowVolumeExpansion = sc.AllowVolumeExpansion
+	if sc.ReclaimPolicy != nil {
+		scConfig.ReclaimPolicy = string(*sc.ReclaimPolicy)
+	}
 
 	// Populate storage class config attributes and backend storage pools
 	for k, v := range sc.Parameters {
 		switch k {
 		case K8sFsType:
-			// Ignore Kubernetes-defined storage class parameters handled by CSI
+			// The class's default file system, for requests that don't specify one
+			scConfig.FileSystem = v
+
+		case SnapshotRetentionMaxAge:
+			// format:  snapshotRetentionMaxAge: "720h"
+			if scConfig.SnapshotRetention == nil {
+				scConfig.SnapshotRetention = &storageclass.SnapshotRetention{}
+			}
+			scConfig.SnapshotRetention.MaxAge = v
+
+		case SnapshotRetentionMaxCount:
+			// format:  snapshotRetentionMaxCount: "10"
+			maxCount, err := strconv.Atoi(v)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"name":        sc.Name,
+					"provisioner": sc.Provisioner,
+					"parameters":  sc.Parameters,
+					"error":       err,
+				}).Errorf("K8S helper could not process the storage class parameter %s", k)
+				p.eventRecorder.Eventf(sc, v1.EventTypeWarning, "InvalidParameter",
+					"could not process the storage class parameter %s: %v", k, err)
+				continue
+			}
+			if scConfig.SnapshotRetention == nil {
+				scConfig.SnapshotRetention = &storageclass.SnapshotRetention{}
+			}
+			scConfig.SnapshotRetention.MaxCount = maxCount
 
 		case storageattribute.RequiredStorage, storageattribute.AdditionalStoragePools:
 			// format:  additionalStoragePools: "backend1:pool1,pool2;backend2:pool1"
@@ -568,6 +817,8 @@ func (p *Plugin) processAddedStorageClass(sc *k8sstoragev1.StorageClass) {
 					"parameters":  sc.Parameters,
 					"error":       err,
 				}).Errorf("K8S helper could not process the storage class parameter %s", k)
+				p.eventRecorder.Eventf(sc, v1.EventTypeWarning, "InvalidParameter",
+					"could not process the storage class parameter %s: %v", k, err)
 			}
 			scConfig.AdditionalPools = additionalPools
 
@@ -581,6 +832,8 @@ func (p *Plugin) processAddedStorageClass(sc *k8sstoragev1.StorageClass) {
 					"parameters":  sc.Parameters,
 					"error":       err,
 				}).Errorf("K8S helper could not process the storage class parameter %s", k)
+				p.eventRecorder.Eventf(sc, v1.EventTypeWarning, "InvalidParameter",
+					"could not process the storage class parameter %s: %v", k, err)
 			}
 			scConfig.ExcludePools = excludeStoragePools
 
@@ -594,6 +847,8 @@ func (p *Plugin) processAddedStorageClass(sc *k8sstoragev1.StorageClass) {
 					"parameters":  sc.Parameters,
 					"error":       err,
 				}).Errorf("K8S helper could not process the storage class parameter %s", k)
+				p.eventRecorder.Eventf(sc, v1.EventTypeWarning, "InvalidParameter",
+					"could not process the storage class parameter %s: %v", k, err)
 			}
 			scConfig.Pools = pools
 
@@ -607,6 +862,8 @@ func (p *Plugin) processAddedStorageClass(sc *k8sstoragev1.StorageClass) {
 					"parameters":  sc.Parameters,
 					"error":       err,
 				}).Errorf("K8S helper could not process the storage class attribute %s", k)
+				p.eventRecorder.Eventf(sc, v1.EventTypeWarning, "InvalidParameter",
+					"could not process the storage class attribute %s: %v", k, err)
 				return
 			}
 			scConfig.Attributes[k] = req
@@ -614,12 +871,15 @@ func (p *Plugin) processAddedStorageClass(sc *k8sstoragev1.StorageClass) {
 	}
 
 	// Add the storage class
-	if _, err := p.orchestrator.AddStorageClass(scConfig); err != nil {
+	scExternal, err := p.orchestrator.AddStorageClass(scConfig)
+	if err != nil {
 		log.WithFields(log.Fields{
 			"name":        sc.Name,
 			"provisioner": sc.Provisioner,
 			"parameters":  sc.Parameters,
 		}).Warningf("K8S helper could not add a storage class: %s", err)
+		p.eventRecorder.Eventf(sc, v1.EventTypeWarning, "RegistrationFailed",
+			"could not register the storage class with %s: %v", config.OrchestratorName, err)
 		return
 	}
 
@@ -628,6 +888,43 @@ func (p *Plugin) processAddedStorageClass(sc *k8sstoragev1.StorageClass) {
 		"provisioner": sc.Provisioner,
 		"parameters":  sc.Parameters,
 	}).Info("K8S helper added a storage class.")
+
+	p.checkStorageClassExpansion(sc, scExternal)
+}
+
+// checkStorageClassExpansion warns if a storage class allows volume expansion but matches backends
+// whose volumes can't be resized.  Only NFS volumes may be resized, since resizing block volumes
+// requires a host-side component.
+func (p *Plugin) checkStorageClassExpansion(sc *k8sstoragev1.StorageClass, scExternal *storageclass.External) {
+
+	if sc.AllowVolumeExpansion == nil || !*sc.AllowVolumeExpansion || scExternal == nil {
+		return
+	}
+
+	backendNames := make([]string, 0)
+	for backendName := range scExternal.StoragePools {
+		backend, err := p.orchestrator.GetBackend(backendName)
+		if err != nil {
+			log.WithField("backend", backendName).Debugf("K8S helper could not get backend; %v", err)
+			continue
+		}
+		if backend.Protocol != config.File {
+			backendNames = append(backendNames, backendName)
+		}
+	}
+	if len(backendNames) == 0 {
+		return
+	}
+	sort.Strings(backendNames)
+
+	log.WithFields(log.Fields{
+		"name":     sc.Name,
+		"backends": backendNames,
+	}).Warning("K8S helper found a storage class that allows volume expansion, but some of its " +
+		"backends can't expand volumes.")
+	p.eventRecorder.Eventf(sc, v1.EventTypeWarning, "ExpansionUnsupported",
+		"storage class allows volume expansion, but backends %s can't expand volumes",
+		strings.Join(backendNames, ","))
 }
 
 // processDeletedStorageClass informs the orchestrator of a deleted storage class.
@@ -685,12 +982,7 @@ func (p *Plugin) waitForCachedStorageClassByName(
 			"increment": duration,
 		}).Debugf("Storage class not yet in cache, waiting.")
 	}
-	scBackoff := backoff.NewExponentialBackOff()
-	scBackoff.InitialInterval = CacheBackoffInitialInterval
-	scBackoff.RandomizationFactor = CacheBackoffRandomizationFactor
-	scBackoff.Multiplier = CacheBackoffMultiplier
-	scBackoff.MaxInterval = CacheBackoffMaxInterval
-	scBackoff.MaxElapsedTime = maxElapsedTime
+	scBackoff := p.newCacheBackoff(maxElapsedTime)
 
 	if err := backoff.RetryNotify(checkForCachedSC, scBackoff, scNotify); err != nil {
 		return nil, fmt.Errorf("storage class %s was not cache after %3.2f seconds", name, maxElapsedTime.Seconds())
@@ -698,3 +990,121 @@ func (p *Plugin) waitForCachedStorageClassByName(
 
 	return sc, nil
 }
+
+// addNode is the add handler for the node watcher.
+func (p *Plugin) addNode(obj interface{}) {
+	switch node := obj.(type) {
+	case *v1.Node:
+		p.processNode(node, eventAdd)
+	default:
+		log.Errorf("K8S helper expected Node; got %v", obj)
+	}
+}
+
+// updateNode is the update handler for the node watcher.
+func (p *Plugin) updateNode(oldObj, newObj interface{}) {
+	switch node := newObj.(type) {
+	case *v1.Node:
+		p.processNode(node, eventUpdate)
+	default:
+		log.Errorf("K8S helper expected Node; got %v", newObj)
+	}
+}
+
+// deleteNode is the delete handler for the node watcher.
+func (p *Plugin) deleteNode(obj interface{}) {
+	switch node := obj.(type) {
+	case *v1.Node:
+		p.processNode(node, eventDelete)
+	default:
+		log.Errorf("K8S helper expected Node; got %v", obj)
+	}
+}
+
+// processNode logs the add/update/delete node events.
+func (p *Plugin) processNode(node *v1.Node, eventType string) {
+
+	topologyLabels := getNodeTopologyLabels(node)
+	logFields := log.Fields{
+		"name":   node.Name,
+		"uid":    node.UID,
+		"region": topologyLabels[K8sTopologyRegionLabel],
+		"zone":   topologyLabels[K8sTopologyZoneLabel],
+	}
+
+	switch eventType {
+	case eventAdd:
+		log.WithFields(logFields).Debug("Node added to cache.")
+	case eventUpdate:
+		log.WithFields(logFields).Debug("Node updated in cache.")
+	case eventDelete:
+		log.WithFields(logFields).Debug("Node deleted from cache.")
+	}
+}
+
+// getNodeTopologyLabels returns a node's region and zone labels, keyed by the topology.kubernetes.io
+// names.  Kubernetes releases before 1.17 only set the failure-domain.beta.kubernetes.io labels, so
+// those are used for any topology.kubernetes.io label the node lacks.
+func getNodeTopologyLabels(node *v1.Node) map[string]string {
+
+	topologyLabels := make(map[string]string)
+	for _, keys := range [][2]string{
+		{K8sTopologyRegionLabel, K8sTopologyRegionBetaLabel},
+		{K8sTopologyZoneLabel, K8sTopologyZoneBetaLabel},
+	} {
+		if value, ok := node.Labels[keys[0]]; ok {
+			topologyLabels[keys[0]] = value
+		} else if value, ok := node.Labels[keys[1]]; ok {
+			topologyLabels[keys[0]] = value
+		}
+	}
+	return topologyLabels
+}
+
+// getCachedNodeByName returns a node (identified by name) from the client's cache,
+// or an error if not found.  In most cases it may be better to call waitForCachedNodeByName().
+func (p *Plugin) getCachedNodeByName(name string) (*v1.Node, error) {
+
+	logFields := log.Fields{"name": name}
+
+	item, exists, err := p.nodeIndexer.GetByKey(name)
+	if err != nil {
+		log.WithFields(logFields).Error("Could not search cache for node by name.")
+		return nil, fmt.Errorf("could not search cache for node %s: %v", name, err)
+	} else if !exists {
+		log.WithFields(logFields).Debug("Node object not found in cache by name.")
+		return nil, fmt.Errorf("node %s not found in cache", name)
+	} else if node, ok := item.(*v1.Node); !ok {
+		log.WithFields(logFields).Error("Non-node cached object found by name.")
+		return nil, fmt.Errorf("non-node object %s found in cache", name)
+	} else {
+		log.WithFields(logFields).Debug("Found cached node by name.")
+		return node, nil
+	}
+}
+
+// waitForCachedNodeByName returns a node (identified by name) from the client's cache,
+// waiting in a backoff loop for the specified duration for the node to become available.
+func (p *Plugin) waitForCachedNodeByName(name string, maxElapsedTime time.Duration) (*v1.Node, error) {
+
+	var node *v1.Node
+
+	checkForCachedNode := func() error {
+		var nodeError error
+		node, nodeError = p.getCachedNodeByName(name)
+		return nodeError
+	}
+	nodeNotify := func(err error, duration time.Duration) {
+		log.WithFields(log.Fields{
+			"name":      name,
+			"increment": duration,
+		}).Debugf("Node not yet in cache, waiting.")
+	}
+	nodeBackoff := p.newCacheBackoff(maxElapsedTime)
+
+	if err := backoff.RetryNotify(checkForCachedNode, nodeBackoff, nodeNotify); err != nil {
+		return nil, fmt.Errorf("node %s was not cached after %3.2f seconds", name, maxElapsedTime.Seconds())
+	}
+
+	return node, nil
+}
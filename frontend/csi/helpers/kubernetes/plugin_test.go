@@ -0,0 +1,729 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package kubernetes
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	k8sstoragev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/netapp/trident/core"
+	"github.com/netapp/trident/frontend/csi"
+	"github.com/netapp/trident/storage"
+	storageclass "github.com/netapp/trident/storage_class"
+)
+
+// failingOrchestrator is a mock orchestrator that refuses to add storage classes.
+type failingOrchestrator struct {
+	*core.MockOrchestrator
+}
+
+func (o *failingOrchestrator) AddStorageClass(scConfig *storageclass.Config) (*storageclass.External, error) {
+	return nil, errors.New("storage class rejected")
+}
+
+func newTestStorageClass(parameters map[string]string) *k8sstoragev1.StorageClass {
+	return &k8sstoragev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "basic"},
+		Provisioner: csi.Provisioner,
+		Parameters:  parameters,
+	}
+}
+
+func getRecordedEvent(t *testing.T, recorder *record.FakeRecorder) string {
+	select {
+	case event := <-recorder.Events:
+		return event
+	default:
+		t.Fatal("Expected an event to be recorded.")
+		return ""
+	}
+}
+
+func TestProcessAddedStorageClassRegistrationFailedEvent(t *testing.T) {
+
+	recorder := record.NewFakeRecorder(10)
+	p := &Plugin{
+		orchestrator:  &failingOrchestrator{core.NewMockOrchestrator()},
+		eventRecorder: recorder,
+	}
+
+	p.processAddedStorageClass(newTestStorageClass(map[string]string{"media": "hdd"}))
+
+	event := getRecordedEvent(t, recorder)
+	if !strings.HasPrefix(event, "Warning RegistrationFailed ") {
+		t.Errorf("Expected a RegistrationFailed warning event, got %s", event)
+	}
+}
+
+func TestProcessAddedStorageClassInvalidParameterEvents(t *testing.T) {
+
+	for _, parameters := range []map[string]string{
+		{"media": "hdd", "additionalStoragePools": "backend1"},
+		{"media": "hdd", "excludeStoragePools": "backend1"},
+		{"media": "hdd", "storagePools": "backend1"},
+		{"unknownAttribute": "value"},
+	} {
+		recorder := record.NewFakeRecorder(10)
+		p := &Plugin{
+			orchestrator:  core.NewMockOrchestrator(),
+			eventRecorder: recorder,
+		}
+
+		p.processAddedStorageClass(newTestStorageClass(parameters))
+
+		event := getRecordedEvent(t, recorder)
+		if !strings.HasPrefix(event, "Warning InvalidParameter ") {
+			t.Errorf("Expected an InvalidParameter warning event for %v, got %s", parameters, event)
+		}
+	}
+}
+
+func TestProcessAddedStorageClassNoEvent(t *testing.T) {
+
+	recorder := record.NewFakeRecorder(10)
+	p := &Plugin{
+		orchestrator:  core.NewMockOrchestrator(),
+		eventRecorder: recorder,
+	}
+
+	p.processAddedStorageClass(newTestStorageClass(map[string]string{"media": "hdd"}))
+
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("Expected no event to be recorded, got %s", event)
+	default:
+	}
+}
+
+func TestProcessAddedStorageClassExpansionAndReclaimPolicy(t *testing.T) {
+
+	orchestrator := core.NewMockOrchestrator()
+	p := &Plugin{
+		orchestrator:  orchestrator,
+		eventRecorder: record.NewFakeRecorder(10),
+	}
+
+	allowVolumeExpansion := false
+	reclaimPolicy := v1.PersistentVolumeReclaimRetain
+	sc := newTestStorageClass(map[string]string{"media": "hdd"})
+	sc.AllowVolumeExpansion = &allowVolumeExpansion
+	sc.ReclaimPolicy = &reclaimPolicy
+
+	p.processAddedStorageClass(sc)
+
+	storageClass, err := orchestrator.GetStorageClass(sc.Name)
+	if err != nil {
+		t.Fatalf("Unexpected error getting storage class: %v", err)
+	}
+	if storageClass.Config.AllowVolumeExpansion == nil || *storageClass.Config.AllowVolumeExpansion {
+		t.Error("Expected the storage class to disallow volume expansion")
+	}
+	if storageClass.Config.ReclaimPolicy != string(v1.PersistentVolumeReclaimRetain) {
+		t.Errorf("Expected reclaim policy Retain, got %s", storageClass.Config.ReclaimPolicy)
+	}
+}
+
+func TestProcessUpdatedStorageClassPolicies(t *testing.T) {
+
+	orchestrator := core.NewMockOrchestrator()
+	p := &Plugin{
+		orchestrator:  orchestrator,
+		eventRecorder: record.NewFakeRecorder(10),
+		provisioners:  getProvisioners(nil),
+	}
+
+	allowVolumeExpansion := false
+	sc := newTestStorageClass(map[string]string{"media": "hdd"})
+	sc.AllowVolumeExpansion = &allowVolumeExpansion
+	p.processStorageClass(sc, eventAdd)
+
+	// Kubernetes allows the volume expansion setting and reclaim policy of a storage class to change
+	updated := sc.DeepCopy()
+	allowVolumeExpansion = true
+	reclaimPolicy := v1.PersistentVolumeReclaimRetain
+	updated.AllowVolumeExpansion = &allowVolumeExpansion
+	updated.ReclaimPolicy = &reclaimPolicy
+	p.processStorageClass(updated, eventUpdate)
+
+	storageClass, err := orchestrator.GetStorageClass(sc.Name)
+	if err != nil {
+		t.Fatalf("Unexpected error getting storage class: %v", err)
+	}
+	if storageClass.Config.AllowVolumeExpansion == nil || !*storageClass.Config.AllowVolumeExpansion {
+		t.Error("Expected the updated storage class to allow volume expansion")
+	}
+	if storageClass.Config.ReclaimPolicy != string(v1.PersistentVolumeReclaimRetain) {
+		t.Errorf("Expected reclaim policy Retain, got %s", storageClass.Config.ReclaimPolicy)
+	}
+	if storageClass.Config.Attributes["media"] == nil {
+		t.Error("Expected the updated storage class to keep its attributes")
+	}
+}
+
+func TestStorageClassDefaultFsType(t *testing.T) {
+
+	orchestrator := core.NewMockOrchestrator()
+	p := &Plugin{
+		orchestrator:  orchestrator,
+		eventRecorder: record.NewFakeRecorder(10),
+	}
+
+	// The Kubernetes fsType parameter becomes the Trident storage class's default file system
+	p.processAddedStorageClass(newTestStorageClass(map[string]string{K8sFsType: "xfs"}))
+	storageClass, err := orchestrator.GetStorageClass("basic")
+	if err != nil {
+		t.Fatalf("Unexpected error getting storage class: %v", err)
+	}
+	if storageClass.Config.FileSystem != "xfs" {
+		t.Errorf("Expected storage class file system xfs, got %s", storageClass.Config.FileSystem)
+	}
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "nodefault"})
+
+	pvc := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pvc1", Namespace: "default"}}
+	accessModes := []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}
+
+	tests := []struct {
+		name      string
+		requested string
+		scName    string
+		expected  string
+	}{
+		{"request-specified", "ext3", "basic", "ext3"},
+		{"class-specified", "", "basic", "xfs"},
+		{"global default", "", "nodefault", "ext4"},
+		{"missing class", "", "missing", "ext4"},
+	}
+
+	for _, test := range tests {
+		fsType := p.getFsTypeForStorageClass(test.requested, test.scName)
+		volumeConfig := getVolumeConfig(accessModes, "pv1", resource.MustParse("1Gi"),
+			processPVCAnnotations(pvc, fsType), test.scName)
+		if volumeConfig.FileSystem != test.expected {
+			t.Errorf("%s: expected file system %s, got %s", test.name, test.expected, volumeConfig.FileSystem)
+		}
+	}
+}
+
+func TestProcessStorageClassProvisioners(t *testing.T) {
+
+	newStorageClass := func(name, provisioner string) *k8sstoragev1.StorageClass {
+		return &k8sstoragev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Provisioner: provisioner,
+		}
+	}
+
+	for _, test := range []struct {
+		name         string
+		provisioners []string
+		expected     map[string]bool
+	}{
+		{"default", nil, map[string]bool{"csi": true, "legacy": false, "other": false}},
+		{"both", []string{csi.Provisioner, csi.LegacyProvisioner},
+			map[string]bool{"csi": true, "legacy": true, "other": false}},
+	} {
+		orchestrator := core.NewMockOrchestrator()
+		p := &Plugin{
+			orchestrator:  orchestrator,
+			eventRecorder: record.NewFakeRecorder(10),
+			provisioners:  getProvisioners(test.provisioners),
+		}
+
+		p.processStorageClass(newStorageClass("csi", csi.Provisioner), eventAdd)
+		p.processStorageClass(newStorageClass("legacy", csi.LegacyProvisioner), eventAdd)
+		p.processStorageClass(newStorageClass("other", "kubernetes.io/no-provisioner"), eventAdd)
+
+		for scName, expected := range test.expected {
+			sc, _ := orchestrator.GetStorageClass(scName)
+			if registered := sc != nil; registered != expected {
+				t.Errorf("%s: expected storage class %s registered to be %v, got %v",
+					test.name, scName, expected, registered)
+			}
+		}
+	}
+}
+
+func TestGetProvisioners(t *testing.T) {
+
+	for _, c := range []struct {
+		provisioners []string
+		expected     []string
+	}{
+		{nil, []string{csi.Provisioner}},
+		{[]string{""}, []string{csi.Provisioner}},
+		{[]string{csi.LegacyProvisioner}, []string{csi.LegacyProvisioner}},
+		{[]string{csi.Provisioner, " " + csi.LegacyProvisioner, csi.Provisioner},
+			[]string{csi.Provisioner, csi.LegacyProvisioner}},
+	} {
+		if provisioners := getProvisioners(c.provisioners); !reflect.DeepEqual(provisioners, c.expected) {
+			t.Errorf("Expected provisioners %v for %v, got %v", c.expected, c.provisioners, provisioners)
+		}
+	}
+}
+
+func TestGetSyncPeriods(t *testing.T) {
+
+	for _, c := range []struct {
+		cacheSyncPeriod          time.Duration
+		resizeSyncPeriod         time.Duration
+		expectedCacheSyncPeriod  time.Duration
+		expectedResizeSyncPeriod time.Duration
+		expectError              bool
+	}{
+		{0, 0, CacheSyncPeriod, ResizeSyncPeriod, false},
+		{5 * time.Minute, 10 * time.Minute, 5 * time.Minute, 10 * time.Minute, false},
+		{2 * time.Minute, 0, 2 * time.Minute, ResizeSyncPeriod, false},
+		{5 * time.Minute, 5 * time.Minute, 5 * time.Minute, 5 * time.Minute, false},
+		{10 * time.Minute, 0, 0, 0, true},
+		{5 * time.Minute, time.Minute, 0, 0, true},
+		{-time.Minute, time.Minute, 0, 0, true},
+	} {
+		cacheSyncPeriod, resizeSyncPeriod, err := getSyncPeriods(c.cacheSyncPeriod, c.resizeSyncPeriod)
+		if c.expectError {
+			if err == nil {
+				t.Errorf("Expected an error for sync periods %v/%v", c.cacheSyncPeriod, c.resizeSyncPeriod)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for sync periods %v/%v: %v", c.cacheSyncPeriod, c.resizeSyncPeriod, err)
+			continue
+		}
+		if cacheSyncPeriod != c.expectedCacheSyncPeriod || resizeSyncPeriod != c.expectedResizeSyncPeriod {
+			t.Errorf("Expected sync periods %v/%v, got %v/%v", c.expectedCacheSyncPeriod,
+				c.expectedResizeSyncPeriod, cacheSyncPeriod, resizeSyncPeriod)
+		}
+	}
+}
+
+func TestGetCacheBackoffConfig(t *testing.T) {
+
+	defaults := CacheBackoffConfig{
+		InitialInterval: CacheBackoffInitialInterval,
+		Multiplier:      CacheBackoffMultiplier,
+		MaxInterval:     CacheBackoffMaxInterval,
+	}
+
+	for _, c := range []struct {
+		config      CacheBackoffConfig
+		expected    CacheBackoffConfig
+		expectError bool
+	}{
+		{CacheBackoffConfig{}, defaults, false},
+		{
+			CacheBackoffConfig{InitialInterval: 2 * time.Second, Multiplier: 2, MaxInterval: 30 * time.Second},
+			CacheBackoffConfig{InitialInterval: 2 * time.Second, Multiplier: 2, MaxInterval: 30 * time.Second},
+			false,
+		},
+		{
+			CacheBackoffConfig{MaxInterval: 20 * time.Second},
+			CacheBackoffConfig{
+				InitialInterval: CacheBackoffInitialInterval,
+				Multiplier:      CacheBackoffMultiplier,
+				MaxInterval:     20 * time.Second,
+			},
+			false,
+		},
+		{CacheBackoffConfig{InitialInterval: 10 * time.Second}, CacheBackoffConfig{}, true},
+		{CacheBackoffConfig{InitialInterval: 3 * time.Second, MaxInterval: time.Second}, CacheBackoffConfig{}, true},
+		{CacheBackoffConfig{InitialInterval: -time.Second}, CacheBackoffConfig{}, true},
+		{CacheBackoffConfig{Multiplier: 0.5}, CacheBackoffConfig{}, true},
+	} {
+		backoffConfig, err := getCacheBackoffConfig(c.config)
+		if c.expectError {
+			if err == nil {
+				t.Errorf("Expected an error for cache backoff %+v", c.config)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for cache backoff %+v: %v", c.config, err)
+			continue
+		}
+		if backoffConfig != c.expected {
+			t.Errorf("Expected cache backoff %+v, got %+v", c.expected, backoffConfig)
+		}
+	}
+}
+
+func TestWaitForCachedStorageClassHonorsBackoff(t *testing.T) {
+
+	p := &Plugin{
+		scIndexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+		cacheBackoff: CacheBackoffConfig{
+			InitialInterval: 10 * time.Millisecond,
+			Multiplier:      1,
+			MaxInterval:     10 * time.Millisecond,
+		},
+	}
+
+	cacheBackoff := p.newCacheBackoff(time.Minute)
+	if cacheBackoff.InitialInterval != 10*time.Millisecond || cacheBackoff.Multiplier != 1 ||
+		cacheBackoff.MaxInterval != 10*time.Millisecond || cacheBackoff.MaxElapsedTime != time.Minute {
+		t.Errorf("Backoff does not match the configured values: %+v", cacheBackoff)
+	}
+
+	// The storage class shows up well before the default initial interval has passed
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		p.scIndexer.Add(newTestStorageClass(nil))
+	}()
+
+	start := time.Now()
+	sc, err := p.waitForCachedStorageClassByName("basic", 5*time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error waiting for the storage class: %v", err)
+	}
+	if sc.Name != "basic" {
+		t.Errorf("Expected storage class basic, got %s", sc.Name)
+	}
+	if elapsed := time.Since(start); elapsed >= CacheBackoffInitialInterval {
+		t.Errorf("Expected the wait to honor the 10ms backoff, but it took %v", elapsed)
+	}
+}
+
+func TestMigrateLegacyStorageClasses(t *testing.T) {
+
+	storageClasses := []*k8sstoragev1.StorageClass{
+		{
+			ObjectMeta:  metav1.ObjectMeta{Name: "legacy1"},
+			Provisioner: csi.LegacyProvisioner,
+			Parameters:  map[string]string{"media": "hdd"},
+		},
+		{
+			ObjectMeta:  metav1.ObjectMeta{Name: "legacy2"},
+			Provisioner: csi.LegacyProvisioner,
+			Parameters:  map[string]string{"media": "ssd"},
+		},
+		{
+			ObjectMeta:  metav1.ObjectMeta{Name: "csi"},
+			Provisioner: csi.Provisioner,
+		},
+		{
+			ObjectMeta:  metav1.ObjectMeta{Name: "other"},
+			Provisioner: "kubernetes.io/no-provisioner",
+		},
+	}
+
+	client := fake.NewSimpleClientset()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{uidIndex: MetaUIDKeyFunc})
+	for _, sc := range storageClasses {
+		if _, err := client.StorageV1().StorageClasses().Create(sc); err != nil {
+			t.Fatalf("Could not create storage class %s: %v", sc.Name, err)
+		}
+		if err := indexer.Add(sc); err != nil {
+			t.Fatalf("Could not cache storage class %s: %v", sc.Name, err)
+		}
+	}
+
+	p := &Plugin{
+		orchestrator: core.NewMockOrchestrator(),
+		kubeClient:   client,
+		scIndexer:    indexer,
+	}
+
+	if err := p.MigrateLegacyStorageClasses(); err != nil {
+		t.Fatalf("Unexpected error migrating storage classes: %v", err)
+	}
+
+	expected := map[string]string{
+		"legacy1": csi.Provisioner,
+		"legacy2": csi.Provisioner,
+		"csi":     csi.Provisioner,
+		"other":   "kubernetes.io/no-provisioner",
+	}
+
+	scList, err := client.StorageV1().StorageClasses().List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Could not list storage classes: %v", err)
+	}
+	if len(scList.Items) != len(expected) {
+		t.Errorf("Expected %d storage classes, got %d", len(expected), len(scList.Items))
+	}
+	for _, sc := range scList.Items {
+		if sc.Provisioner != expected[sc.Name] {
+			t.Errorf("Expected provisioner %s for storage class %s, got %s",
+				expected[sc.Name], sc.Name, sc.Provisioner)
+		}
+	}
+
+	legacy1, err := client.StorageV1().StorageClasses().Get("legacy1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Could not get migrated storage class: %v", err)
+	}
+	if legacy1.Parameters["media"] != "hdd" {
+		t.Errorf("Expected migrated storage class to keep its parameters, got %v", legacy1.Parameters)
+	}
+}
+
+func newTestLegacyPV() *v1.PersistentVolume {
+	return &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "legacy-pv",
+			Annotations: map[string]string{AnnDynamicallyProvisioned: csi.LegacyProvisioner},
+		},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimDelete,
+		},
+		Status: v1.PersistentVolumeStatus{Phase: v1.VolumeReleased},
+	}
+}
+
+func countDeleteActions(client *fake.Clientset) int {
+	deletes := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "delete" {
+			deletes++
+		}
+	}
+	return deletes
+}
+
+func TestUpdateLegacyPV(t *testing.T) {
+
+	for _, c := range []struct {
+		skipLegacyPVDeletion bool
+		expectedDeletes      int
+	}{
+		{false, 1},
+		{true, 0},
+	} {
+		pv := newTestLegacyPV()
+		client := fake.NewSimpleClientset(pv)
+		p := &Plugin{
+			orchestrator:         core.NewMockOrchestrator(),
+			kubeClient:           client,
+			skipLegacyPVDeletion: c.skipLegacyPVDeletion,
+		}
+
+		p.updateLegacyPV(pv, pv)
+
+		if deletes := countDeleteActions(client); deletes != c.expectedDeletes {
+			t.Errorf("Expected %d delete calls with skipLegacyPVDeletion=%v, got %d",
+				c.expectedDeletes, c.skipLegacyPVDeletion, deletes)
+		}
+	}
+}
+
+func newTestPVC(name, namespace, size, pvName string, annotations map[string]string) *v1.PersistentVolumeClaim {
+	scName := "sc"
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Annotations: annotations},
+		Spec: v1.PersistentVolumeClaimSpec{
+			StorageClassName: &scName,
+			VolumeName:       pvName,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+}
+
+func newTestCSIPV(name, volumeHandle string) *v1.PersistentVolume {
+	return &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{Driver: csi.Provisioner, VolumeHandle: volumeHandle},
+			},
+		},
+	}
+}
+
+func TestGetCloneSourceInfo(t *testing.T) {
+
+	orchestrator := core.NewMockOrchestrator()
+	orchestrator.AddMockONTAPNFSBackend("nas", "10.0.0.1")
+	orchestrator.AddStorageClass(&storageclass.Config{Name: "sc"})
+	if _, err := orchestrator.AddVolume(&storage.VolumeConfig{Name: "pvc-source", StorageClass: "sc"}); err != nil {
+		t.Fatalf("Unexpected error adding volume: %v", err)
+	}
+	if _, err := orchestrator.CreateSnapshot(&storage.SnapshotConfig{Name: "snap1", VolumeName: "pvc-source"}); err != nil {
+		t.Fatalf("Unexpected error creating snapshot: %v", err)
+	}
+
+	p := &Plugin{
+		orchestrator: orchestrator,
+		pvcIndexer:   cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+		pvIndexer:    cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+	}
+	for _, pvc := range []*v1.PersistentVolumeClaim{
+		newTestPVC("source", "default", "1Gi", "pv-source", nil),
+		newTestPVC("unbound", "default", "1Gi", "", nil),
+		newTestPVC("orphan", "default", "1Gi", "pv-orphan", nil),
+	} {
+		p.pvcIndexer.Add(pvc)
+	}
+	p.pvIndexer.Add(newTestCSIPV("pv-source", "pvc-source"))
+	p.pvIndexer.Add(newTestCSIPV("pv-orphan", "pvc-missing"))
+
+	tests := []struct {
+		name           string
+		size           string
+		annotations    map[string]string
+		sourceVolume   string
+		sourceSnapshot string
+		expectError    bool
+	}{
+		{"notClone", "1Gi", nil, "", "", false},
+		{"clone", "1Gi", map[string]string{AnnCloneFromPVC: "source"}, "pvc-source", "", false},
+		{"cloneFromSnapshot", "1Gi", map[string]string{AnnCloneFromPVC: "source", AnnCloneFromSnap: "snap1"},
+			"pvc-source", "snap1", false},
+		{"snapshotWithoutPVC", "1Gi", map[string]string{AnnCloneFromSnap: "snap1"}, "", "", true},
+		{"otherNamespace", "1Gi", map[string]string{AnnCloneFromPVC: "other/source"}, "", "", true},
+		{"unboundSource", "1Gi", map[string]string{AnnCloneFromPVC: "unbound"}, "", "", true},
+		{"missingSourceVolume", "1Gi", map[string]string{AnnCloneFromPVC: "orphan"}, "", "", true},
+		{"missingSourceSnapshot", "1Gi", map[string]string{AnnCloneFromPVC: "source", AnnCloneFromSnap: "snap2"},
+			"", "", true},
+		{"cloneTooLarge", "2Gi", map[string]string{AnnCloneFromPVC: "source"}, "", "", true},
+	}
+
+	for _, test := range tests {
+		clonePVC := newTestPVC("clone", "default", test.size, "", test.annotations)
+		sourceVolume, sourceSnapshot, err := p.getCloneSourceInfo(clonePVC)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if sourceVolume != test.sourceVolume || sourceSnapshot != test.sourceSnapshot {
+			t.Errorf("%s: expected source %s/%s, got %s/%s", test.name, test.sourceVolume,
+				test.sourceSnapshot, sourceVolume, sourceSnapshot)
+		}
+	}
+}
+
+func TestNewEventRecorder(t *testing.T) {
+
+	pvc := newTestPVC("pvc1", "default", "1Gi", "", nil)
+
+	for component, expected := range map[string]string{
+		"trident-east": "trident-east",
+		"":             csi.Provisioner,
+	} {
+		broadcaster := record.NewBroadcaster()
+		events := make(chan *v1.Event, 1)
+		broadcaster.StartEventWatcher(func(event *v1.Event) { events <- event })
+
+		newEventRecorder(broadcaster, component).Event(pvc, v1.EventTypeNormal, "Provisioned", "Volume created")
+
+		select {
+		case event := <-events:
+			if event.Source.Component != expected {
+				t.Errorf("Expected event component %s for %q, got %s", expected, component,
+					event.Source.Component)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("No event was recorded for component %q", component)
+		}
+		broadcaster.Shutdown()
+	}
+}
+
+func TestGetNodeTopologyLabelsFromInformer(t *testing.T) {
+
+	nodes := []*v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "ga", Labels: map[string]string{
+			K8sTopologyRegionLabel: "us-east1",
+			K8sTopologyZoneLabel:   "us-east1-a",
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "beta", Labels: map[string]string{
+			K8sTopologyRegionBetaLabel: "us-east1",
+			K8sTopologyZoneBetaLabel:   "us-east1-b",
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "mixed", Labels: map[string]string{
+			K8sTopologyZoneLabel:       "us-east1-c",
+			K8sTopologyZoneBetaLabel:   "us-east1-x",
+			K8sTopologyRegionBetaLabel: "us-east1",
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled"}},
+	}
+
+	client := fake.NewSimpleClientset()
+	for _, node := range nodes {
+		if _, err := client.CoreV1().Nodes().Create(node); err != nil {
+			t.Fatalf("Could not create node %s: %v", node.Name, err)
+		}
+	}
+
+	p := &Plugin{
+		kubeClient: client,
+		cacheBackoff: CacheBackoffConfig{
+			InitialInterval: 10 * time.Millisecond,
+			Multiplier:      1,
+			MaxInterval:     10 * time.Millisecond,
+		},
+	}
+	p.nodeController = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.CoreV1().Nodes().List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.CoreV1().Nodes().Watch(options)
+			},
+		},
+		&v1.Node{},
+		0,
+		cache.Indexers{uidIndex: MetaUIDKeyFunc},
+	)
+	p.nodeIndexer = p.nodeController.GetIndexer()
+	p.nodeController.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    p.addNode,
+		UpdateFunc: p.updateNode,
+		DeleteFunc: p.deleteNode,
+	})
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	go p.nodeController.Run(stopChan)
+	if !cache.WaitForCacheSync(stopChan, p.nodeController.HasSynced) {
+		t.Fatal("Node cache did not sync")
+	}
+
+	expected := map[string]map[string]string{
+		"ga":        {K8sTopologyRegionLabel: "us-east1", K8sTopologyZoneLabel: "us-east1-a"},
+		"beta":      {K8sTopologyRegionLabel: "us-east1", K8sTopologyZoneLabel: "us-east1-b"},
+		"mixed":     {K8sTopologyRegionLabel: "us-east1", K8sTopologyZoneLabel: "us-east1-c"},
+		"unlabeled": {},
+	}
+	for name, expectedLabels := range expected {
+		labels, err := p.GetNodeTopologyLabels(name)
+		if err != nil {
+			t.Errorf("Unexpected error getting topology labels for node %s: %v", name, err)
+		} else if !reflect.DeepEqual(labels, expectedLabels) {
+			t.Errorf("Expected topology labels %v for node %s, got %v", expectedLabels, name, labels)
+		}
+	}
+
+	// A node added after the cache syncs is found once the informer sees it
+	late := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "late", Labels: map[string]string{
+		K8sTopologyZoneBetaLabel: "us-east1-d",
+	}}}
+	if _, err := client.CoreV1().Nodes().Create(late); err != nil {
+		t.Fatalf("Could not create node %s: %v", late.Name, err)
+	}
+	labels, err := p.GetNodeTopologyLabels(late.Name)
+	if err != nil {
+		t.Fatalf("Unexpected error getting topology labels for node %s: %v", late.Name, err)
+	}
+	if !reflect.DeepEqual(labels, map[string]string{K8sTopologyZoneLabel: "us-east1-d"}) {
+		t.Errorf("Unexpected topology labels for node %s: %v", late.Name, labels)
+	}
+}
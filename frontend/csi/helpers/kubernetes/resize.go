@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"fmt"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
@@ -70,8 +71,9 @@ func (p *Plugin) updatePVCResize(oldObj, newObj interface{}) {
 	}
 
 	// Verify the storage class is managed by Trident (all SC's will have been upgraded to the new provisioner)
-	if sc.Provisioner != csi.Provisioner {
-		log.WithField("name", scName).Warningf("The storage class provisioner is not %s.", csi.Provisioner)
+	if !p.handlesProvisioner(sc.Provisioner) {
+		log.WithField("name", scName).Warningf("The storage class provisioner is not one of %s.",
+			strings.Join(getProvisioners(p.provisioners), ", "))
 		return
 	}
 
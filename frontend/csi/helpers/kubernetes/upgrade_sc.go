@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"encoding/json"
+	"fmt"
 
 	log "github.com/sirupsen/logrus"
 	k8sstoragev1 "k8s.io/api/storage/v1"
@@ -66,10 +67,10 @@ func (p *Plugin) processLegacyStorageClass(sc *k8sstoragev1.StorageClass, eventT
 	switch eventType {
 	case eventAdd:
 		log.WithFields(logFields).Debug("Legacy storage class added to cache.")
-		p.replaceLegacyStorageClass(sc)
+		_ = p.replaceLegacyStorageClass(sc)
 	case eventUpdate:
 		log.WithFields(logFields).Debug("Legacy storage class updated in cache.")
-		p.replaceLegacyStorageClass(sc)
+		_ = p.replaceLegacyStorageClass(sc)
 	case eventDelete:
 		log.WithFields(logFields).Debug("Legacy storage class deleted from cache.")
 	}
@@ -77,7 +78,7 @@ func (p *Plugin) processLegacyStorageClass(sc *k8sstoragev1.StorageClass, eventT
 
 // replaceLegacyStorageClass replaces a storage class with the legacy Trident provisioner name (netapp.io/trident)
 // with an identical storage class with the CSI Trident provisioner name (csi.trident.netapp.io).
-func (p *Plugin) replaceLegacyStorageClass(oldSC *k8sstoragev1.StorageClass) {
+func (p *Plugin) replaceLegacyStorageClass(oldSC *k8sstoragev1.StorageClass) error {
 
 	// Clone the storage class
 	scBytes, err := json.Marshal(oldSC)
@@ -86,7 +87,7 @@ func (p *Plugin) replaceLegacyStorageClass(oldSC *k8sstoragev1.StorageClass) {
 			"name":  oldSC.Name,
 			"error": err,
 		}).Error("Could not marshal legacy storage class.")
-		return
+		return err
 	}
 
 	var newSC k8sstoragev1.StorageClass
@@ -95,7 +96,7 @@ func (p *Plugin) replaceLegacyStorageClass(oldSC *k8sstoragev1.StorageClass) {
 			"name":  oldSC.Name,
 			"error": err,
 		}).Error("Could not unmarshal legacy storage class.")
-		return
+		return err
 	}
 	newSC.Provisioner = csi.Provisioner
 	newSC.ResourceVersion = ""
@@ -107,15 +108,15 @@ func (p *Plugin) replaceLegacyStorageClass(oldSC *k8sstoragev1.StorageClass) {
 			"name":  oldSC.Name,
 			"error": err,
 		}).Error("Could not delete legacy storage class.")
-		return
+		return err
 	}
 
 	// Create the new storage class
-	if _, err := p.kubeClient.StorageV1().StorageClasses().Create(&newSC); err != nil {
+	if _, createErr := p.kubeClient.StorageV1().StorageClasses().Create(&newSC); createErr != nil {
 
 		log.WithFields(log.Fields{
 			"name":  newSC.Name,
-			"error": err,
+			"error": createErr,
 		}).Error("Could not replace storage class, attempting to restore old one.")
 
 		// Failed to create the new storage class, so try to restore the old one
@@ -127,7 +128,7 @@ func (p *Plugin) replaceLegacyStorageClass(oldSC *k8sstoragev1.StorageClass) {
 			}).Error("Could not restore storage class, please recreate it manually.")
 		}
 
-		return
+		return createErr
 	}
 
 	log.WithFields(log.Fields{
@@ -135,4 +136,49 @@ func (p *Plugin) replaceLegacyStorageClass(oldSC *k8sstoragev1.StorageClass) {
 		"oldProvisioner": oldSC.Provisioner,
 		"newProvisioner": newSC.Provisioner,
 	}).Info("Replaced storage class so it works with CSI Trident.")
+
+	return nil
+}
+
+// MigrateLegacyStorageClasses replaces every cached storage class that references the legacy Trident
+// provisioner with an equivalent one that references the CSI Trident provisioner.  Unlike the informer
+// handlers, this sweeps all storage classes in a single pass, so it may be used to complete a migration
+// deterministically after an upgrade.
+func (p *Plugin) MigrateLegacyStorageClasses() error {
+
+	migrated, failed := make([]string, 0), make([]string, 0)
+
+	for _, obj := range p.scIndexer.List() {
+
+		var sc *k8sstoragev1.StorageClass
+		switch typedSC := obj.(type) {
+		case *k8sstoragev1beta.StorageClass:
+			sc = convertStorageClassV1BetaToV1(typedSC)
+		case *k8sstoragev1.StorageClass:
+			sc = typedSC
+		default:
+			log.Errorf("K8S helper expected storage.k8s.io/v1beta1 or storage.k8s.io/v1 storage class; got %v", obj)
+			continue
+		}
+
+		if sc.Provisioner != csi.LegacyProvisioner {
+			continue
+		}
+
+		if err := p.replaceLegacyStorageClass(sc); err != nil {
+			failed = append(failed, sc.Name)
+		} else {
+			migrated = append(migrated, sc.Name)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"migrated": migrated,
+		"failed":   failed,
+	}).Infof("Migrated %d legacy storage class(es).", len(migrated))
+
+	if len(failed) > 0 {
+		return fmt.Errorf("could not migrate legacy storage class(es) %v", failed)
+	}
+	return nil
 }
@@ -166,8 +166,10 @@ func isNotManaged(annotations map[string]string, name string, kind string) bool
 func convertStorageClassV1BetaToV1(class *k8sstoragev1beta.StorageClass) *k8sstoragev1.StorageClass {
 	// For now we just copy the fields used by Trident.
 	v1Class := &k8sstoragev1.StorageClass{
-		Provisioner: class.Provisioner,
-		Parameters:  class.Parameters,
+		Provisioner:          class.Provisioner,
+		Parameters:           class.Parameters,
+		ReclaimPolicy:        class.ReclaimPolicy,
+		AllowVolumeExpansion: class.AllowVolumeExpansion,
 	}
 	v1Class.Name = class.Name
 	return v1Class
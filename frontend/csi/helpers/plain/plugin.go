@@ -2,6 +2,7 @@ package plain
 
 import (
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -58,7 +59,7 @@ func (p *Plugin) Version() string {
 // provisioner, finds or creates/registers a matching storage class, and returns
 // a VolumeConfig structure as needed by Trident to create a new volume.
 func (p *Plugin) GetVolumeConfig(
-	name string, sizeBytes int64, parameters map[string]string,
+	ctx context.Context, name string, sizeBytes int64, parameters map[string]string,
 	protocol config.Protocol, accessMode config.AccessMode, fsType string,
 ) (*storage.VolumeConfig, error) {
 
@@ -82,12 +83,10 @@ func (p *Plugin) GetVolumeConfig(
 
 // GetSnapshotConfig accepts the attributes of a snapshot being requested by the CSI
 // provisioner and returns a SnapshotConfig structure as needed by Trident to create a new snapshot.
-func (p *Plugin) GetSnapshotConfig(volumeName, snapshotName string) (*storage.SnapshotConfig, error) {
-	return &storage.SnapshotConfig{
-		Version:    config.OrchestratorAPIVersion,
-		Name:       snapshotName,
-		VolumeName: volumeName,
-	}, nil
+func (p *Plugin) GetSnapshotConfig(
+	volumeName, snapshotName string, parameters map[string]string,
+) (*storage.SnapshotConfig, error) {
+	return frontendcommon.GetSnapshotConfig(volumeName, snapshotName, parameters)
 }
 
 // RecordVolumeEvent accepts the name of a CSI volume and writes the specified
@@ -100,3 +99,9 @@ func (p *Plugin) RecordVolumeEvent(name, eventType, reason, message string) {
 		"message":   message,
 	}).Debug("Volume event.")
 }
+
+// GetNodeTopologyLabels returns an empty set of topology labels, since plain CSI has no
+// knowledge of node failure domains.
+func (p *Plugin) GetNodeTopologyLabels(nodeName string) (map[string]string, error) {
+	return make(map[string]string), nil
+}
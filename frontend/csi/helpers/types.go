@@ -3,6 +3,8 @@
 package helpers
 
 import (
+	"golang.org/x/net/context"
+
 	"github.com/netapp/trident/config"
 	"github.com/netapp/trident/storage"
 )
@@ -22,21 +24,29 @@ type HybridPlugin interface {
 
 	// GetVolumeConfig accepts the attributes of a volume being requested by the CSI
 	// provisioner, adds in any CO-specific details about the new volume, and returns
-	// a VolumeConfig structure as needed by Trident to create a new volume.
+	// a VolumeConfig structure as needed by Trident to create a new volume.  The context
+	// carries the correlation ID of the CSI request being served.
 	GetVolumeConfig(
-		name string, sizeBytes int64, parameters map[string]string,
+		ctx context.Context, name string, sizeBytes int64, parameters map[string]string,
 		protocol config.Protocol, accessMode config.AccessMode, fsType string,
 	) (*storage.VolumeConfig, error)
 
 	// GetSnapshotConfig accepts the attributes of a snapshot being requested byt the CSI
 	// provisioner, adds in any CO-specific details about the new volume, and returns
-	// a SnapshotConfig structure as needed by Trident to create a new snapshot.
-	GetSnapshotConfig(volumeName, snapshotName string) (*storage.SnapshotConfig, error)
+	// a SnapshotConfig structure as needed by Trident to create a new snapshot.  Snapshot
+	// class parameters that Trident does not recognize result in an error.
+	GetSnapshotConfig(
+		volumeName, snapshotName string, parameters map[string]string,
+	) (*storage.SnapshotConfig, error)
 
 	// RecordVolumeEvent accepts the name of a CSI volume and writes the specified
 	// event message in a manner appropriate to the container orchestrator.
 	RecordVolumeEvent(name, eventType, reason, message string)
 
+	// GetNodeTopologyLabels accepts the name of a node (i.e. a CSI node ID) and returns the
+	// topology labels (region, zone) that identify the node's failure domain, if any.
+	GetNodeTopologyLabels(nodeName string) (map[string]string, error)
+
 	// Version returns the version of the CO this helper is managing, or the supported
 	// CSI version in the plain-CSI case.  This value is reported in Trident's telemetry.
 	Version() string
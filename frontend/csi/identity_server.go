@@ -16,7 +16,8 @@ func (p *Plugin) Probe(
 	ctx context.Context, req *csi.ProbeRequest,
 ) (*csi.ProbeResponse, error) {
 
-	fields := log.Fields{"Method": "Probe", "Type": "CSI_Identity"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "Probe", "Type": "CSI_Identity", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> Probe")
 	defer log.WithFields(fields).Debug("<<<< Probe")
 
@@ -34,7 +35,8 @@ func (p *Plugin) GetPluginInfo(
 	ctx context.Context, req *csi.GetPluginInfoRequest,
 ) (*csi.GetPluginInfoResponse, error) {
 
-	fields := log.Fields{"Method": "GetPluginInfo", "Type": "CSI_Identity"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "GetPluginInfo", "Type": "CSI_Identity", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> GetPluginInfo")
 	defer log.WithFields(fields).Debug("<<<< GetPluginInfo")
 
@@ -48,7 +50,8 @@ func (p *Plugin) GetPluginCapabilities(
 	ctx context.Context, req *csi.GetPluginCapabilitiesRequest,
 ) (*csi.GetPluginCapabilitiesResponse, error) {
 
-	fields := log.Fields{"Method": "GetPluginCapabilities", "Type": "CSI_Identity"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "GetPluginCapabilities", "Type": "CSI_Identity", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> GetPluginCapabilities")
 	defer log.WithFields(fields).Debug("<<<< GetPluginCapabilities")
 
@@ -61,6 +64,13 @@ func (p *Plugin) GetPluginCapabilities(
 					},
 				},
 			},
+			{
+				Type: &csi.PluginCapability_VolumeExpansion_{
+					VolumeExpansion: &csi.PluginCapability_VolumeExpansion{
+						Type: csi.PluginCapability_VolumeExpansion_ONLINE,
+					},
+				},
+			},
 		},
 	}, nil
 }
@@ -0,0 +1,85 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package csi
+
+import (
+	"path"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/netapp/trident/config"
+)
+
+const (
+	metricsNamespace = config.OrchestratorName
+	metricsSubsystem = "csi"
+)
+
+var (
+	// RequestsTotal counts CSI RPCs by method.
+	RequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "requests_total",
+			Help:      "The total number of CSI requests.",
+		},
+		[]string{"method"},
+	)
+
+	// RequestErrorsTotal counts failed CSI RPCs by method and gRPC status code.
+	RequestErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "request_errors_total",
+			Help:      "The total number of failed CSI requests.",
+		},
+		[]string{"method", "code"},
+	)
+
+	// RequestDurationSeconds records CSI RPC latency by method.
+	RequestDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "request_duration_seconds",
+			Help:      "The latency of CSI requests.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 18),
+		},
+		[]string{"method"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(RequestsTotal, RequestErrorsTotal, RequestDurationSeconds)
+}
+
+// metricsGRPC is a unary interceptor that records the count, outcome and latency of every CSI
+// RPC, labeled by the short method name (e.g. CreateVolume).
+func metricsGRPC(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+
+	method := path.Base(info.FullMethod)
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	RequestsTotal.WithLabelValues(method).Inc()
+	RequestDurationSeconds.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		code := codes.Unknown
+		if st, ok := status.FromError(err); ok {
+			code = st.Code()
+		}
+		RequestErrorsTotal.WithLabelValues(method, code.String()).Inc()
+	}
+
+	return resp, err
+}
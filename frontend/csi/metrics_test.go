@@ -0,0 +1,49 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package csi
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsGRPC(t *testing.T) {
+
+	const method = "CreateVolume"
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/" + method}
+
+	requestsBefore := testutil.ToFloat64(RequestsTotal.WithLabelValues(method))
+	notFoundBefore := testutil.ToFloat64(RequestErrorsTotal.WithLabelValues(method, codes.NotFound.String()))
+	okBefore := testutil.ToFloat64(RequestErrorsTotal.WithLabelValues(method, codes.OK.String()))
+
+	succeed := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+	fail := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "volume not found")
+	}
+
+	resp, err := metricsGRPC(context.Background(), "request", info, succeed)
+	if err != nil || resp != "response" {
+		t.Fatalf("Expected the handler's response, got %v: %v", resp, err)
+	}
+	if _, err = metricsGRPC(context.Background(), "request", info, fail); statusCode(err) != codes.NotFound {
+		t.Fatalf("Expected the handler's NotFound error, got %v", err)
+	}
+
+	if requests := testutil.ToFloat64(RequestsTotal.WithLabelValues(method)); requests != requestsBefore+2 {
+		t.Errorf("Expected %v requests, got %v", requestsBefore+2, requests)
+	}
+	notFound := testutil.ToFloat64(RequestErrorsTotal.WithLabelValues(method, codes.NotFound.String()))
+	if notFound != notFoundBefore+1 {
+		t.Errorf("Expected %v NotFound errors, got %v", notFoundBefore+1, notFound)
+	}
+	if ok := testutil.ToFloat64(RequestErrorsTotal.WithLabelValues(method, codes.OK.String())); ok != okBefore {
+		t.Errorf("Expected no errors to be recorded for a successful request, got %v", ok-okBefore)
+	}
+}
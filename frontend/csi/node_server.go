@@ -30,7 +30,8 @@ func (p *Plugin) NodeStageVolume(
 	ctx context.Context, req *csi.NodeStageVolumeRequest,
 ) (*csi.NodeStageVolumeResponse, error) {
 
-	fields := log.Fields{"Method": "NodeStageVolume", "Type": "CSI_Node"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "NodeStageVolume", "Type": "CSI_Node", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> NodeStageVolume")
 	defer log.WithFields(fields).Debug("<<<< NodeStageVolume")
 
@@ -48,7 +49,8 @@ func (p *Plugin) NodeUnstageVolume(
 	ctx context.Context, req *csi.NodeUnstageVolumeRequest,
 ) (*csi.NodeUnstageVolumeResponse, error) {
 
-	fields := log.Fields{"Method": "NodeUnstageVolume", "Type": "CSI_Node"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "NodeUnstageVolume", "Type": "CSI_Node", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> NodeUnstageVolume")
 	defer log.WithFields(fields).Debug("<<<< NodeUnstageVolume")
 
@@ -86,17 +88,61 @@ func (p *Plugin) NodePublishVolume(
 	ctx context.Context, req *csi.NodePublishVolumeRequest,
 ) (*csi.NodePublishVolumeResponse, error) {
 
-	fields := log.Fields{"Method": "NodePublishVolume", "Type": "CSI_Node"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "NodePublishVolume", "Type": "CSI_Node", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> NodePublishVolume")
 	defer log.WithFields(fields).Debug("<<<< NodePublishVolume")
 
+	if err := p.claimSingleWriterVolume(req); err != nil {
+		return nil, err
+	}
+
+	var resp *csi.NodePublishVolumeResponse
+	var err error
 	switch req.PublishContext["protocol"] {
 	case string(tridentconfig.File):
-		return p.nodePublishNFSVolume(ctx, req)
+		resp, err = p.nodePublishNFSVolume(ctx, req)
 	case string(tridentconfig.Block):
-		return p.nodePublishISCSIVolume(ctx, req)
+		resp, err = p.nodePublishISCSIVolume(ctx, req)
 	default:
-		return nil, status.Error(codes.InvalidArgument, "unknown protocol")
+		err = status.Error(codes.InvalidArgument, "unknown protocol")
+	}
+	if err != nil {
+		p.releaseSingleWriterVolume(req.GetVolumeId(), req.GetTargetPath())
+	}
+	return resp, err
+}
+
+// claimSingleWriterVolume records the target path of a volume published with the single-node
+// single-writer access mode.  It fails if the volume is already published at another target path.
+func (p *Plugin) claimSingleWriterVolume(req *csi.NodePublishVolumeRequest) error {
+
+	if req.GetVolumeCapability().GetAccessMode().GetMode() != accessModeSingleNodeSingleWriter {
+		return nil
+	}
+
+	p.singleWriterLock.Lock()
+	defer p.singleWriterLock.Unlock()
+
+	if p.singleWriterPaths == nil {
+		p.singleWriterPaths = make(map[string]string)
+	}
+	if targetPath, ok := p.singleWriterPaths[req.GetVolumeId()]; ok && targetPath != req.GetTargetPath() {
+		return status.Errorf(codes.FailedPrecondition, "volume %s is already published at %s",
+			req.GetVolumeId(), targetPath)
+	}
+	p.singleWriterPaths[req.GetVolumeId()] = req.GetTargetPath()
+	return nil
+}
+
+// releaseSingleWriterVolume forgets a single-writer volume's target path once it is unpublished.
+func (p *Plugin) releaseSingleWriterVolume(volumeID, targetPath string) {
+
+	p.singleWriterLock.Lock()
+	defer p.singleWriterLock.Unlock()
+
+	if p.singleWriterPaths[volumeID] == targetPath {
+		delete(p.singleWriterPaths, volumeID)
 	}
 }
 
@@ -104,7 +150,8 @@ func (p *Plugin) NodeUnpublishVolume(
 	ctx context.Context, req *csi.NodeUnpublishVolumeRequest,
 ) (*csi.NodeUnpublishVolumeResponse, error) {
 
-	fields := log.Fields{"Method": "NodeUnpublishVolume", "Type": "CSI_Node"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "NodeUnpublishVolume", "Type": "CSI_Node", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> NodeUnpublishVolume")
 	defer log.WithFields(fields).Debug("<<<< NodeUnpublishVolume")
 
@@ -122,19 +169,26 @@ func (p *Plugin) NodeUnpublishVolume(
 
 	if err != nil {
 		if os.IsNotExist(err) {
+			p.releaseSingleWriterVolume(req.GetVolumeId(), targetPath)
 			return nil, status.Error(codes.NotFound, "target path not found")
 		} else {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 	}
 	if notMnt {
+		p.releaseSingleWriterVolume(req.GetVolumeId(), targetPath)
 		return nil, status.Error(codes.NotFound, "volume not mounted")
 	}
 
 	if err := utils.Umount(targetPath); err != nil {
-		log.WithFields(log.Fields{"path": targetPath, "error": err}).Error("unable to unmount volume.")
+		log.WithFields(log.Fields{
+			"path":      targetPath,
+			"error":     err,
+			"requestID": GetRequestID(ctx),
+		}).Error("unable to unmount volume.")
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	p.releaseSingleWriterVolume(req.GetVolumeId(), targetPath)
 
 	return &csi.NodeUnpublishVolumeResponse{}, nil
 }
@@ -159,7 +213,8 @@ func (p *Plugin) NodeGetCapabilities(
 	ctx context.Context, req *csi.NodeGetCapabilitiesRequest,
 ) (*csi.NodeGetCapabilitiesResponse, error) {
 
-	fields := log.Fields{"Method": "NodeGetCapabilities", "Type": "CSI_Node"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "NodeGetCapabilities", "Type": "CSI_Node", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> NodeGetCapabilities")
 	defer log.WithFields(fields).Debug("<<<< NodeGetCapabilities")
 
@@ -170,7 +225,8 @@ func (p *Plugin) NodeGetInfo(
 	ctx context.Context, req *csi.NodeGetInfoRequest,
 ) (*csi.NodeGetInfoResponse, error) {
 
-	fields := log.Fields{"Method": "NodeGetInfo", "Type": "CSI_Node"}
+	ctx = GenerateRequestContext(ctx)
+	fields := log.Fields{"Method": "NodeGetInfo", "Type": "CSI_Node", "requestID": GetRequestID(ctx)}
 	log.WithFields(fields).Debug(">>>> NodeGetInfo")
 	defer log.WithFields(fields).Debug("<<<< NodeGetInfo")
 
@@ -247,6 +303,7 @@ func (p *Plugin) nodeStageNFSVolume(ctx context.Context, req *csi.NodeStageVolum
 	publishInfo.MountOptions = req.PublishContext["mountOptions"]
 	publishInfo.NfsServerIP = req.PublishContext["nfsServerIp"]
 	publishInfo.NfsPath = req.PublishContext["nfsPath"]
+	publishInfo.FSGroupChangePolicy = req.PublishContext["fsGroupChangePolicy"]
 
 	// Save the device info to the staging path for use in the publish & unstage calls
 	if err := p.writeStagedDeviceInfo(req.StagingTargetPath, publishInfo); err != nil {
@@ -293,7 +350,7 @@ func (p *Plugin) nodePublishNFSVolume(
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	if req.GetReadonly() {
+	if isReadOnlyPublish(req) {
 		mountOptions := strings.Split(publishInfo.MountOptions, ",")
 		mountOptions = append(mountOptions, "ro")
 		publishInfo.MountOptions = strings.Join(mountOptions, ",")
@@ -313,6 +370,16 @@ func (p *Plugin) nodePublishNFSVolume(
 	return &csi.NodePublishVolumeResponse{}, nil
 }
 
+// isReadOnlyPublish reports whether a volume must be mounted read-only, either because the request
+// says so or because the controller recorded a read-only access mode when publishing the volume.
+func isReadOnlyPublish(req *csi.NodePublishVolumeRequest) bool {
+	if req.GetReadonly() {
+		return true
+	}
+	readOnly, _ := strconv.ParseBool(req.PublishContext["readOnly"])
+	return readOnly
+}
+
 func unstashIscsiTargetPortals(publishInfo *utils.VolumePublishInfo, reqPublishInfo map[string]string) error {
 
 	count, err := strconv.Atoi(reqPublishInfo["iscsiTargetPortalCount"])
@@ -341,17 +408,17 @@ func (p *Plugin) nodeStageISCSIVolume(
 
 	var err error
 
-	fstype := "ext4"
-	mountCapability := req.GetVolumeCapability().GetMount()
-	if mountCapability != nil {
-		if mountCapability.GetFsType() != "" {
-			fstype = mountCapability.GetFsType()
-		}
+	// Prefer the requested fsType, then the one the volume was created with
+	fstype := req.GetVolumeCapability().GetMount().GetFsType()
+	if fstype == "" {
+		fstype = req.VolumeContext["fsType"]
 	}
-
 	if fstype == "" {
 		fstype = req.PublishContext["filesystemType"]
 	}
+	if fstype == "" {
+		fstype = "ext4"
+	}
 
 	useCHAP, err := strconv.ParseBool(req.PublishContext["useCHAP"])
 	if err != nil {
@@ -445,7 +512,7 @@ func (p *Plugin) nodePublishISCSIVolume(
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	if req.GetReadonly() {
+	if isReadOnlyPublish(req) {
 		mountOptions := strings.Split(publishInfo.MountOptions, ",")
 		mountOptions = append(mountOptions, "ro")
 		publishInfo.MountOptions = strings.Join(mountOptions, ",")
@@ -0,0 +1,67 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package csi
+
+import (
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+)
+
+func TestClaimSingleWriterVolume(t *testing.T) {
+
+	p := &Plugin{}
+
+	publishRequest := func(
+		volumeID, targetPath string, mode csi.VolumeCapability_AccessMode_Mode,
+	) *csi.NodePublishVolumeRequest {
+		return &csi.NodePublishVolumeRequest{
+			VolumeId:   volumeID,
+			TargetPath: targetPath,
+			VolumeCapability: &csi.VolumeCapability{
+				AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+				AccessMode: &csi.VolumeCapability_AccessMode{Mode: mode},
+			},
+		}
+	}
+
+	// A single-writer volume may be published once, and republished at the same path
+	if err := p.claimSingleWriterVolume(publishRequest("vol1", "/pod1", accessModeSingleNodeSingleWriter)); err != nil {
+		t.Fatalf("Unexpected error claiming single-writer volume: %v", err)
+	}
+	if err := p.claimSingleWriterVolume(publishRequest("vol1", "/pod1", accessModeSingleNodeSingleWriter)); err != nil {
+		t.Errorf("Unexpected error reclaiming single-writer volume at the same path: %v", err)
+	}
+
+	// Publishing it for a second pod fails
+	err := p.claimSingleWriterVolume(publishRequest("vol1", "/pod2", accessModeSingleNodeSingleWriter))
+	if code := statusCode(err); code != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition publishing a single-writer volume twice, got %v", err)
+	}
+
+	// Other access modes and volumes are unaffected
+	for _, mode := range []csi.VolumeCapability_AccessMode_Mode{
+		csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+		accessModeSingleNodeMultiWriter,
+		csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+	} {
+		if err := p.claimSingleWriterVolume(publishRequest("vol1", "/pod2", mode)); err != nil {
+			t.Errorf("Unexpected error publishing with %s: %v", mode, err)
+		}
+	}
+	if err := p.claimSingleWriterVolume(publishRequest("vol2", "/pod2", accessModeSingleNodeSingleWriter)); err != nil {
+		t.Errorf("Unexpected error claiming another single-writer volume: %v", err)
+	}
+
+	// Releasing a different path leaves the claim in place, but releasing the claimed path frees the volume
+	p.releaseSingleWriterVolume("vol1", "/pod2")
+	err = p.claimSingleWriterVolume(publishRequest("vol1", "/pod2", accessModeSingleNodeSingleWriter))
+	if code := statusCode(err); code != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition after releasing an unclaimed path, got %v", err)
+	}
+	p.releaseSingleWriterVolume("vol1", "/pod1")
+	if err := p.claimSingleWriterVolume(publishRequest("vol1", "/pod2", accessModeSingleNodeSingleWriter)); err != nil {
+		t.Errorf("Unexpected error claiming a released single-writer volume: %v", err)
+	}
+}
@@ -5,9 +5,12 @@ package csi
 import (
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -15,6 +18,7 @@ import (
 	"github.com/netapp/trident/core"
 	"github.com/netapp/trident/frontend/csi/helpers"
 	"github.com/netapp/trident/frontend/rest"
+	"github.com/netapp/trident/storage"
 )
 
 const (
@@ -35,53 +39,68 @@ type Plugin struct {
 	restClient *RestClient
 	helper     helpers.HybridPlugin
 
+	// nfsMountOptions are used when publishing NFS volumes that don't specify any mount options
+	nfsMountOptions string
+
 	grpc NonBlockingGRPCServer
 
 	csCap []*csi.ControllerServiceCapability
 	nsCap []*csi.NodeServiceCapability
 	vCap  []*csi.VolumeCapability_AccessMode
 
-	opCache map[string]bool
+	// opCache tracks the names of in-flight operations, opLock guards it, and opWait lets
+	// shutdown wait for the operations to finish.  Once stopping is set, no operations start.
+	opCache  map[string]bool
+	opLock   sync.Mutex
+	opWait   sync.WaitGroup
+	stopping bool
+
+	// singleWriterPaths records the target path of each volume published with the single-node
+	// single-writer (ReadWriteOncePod) access mode, so that it isn't published to a second pod.
+	singleWriterPaths map[string]string
+	singleWriterLock  sync.Mutex
 }
 
 func NewControllerPlugin(
-	nodeName, endpoint string, orchestrator core.Orchestrator, helper *helpers.HybridPlugin,
+	nodeName, endpoint, nfsMountOptions string, readWriteOncePod bool, orchestrator core.Orchestrator,
+	helper *helpers.HybridPlugin,
 ) (*Plugin, error) {
 
 	p := &Plugin{
-		orchestrator: orchestrator,
-		name:         Provisioner,
-		nodeName:     nodeName,
-		version:      tridentconfig.OrchestratorVersion.ShortString(),
-		endpoint:     endpoint,
-		role:         CSIController,
-		helper:       *helper,
-		opCache:      make(map[string]bool),
+		orchestrator:    orchestrator,
+		name:            Provisioner,
+		nodeName:        nodeName,
+		version:         tridentconfig.OrchestratorVersion.ShortString(),
+		endpoint:        endpoint,
+		role:            CSIController,
+		helper:          *helper,
+		nfsMountOptions: nfsMountOptions,
+		opCache:         make(map[string]bool),
 	}
 
 	// Define controller capabilities
-	p.addControllerServiceCapabilities([]csi.ControllerServiceCapability_RPC_Type{
+	controllerCapabilities := []csi.ControllerServiceCapability_RPC_Type{
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
 		csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME,
 		csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
 		//csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS,
-	})
+		csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
+	}
+	if readWriteOncePod {
+		controllerCapabilities = append(controllerCapabilities, controllerCapabilitySingleNodeMultiWriter)
+	}
+	p.addControllerServiceCapabilities(controllerCapabilities)
 
 	// Define volume capabilities
-	p.addVolumeCapabilityAccessModes([]csi.VolumeCapability_AccessMode_Mode{
-		csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
-		csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY,
-		csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
-		csi.VolumeCapability_AccessMode_MULTI_NODE_SINGLE_WRITER,
-		csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
-	})
+	p.addVolumeCapabilityAccessModes(volumeAccessModes(readWriteOncePod))
 
 	return p, nil
 }
 
 func NewNodePlugin(
-	nodeName, endpoint, caCert, clientCert, clientKey string, orchestrator core.Orchestrator,
+	nodeName, endpoint, caCert, clientCert, clientKey string, readWriteOncePod bool,
+	orchestrator core.Orchestrator,
 ) (*Plugin, error) {
 
 	p := &Plugin{
@@ -94,9 +113,13 @@ func NewNodePlugin(
 		opCache:      make(map[string]bool),
 	}
 
-	p.addNodeServiceCapabilities([]csi.NodeServiceCapability_RPC_Type{
+	nodeCapabilities := []csi.NodeServiceCapability_RPC_Type{
 		csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
-	})
+	}
+	if readWriteOncePod {
+		nodeCapabilities = append(nodeCapabilities, nodeCapabilitySingleNodeMultiWriter)
+	}
+	p.addNodeServiceCapabilities(nodeCapabilities)
 	port := "34571"
 	for _, envVar := range os.Environ() {
 		values := strings.Split(envVar, "=")
@@ -113,45 +136,49 @@ func NewNodePlugin(
 	}
 
 	// Define volume capabilities
-	p.addVolumeCapabilityAccessModes([]csi.VolumeCapability_AccessMode_Mode{
-		csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
-		csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY,
-		csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
-		csi.VolumeCapability_AccessMode_MULTI_NODE_SINGLE_WRITER,
-		csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
-	})
+	p.addVolumeCapabilityAccessModes(volumeAccessModes(readWriteOncePod))
 
 	return p, nil
 }
 
 func NewAllInOnePlugin(
-	nodeName, endpoint, caCert, clientCert, clientKey string,
+	nodeName, endpoint, caCert, clientCert, clientKey, nfsMountOptions string, readWriteOncePod bool,
 	orchestrator core.Orchestrator, helper *helpers.HybridPlugin,
 ) (*Plugin, error) {
 
 	p := &Plugin{
-		orchestrator: orchestrator,
-		name:         Provisioner,
-		nodeName:     nodeName,
-		version:      tridentconfig.OrchestratorVersion.ShortString(),
-		endpoint:     endpoint,
-		role:         CSIAllInOne,
-		helper:       *helper,
-		opCache:      make(map[string]bool),
+		orchestrator:    orchestrator,
+		name:            Provisioner,
+		nodeName:        nodeName,
+		version:         tridentconfig.OrchestratorVersion.ShortString(),
+		endpoint:        endpoint,
+		role:            CSIAllInOne,
+		helper:          *helper,
+		nfsMountOptions: nfsMountOptions,
+		opCache:         make(map[string]bool),
 	}
 
 	// Define controller capabilities
-	p.addControllerServiceCapabilities([]csi.ControllerServiceCapability_RPC_Type{
+	controllerCapabilities := []csi.ControllerServiceCapability_RPC_Type{
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
 		csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME,
 		csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
 		//csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS,
-	})
+		csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
+	}
+	if readWriteOncePod {
+		controllerCapabilities = append(controllerCapabilities, controllerCapabilitySingleNodeMultiWriter)
+	}
+	p.addControllerServiceCapabilities(controllerCapabilities)
 
-	p.addNodeServiceCapabilities([]csi.NodeServiceCapability_RPC_Type{
+	nodeCapabilities := []csi.NodeServiceCapability_RPC_Type{
 		csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
-	})
+	}
+	if readWriteOncePod {
+		nodeCapabilities = append(nodeCapabilities, nodeCapabilitySingleNodeMultiWriter)
+	}
+	p.addNodeServiceCapabilities(nodeCapabilities)
 	port := "34571"
 	for _, envVar := range os.Environ() {
 		values := strings.Split(envVar, "=")
@@ -168,13 +195,7 @@ func NewAllInOnePlugin(
 	}
 
 	// Define volume capabilities
-	p.addVolumeCapabilityAccessModes([]csi.VolumeCapability_AccessMode_Mode{
-		csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
-		csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY,
-		csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
-		csi.VolumeCapability_AccessMode_MULTI_NODE_SINGLE_WRITER,
-		csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
-	})
+	p.addVolumeCapabilityAccessModes(volumeAccessModes(readWriteOncePod))
 
 	return p, nil
 }
@@ -197,7 +218,7 @@ func (p *Plugin) Activate() error {
 
 func (p *Plugin) Deactivate() error {
 	log.Info("Deactivating CSI frontend.")
-	p.grpc.GracefulStop()
+	p.shutdown(ShutdownTimeout)
 	if p.role == CSINode || p.role == CSIAllInOne {
 		err := p.nodeDeregisterWithController()
 		if err != nil {
@@ -208,6 +229,77 @@ func (p *Plugin) Deactivate() error {
 	return nil
 }
 
+// startOperation records the start of a named operation.  It fails if an operation with the same
+// name is already in progress or if the plugin is shutting down.
+func (p *Plugin) startOperation(name string) error {
+
+	p.opLock.Lock()
+	defer p.opLock.Unlock()
+
+	if p.stopping {
+		return status.Error(codes.Unavailable, "CSI frontend is shutting down")
+	}
+	if _, ok := p.opCache[name]; ok {
+		return status.Error(codes.DeadlineExceeded, "operation already in progress")
+	}
+	p.opCache[name] = true
+	p.opWait.Add(1)
+	return nil
+}
+
+// finishOperation records the end of a named operation begun with startOperation.
+func (p *Plugin) finishOperation(name string) {
+
+	p.opLock.Lock()
+	defer p.opLock.Unlock()
+
+	delete(p.opCache, name)
+	p.opWait.Done()
+}
+
+// shutdown stops the plugin from accepting new operations, then waits up to the specified timeout
+// for in-flight operations to finish.  Operations still running at the timeout are abandoned, and
+// any transactions they leave behind are recovered when Trident next starts.
+func (p *Plugin) shutdown(timeout time.Duration) {
+
+	p.opLock.Lock()
+	p.stopping = true
+	inFlight := len(p.opCache)
+	p.opLock.Unlock()
+
+	deadline := time.After(timeout)
+	log.WithField("operations", inFlight).Info("Waiting for in-flight CSI operations to finish.")
+
+	// Stop serving gRPC, which refuses new RPCs and waits for pending ones
+	if p.grpc != nil {
+		stopped := make(chan struct{})
+		go func() {
+			p.grpc.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-deadline:
+			log.Warning("Timed out waiting for CSI requests to finish, stopping the gRPC server.")
+			p.grpc.Stop()
+			return
+		}
+	}
+
+	// Wait for tracked operations, which may have been started by callers other than gRPC
+	finished := make(chan struct{})
+	go func() {
+		p.opWait.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		log.Info("In-flight CSI operations finished.")
+	case <-deadline:
+		log.Warning("Timed out waiting for in-flight CSI operations to finish.")
+	}
+}
+
 func (p *Plugin) GetName() string {
 	return string(tridentconfig.ContextCSI)
 }
@@ -240,6 +332,24 @@ func (p *Plugin) addNodeServiceCapabilities(cl []csi.NodeServiceCapability_RPC_T
 	p.nsCap = nsCap
 }
 
+// volumeAccessModes returns the CSI access modes Trident supports.  The single-node single- and
+// multi-writer modes are only advertised if ReadWriteOncePod is enabled, since Kubernetes only
+// uses them with drivers that also advertise the SINGLE_NODE_MULTI_WRITER capabilities.
+func volumeAccessModes(readWriteOncePod bool) []csi.VolumeCapability_AccessMode_Mode {
+
+	modes := []csi.VolumeCapability_AccessMode_Mode{
+		csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+		csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY,
+		csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
+		csi.VolumeCapability_AccessMode_MULTI_NODE_SINGLE_WRITER,
+		csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+	}
+	if readWriteOncePod {
+		modes = append(modes, accessModeSingleNodeSingleWriter, accessModeSingleNodeMultiWriter)
+	}
+	return modes
+}
+
 func (p *Plugin) addVolumeCapabilityAccessModes(vc []csi.VolumeCapability_AccessMode_Mode) {
 
 	var vCap []*csi.VolumeCapability_AccessMode
@@ -252,6 +362,22 @@ func (p *Plugin) addVolumeCapabilityAccessModes(vc []csi.VolumeCapability_Access
 	p.vCap = vCap
 }
 
+// recordVolumeEvent writes an event for a volume to the container orchestrator, if any, and to
+// Trident's own volume event history.  Failing to record an event doesn't fail the request.
+func (p *Plugin) recordVolumeEvent(ctx context.Context, name, eventType, reason, message string) {
+
+	p.helper.RecordVolumeEvent(name, eventType, reason, message)
+
+	event := &storage.VolumeEvent{Type: eventType, Reason: reason, Message: message}
+	if err := p.orchestrator.AddVolumeEvent(name, event); err != nil {
+		log.WithFields(log.Fields{
+			"volume":    name,
+			"reason":    reason,
+			"requestID": GetRequestID(ctx),
+		}).Debugf("Could not record volume event; %v", err)
+	}
+}
+
 func (p *Plugin) getCSIErrorForOrchestratorError(err error) error {
 	if core.IsNotReadyError(err) {
 		return status.Error(codes.Unavailable, err.Error())
@@ -259,6 +385,8 @@ func (p *Plugin) getCSIErrorForOrchestratorError(err error) error {
 		return status.Error(codes.FailedPrecondition, err.Error())
 	} else if core.IsNotFoundError(err) {
 		return status.Error(codes.NotFound, err.Error())
+	} else if core.IsVolumeProtectedError(err) || core.IsSnapshotInUseError(err) {
+		return status.Error(codes.FailedPrecondition, err.Error())
 	} else {
 		return status.Error(codes.Unknown, err.Error())
 	}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/rs/xid"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
@@ -48,14 +49,57 @@ func NewNodeServiceCapability(cap csi.NodeServiceCapability_RPC_Type) *csi.NodeS
 	}
 }
 
+type contextKey string
+
+// ContextKeyRequestID is the context key for the correlation ID that ties together the log
+// messages written while serving a single CSI request.
+const ContextKeyRequestID contextKey = "requestID"
+
+// GenerateRequestContext returns a context carrying a new correlation ID, unless the supplied
+// context already carries one.
+func GenerateRequestContext(ctx context.Context) context.Context {
+	if GetRequestID(ctx) != "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ContextKeyRequestID, xid.New().String())
+}
+
+// GetRequestID returns the correlation ID carried by a context, or an empty string if there is none.
+func GetRequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if requestID, ok := ctx.Value(ContextKeyRequestID).(string); ok {
+		return requestID
+	}
+	return ""
+}
+
 func logGRPC(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	log.Debugf("GRPC call: %s", info.FullMethod)
-	log.Debugf("GRPC request: %+v", req)
+	ctx = GenerateRequestContext(ctx)
+	logFields := log.Fields{"requestID": GetRequestID(ctx)}
+	log.WithFields(logFields).Debugf("GRPC call: %s", info.FullMethod)
+	log.WithFields(logFields).Debugf("GRPC request: %+v", req)
 	resp, err := handler(ctx, req)
 	if err != nil {
-		log.Errorf("GRPC error: %v", err)
+		log.WithFields(logFields).Errorf("GRPC error: %v", err)
 	} else {
-		log.Debugf("GRPC response: %+v", resp)
+		log.WithFields(logFields).Debugf("GRPC response: %+v", resp)
 	}
 	return resp, err
 }
+
+// chainUnaryInterceptors combines unary interceptors into one, with the first interceptor outermost.
+func chainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
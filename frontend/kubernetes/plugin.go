@@ -90,7 +90,7 @@ func NewPlugin(o core.Orchestrator, apiServerIP, kubeConfigPath string) (*Plugin
 	}
 
 	// Create the CLI-based Kubernetes client
-	client, err := clik8sclient.NewKubectlClient("")
+	client, err := clik8sclient.NewKubectlClient("", "")
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize Kubernetes client; %v", err)
 	}
@@ -0,0 +1,70 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/config"
+)
+
+const (
+	MetricsPath = "/metrics"
+	HTTPTimeout = 90 * time.Second
+)
+
+// Server exposes Trident's Prometheus metrics over HTTP.
+type Server struct {
+	server *http.Server
+}
+
+func NewMetricsServer(address, port string) *Server {
+
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, promhttp.Handler())
+
+	metricsServer := &Server{
+		server: &http.Server{
+			Addr:         fmt.Sprintf("%s:%s", address, port),
+			Handler:      mux,
+			ReadTimeout:  HTTPTimeout,
+			WriteTimeout: HTTPTimeout,
+		},
+	}
+
+	log.WithField("address", metricsServer.server.Addr).Info("Initializing metrics frontend.")
+
+	return metricsServer
+}
+
+func (s *Server) Activate() error {
+	go func() {
+		log.WithField("address", s.server.Addr).Info("Activating metrics frontend.")
+		err := s.server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) Deactivate() error {
+	log.WithField("address", s.server.Addr).Info("Deactivating metrics frontend.")
+	ctx, cancel := context.WithTimeout(context.Background(), HTTPTimeout)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) GetName() string {
+	return "metrics"
+}
+
+func (s *Server) Version() string {
+	return config.OrchestratorAPIVersion
+}
@@ -21,6 +21,7 @@ type APIServerHTTPS struct {
 	caCertFile     string
 	serverCertFile string
 	serverKeyFile  string
+	certReloader   *certReloader
 }
 
 func NewHTTPSServer(
@@ -42,6 +43,13 @@ func NewHTTPSServer(
 		serverKeyFile:  serverKeyFile,
 	}
 
+	reloader, err := newCertReloader(serverCertFile, serverKeyFile, CertReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+	apiServer.certReloader = reloader
+	apiServer.server.TLSConfig.GetCertificate = reloader.GetCertificate
+
 	if caCertFile != "" {
 		caCert, err := ioutil.ReadFile(caCertFile)
 		if err != nil {
@@ -58,9 +66,11 @@ func NewHTTPSServer(
 }
 
 func (s *APIServerHTTPS) Activate() error {
+	s.certReloader.Start()
 	go func() {
 		log.WithField("address", s.server.Addr).Infof("Activating HTTPS REST frontend.")
-		err := s.server.ListenAndServeTLS(s.serverCertFile, s.serverKeyFile)
+		// The certificate is served by the reloader, so no certificate files are passed here
+		err := s.server.ListenAndServeTLS("", "")
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -70,6 +80,7 @@ func (s *APIServerHTTPS) Activate() error {
 
 func (s *APIServerHTTPS) Deactivate() error {
 	log.WithField("address", s.server.Addr).Infof("Deactivating HTTPS REST frontend.")
+	s.certReloader.Stop()
 	ctx, cancel := context.WithTimeout(context.Background(), HTTPTimeout)
 	defer cancel()
 	return s.server.Shutdown(ctx)
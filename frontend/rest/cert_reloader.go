@@ -0,0 +1,120 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package rest
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CertReloadInterval is how often the server certificate files are checked for changes.
+const CertReloadInterval = 30 * time.Second
+
+// certReloader serves the certificate in a pair of PEM files, reloading it when the files change.
+// Kubernetes updates a mounted secret by atomically swapping a symlink, which would go unnoticed by
+// a watch on the original files, so the files are polled instead.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	lock      sync.RWMutex
+	cert      *tls.Certificate
+	certBytes []byte
+	keyBytes  []byte
+
+	done   chan struct{}
+	ticker *time.Ticker
+}
+
+// newCertReloader returns a reloader for the specified certificate and key files.  The files
+// must contain a valid key pair when the reloader is created.
+func newCertReloader(certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the most recently loaded certificate.  It is suitable for use as the
+// GetCertificate callback of a tls.Config.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.cert, nil
+}
+
+// reload reads the certificate and key files, replacing the served certificate if the files
+// changed.  It reports whether the certificate was replaced.  If the files can't be read or
+// don't contain a valid key pair, the current certificate continues to be served.
+func (r *certReloader) reload() (bool, error) {
+
+	certBytes, err := ioutil.ReadFile(r.certFile)
+	if err != nil {
+		return false, fmt.Errorf("could not read server certificate file: %v", err)
+	}
+	keyBytes, err := ioutil.ReadFile(r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("could not read server key file: %v", err)
+	}
+
+	r.lock.RLock()
+	unchanged := bytes.Equal(certBytes, r.certBytes) && bytes.Equal(keyBytes, r.keyBytes)
+	r.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certBytes, keyBytes)
+	if err != nil {
+		return false, fmt.Errorf("could not load server certificate: %v", err)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cert = &cert
+	r.certBytes = certBytes
+	r.keyBytes = keyBytes
+	return true, nil
+}
+
+// Start checks the certificate files for changes until Stop is called.
+func (r *certReloader) Start() {
+	r.ticker = time.NewTicker(r.interval)
+	go func() {
+		for {
+			select {
+			case <-r.ticker.C:
+				if reloaded, err := r.reload(); err != nil {
+					log.WithField("certFile", r.certFile).Errorf("Could not reload server certificate; %v", err)
+				} else if reloaded {
+					log.WithField("certFile", r.certFile).Info("Reloaded server certificate.")
+				}
+			case <-r.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the checks for certificate changes.
+func (r *certReloader) Stop() {
+	if r.ticker != nil {
+		r.ticker.Stop()
+	}
+	close(r.done)
+}
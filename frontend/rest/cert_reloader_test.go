@@ -0,0 +1,113 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package rest
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/netapp/trident/utils"
+)
+
+func writeServerCert(t *testing.T, dir, serverCertName string) {
+
+	certInfo, err := utils.MakeHTTPCertInfo(CACertName, serverCertName, ClientCertName)
+	if err != nil {
+		t.Fatalf("Could not create certificates: %v", err)
+	}
+
+	for fileName, encoded := range map[string]string{
+		ServerCertFile: certInfo.ServerCert,
+		ServerKeyFile:  certInfo.ServerKey,
+	} {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, fileName), decoded, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// servedCertName connects to a TLS listener and returns the common name of the certificate it presents.
+func servedCertName(t *testing.T, address string) string {
+
+	conn, err := tls.Dial("tcp", address, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("Could not connect to the TLS listener: %v", err)
+	}
+	defer conn.Close()
+
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "trident-certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeServerCert(t, dir, "server-1")
+
+	reloader, err := newCertReloader(
+		filepath.Join(dir, ServerCertFile), filepath.Join(dir, ServerKeyFile), 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Could not create the certificate reloader: %v", err)
+	}
+	reloader.Start()
+	defer reloader.Stop()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{GetCertificate: reloader.GetCertificate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	if name := servedCertName(t, listener.Addr().String()); name != "server-1" {
+		t.Fatalf("Expected certificate server-1, got %s", name)
+	}
+
+	// Rotate the certificate on disk
+	writeServerCert(t, dir, "server-2")
+
+	var name string
+	for i := 0; i < 100; i++ {
+		if name = servedCertName(t, listener.Addr().String()); name == "server-2" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if name != "server-2" {
+		t.Errorf("Expected rotated certificate server-2, got %s", name)
+	}
+
+	// An invalid key pair leaves the current certificate in place
+	if err = ioutil.WriteFile(filepath.Join(dir, ServerKeyFile), []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = reloader.reload(); err == nil {
+		t.Error("Expected an error reloading an invalid key pair")
+	}
+	if name = servedCertName(t, listener.Addr().String()); name != "server-2" {
+		t.Errorf("Expected certificate server-2 after a failed reload, got %s", name)
+	}
+}
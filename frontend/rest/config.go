@@ -7,6 +7,13 @@ import "time"
 const (
 	HTTPTimeout = 90 * time.Second
 
+	// LivenessPath is the path of the liveness probe handler, which is served both by the REST
+	// API and by the probe server.
+	LivenessPath = "/liveness"
+
+	// LivenessTimeout bounds how long the liveness probe handler waits for the persistent store.
+	LivenessTimeout = 10 * time.Second
+
 	CACertName     = "trident-ca"
 	ServerCertName = "trident-csi" // Must match CSI service name
 	ClientCertName = "trident-node"
@@ -3,11 +3,13 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
 	uuid "github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -15,8 +17,11 @@ import (
 
 	"github.com/netapp/trident/config"
 	"github.com/netapp/trident/core"
+	"github.com/netapp/trident/frontend/csi/helpers"
 	"github.com/netapp/trident/frontend/kubernetes"
+	persistentstore "github.com/netapp/trident/persistent_store"
 	"github.com/netapp/trident/storage"
+	sa "github.com/netapp/trident/storage_attribute"
 	storageclass "github.com/netapp/trident/storage_class"
 	"github.com/netapp/trident/utils"
 )
@@ -25,6 +30,28 @@ type listResponse interface {
 	setList([]string)
 }
 
+// SelectorQueryParameter is the query parameter that filters backend and volume lists by the
+// labels of their storage pools.
+const SelectorQueryParameter = "selector"
+
+// ShowSecretsQueryParameter is the query parameter that, if true, includes the secrets in a
+// backend's config.
+const ShowSecretsQueryParameter = "showSecrets"
+
+// CleanupQueryParameter is the query parameter that, if true, revokes a deleted node's access to
+// every backend before its record is removed.  Without it, deleting a node only removes the record.
+const CleanupQueryParameter = "cleanup"
+
+// getLabelSelector returns the label selector in a request's query parameters, or nil if the
+// request doesn't have one.
+func getLabelSelector(r *http.Request) (sa.Request, error) {
+	selector := r.URL.Query().Get(SelectorQueryParameter)
+	if selector == "" {
+		return nil, nil
+	}
+	return sa.NewLabelRequest(selector)
+}
+
 func httpStatusCodeForAdd(err error) int {
 	if err == nil {
 		return http.StatusCreated
@@ -68,6 +95,8 @@ func httpStatusCodeForDelete(err error) int {
 			return http.StatusInternalServerError
 		case *core.NotFoundError:
 			return http.StatusNotFound
+		case *core.VolumeProtectedError, *core.SnapshotInUseError:
+			return http.StatusConflict
 		default:
 			return http.StatusBadRequest
 		}
@@ -181,6 +210,16 @@ func AddGeneric(
 	r *http.Request,
 	response httpResponse,
 	adder func([]byte) int,
+) {
+	addGenericWithLimit(w, r, response, config.MaxRESTRequestSize, adder)
+}
+
+func addGenericWithLimit(
+	w http.ResponseWriter,
+	r *http.Request,
+	response httpResponse,
+	maxRequestSize int64,
+	adder func([]byte) int,
 ) {
 	var err error
 	var httpStatusCode int
@@ -197,7 +236,7 @@ func AddGeneric(
 		writeHTTPResponse(w, response, httpStatusCode)
 	}()
 
-	body, err := ioutil.ReadAll(io.LimitReader(r.Body, config.MaxRESTRequestSize))
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxRequestSize))
 	if err != nil {
 		response.setError(err)
 		httpStatusCode = httpStatusCodeForAdd(err)
@@ -327,8 +366,9 @@ func (r *AddBackendResponse) logFailure() {
 }
 
 type GetVersionResponse struct {
-	Version string `json:"version"`
-	Error   string `json:"error,omitempty"`
+	Version    string `json:"version"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Error      string `json:"error,omitempty"`
 }
 
 func GetVersion(w http.ResponseWriter, r *http.Request) {
@@ -340,11 +380,33 @@ func GetVersion(w http.ResponseWriter, r *http.Request) {
 				response.Error = err.Error()
 			}
 			response.Version = version
+			response.APIVersion = config.OrchestratorAPIVersion
 			return httpStatusCodeForGetUpdateList(err)
 		},
 	)
 }
 
+type LivenessResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+// Liveness reports whether the orchestrator is healthy and can reach its persistent store.  It
+// returns 503 if not, so that Kubernetes restarts a controller that can no longer do its job.
+func Liveness(w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), LivenessTimeout)
+	defer cancel()
+
+	response := &LivenessResponse{}
+	if err := orchestrator.Ping(ctx); err != nil {
+		log.WithField("error", err).Warning("Liveness check failed.")
+		response.Error = err.Error()
+		writeHTTPResponse(w, response, http.StatusServiceUnavailable)
+		return
+	}
+	writeHTTPResponse(w, response, http.StatusOK)
+}
+
 func AddBackend(w http.ResponseWriter, r *http.Request) {
 	response := &AddBackendResponse{}
 	AddGeneric(w, r, response,
@@ -426,6 +488,28 @@ func UpdateBackendState(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+func UpdateBackendCredentials(w http.ResponseWriter, r *http.Request) {
+	response := &UpdateBackendResponse{}
+	UpdateGeneric(w, r, "backend", response,
+		func(backendName string, body []byte) int {
+			request := new(storage.UpdateBackendCredentialsRequest)
+			err := json.Unmarshal(body, request)
+			if err != nil {
+				response.setError(fmt.Errorf("invalid JSON: %s", err.Error()))
+				return httpStatusCodeForGetUpdateList(err)
+			}
+			backend, err := orchestrator.UpdateBackendCredentials(backendName, request.Username, request.Password)
+			if err != nil {
+				response.Error = err.Error()
+			}
+			if backend != nil {
+				response.BackendID = backend.Name
+			}
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
 type ListBackendsResponse struct {
 	Backends []string `json:"backends"`
 	Error    string   `json:"error,omitempty"`
@@ -439,6 +523,12 @@ func ListBackends(w http.ResponseWriter, r *http.Request) {
 	response := &ListBackendsResponse{}
 	ListGeneric(w, r, response,
 		func() int {
+			selector, err := getLabelSelector(r)
+			if err != nil {
+				response.Error = err.Error()
+				response.setList(make([]string, 0))
+				return http.StatusBadRequest
+			}
 			backends, err := orchestrator.ListBackends()
 			backendNames := make([]string, 0, len(backends))
 			if err != nil {
@@ -446,7 +536,9 @@ func ListBackends(w http.ResponseWriter, r *http.Request) {
 				response.Error = err.Error()
 			} else if backends != nil {
 				for _, backend := range backends {
-					backendNames = append(backendNames, backend.Name)
+					if selector == nil || backend.MatchesLabels(selector) {
+						backendNames = append(backendNames, backend.Name)
+					}
 				}
 			}
 			response.setList(backendNames)
@@ -466,14 +558,22 @@ func IsValidUUID(s string) bool {
 	return err == nil
 }
 
+// GetBackend returns a backend, with any secrets in its config redacted unless the showSecrets
+// query parameter is true.
 func GetBackend(w http.ResponseWriter, r *http.Request) {
 	response := &GetBackendResponse{}
+	showSecrets := r.URL.Query().Get(ShowSecretsQueryParameter) == "true"
 	GetGeneric(w, r, "backend", response,
 		func(backend string) int {
 			var result *storage.BackendExternal
 			var err error
 			if IsValidUUID(backend) {
 				result, err = orchestrator.GetBackendByBackendUUID(backend)
+				if err == nil && showSecrets {
+					result, err = orchestrator.GetBackendWithSecrets(result.Name)
+				}
+			} else if showSecrets {
+				result, err = orchestrator.GetBackendWithSecrets(backend)
 			} else {
 				result, err = orchestrator.GetBackend(backend)
 			}
@@ -503,6 +603,46 @@ func GetBackendByBackendUUID(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+type GetBackendPoolsResponse struct {
+	Pools []*storage.PoolCapacityExternal `json:"pools"`
+	Error string                          `json:"error,omitempty"`
+}
+
+func GetBackendPools(w http.ResponseWriter, r *http.Request) {
+	response := &GetBackendPoolsResponse{}
+	GetGeneric(w, r, "backend", response,
+		func(backend string) int {
+			pools, err := orchestrator.GetBackendPools(backend)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Pools = pools
+			}
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
+// ListBackendVolumes lists the names of the volumes on a backend.
+func ListBackendVolumes(w http.ResponseWriter, r *http.Request) {
+	response := &ListVolumesResponse{}
+	GetGeneric(w, r, "backend", response,
+		func(backend string) int {
+			volumes, err := orchestrator.ListVolumesForBackend(backend)
+			volumeNames := make([]string, 0, len(volumes))
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				for _, volume := range volumes {
+					volumeNames = append(volumeNames, volume.Config.Name)
+				}
+			}
+			response.setList(volumeNames)
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
 // DeleteBackend calls OfflineBackend in the orchestrator, as we currently do
 // not allow for full deletion of backends due to the potential for race
 // conditions and the additional bookkeeping that would be required.
@@ -549,7 +689,12 @@ func AddVolume(w http.ResponseWriter, r *http.Request) {
 				response.setError(err)
 				return httpStatusCodeForAdd(err)
 			}
-			volume, err := orchestrator.AddVolume(volumeConfig)
+			var volume *storage.VolumeExternal
+			if volumeConfig.CloneSourceVolume == "" {
+				volume, err = orchestrator.AddVolume(volumeConfig)
+			} else {
+				volume, err = orchestrator.CloneVolume(volumeConfig)
+			}
 			if err != nil {
 				response.setError(err)
 			}
@@ -574,7 +719,16 @@ func ListVolumes(w http.ResponseWriter, r *http.Request) {
 	response := &ListVolumesResponse{}
 	ListGeneric(w, r, response,
 		func() int {
+			selector, err := getLabelSelector(r)
+			if err != nil {
+				response.Error = err.Error()
+				response.setList(make([]string, 0))
+				return http.StatusBadRequest
+			}
 			volumes, err := orchestrator.ListVolumes()
+			if err == nil && selector != nil {
+				volumes, err = filterVolumesByLabels(volumes, selector)
+			}
 			volumeNames := make([]string, 0, len(volumes))
 			if err != nil {
 				response.Error = err.Error()
@@ -589,6 +743,36 @@ func ListVolumes(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+// filterVolumesByLabels returns the volumes whose storage pools have labels satisfying a label
+// selector.
+func filterVolumesByLabels(
+	volumes []*storage.VolumeExternal, selector sa.Request,
+) ([]*storage.VolumeExternal, error) {
+
+	backends, err := orchestrator.ListBackends()
+	if err != nil {
+		return nil, err
+	}
+	backendsByUUID := make(map[string]*storage.BackendExternal, len(backends))
+	for _, backend := range backends {
+		backendsByUUID[backend.BackendUUID] = backend
+	}
+
+	filtered := make([]*storage.VolumeExternal, 0, len(volumes))
+	for _, volume := range volumes {
+		pool := &storage.PoolExternal{}
+		if backend, ok := backendsByUUID[volume.BackendUUID]; ok {
+			if backendPool, ok := backend.Storage[volume.Pool].(*storage.PoolExternal); ok {
+				pool = backendPool
+			}
+		}
+		if pool.MatchesLabels(selector) {
+			filtered = append(filtered, volume)
+		}
+	}
+	return filtered, nil
+}
+
 type GetVolumeResponse struct {
 	Volume *storage.VolumeExternal `json:"volume"`
 	Error  string                  `json:"error,omitempty"`
@@ -609,7 +793,143 @@ func GetVolume(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+type GetVolumeEventsResponse struct {
+	Events []*storage.VolumeEvent `json:"events"`
+	Error  string                 `json:"error,omitempty"`
+}
+
+// GetVolumeEvents returns the recent events recorded for a volume, newest first.
+func GetVolumeEvents(w http.ResponseWriter, r *http.Request) {
+	response := &GetVolumeEventsResponse{}
+	GetGeneric(w, r, "volume", response,
+		func(volName string) int {
+			events, err := orchestrator.GetVolumeEvents(volName)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Events = events
+			}
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
+type GetVolumeUsageResponse struct {
+	Usage *storage.VolumeUsage `json:"usage"`
+	Error string               `json:"error,omitempty"`
+}
+
+// GetVolumeUsage returns how much of a volume's space is in use, as reported by its backend.  The
+// usage is null if the backend's storage driver can't report it.
+func GetVolumeUsage(w http.ResponseWriter, r *http.Request) {
+	response := &GetVolumeUsageResponse{}
+	GetGeneric(w, r, "volume", response,
+		func(volName string) int {
+			usage, err := orchestrator.GetVolumeUsage(volName)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Usage = usage
+			}
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
+func GetVolumeByInternalName(w http.ResponseWriter, r *http.Request) {
+	response := &GetVolumeResponse{}
+	GetGeneric(w, r, "internalName", response,
+		func(internalName string) int {
+			volume, err := orchestrator.GetVolumeByInternalName(internalName)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Volume = volume
+			}
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
+type UpdateVolumeResponse struct {
+	Volume *storage.VolumeExternal `json:"volume"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+func (r *UpdateVolumeResponse) setError(err error) {
+	r.Error = err.Error()
+}
+
+func (r *UpdateVolumeResponse) isError() bool {
+	return r.Error != ""
+}
+
+func (r *UpdateVolumeResponse) logSuccess() {
+	log.WithFields(log.Fields{
+		"volume":             r.Volume.Config.Name,
+		"accessMode":         r.Volume.Config.AccessMode,
+		"deletionProtection": r.Volume.Config.DeletionProtection,
+		"handler":            "UpdateVolume",
+	}).Info("Updated a volume.")
+}
+
+func (r *UpdateVolumeResponse) logFailure() {
+	log.WithFields(log.Fields{
+		"handler": "UpdateVolume",
+	}).Error(r.Error)
+}
+
+func UpdateVolumeAccessMode(w http.ResponseWriter, r *http.Request) {
+	response := &UpdateVolumeResponse{}
+	UpdateGeneric(w, r, "volume", response,
+		func(volumeName string, body []byte) int {
+			request := new(storage.UpdateVolumeAccessModeRequest)
+			err := json.Unmarshal(body, request)
+			if err != nil {
+				response.setError(fmt.Errorf("invalid JSON: %s", err.Error()))
+				return httpStatusCodeForGetUpdateList(err)
+			}
+			volume, err := orchestrator.ChangeVolumeAccessMode(volumeName, request.AccessMode)
+			if err != nil {
+				response.setError(err)
+			}
+			if volume != nil {
+				response.Volume = volume
+			}
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
+func UpdateVolumeDeletionProtection(w http.ResponseWriter, r *http.Request) {
+	response := &UpdateVolumeResponse{}
+	UpdateGeneric(w, r, "volume", response,
+		func(volumeName string, body []byte) int {
+			request := new(storage.UpdateVolumeDeletionProtectionRequest)
+			err := json.Unmarshal(body, request)
+			if err != nil {
+				response.setError(fmt.Errorf("invalid JSON: %s", err.Error()))
+				return httpStatusCodeForGetUpdateList(err)
+			}
+			volume, err := orchestrator.SetVolumeDeletionProtection(volumeName, request.DeletionProtection)
+			if err != nil {
+				response.setError(err)
+			}
+			if volume != nil {
+				response.Volume = volume
+			}
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
+// DeleteVolume deletes a volume.  The "force" query parameter removes the volume from
+// Trident even if its backend fails to delete it.
 func DeleteVolume(w http.ResponseWriter, r *http.Request) {
+	if force, _ := strconv.ParseBool(r.URL.Query().Get("force")); force {
+		DeleteGeneric(w, r, orchestrator.ForceDeleteVolume, "volume")
+		return
+	}
 	DeleteGeneric(w, r, orchestrator.DeleteVolume, "volume")
 }
 
@@ -820,6 +1140,9 @@ func AddNode(w http.ResponseWriter, r *http.Request) {
 				response.setError(fmt.Errorf("invalid JSON: %s", err.Error()))
 				return httpStatusCodeForAdd(err)
 			}
+			if node.TopologyLabels == nil {
+				node.TopologyLabels = getNodeTopologyLabels(node.Name)
+			}
 			err = orchestrator.AddNode(node)
 			if err != nil {
 				response.setError(err)
@@ -830,6 +1153,28 @@ func AddNode(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+// getNodeTopologyLabels asks the CSI helper, if any, for the topology labels of the named node.
+// Failures are not fatal to node registration, so any error is merely logged.
+func getNodeTopologyLabels(nodeName string) map[string]string {
+	helperFrontend, err := orchestrator.GetFrontend(helpers.KubernetesHelper)
+	if err != nil {
+		return nil
+	}
+	helper, ok := helperFrontend.(helpers.HybridPlugin)
+	if !ok {
+		return nil
+	}
+	labels, err := helper.GetNodeTopologyLabels(nodeName)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"node":  nodeName,
+			"error": err,
+		}).Warning("Could not get node topology labels.")
+		return nil
+	}
+	return labels
+}
+
 type GetNodeResponse struct {
 	Node  *utils.Node `json:"node"`
 	Error string      `json:"error,omitempty"`
@@ -878,7 +1223,52 @@ func ListNodes(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+func ListReachableNodesForVolume(w http.ResponseWriter, r *http.Request) {
+	response := &ListNodesResponse{}
+	ListGenericOneArg(w, r, "volume", response,
+		func(volumeName string) int {
+			nodes, err := orchestrator.GetReachableNodesForVolume(volumeName)
+			nodeNames := make([]string, 0, len(nodes))
+			if err != nil {
+				response.Error = err.Error()
+			} else if nodes != nil {
+				for _, node := range nodes {
+					nodeNames = append(nodeNames, node.Name)
+				}
+			}
+			response.setList(nodeNames)
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
+// ListPublishedNodesForVolume lists the nodes a volume has been published to.
+func ListPublishedNodesForVolume(w http.ResponseWriter, r *http.Request) {
+	response := &ListNodesResponse{}
+	ListGenericOneArg(w, r, "volume", response,
+		func(volumeName string) int {
+			nodes, err := orchestrator.GetPublishedNodesForVolume(volumeName)
+			nodeNames := make([]string, 0, len(nodes))
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				for _, node := range nodes {
+					nodeNames = append(nodeNames, node.Name)
+				}
+			}
+			response.setList(nodeNames)
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
+// DeleteNode removes a node's record from Trident.  Node plugins delete their own records when they
+// stop, so a node's backend access is only revoked if cleanup is requested explicitly.
 func DeleteNode(w http.ResponseWriter, r *http.Request) {
+	if cleanup, _ := strconv.ParseBool(r.URL.Query().Get(CleanupQueryParameter)); cleanup {
+		DeleteGeneric(w, r, orchestrator.CleanupNode, "node")
+		return
+	}
 	DeleteGeneric(w, r, orchestrator.DeleteNode, "node")
 }
 
@@ -1001,6 +1391,155 @@ func AddSnapshot(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+type AddSnapshotGroupResponse struct {
+	Group       string   `json:"group"`
+	SnapshotIDs []string `json:"snapshotIDs"`
+	Error       string   `json:"error,omitempty"`
+}
+
+func (r *AddSnapshotGroupResponse) setError(err error) {
+	r.Error = err.Error()
+}
+
+func (r *AddSnapshotGroupResponse) isError() bool {
+	return r.Error != ""
+}
+
+func (r *AddSnapshotGroupResponse) logSuccess() {
+	log.WithFields(log.Fields{
+		"group":     r.Group,
+		"snapshots": r.SnapshotIDs,
+		"handler":   "AddSnapshotGroup",
+	}).Info("Added a new snapshot group.")
+}
+
+func (r *AddSnapshotGroupResponse) logFailure() {
+	log.WithFields(log.Fields{
+		"group":   r.Group,
+		"handler": "AddSnapshotGroup",
+	}).Error(r.Error)
+}
+
+func AddSnapshotGroup(w http.ResponseWriter, r *http.Request) {
+	response := &AddSnapshotGroupResponse{}
+	AddGeneric(w, r, response,
+		func(body []byte) int {
+			groupConfig := new(storage.SnapshotGroupConfig)
+			if err := json.Unmarshal(body, groupConfig); err != nil {
+				response.setError(fmt.Errorf("invalid JSON: %s", err.Error()))
+				return httpStatusCodeForAdd(err)
+			}
+			if err := groupConfig.Validate(); err != nil {
+				response.setError(err)
+				return httpStatusCodeForAdd(err)
+			}
+			response.Group = groupConfig.Name
+			snapshots, err := orchestrator.CreateSnapshotGroup(groupConfig.Volumes, groupConfig.Name)
+			if err != nil {
+				response.setError(err)
+			}
+			response.SnapshotIDs = make([]string, 0, len(snapshots))
+			for _, snapshot := range snapshots {
+				response.SnapshotIDs = append(response.SnapshotIDs, snapshot.ID())
+			}
+			return httpStatusCodeForAdd(err)
+		},
+	)
+}
+
+// DeleteSnapshot deletes a snapshot.  The "force" query parameter deletes the snapshot even if
+// volumes were cloned from it.
 func DeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	if force, _ := strconv.ParseBool(r.URL.Query().Get("force")); force {
+		DeleteGenericTwoArg(w, r, orchestrator.ForceDeleteSnapshot, "volume", "snapshot")
+		return
+	}
 	DeleteGenericTwoArg(w, r, orchestrator.DeleteSnapshot, "volume", "snapshot")
 }
+
+type ListTransactionsResponse struct {
+	Transactions []*persistentstore.VolumeTransaction `json:"transactions"`
+	Error        string                               `json:"error,omitempty"`
+}
+
+func ListTransactions(w http.ResponseWriter, r *http.Request) {
+	response := &ListTransactionsResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			transactions, err := orchestrator.ListVolumeTransactions()
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Transactions = transactions
+			}
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
+type ExportStateResponse struct {
+	State *persistentstore.State `json:"state"`
+	Error string                 `json:"error,omitempty"`
+}
+
+func ExportState(w http.ResponseWriter, r *http.Request) {
+	response := &ExportStateResponse{}
+	GetGenericNoArg(w, r, response,
+		func() int {
+			state, err := orchestrator.ExportState()
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.State = state
+			}
+			return httpStatusCodeForGetUpdateList(err)
+		},
+	)
+}
+
+type ImportStateResponse struct {
+	Result *persistentstore.ImportStateResult `json:"result"`
+	Error  string                             `json:"error,omitempty"`
+}
+
+func (r *ImportStateResponse) setError(err error) {
+	r.Error = err.Error()
+}
+
+func (r *ImportStateResponse) isError() bool {
+	return r.Error != ""
+}
+
+func (r *ImportStateResponse) logSuccess() {
+	log.WithFields(log.Fields{
+		"imported": r.Result.Imported,
+		"skipped":  r.Result.Skipped,
+		"handler":  "ImportState",
+	}).Info("Imported state.")
+}
+
+func (r *ImportStateResponse) logFailure() {
+	log.WithFields(log.Fields{
+		"handler": "ImportState",
+	}).Error(r.Error)
+}
+
+func ImportState(w http.ResponseWriter, r *http.Request) {
+	response := &ImportStateResponse{}
+	addGenericWithLimit(w, r, response, config.MaxRESTStateRequestSize,
+		func(body []byte) int {
+			state := new(persistentstore.State)
+			if err := json.Unmarshal(body, state); err != nil {
+				response.setError(fmt.Errorf("invalid JSON: %s", err.Error()))
+				return httpStatusCodeForAdd(err)
+			}
+			result, err := orchestrator.ImportState(state)
+			if err != nil {
+				response.setError(err)
+			} else {
+				response.Result = result
+			}
+			return httpStatusCodeForAdd(err)
+		},
+	)
+}
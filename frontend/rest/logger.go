@@ -10,11 +10,18 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// RequestIDHeader is the response header carrying the ID logged for each REST call.
+const RequestIDHeader = "X-Request-ID"
+
 func Logger(inner http.Handler, name string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		requestId := xid.New()
 		logRestCallInfo("REST API call received.", r, start, requestId, name)
+
+		// Return the request ID so clients can correlate their calls with the server log
+		w.Header().Set(RequestIDHeader, requestId.String())
+
 		inner.ServeHTTP(w, r)
 		logRestCallInfo("REST API call complete.", r, start, requestId, name)
 	})
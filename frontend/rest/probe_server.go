@@ -0,0 +1,68 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/core"
+)
+
+// ProbeServer serves only the liveness probe handler.  Unlike the HTTP REST frontend, which
+// listens on the loopback address, it may listen where the kubelet can reach it without
+// exposing the rest of the API.
+type ProbeServer struct {
+	server *http.Server
+}
+
+func NewProbeServer(p core.Orchestrator, address, port string) *ProbeServer {
+
+	orchestrator = p
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, Liveness)
+
+	probeServer := &ProbeServer{
+		server: &http.Server{
+			Addr:         fmt.Sprintf("%s:%s", address, port),
+			Handler:      mux,
+			ReadTimeout:  HTTPTimeout,
+			WriteTimeout: HTTPTimeout,
+		},
+	}
+
+	log.WithField("address", probeServer.server.Addr).Info("Initializing probe frontend.")
+
+	return probeServer
+}
+
+func (s *ProbeServer) Activate() error {
+	go func() {
+		log.WithField("address", s.server.Addr).Info("Activating probe frontend.")
+		err := s.server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	return nil
+}
+
+func (s *ProbeServer) Deactivate() error {
+	log.WithField("address", s.server.Addr).Info("Deactivating probe frontend.")
+	ctx, cancel := context.WithTimeout(context.Background(), HTTPTimeout)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+func (s *ProbeServer) GetName() string {
+	return "probe"
+}
+
+func (s *ProbeServer) Version() string {
+	return config.OrchestratorAPIVersion
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/netapp/trident/core"
+	persistentstore "github.com/netapp/trident/persistent_store"
+)
+
+// unreachableStoreClient is an in-memory store whose Ping fails, as if it could not be reached.
+type unreachableStoreClient struct {
+	persistentstore.Client
+}
+
+func (c *unreachableStoreClient) Ping(ctx context.Context) error {
+	return errors.New("store unreachable")
+}
+
+func TestLiveness(t *testing.T) {
+
+	for _, c := range []struct {
+		name           string
+		storeClient    persistentstore.Client
+		expectedStatus int
+	}{
+		{"healthy", persistentstore.NewInMemoryClient(), http.StatusOK},
+		{"store unreachable", &unreachableStoreClient{persistentstore.NewInMemoryClient()},
+			http.StatusServiceUnavailable},
+	} {
+		o := core.NewTridentOrchestrator(c.storeClient)
+		if err := o.Bootstrap(); err != nil {
+			t.Fatalf("%s: could not bootstrap the orchestrator: %v", c.name, err)
+		}
+		NewProbeServer(o, "127.0.0.1", "0")
+
+		recorder := httptest.NewRecorder()
+		Liveness(recorder, httptest.NewRequest("GET", LivenessPath, nil))
+
+		if recorder.Code != c.expectedStatus {
+			t.Errorf("%s: expected status %d, got %d", c.name, c.expectedStatus, recorder.Code)
+		}
+	}
+}
@@ -31,6 +31,12 @@ var routes = Routes{
 		config.VersionURL,
 		GetVersion,
 	},
+	Route{
+		"Liveness",
+		"GET",
+		LivenessPath,
+		Liveness,
+	},
 	Route{
 		"AddBackend",
 		"POST",
@@ -49,12 +55,30 @@ var routes = Routes{
 		config.BackendURL + "/{backend}" + "/state",
 		UpdateBackendState,
 	},
+	Route{
+		"UpdateBackendCredentials",
+		"PATCH",
+		config.BackendURL + "/{backend}" + "/credentials",
+		UpdateBackendCredentials,
+	},
 	Route{
 		"GetBackend",
 		"GET",
 		config.BackendURL + "/{backend}",
 		GetBackend,
 	},
+	Route{
+		"GetBackendPools",
+		"GET",
+		config.BackendURL + "/{backend}" + "/pools",
+		GetBackendPools,
+	},
+	Route{
+		"ListBackendVolumes",
+		"GET",
+		config.BackendURL + "/{backend}" + "/volume",
+		ListBackendVolumes,
+	},
 	Route{
 		"ListBackends",
 		"GET",
@@ -85,6 +109,48 @@ var routes = Routes{
 		config.VolumeURL,
 		ListVolumes,
 	},
+	Route{
+		"UpdateVolumeAccessMode",
+		"POST",
+		config.VolumeURL + "/{volume}/accessMode",
+		UpdateVolumeAccessMode,
+	},
+	Route{
+		"UpdateVolumeDeletionProtection",
+		"POST",
+		config.VolumeURL + "/{volume}/deletionProtection",
+		UpdateVolumeDeletionProtection,
+	},
+	Route{
+		"GetVolumeEvents",
+		"GET",
+		config.VolumeURL + "/{volume}/events",
+		GetVolumeEvents,
+	},
+	Route{
+		"GetVolumeUsage",
+		"GET",
+		config.VolumeURL + "/{volume}/usage",
+		GetVolumeUsage,
+	},
+	Route{
+		"ListReachableNodesForVolume",
+		"GET",
+		config.VolumeURL + "/{volume}/reachableNodes",
+		ListReachableNodesForVolume,
+	},
+	Route{
+		"ListPublishedNodesForVolume",
+		"GET",
+		config.VolumeURL + "/{volume}/publishedNodes",
+		ListPublishedNodesForVolume,
+	},
+	Route{
+		"GetVolumeByInternalName",
+		"GET",
+		config.VolumeURL + "/internalName/{internalName}",
+		GetVolumeByInternalName,
+	},
 	Route{
 		"DeleteVolume",
 		"DELETE",
@@ -169,10 +235,34 @@ var routes = Routes{
 		config.SnapshotURL,
 		AddSnapshot,
 	},
+	Route{
+		"AddSnapshotGroup",
+		"POST",
+		config.SnapshotURL + "/group",
+		AddSnapshotGroup,
+	},
 	Route{
 		"DeleteSnapshot",
 		"DELETE",
 		config.SnapshotURL + "/{volume}/{snapshot}",
 		DeleteSnapshot,
 	},
+	Route{
+		"ExportState",
+		"GET",
+		config.StateURL,
+		ExportState,
+	},
+	Route{
+		"ImportState",
+		"POST",
+		config.StateURL,
+		ImportState,
+	},
+	Route{
+		"ListTransactions",
+		"GET",
+		config.TransactionURL,
+		ListTransactions,
+	},
 }
@@ -23,6 +23,7 @@ import (
 	plainhelper "github.com/netapp/trident/frontend/csi/helpers/plain"
 	"github.com/netapp/trident/frontend/docker"
 	"github.com/netapp/trident/frontend/kubernetes"
+	"github.com/netapp/trident/frontend/metrics"
 	"github.com/netapp/trident/frontend/rest"
 	"github.com/netapp/trident/logging"
 	persistentstore "github.com/netapp/trident/persistent_store"
@@ -39,6 +40,24 @@ var (
 	k8sConfigPath = flag.String("k8s_config_path", "", "Path to KubeConfig file.")
 	k8sPod        = flag.Bool("k8s_pod", false, "Enables dynamic storage provisioning "+
 		"for Kubernetes if running in a pod.")
+	k8sCacheSyncPeriod = flag.Duration("k8s_cache_sync_period", k8shelper.CacheSyncPeriod,
+		"Resync period of the Kubernetes PVC, PV, storage class and node caches.")
+	k8sResizeSyncPeriod = flag.Duration("k8s_resize_sync_period", k8shelper.ResizeSyncPeriod,
+		"Resync period of the Kubernetes PVC resize handler; may not be shorter than the cache sync period.")
+	k8sCacheBackoffInitialInterval = flag.Duration("k8s_cache_backoff_initial_interval",
+		k8shelper.CacheBackoffInitialInterval, "Initial interval between checks for an object in the Kubernetes caches.")
+	k8sCacheBackoffMultiplier = flag.Float64("k8s_cache_backoff_multiplier", k8shelper.CacheBackoffMultiplier,
+		"Factor by which the interval between checks for an object in the Kubernetes caches grows.")
+	k8sCacheBackoffMaxInterval = flag.Duration("k8s_cache_backoff_max_interval", k8shelper.CacheBackoffMaxInterval,
+		"Maximum interval between checks for an object in the Kubernetes caches; may not be shorter than the "+
+			"initial interval.")
+	k8sProvisioners = flag.String("k8s_provisioners", csi.Provisioner,
+		"Comma-separated storage class provisioner names handled by Trident, such as the legacy "+
+			"provisioner during a migration to CSI.")
+	k8sSkipLegacyPVDeletion = flag.Bool("k8s_skip_legacy_pv_deletion", false,
+		"Log released legacy (non-CSI) PVs instead of deleting them and their volumes.")
+	k8sEventComponent = flag.String("k8s_event_component", csi.Provisioner,
+		"Source component of the Kubernetes events Trident records, to tell apart multiple Trident deployments.")
 
 	// Docker
 	driverName = flag.String("volume_driver", "netapp", "Register as a Docker "+
@@ -50,12 +69,20 @@ var (
 	// CSI
 	csiEndpoint = flag.String("csi_endpoint", "", "Register as a CSI storage "+
 		"provider with this endpoint")
-	csiNodeName = flag.String("csi_node_name", "", "CSI node name")
-	csiRole     = flag.String("csi_role", "", fmt.Sprintf("CSI role to play: '%s' or '%s'", csi.CSIController, csi.CSINode))
+	csiNodeName       = flag.String("csi_node_name", "", "CSI node name")
+	csiRole           = flag.String("csi_role", "", fmt.Sprintf("CSI role to play: '%s' or '%s'", csi.CSIController, csi.CSINode))
+	csiLeaderElection = flag.Bool("csi_leader_election", false, "Serve CSI controller requests only while "+
+		"elected leader among multiple controller replicas")
+	csiNFSMountOptions = flag.String("csi_nfs_mount_options", "", "Default mount options (comma-separated) "+
+		"for NFS volumes that don't specify any (e.g., -csi_nfs_mount_options=vers=4.1,nconnect=4)")
+	csiReadWriteOncePod = flag.Bool("csi_read_write_once_pod", false, "Advertise the CSI single-node "+
+		"writer access modes, so that Kubernetes 1.22+ can enforce ReadWriteOncePod volumes")
 
 	// Persistence
-	etcdV2 = flag.String("etcd_v2", "", "etcd server (v2 API) for "+
+	etcdV2 = flag.String("etcd_v2", "", "etcd server(s) (v2 API, comma-separated) for "+
 		"persisting orchestrator state (e.g., -etcd_v2=http://127.0.0.1:8001)")
+	etcdV2RetryBudget = flag.Duration("etcd_v2_retry_budget", persistentstore.DefaultEtcdV2RetryBudget,
+		"How long etcd (v2 API) operations are retried while the cluster is unavailable; 0 disables retries")
 	etcdV3 = flag.String("etcd_v3", "", "etcd server (v3 API) for "+
 		"persisting orchestrator state (e.g., -etcd_v3=http://127.0.0.1:8001)")
 	etcdV3Cert = flag.String("etcd_v3_cert", "/root/certs/etcd-client.crt",
@@ -70,6 +97,16 @@ var (
 		"as the source of truth.  No data is stored anywhere else.")
 	useCRD = flag.Bool("crd_persistence", false, "Uses CRDs for persisting orchestrator state.")
 
+	// Volume transactions
+	txnReconcilePeriod = flag.Duration("txn_reconcile_period", core.DefaultTransactionReconcilePeriod,
+		"How often volume transactions stranded by failed operations are reconciled; 0 disables reconciliation")
+	txnMaxAge = flag.Duration("txn_max_age", core.DefaultTransactionMaxAge,
+		"How long a volume transaction that can't be reconciled is kept before it is deleted")
+
+	// Snapshot retention
+	snapshotPrunePeriod = flag.Duration("snapshot_prune_period", core.DefaultSnapshotPrunePeriod,
+		"How often snapshots exceeding their storage class retention policy are pruned; 0 disables pruning")
+
 	// HTTP REST interface
 	address    = flag.String("address", "127.0.0.1", "Storage orchestrator HTTP API address")
 	port       = flag.String("port", "8000", "Storage orchestrator HTTP API port")
@@ -85,10 +122,22 @@ var (
 	httpsClientKey  = flag.String("https_client_key", rest.ClientKeyPath, "HTTPS client private key")
 	httpsClientCert = flag.String("https_client_cert", rest.ClientCertPath, "HTTPS client certificate")
 
+	// Metrics interface
+	metricsAddress = flag.String("metrics_address", "", "Prometheus metrics address")
+	metricsPort    = flag.String("metrics_port", "8001", "Prometheus metrics port")
+	enableMetrics  = flag.Bool("metrics", false, "Enable Prometheus metrics interface")
+
+	// Liveness probe
+	probeAddress = flag.String("probe_address", "", "Liveness probe address")
+	probePort    = flag.String("probe_port", "", "Liveness probe port; the probe is disabled if not specified")
+
 	storeClient      persistentstore.Client
 	enableKubernetes bool
 	enableDocker     bool
 	enableCSI        bool
+
+	// The K8S helper elects a leader among CSI controller replicas, if requested
+	leaderElector *k8shelper.Plugin
 )
 
 func shouldEnableTLS() bool {
@@ -187,10 +236,13 @@ func processCmdLineArgs() {
 		}
 	} else if *etcdV2 != "" {
 		log.Debug("Trident is configured with an etcdv2 client.")
-		storeClient, err = persistentstore.NewEtcdClientV2(*etcdV2)
+		var etcdV2Client *persistentstore.EtcdClientV2
+		etcdV2Client, err = persistentstore.NewEtcdClientV2(*etcdV2)
 		if err != nil {
 			log.Fatalf("Unable to create the etcd V2 client. %v", err)
 		}
+		etcdV2Client.SetRetryBudget(*etcdV2RetryBudget)
+		storeClient = etcdV2Client
 	} else if *useInMemory {
 		log.Debug("Trident is configured with an in-memory store client.")
 		storeClient = persistentstore.NewInMemoryClient()
@@ -213,6 +265,9 @@ func processCmdLineArgs() {
 	}
 
 	config.UsingPassthroughStore = storeClient.GetType() == persistentstore.PassthroughStore
+
+	// Record metrics for all persistent store operations
+	storeClient = persistentstore.NewMetricsClient(storeClient)
 }
 
 func main() {
@@ -272,6 +327,24 @@ func main() {
 		}
 	}
 
+	// Create metrics frontend
+	if *enableMetrics {
+		if *metricsPort == "" {
+			log.Warning("Metrics interface will not be available (port not specified).")
+		} else {
+			metricsServer := metrics.NewMetricsServer(*metricsAddress, *metricsPort)
+			preBootstrapFrontends = append(preBootstrapFrontends, metricsServer)
+			log.WithFields(log.Fields{"name": metricsServer.GetName()}).Info("Added frontend.")
+		}
+	}
+
+	// Create liveness probe frontend
+	if *probePort != "" {
+		probeServer := rest.NewProbeServer(orchestrator, *probeAddress, *probePort)
+		preBootstrapFrontends = append(preBootstrapFrontends, probeServer)
+		log.WithFields(log.Fields{"name": probeServer.GetName()}).Info("Added frontend.")
+	}
+
 	// Create Kubernetes *or* Docker *or* CSI/K8S frontend
 	if enableKubernetes {
 
@@ -336,16 +409,34 @@ func main() {
 		}
 
 		var hybridFrontend frontend.Plugin
+		cacheBackoff := k8shelper.CacheBackoffConfig{
+			InitialInterval: *k8sCacheBackoffInitialInterval,
+			Multiplier:      *k8sCacheBackoffMultiplier,
+			MaxInterval:     *k8sCacheBackoffMaxInterval,
+		}
+		provisioners := strings.Split(*k8sProvisioners, ",")
 		if *k8sAPIServer != "" {
-			hybridFrontend, err = k8shelper.NewPlugin(orchestrator, *k8sAPIServer, *k8sConfigPath)
+			hybridFrontend, err = k8shelper.NewPlugin(orchestrator, *k8sAPIServer, *k8sConfigPath,
+				*k8sCacheSyncPeriod, *k8sResizeSyncPeriod, cacheBackoff, provisioners, *k8sSkipLegacyPVDeletion,
+				*k8sEventComponent)
 		} else if *k8sPod {
-			hybridFrontend, err = k8shelper.NewPluginInCluster(orchestrator)
+			hybridFrontend, err = k8shelper.NewPluginInCluster(orchestrator, *k8sCacheSyncPeriod,
+				*k8sResizeSyncPeriod, cacheBackoff, provisioners, *k8sSkipLegacyPVDeletion, *k8sEventComponent)
 		} else {
 			hybridFrontend = plainhelper.NewPlugin(orchestrator)
 		}
 		if err != nil {
 			log.Fatalf("Unable to start the K8S hybrid frontend. %v", err)
 		}
+		if *csiLeaderElection {
+			if *csiRole != csi.CSIController {
+				log.Fatal("CSI leader election is only supported for the controller role.")
+			}
+			var ok bool
+			if leaderElector, ok = hybridFrontend.(*k8shelper.Plugin); !ok {
+				log.Fatal("CSI leader election requires the K8S hybrid frontend.")
+			}
+		}
 		orchestrator.AddFrontend(hybridFrontend)
 		postBootstrapFrontends = append(postBootstrapFrontends, hybridFrontend)
 		hybridPlugin := hybridFrontend.(helpers.HybridPlugin)
@@ -358,13 +449,14 @@ func main() {
 		var csiFrontend *csi.Plugin
 		switch *csiRole {
 		case csi.CSIController:
-			csiFrontend, err = csi.NewControllerPlugin(*csiNodeName, *csiEndpoint, orchestrator, &hybridPlugin)
+			csiFrontend, err = csi.NewControllerPlugin(*csiNodeName, *csiEndpoint, *csiNFSMountOptions,
+				*csiReadWriteOncePod, orchestrator, &hybridPlugin)
 		case csi.CSINode:
 			csiFrontend, err = csi.NewNodePlugin(*csiNodeName, *csiEndpoint, *httpsCACert, *httpsClientCert,
-				*httpsClientKey, orchestrator)
+				*httpsClientKey, *csiReadWriteOncePod, orchestrator)
 		case csi.CSIAllInOne:
 			csiFrontend, err = csi.NewAllInOnePlugin(*csiNodeName, *csiEndpoint, *httpsCACert, *httpsClientCert,
-				*httpsClientKey, orchestrator, &hybridPlugin)
+				*httpsClientKey, *csiNFSMountOptions, *csiReadWriteOncePod, orchestrator, &hybridPlugin)
 		}
 		if err != nil {
 			log.Fatalf("Unable to start the CSI frontend. %v", err)
@@ -394,8 +486,29 @@ func main() {
 	for _, f := range preBootstrapFrontends {
 		f.Activate()
 	}
+	if leaderElector != nil {
+		// Standby replicas don't bootstrap until elected, so they never act on stale state and their
+		// REST interface refuses requests as not ready.
+		identity, err := os.Hostname()
+		if err != nil {
+			log.Fatalf("Unable to determine the leader election identity. %v", err)
+		}
+		leaderElector.WaitForLeadership(identity, func() {
+			// A former leader must not reconcile transactions or prune snapshots that the new leader now owns
+			orchestrator.StopTransactionReconciler()
+			orchestrator.StopSnapshotPruner()
+			log.Fatal("Lost CSI controller leadership, exiting.")
+		})
+	}
 	if err = orchestrator.Bootstrap(); err != nil {
 		log.Error(err.Error())
+	} else {
+		if *txnReconcilePeriod > 0 {
+			orchestrator.StartTransactionReconciler(*txnReconcilePeriod, *txnMaxAge)
+		}
+		if *snapshotPrunePeriod > 0 {
+			orchestrator.StartSnapshotPruner(*snapshotPrunePeriod)
+		}
 	}
 	for _, f := range postBootstrapFrontends {
 		f.Activate()
@@ -406,11 +519,16 @@ func main() {
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	<-c
 	log.Info("Shutting down.")
-	for _, f := range postBootstrapFrontends {
-		f.Deactivate()
+	orchestrator.StopTransactionReconciler()
+	orchestrator.StopSnapshotPruner()
+
+	// Deactivate the frontends in the reverse of the order they were activated, so that CSI finishes
+	// in-flight operations while the frontends it depends on are still running
+	for i := len(postBootstrapFrontends) - 1; i >= 0; i-- {
+		postBootstrapFrontends[i].Deactivate()
 	}
-	for _, f := range preBootstrapFrontends {
-		f.Deactivate()
+	for i := len(preBootstrapFrontends) - 1; i >= 0; i-- {
+		preBootstrapFrontends[i].Deactivate()
 	}
 	storeClient.Stop()
 }
@@ -0,0 +1,31 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"encoding/json"
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// checkStoredBackend decides whether adding a backend whose key already exists succeeded.  A
+// write that failed transiently may still have been persisted, in which case a retry finds the
+// identical backend already stored and is treated as a success.  A different backend stored
+// under the same name remains a KeyExistsErr.
+func checkStoredBackend(storedJSON, backendJSON, key string) error {
+
+	var stored, backend interface{}
+	if err := json.Unmarshal([]byte(storedJSON), &stored); err != nil {
+		return NewPersistentStoreError(KeyExistsErr, key)
+	}
+	if err := json.Unmarshal([]byte(backendJSON), &backend); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(stored, backend) {
+		return NewPersistentStoreError(KeyExistsErr, key)
+	}
+
+	log.WithField("key", key).Debug("Identical backend already stored, treating add as successful.")
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCheckStoredBackend(t *testing.T) {
+
+	marshal := func(password string) string {
+		backendJSON, err := json.Marshal(getOntapNASBackend("nas", "10.0.0.1", password).ConstructPersistent())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(backendJSON)
+	}
+
+	// An identical backend, even if stored with different formatting, means the add succeeded
+	var indented interface{}
+	if err := json.Unmarshal([]byte(marshal("secret")), &indented); err != nil {
+		t.Fatal(err)
+	}
+	indentedJSON, err := json.MarshalIndent(indented, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkStoredBackend(string(indentedJSON), marshal("secret"), "/backend/nas"); err != nil {
+		t.Errorf("Expected an identical stored backend to be accepted, got %v", err)
+	}
+
+	// A different backend with the same name is a duplicate
+	for name, storedJSON := range map[string]string{
+		"different": marshal("other"),
+		"corrupt":   "{",
+	} {
+		if err := checkStoredBackend(storedJSON, marshal("secret"), "/backend/nas"); !MatchKeyExistsErr(err) {
+			t.Errorf("%s: expected a KeyExistsErr, got %v", name, err)
+		}
+	}
+}
@@ -46,6 +46,7 @@ func (in *TridentSnapshot) Apply(persistent *storage.SnapshotPersistent) error {
 	in.Spec.Raw = config
 	in.SizeBytes = persistent.SizeBytes
 	in.Created = persistent.Created
+	in.State = string(persistent.State)
 
 	return nil
 }
@@ -58,6 +59,7 @@ func (in *TridentSnapshot) Persistent() (*storage.SnapshotPersistent, error) {
 	persistent.Config = &storage.SnapshotConfig{}
 	persistent.SizeBytes = in.SizeBytes
 	persistent.Created = in.Created
+	persistent.State = storage.SnapshotState(in.State)
 
 	return persistent, json.Unmarshal(in.Spec.Raw, persistent.Config)
 }
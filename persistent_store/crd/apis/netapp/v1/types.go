@@ -75,6 +75,8 @@ type TridentVolume struct {
 	Orphaned bool `json:"orphaned"`
 	// State records the TridentVolume's state
 	State string `json:"state"`
+	// PublishedNodes names the nodes the TridentVolume is published to
+	PublishedNodes []string `json:"publishedNodes,omitempty"`
 }
 
 // TridentVolumeList is a list of TridentVolume objects.
@@ -199,6 +201,8 @@ type TridentSnapshot struct {
 	Created string `json:"dateCreated"`
 	// The size of the volume at the time the snapshot was created
 	SizeBytes int64 `json:"size"`
+	// Whether the snapshot is ready to use
+	State string `json:"state,omitempty"`
 }
 
 // TridentSnapshotList is a list of TridentSnapshot objects.
@@ -74,10 +74,11 @@ func TestVolume_Persistent(t *testing.T) {
 		StorageClass: "gold",
 	}
 	vol := &storage.Volume{
-		Config:      &volConfig,
-		BackendUUID: "686979c7-6960-4380-a14d-2d740a13f0f5",
-		Pool:        "aggr1",
-		State:       storage.VolumeStateOnline,
+		Config:         &volConfig,
+		BackendUUID:    "686979c7-6960-4380-a14d-2d740a13f0f5",
+		Pool:           "aggr1",
+		State:          storage.VolumeStateOnline,
+		PublishedNodes: []string{"node1", "node2"},
 	}
 
 	// Build Kubernetes Object
@@ -90,10 +91,11 @@ func TestVolume_Persistent(t *testing.T) {
 			Name:       NameFix(volConfig.Name),
 			Finalizers: GetTridentFinalizers(),
 		},
-		BackendUUID: vol.BackendUUID,
-		Orphaned:    false,
-		State:       string(storage.VolumeStateOnline),
-		Pool:        vol.Pool,
+		BackendUUID:    vol.BackendUUID,
+		Orphaned:       false,
+		State:          string(storage.VolumeStateOnline),
+		Pool:           vol.Pool,
+		PublishedNodes: vol.PublishedNodes,
 		Config: runtime.RawExtension{
 			Raw: MustEncode(json.Marshal(vol.ConstructExternal().Config)),
 		},
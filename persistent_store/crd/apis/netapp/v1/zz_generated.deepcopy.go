@@ -403,6 +403,11 @@ func (in *TridentVolume) DeepCopyInto(out *TridentVolume) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Config.DeepCopyInto(&out.Config)
+	if in.PublishedNodes != nil {
+		in, out := &in.PublishedNodes, &out.PublishedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -3,6 +3,8 @@
 package persistentstore
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"strings"
@@ -31,6 +33,9 @@ var (
 	getOpts  = metav1.GetOptions{}
 )
 
+// volumeEventsAnnotation holds the JSON-encoded recent events of a TridentVolume
+const volumeEventsAnnotation = "trident.netapp.io/volumeEvents"
+
 // CRDClientV1 stores persistent state in CRD objects in Kubernetes
 type CRDClientV1 struct {
 	client    versioned.Interface
@@ -51,7 +56,7 @@ func NewCRDClientV1(apiServerIP, kubeConfigPath string) (*CRDClientV1, error) {
 	}
 
 	// Create the CLI-based Kubernetes client
-	client, err := cliclient.NewKubectlClient("")
+	client, err := cliclient.NewKubectlClient("", "")
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize CRD client; %v", err)
 	}
@@ -100,6 +105,13 @@ func newCRDClientV1(kubeConfig *rest.Config, tridentNamespace string) (*CRDClien
 	}, err
 }
 
+// Ping checks that the Kubernetes API server can be reached by listing at most one Trident
+// version CR.
+func (k *CRDClientV1) Ping(ctx context.Context) error {
+	_, err := k.client.TridentV1().TridentVersions(k.namespace).List(metav1.ListOptions{Limit: 1})
+	return err
+}
+
 func (k *CRDClientV1) GetVersion() (*config.PersistentStateVersion, error) {
 
 	versionList, err := k.client.TridentV1().TridentVersions(k.namespace).List(listOpts)
@@ -396,10 +408,17 @@ func (k *CRDClientV1) ReplaceBackendAndUpdateVolumes(origBackend, newBackend *st
 		"newBackend.Name":         newBackend.Name,
 	}).Debug("ReplaceBackendAndUpdateVolumes.")
 
+	if err := validateBackendReplacement(origBackend, newBackend); err != nil {
+		return err
+	}
+
 	backend, err := k.getBackendCRD(origBackend.Name)
 	if err != nil {
 		return err
 	}
+
+	// Volumes refer to the backend by UUID, so the replacement must keep it
+	newBackend.BackendUUID = backend.BackendUUID
 	log.WithFields(log.Fields{
 		"backend":                 backend,
 		"backend.Name":            backend.Name,
@@ -542,10 +561,28 @@ func (k *CRDClientV1) DeleteVolumeIgnoreNotFound(volume *storage.Volume) error {
 }
 
 func (k *CRDClientV1) GetVolumes() ([]*storage.VolumeExternal, error) {
+	return getAllVolumePages(k.GetVolumesPaged)
+}
 
-	volumeList, err := k.client.TridentV1().TridentVolumes(k.namespace).List(listOpts)
+// GetVolumesPaged retrieves up to limit volumes using the Kubernetes API's chunked list
+// support.  The continue token is the one issued by the API server, and it is empty if
+// there are no more volumes.  Volumes being deleted are skipped, so a page may hold fewer
+// than limit volumes even when more remain.
+func (k *CRDClientV1) GetVolumesPaged(
+	continueToken string, limit int,
+) ([]*storage.VolumeExternal, string, error) {
+
+	if err := validatePageLimit(limit); err != nil {
+		return nil, "", err
+	}
+
+	pageOpts := listOpts
+	pageOpts.Limit = int64(limit)
+	pageOpts.Continue = continueToken
+
+	volumeList, err := k.client.TridentV1().TridentVolumes(k.namespace).List(pageOpts)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	results := make([]*storage.VolumeExternal, 0)
@@ -562,13 +599,13 @@ func (k *CRDClientV1) GetVolumes() ([]*storage.VolumeExternal, error) {
 
 		persistentVolume, err := item.Persistent()
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		results = append(results, persistentVolume)
 	}
 
-	return results, nil
+	return results, volumeList.Continue, nil
 }
 
 func (k *CRDClientV1) DeleteVolumes() error {
@@ -588,6 +625,78 @@ func (k *CRDClientV1) DeleteVolumes() error {
 	return nil
 }
 
+// DeleteVolumesForBackend deletes all volume CRs that refer to the specified backend.
+func (k *CRDClientV1) DeleteVolumesForBackend(backendUUID string) error {
+
+	volumeList, err := k.client.TridentV1().TridentVolumes(k.namespace).List(listOpts)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range volumeList.Items {
+		if item.BackendUUID != backendUUID {
+			continue
+		}
+		err := k.client.TridentV1().TridentVolumes(k.namespace).Delete(item.ObjectMeta.Name, k.deleteOpts())
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetVolumeEvents returns the recent events recorded for a volume, newest first.  Events are
+// kept in an annotation on the volume's CR, so none are returned for an unknown volume.
+func (k *CRDClientV1) GetVolumeEvents(volName string) ([]*storage.VolumeEvent, error) {
+
+	events := make([]*storage.VolumeEvent, 0)
+
+	volume, err := k.client.TridentV1().TridentVolumes(k.namespace).Get(v1.NameFix(volName), getOpts)
+	if errors.IsNotFound(err) {
+		return events, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	eventsJSON, ok := volume.Annotations[volumeEventsAnnotation]
+	if !ok {
+		return events, nil
+	}
+	if err = json.Unmarshal([]byte(eventsJSON), &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// UpdateVolumeEvents replaces the events recorded for a volume.  The volume's CR must exist.
+func (k *CRDClientV1) UpdateVolumeEvents(volName string, events []*storage.VolumeEvent) error {
+
+	volume, err := k.client.TridentV1().TridentVolumes(k.namespace).Get(v1.NameFix(volName), getOpts)
+	if errors.IsNotFound(err) {
+		return NewPersistentStoreError(KeyNotFoundErr, volName)
+	} else if err != nil {
+		return err
+	}
+
+	eventsJSON, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	if volume.Annotations == nil {
+		volume.Annotations = make(map[string]string)
+	}
+	volume.Annotations[volumeEventsAnnotation] = string(eventsJSON)
+
+	_, err = k.client.TridentV1().TridentVolumes(k.namespace).Update(volume)
+	return err
+}
+
+// DeleteVolumeEvents does nothing, since a volume's events are deleted along with its CR.
+func (k *CRDClientV1) DeleteVolumeEvents(volName string) error {
+	return nil
+}
+
 func (k *CRDClientV1) AddVolumeTransaction(volTxn *VolumeTransaction) error {
 
 	newTtxn, err := v1.NewTridentTransaction(string(volTxn.Op), volTxn.Config)
@@ -941,6 +1050,45 @@ func (k *CRDClientV1) GetSnapshots() ([]*storage.SnapshotPersistent, error) {
 	return results, nil
 }
 
+// GetSnapshotsForVolume retrieves all snapshots of a volume.  Snapshot CRs are named by a
+// sanitized form of the snapshot ID, so they are filtered by their volume name.
+func (k *CRDClientV1) GetSnapshotsForVolume(volumeName string) ([]*storage.SnapshotPersistent, error) {
+
+	snapshots, err := k.GetSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*storage.SnapshotPersistent, 0)
+	for _, snapshot := range snapshots {
+		if snapshot.Config.VolumeName == volumeName {
+			results = append(results, snapshot)
+		}
+	}
+
+	return results, nil
+}
+
+// UpdateSnapshot updates a snapshot's state in the persistent store
+func (k *CRDClientV1) UpdateSnapshot(update *storage.Snapshot) error {
+
+	snapshot, err := k.client.TridentV1().TridentSnapshots(k.namespace).Get(v1.NameFix(update.ID()), getOpts)
+	if err != nil {
+		return err
+	}
+
+	if err = snapshot.Apply(update.ConstructPersistent()); err != nil {
+		return err
+	}
+
+	_, err = k.client.TridentV1().TridentSnapshots(k.namespace).Update(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (k *CRDClientV1) DeleteSnapshot(snapshot *storage.Snapshot) error {
 	return k.client.TridentV1().TridentSnapshots(k.namespace).Delete(v1.NameFix(snapshot.ID()), k.deleteOpts())
 }
@@ -952,6 +952,48 @@ func TestKubernetesSnapshots(t *testing.T) {
 	}
 }
 
+func TestKubernetesSnapshotsForVolume(t *testing.T) {
+	var err error
+
+	p := GetTestKubernetesClient()
+
+	// Adding snapshots across two volumes whose names share a prefix
+	for _, volumeName := range []string{"vol1", "vol10"} {
+		for i := 1; i <= 3; i++ {
+			snapConfig := &storage.SnapshotConfig{
+				Version:            "1",
+				Name:               "snap" + strconv.Itoa(i),
+				InternalName:       "internal_snap" + strconv.Itoa(i),
+				VolumeName:         volumeName,
+				VolumeInternalName: "internal_" + volumeName,
+			}
+			now := time.Now().UTC().Format(storage.SnapshotNameFormat)
+			snap := storage.NewSnapshot(snapConfig, now, int64(1000000000))
+			if err = p.AddSnapshot(snap); err != nil {
+				t.Fatal(err.Error())
+			}
+		}
+	}
+
+	// Retrieving only the snapshots of one volume
+	var snapshots []*storage.SnapshotPersistent
+	if snapshots, err = p.GetSnapshotsForVolume("vol1"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(snapshots) != 3 {
+		t.Errorf("Expected %d snapshots; retrieved %d", 3, len(snapshots))
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Config.VolumeName != "vol1" {
+			t.Errorf("Retrieved snapshot %s of volume %s", snapshot.Config.Name, snapshot.Config.VolumeName)
+		}
+	}
+
+	if err = p.DeleteSnapshots(); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 /*
 func TestBackend_RemoveFinalizers(t *testing.T) {
 
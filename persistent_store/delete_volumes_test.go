@@ -0,0 +1,69 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+)
+
+// testDeleteVolumesForBackend seeds volumes across two backends and verifies that only the
+// targeted backend's volumes are removed.
+func testDeleteVolumesForBackend(t *testing.T, p Client) {
+
+	backendUUIDs := []string{"bd8a0a35-35e5-4b4b-9d2a-7f0f0e8d1c21", "1f2c6d7e-8a9b-4c0d-9e1f-2a3b4c5d6e7f"}
+	for _, backendUUID := range backendUUIDs {
+		for i := 0; i < 3; i++ {
+			vol := &storage.Volume{
+				Config: &storage.VolumeConfig{
+					Version:  string(config.OrchestratorAPIVersion),
+					Name:     fmt.Sprintf("vol-%s-%d", backendUUID[:8], i),
+					Size:     "1GB",
+					Protocol: config.File,
+				},
+				BackendUUID: backendUUID,
+				Pool:        storagePool,
+			}
+			if err := p.AddVolume(vol); err != nil {
+				t.Fatalf("Volume creation failed: %v", err)
+			}
+		}
+	}
+	defer p.DeleteVolumes()
+
+	if err := p.DeleteVolumesForBackend(backendUUIDs[0]); err != nil {
+		t.Fatalf("DeleteVolumesForBackend failed: %v", err)
+	}
+
+	volumes, err := p.GetVolumes()
+	if err != nil {
+		t.Fatalf("Volume retrieval failed: %v", err)
+	}
+	if len(volumes) != 3 {
+		t.Errorf("Expected 3 volumes to remain, got %d", len(volumes))
+	}
+	for _, volume := range volumes {
+		if volume.BackendUUID != backendUUIDs[1] {
+			t.Errorf("Volume %s of backend %s was not deleted", volume.Config.Name, volume.BackendUUID)
+		}
+	}
+
+	// Deleting again should be a no-op
+	if err = p.DeleteVolumesForBackend(backendUUIDs[0]); err != nil {
+		t.Errorf("Repeated DeleteVolumesForBackend failed: %v", err)
+	}
+	if volumes, err = p.GetVolumes(); err != nil || len(volumes) != 3 {
+		t.Errorf("Expected 3 volumes to remain; volumes: %v, err: %v", volumes, err)
+	}
+}
+
+func TestInMemoryDeleteVolumesForBackend(t *testing.T) {
+	testDeleteVolumesForBackend(t, NewInMemoryClient())
+}
+
+func TestKubernetesDeleteVolumesForBackend(t *testing.T) {
+	testDeleteVolumesForBackend(t, GetTestKubernetesClient())
+}
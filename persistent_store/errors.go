@@ -40,6 +40,13 @@ func MatchKeyNotFoundErr(err error) bool {
 	return false
 }
 
+func MatchKeyExistsErr(err error) bool {
+	if err != nil && err.Error() == KeyExistsErr {
+		return true
+	}
+	return false
+}
+
 func MatchUnavailableClusterErr(err error) bool {
 	if err != nil && err.Error() == UnavailableClusterErr {
 		return true
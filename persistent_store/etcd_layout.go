@@ -0,0 +1,130 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"fmt"
+	"strconv"
+
+	conc "github.com/coreos/etcd/clientv3/concurrency"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+
+	"github.com/netapp/trident/config"
+)
+
+// CurrentStoreLayoutVersion is the etcd key layout written by this version of Trident.  Layout 1
+// keeps Trident's objects in the versioned key tree and the persistent state version at StoreURL.
+const CurrentStoreLayoutVersion = 1
+
+// layoutMigration transforms the keys of one layout version into those of the next.
+type layoutMigration struct {
+	from        int
+	description string
+	migrate     func(s conc.STM) error
+}
+
+// storeLayoutMigrations lists the layout migrations in order, one per layout version.  There are
+// none yet, since every store has been written with layout 1.
+var storeLayoutMigrations []layoutMigration
+
+// GetStoreLayoutVersion returns the key layout version recorded in etcd.  Stores written
+// before the layout version was recorded report layout 1, and empty stores report 0.
+func (p *EtcdClientV3) GetStoreLayoutVersion() (int, error) {
+
+	layoutVersion, err := p.Read(config.StoreLayoutURL)
+	if err == nil {
+		return parseStoreLayoutVersion(layoutVersion)
+	} else if !MatchKeyNotFoundErr(err) {
+		return 0, err
+	}
+
+	if _, err = p.ReadKeys("/" + config.OrchestratorName + "/"); err != nil {
+		if MatchKeyNotFoundErr(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return 1, nil
+}
+
+// migrateStoreLayout brings the etcd key layout up to CurrentStoreLayoutVersion.  All of the
+// migrations run in a single transaction, so the store is never left in a partially migrated
+// layout.  A layout newer than this version of Trident understands is an error.
+func (p *EtcdClientV3) migrateStoreLayout() error {
+
+	layoutVersion, err := p.GetStoreLayoutVersion()
+	if err != nil {
+		return fmt.Errorf("could not read the persistent store layout version; %v", err)
+	}
+
+	if layoutVersion > CurrentStoreLayoutVersion {
+		return fmt.Errorf("persistent store layout version %d is newer than version %d supported by "+
+			"Trident %s; upgrade Trident to use this store", layoutVersion, CurrentStoreLayoutVersion,
+			config.OrchestratorVersion.String())
+	} else if layoutVersion == CurrentStoreLayoutVersion {
+		// Stores written before the layout version was recorded still need it recorded
+		if _, err = p.Read(config.StoreLayoutURL); err == nil {
+			log.WithField("layoutVersion", layoutVersion).Debug("Persistent store layout is current.")
+			return nil
+		} else if !MatchKeyNotFoundErr(err) {
+			return fmt.Errorf("could not read the persistent store layout version; %v", err)
+		}
+	}
+
+	_, err = conc.NewSTMSerializable(context.TODO(), p.clientV3,
+		func(s conc.STM) error {
+
+			// Another Trident may have migrated the store since the version was read
+			storedVersion := layoutVersion
+			if value := s.Get(config.StoreLayoutURL); value != "" {
+				version, err := parseStoreLayoutVersion(value)
+				if err != nil {
+					return err
+				} else if version > CurrentStoreLayoutVersion {
+					return fmt.Errorf("persistent store layout version %d is newer than version %d",
+						version, CurrentStoreLayoutVersion)
+				}
+				storedVersion = version
+			}
+
+			// An empty store starts out with the current layout
+			if storedVersion > 0 {
+				for _, migration := range storeLayoutMigrations {
+					if migration.from < storedVersion {
+						continue
+					}
+					log.WithFields(log.Fields{
+						"from": migration.from,
+						"to":   migration.from + 1,
+					}).Infof("Migrating persistent store layout: %s.", migration.description)
+
+					if err := migration.migrate(s); err != nil {
+						return fmt.Errorf("could not migrate persistent store layout from version %d; %v",
+							migration.from, err)
+					}
+				}
+			}
+
+			s.Put(config.StoreLayoutURL, strconv.Itoa(CurrentStoreLayoutVersion))
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"from": layoutVersion,
+		"to":   CurrentStoreLayoutVersion,
+	}).Info("Persistent store layout is current.")
+
+	return nil
+}
+
+func parseStoreLayoutVersion(value string) (int, error) {
+	layoutVersion, err := strconv.Atoi(value)
+	if err != nil || layoutVersion < 1 {
+		return 0, fmt.Errorf("invalid persistent store layout version %s", value)
+	}
+	return layoutVersion, nil
+}
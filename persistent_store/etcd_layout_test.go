@@ -0,0 +1,73 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/netapp/trident/config"
+)
+
+func TestEtcdv3StoreLayoutRecorded(t *testing.T) {
+	p, err := NewEtcdClientV3(*etcdV3)
+	if err != nil {
+		t.Fatalf("Creating the etcdv3 client failed: %v", err)
+	}
+	defer p.DeleteKeys("/" + config.OrchestratorName + "/")
+
+	// Set up a store written before the layout version was recorded
+	version := &config.PersistentStateVersion{
+		PersistentStoreVersion: string(EtcdV3bStore),
+		OrchestratorAPIVersion: config.OrchestratorAPIVersion,
+	}
+	if err = p.DeleteKeys("/" + config.OrchestratorName + "/"); err != nil && !MatchKeyNotFoundErr(err) {
+		t.Fatalf("Cleaning up the store failed: %v", err)
+	}
+	if err = p.SetVersion(version); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Set(config.BackendURL+"/backend1", "BACKEND1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if layoutVersion, err := p.GetStoreLayoutVersion(); err != nil || layoutVersion != 1 {
+		t.Fatalf("Expected layout version 1, got %d: %v", layoutVersion, err)
+	}
+
+	// A new client records the store's layout version
+	p, err = NewEtcdClientV3(*etcdV3)
+	if err != nil {
+		t.Fatalf("Migrating the store layout failed: %v", err)
+	}
+
+	if value, err := p.Read(config.StoreLayoutURL); err != nil || value != strconv.Itoa(CurrentStoreLayoutVersion) {
+		t.Errorf("Expected layout version %d to be recorded, got %s: %v", CurrentStoreLayoutVersion, value, err)
+	}
+	storedVersion, err := p.GetVersion()
+	if err != nil {
+		t.Fatalf("Reading the persistent state version failed: %v", err)
+	}
+	if *storedVersion != *version {
+		t.Errorf("Expected persistent state version %v, got %v", version, storedVersion)
+	}
+	if value, err := p.Read(config.BackendURL + "/backend1"); err != nil || value != "BACKEND1" {
+		t.Errorf("Expected unrelated keys to be preserved, got %s: %v", value, err)
+	}
+}
+
+func TestEtcdv3StoreLayoutTooNew(t *testing.T) {
+	p, err := NewEtcdClientV3(*etcdV3)
+	if err != nil {
+		t.Fatalf("Creating the etcdv3 client failed: %v", err)
+	}
+	defer p.Set(config.StoreLayoutURL, strconv.Itoa(CurrentStoreLayoutVersion))
+
+	if err = p.Set(config.StoreLayoutURL, strconv.Itoa(CurrentStoreLayoutVersion+1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = NewEtcdClientV3(*etcdV3); err == nil {
+		t.Error("Expected an error for a store layout newer than this Trident supports")
+	}
+}
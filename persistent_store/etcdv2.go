@@ -5,9 +5,12 @@ package persistentstore
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/cenkalti/backoff"
 	etcdclientv2 "github.com/coreos/etcd/client"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
@@ -18,15 +21,37 @@ import (
 	"github.com/netapp/trident/utils"
 )
 
+const (
+	// DefaultEtcdV2RetryBudget is how long an operation is retried while the etcd cluster is unavailable
+	DefaultEtcdV2RetryBudget = 30 * time.Second
+
+	etcdV2RetryInitialInterval = 250 * time.Millisecond
+	etcdV2RetryMultiplier      = 2
+	etcdV2RetryMaxInterval     = 5 * time.Second
+)
+
 type EtcdClientV2 struct {
 	clientV2  *etcdclientv2.Client
 	keysAPI   etcdclientv2.KeysAPI
 	endpoints string
+
+	// memberKeysAPIs holds one KeysAPI per endpoint, so reads may be spread across members
+	memberKeysAPIs []etcdclientv2.KeysAPI
+	nextMember     uint32
+
+	// retryBudget limits how long operations are retried while the cluster is unavailable
+	retryBudget time.Duration
 }
 
+// NewEtcdClientV2 creates a client for the etcd cluster at the specified comma-separated
+// list of endpoints.  Requests fail over to the next endpoint on connection failure.
 func NewEtcdClientV2(endpoints string) (*EtcdClientV2, error) {
+	endpointList := parseEtcdEndpoints(endpoints)
+	if len(endpointList) == 0 {
+		return nil, fmt.Errorf("no etcd endpoints specified")
+	}
 	cfg := etcdclientv2.Config{
-		Endpoints: []string{endpoints}, //TODO: support for multiple IP addresses
+		Endpoints: endpointList,
 	}
 	c, err := etcdclientv2.New(cfg)
 	if err != nil {
@@ -34,6 +59,15 @@ func NewEtcdClientV2(endpoints string) (*EtcdClientV2, error) {
 	}
 	keysAPI := etcdclientv2.NewKeysAPI(c)
 
+	memberKeysAPIs := make([]etcdclientv2.KeysAPI, 0, len(endpointList))
+	for _, endpoint := range endpointList {
+		memberClient, err := etcdclientv2.New(etcdclientv2.Config{Endpoints: []string{endpoint}})
+		if err != nil {
+			return nil, err
+		}
+		memberKeysAPIs = append(memberKeysAPIs, etcdclientv2.NewKeysAPI(memberClient))
+	}
+
 	// Making sure the etcd server is up
 	for tries := 0; tries <= config.PersistentStoreBootstrapAttempts; tries++ {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
@@ -63,9 +97,11 @@ func NewEtcdClientV2(endpoints string) (*EtcdClientV2, error) {
 	}
 
 	client := &EtcdClientV2{
-		clientV2:  &c,
-		keysAPI:   keysAPI,
-		endpoints: endpoints,
+		clientV2:       &c,
+		keysAPI:        keysAPI,
+		endpoints:      endpoints,
+		memberKeysAPIs: memberKeysAPIs,
+		retryBudget:    DefaultEtcdV2RetryBudget,
 	}
 
 	// Warn if etcd version is not what we expect
@@ -78,6 +114,112 @@ func NewEtcdClientV2FromConfig(etcdConfig *ClientConfig) (*EtcdClientV2, error)
 	return NewEtcdClientV2(etcdConfig.endpoints)
 }
 
+// parseEtcdEndpoints splits a comma-separated list of etcd endpoints
+func parseEtcdEndpoints(endpoints string) []string {
+	endpointList := make([]string, 0)
+	for _, endpoint := range strings.Split(endpoints, ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			endpointList = append(endpointList, endpoint)
+		}
+	}
+	return endpointList
+}
+
+// isEtcdV2ConnectionError returns true if an etcd error indicates the member couldn't be reached
+func isEtcdV2ConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return err == context.DeadlineExceeded ||
+		strings.Contains(err.Error(), etcdclientv2.ErrClusterUnavailable.Error())
+}
+
+// getWithFailover reads a key, rotating the starting member on each call so reads are spread
+// across the cluster.  Members that can't be reached are skipped, and an UnavailableClusterErr
+// is returned only if no member could be reached.
+func (p *EtcdClientV2) getWithFailover(key string) (*etcdclientv2.Response, error) {
+
+	start := int(atomic.AddUint32(&p.nextMember, 1))
+	for i := range p.memberKeysAPIs {
+		keysAPI := p.memberKeysAPIs[(start+i)%len(p.memberKeysAPIs)]
+
+		ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
+		resp, err := keysAPI.Get(ctx, key, &etcdclientv2.GetOptions{Recursive: true, Sort: true, Quorum: true})
+		cancel()
+		if !isEtcdV2ConnectionError(err) {
+			return resp, err
+		}
+		log.WithFields(log.Fields{
+			"key":   key,
+			"error": err,
+		}).Debug("etcd member unavailable, trying next member.")
+	}
+
+	return nil, NewPersistentStoreError(UnavailableClusterErr, key)
+}
+
+// SetRetryBudget sets how long operations are retried while the etcd cluster is unavailable,
+// such as during a leader election.  A budget of zero disables retries.
+func (p *EtcdClientV2) SetRetryBudget(retryBudget time.Duration) {
+	p.retryBudget = retryBudget
+}
+
+// withRetry invokes an etcd operation, retrying it with exponential backoff for as long as it
+// fails with an UnavailableClusterErr and the retry budget allows.  Any other error, including
+// a KeyNotFoundErr, is returned immediately.
+func (p *EtcdClientV2) withRetry(key string, operation func() error) error {
+
+	if p.retryBudget <= 0 {
+		return operation()
+	}
+
+	retryOperation := func() error {
+		err := operation()
+		if err != nil && !MatchUnavailableClusterErr(err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+	retryNotify := func(err error, duration time.Duration) {
+		log.WithFields(log.Fields{
+			"key":       key,
+			"increment": duration,
+		}).Warning("etcd cluster unavailable, retrying.")
+	}
+	retryBackoff := backoff.NewExponentialBackOff()
+	retryBackoff.InitialInterval = etcdV2RetryInitialInterval
+	retryBackoff.Multiplier = etcdV2RetryMultiplier
+	retryBackoff.MaxInterval = etcdV2RetryMaxInterval
+	retryBackoff.MaxElapsedTime = p.retryBudget
+
+	return backoff.RetryNotify(retryOperation, retryBackoff, retryNotify)
+}
+
+// getWithRetry reads a key like getWithFailover, retrying while no member can be reached.
+func (p *EtcdClientV2) getWithRetry(key string) (*etcdclientv2.Response, error) {
+	var resp *etcdclientv2.Response
+	err := p.withRetry(key, func() error {
+		var getErr error
+		resp, getErr = p.getWithFailover(key)
+		return getErr
+	})
+	return resp, err
+}
+
+// translateEtcdV2Error converts etcd errors that callers must recognize into persistent store errors.
+func translateEtcdV2Error(err error, key string) error {
+	if err == nil {
+		return nil
+	} else if etcdErr, ok := err.(etcdclientv2.Error); ok && etcdErr.Code == etcdclientv2.ErrorCodeKeyNotFound {
+		return NewPersistentStoreError(KeyNotFoundErr, key)
+	} else if etcdErr, ok := err.(etcdclientv2.Error); ok && etcdErr.Code == etcdclientv2.ErrorCodeNodeExist {
+		return NewPersistentStoreError(KeyExistsErr, key)
+	} else if isEtcdV2ConnectionError(err) {
+		return NewPersistentStoreError(UnavailableClusterErr, key)
+	}
+	return err
+}
+
 func (p *EtcdClientV2) checkEtcdVersion() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -102,21 +244,35 @@ func (p *EtcdClientV2) checkEtcdVersion() {
 	}
 }
 
+// Ping checks that etcd can be reached by reading the persistent state version key.  An
+// UnavailableClusterErr is returned if no etcd member responds.
+func (p *EtcdClientV2) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, config.PersistentStoreTimeout)
+	defer cancel()
+
+	_, err := p.keysAPI.Get(ctx, config.VersionURL, &etcdclientv2.GetOptions{Quorum: true})
+	if err == nil {
+		return nil
+	} else if etcdErr, ok := err.(etcdclientv2.Error); ok && etcdErr.Code == etcdclientv2.ErrorCodeKeyNotFound {
+		return nil
+	} else if isEtcdV2ConnectionError(err) {
+		return NewPersistentStoreError(UnavailableClusterErr, config.VersionURL)
+	}
+	return err
+}
+
 // Create is the abstract CRUD interface
 func (p *EtcdClientV2) Create(key, value string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
-	_, err := p.keysAPI.Create(ctx, key, value)
-	cancel()
-	if err != nil {
-		return err
-	}
-	return nil
+	return p.withRetry(key, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
+		_, err := p.keysAPI.Create(ctx, key, value)
+		cancel()
+		return translateEtcdV2Error(err, key)
+	})
 }
 
 func (p *EtcdClientV2) Read(key string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
-	resp, err := p.keysAPI.Get(ctx, key, &etcdclientv2.GetOptions{Recursive: true, Sort: true, Quorum: true})
-	cancel()
+	resp, err := p.getWithRetry(key)
 	if err != nil {
 		if etcdErr, ok := err.(etcdclientv2.Error); ok && etcdErr.Code == etcdclientv2.ErrorCodeKeyNotFound {
 			return "", NewPersistentStoreError(KeyNotFoundErr, key)
@@ -129,9 +285,7 @@ func (p *EtcdClientV2) Read(key string) (string, error) {
 // ReadKeys returns all the keys with the designated prefix
 func (p *EtcdClientV2) ReadKeys(keyPrefix string) ([]string, error) {
 	keys := make([]string, 0)
-	ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
-	resp, err := p.keysAPI.Get(ctx, keyPrefix, &etcdclientv2.GetOptions{Recursive: true, Sort: true, Quorum: true})
-	cancel()
+	resp, err := p.getWithRetry(keyPrefix)
 	if err != nil {
 		if etcdErr, ok := err.(etcdclientv2.Error); ok && etcdErr.Code == etcdclientv2.ErrorCodeKeyNotFound {
 			err = NewPersistentStoreError(KeyNotFoundErr, keyPrefix)
@@ -161,42 +315,30 @@ func (p *EtcdClientV2) ReadKeys(keyPrefix string) ([]string, error) {
 }
 
 func (p *EtcdClientV2) Update(key, value string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
-	_, err := p.keysAPI.Update(ctx, key, value)
-	cancel()
-	if err != nil {
-		if etcdErr, ok := err.(etcdclientv2.Error); ok && etcdErr.Code == etcdclientv2.ErrorCodeKeyNotFound {
-			return NewPersistentStoreError(KeyNotFoundErr, key)
-		}
-		return err
-	}
-	return nil
+	return p.withRetry(key, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
+		_, err := p.keysAPI.Update(ctx, key, value)
+		cancel()
+		return translateEtcdV2Error(err, key)
+	})
 }
 
 func (p *EtcdClientV2) Set(key, value string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
-	_, err := p.keysAPI.Set(ctx, key, value, &etcdclientv2.SetOptions{})
-	cancel()
-	if err != nil {
-		if etcdErr, ok := err.(etcdclientv2.Error); ok && etcdErr.Code == etcdclientv2.ErrorCodeKeyNotFound {
-			return NewPersistentStoreError(KeyNotFoundErr, key)
-		}
-		return err
-	}
-	return nil
+	return p.withRetry(key, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
+		_, err := p.keysAPI.Set(ctx, key, value, &etcdclientv2.SetOptions{})
+		cancel()
+		return translateEtcdV2Error(err, key)
+	})
 }
 
 func (p *EtcdClientV2) Delete(key string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
-	_, err := p.keysAPI.Delete(ctx, key, &etcdclientv2.DeleteOptions{Recursive: true})
-	cancel()
-	if err != nil {
-		if etcdErr, ok := err.(etcdclientv2.Error); ok && etcdErr.Code == etcdclientv2.ErrorCodeKeyNotFound {
-			return NewPersistentStoreError(KeyNotFoundErr, key)
-		}
-		return err
-	}
-	return nil
+	return p.withRetry(key, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
+		_, err := p.keysAPI.Delete(ctx, key, &etcdclientv2.DeleteOptions{Recursive: true})
+		cancel()
+		return translateEtcdV2Error(err, key)
+	})
 }
 
 // DeleteKeys deletes all the keys with the designated prefix
@@ -258,18 +400,25 @@ func (p *EtcdClientV2) SetVersion(version *config.PersistentStateVersion) error
 	return p.Set(config.StoreURL, string(versionJSON))
 }
 
-// AddBackend saves the minimally required backend state to the persistent store
+// AddBackend saves the minimally required backend state to the persistent store.  Adding a
+// backend identical to one already stored succeeds, so a write retried after a transient
+// failure isn't mistaken for a duplicate.
 func (p *EtcdClientV2) AddBackend(b *storage.Backend) error {
 	backend := b.ConstructPersistent()
 	backendJSON, err := json.Marshal(backend)
 	if err != nil {
 		return err
 	}
-	err = p.Create(config.BackendURL+"/"+backend.Name, string(backendJSON))
-	if err != nil {
-		return err
+	key := config.BackendURL + "/" + backend.Name
+	err = p.Create(key, string(backendJSON))
+	if MatchKeyExistsErr(err) {
+		storedJSON, readErr := p.Read(key)
+		if readErr != nil {
+			return readErr
+		}
+		return checkStoredBackend(storedJSON, string(backendJSON), key)
 	}
-	return nil
+	return err
 }
 
 func (p *EtcdClientV2) AddBackendPersistent(backend *storage.BackendPersistent) error {
@@ -422,21 +571,45 @@ func (p *EtcdClientV2) DeleteVolumeIgnoreNotFound(vol *storage.Volume) error {
 
 // GetVolumes retrieves all volumes
 func (p *EtcdClientV2) GetVolumes() ([]*storage.VolumeExternal, error) {
-	volumeList := make([]*storage.VolumeExternal, 0)
+	return getAllVolumePages(p.GetVolumesPaged)
+}
+
+// GetVolumesPaged retrieves up to limit volumes, ordered by name, starting with the volume
+// identified by continueToken.  It returns the token for the next page, or an empty token
+// if there are no more volumes.  The etcd v2 API has no ranged reads, so the volume keys are
+// listed in full, but only the volumes in the requested page are read and decoded.
+func (p *EtcdClientV2) GetVolumesPaged(
+	continueToken string, limit int,
+) ([]*storage.VolumeExternal, string, error) {
+
+	if err := validatePageLimit(limit); err != nil {
+		return nil, "", err
+	}
+
 	keys, err := p.ReadKeys(config.VolumeURL)
 	if err != nil && MatchKeyNotFoundErr(err) {
-		return volumeList, nil
+		return make([]*storage.VolumeExternal, 0), "", nil
 	} else if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+
+	volNames := make([]string, 0, len(keys))
 	for _, key := range keys {
-		vol, err := p.GetVolume(strings.TrimPrefix(key, config.VolumeURL))
+		volNames = append(volNames, strings.TrimPrefix(key, config.VolumeURL+"/"))
+	}
+	sort.Strings(volNames)
+
+	pageVolNames, nextToken := pageNames(volNames, continueToken, limit)
+
+	volumeList := make([]*storage.VolumeExternal, 0, len(pageVolNames))
+	for _, volName := range pageVolNames {
+		vol, err := p.GetVolume(volName)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		volumeList = append(volumeList, vol)
 	}
-	return volumeList, nil
+	return volumeList, nextToken, nil
 }
 
 // DeleteVolumes deletes all volumes
@@ -444,6 +617,58 @@ func (p *EtcdClientV2) DeleteVolumes() error {
 	return p.deleteKeys(config.VolumeURL)
 }
 
+// DeleteVolumesForBackend deletes all volumes that refer to the specified backend.  Because
+// etcdv2 doesn't support transactions, the volumes are deleted one at a time.
+func (p *EtcdClientV2) DeleteVolumesForBackend(backendUUID string) error {
+	volumes, err := p.GetVolumes()
+	if err != nil && !MatchKeyNotFoundErr(err) {
+		return err
+	}
+	for _, volume := range volumes {
+		if volume.BackendUUID != backendUUID {
+			continue
+		}
+		if err = p.Delete(config.VolumeURL + "/" + volume.Config.Name); err != nil && !MatchKeyNotFoundErr(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetVolumeEvents returns the recent events recorded for a volume, newest first
+func (p *EtcdClientV2) GetVolumeEvents(volName string) ([]*storage.VolumeEvent, error) {
+	events := make([]*storage.VolumeEvent, 0)
+	eventsJSON, err := p.Read(config.EventURL + "/" + volName)
+	if err != nil && MatchKeyNotFoundErr(err) {
+		return events, nil
+	} else if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(eventsJSON), &events)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// UpdateVolumeEvents replaces the events recorded for a volume
+func (p *EtcdClientV2) UpdateVolumeEvents(volName string, events []*storage.VolumeEvent) error {
+	eventsJSON, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	return p.Set(config.EventURL+"/"+volName, string(eventsJSON))
+}
+
+// DeleteVolumeEvents deletes the events recorded for a volume, if any
+func (p *EtcdClientV2) DeleteVolumeEvents(volName string) error {
+	err := p.Delete(config.EventURL + "/" + volName)
+	if err != nil && !MatchKeyNotFoundErr(err) {
+		return err
+	}
+	return nil
+}
+
 // AddVolumeTransaction logs an AddVolume operation
 func (p *EtcdClientV2) AddVolumeTransaction(volTxn *VolumeTransaction) error {
 	if volTxnJSON, err := json.Marshal(volTxn); err != nil {
@@ -662,6 +887,34 @@ func (p *EtcdClientV2) GetSnapshots() ([]*storage.SnapshotPersistent, error) {
 	return snapshotList, nil
 }
 
+// GetSnapshotsForVolume retrieves all snapshots of a volume
+func (p *EtcdClientV2) GetSnapshotsForVolume(volumeName string) ([]*storage.SnapshotPersistent, error) {
+	snapshotList := make([]*storage.SnapshotPersistent, 0)
+	keys, err := p.ReadKeys(config.SnapshotURL + "/" + volumeName)
+	if err != nil && MatchKeyNotFoundErr(err) {
+		return snapshotList, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		snapshot, err := p.getSnapshotByID(strings.TrimPrefix(key, config.SnapshotURL+"/"))
+		if err != nil {
+			return nil, err
+		}
+		snapshotList = append(snapshotList, snapshot)
+	}
+	return snapshotList, nil
+}
+
+// UpdateSnapshot updates a snapshot's state in the persistent store
+func (p *EtcdClientV2) UpdateSnapshot(snapshot *storage.Snapshot) error {
+	if snapJSON, err := json.Marshal(snapshot.ConstructPersistent()); err != nil {
+		return err
+	} else {
+		return p.Update(config.SnapshotURL+"/"+snapshot.ID(), string(snapJSON))
+	}
+}
+
 // DeleteSnapshot deletes a snapshot from the persistent store
 func (p *EtcdClientV2) DeleteSnapshot(snapshot *storage.Snapshot) error {
 	return p.Delete(config.SnapshotURL + "/" + snapshot.ID())
@@ -11,8 +11,10 @@ import (
 	"testing"
 	"time"
 
+	etcdclientv2 "github.com/coreos/etcd/client"
 	uuid "github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 
 	"github.com/netapp/trident/config"
 	"github.com/netapp/trident/storage"
@@ -54,6 +56,79 @@ func TestEtcdv2InvalidClient(t *testing.T) {
 	}
 }
 
+func TestEtcdv2MultipleEndpoints(t *testing.T) {
+	// The unreachable endpoint is listed first so the client must fail over
+	p, err := NewEtcdClientV2("http://127.0.0.1:9999, " + *etcdV2)
+	if err != nil {
+		t.Fatalf("Failed to create an etcdv2 client with one healthy endpoint: %v", err)
+	}
+
+	if err = p.Create("multiEndpointKey", "val1"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	defer p.Delete("multiEndpointKey")
+
+	// Reads rotate across members, so read enough times to hit each of them
+	for i := 0; i < 4; i++ {
+		val, err := p.Read("multiEndpointKey")
+		if err != nil {
+			t.Fatalf("Read %d failed: %v", i, err)
+		}
+		if val != "val1" {
+			t.Errorf("Read %d returned %s; expected val1", i, val)
+		}
+	}
+
+	keys, err := p.ReadKeys("multiEndpointKey")
+	if err != nil || len(keys) != 1 {
+		t.Errorf("ReadKeys failed; keys: %v, err: %v", keys, err)
+	}
+}
+
+func TestEtcdv2AllEndpointsUnavailable(t *testing.T) {
+	_, err := NewEtcdClientV2("http://127.0.0.1:9998,http://127.0.0.1:9999")
+	if !MatchUnavailableClusterErr(err) {
+		t.Errorf("Expected an unavailable cluster error, got %v", err)
+	}
+}
+
+func TestParseEtcdEndpoints(t *testing.T) {
+	for input, expected := range map[string][]string{
+		"":                                 {},
+		"http://a:2379":                    {"http://a:2379"},
+		"http://a:2379,http://b:2379":      {"http://a:2379", "http://b:2379"},
+		" http://a:2379 , ,http://b:2379,": {"http://a:2379", "http://b:2379"},
+	} {
+		if actual := parseEtcdEndpoints(input); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("parseEtcdEndpoints(%q) = %v; expected %v", input, actual, expected)
+		}
+	}
+}
+
+func TestEtcdv2Ping(t *testing.T) {
+	p, err := NewEtcdClientV2(*etcdV2)
+	if err != nil {
+		t.Fatalf("Failed to create an etcdv2 client: %v", err)
+	}
+	if err = p.Ping(context.Background()); err != nil {
+		t.Errorf("Ping failed against a live store: %v", err)
+	}
+
+	// Build a client directly, since NewEtcdClientV2 won't return one for a dead store
+	deadClient, err := etcdclientv2.New(etcdclientv2.Config{Endpoints: []string{"http://127.0.0.1:9999"}})
+	if err != nil {
+		t.Fatalf("Failed to create an etcd client: %v", err)
+	}
+	dead := &EtcdClientV2{
+		clientV2:  &deadClient,
+		keysAPI:   etcdclientv2.NewKeysAPI(deadClient),
+		endpoints: "http://127.0.0.1:9999",
+	}
+	if err = dead.Ping(context.Background()); !MatchUnavailableClusterErr(err) {
+		t.Errorf("Expected an unavailable cluster error pinging a dead store, got %v", err)
+	}
+}
+
 func TestEtcdv2CRUD(t *testing.T) {
 	p, err := NewEtcdClientV2(*etcdV2)
 
@@ -367,9 +442,22 @@ func TestEtcdv2DuplicateBackend(t *testing.T) {
 		t.Error(err.Error())
 		t.FailNow()
 	}
+	// Retrying an add that was already persisted succeeds
 	err = p.AddBackend(nfsServer)
-	if err == nil {
-		t.Error("Second Create should have failed!")
+	if err != nil {
+		t.Errorf("Adding an identical backend should have succeeded: %v", err)
+	}
+
+	// A different backend with the same name is still a duplicate
+	nfsServerConfig.SVM = "svm2"
+	err = p.AddBackend(&storage.Backend{
+		Driver: &ontap.NASStorageDriver{
+			Config: nfsServerConfig,
+		},
+		Name: nfsServer.Name,
+	})
+	if !MatchKeyExistsErr(err) {
+		t.Errorf("Adding a different backend with the same name should have failed, got %v", err)
 	}
 	err = p.DeleteBackend(nfsServer)
 	if err != nil {
@@ -1033,3 +1121,84 @@ func TestEtcdv2AddGetSnapshots(t *testing.T) {
 		t.Error(err.Error())
 	}
 }
+
+// flakyKeysAPI fails the first call to each operation as if the etcd cluster were unavailable,
+// then succeeds.  A missing key always reports KeyNotFound.
+type flakyKeysAPI struct {
+	etcdclientv2.KeysAPI
+	calls  map[string]int
+	values map[string]string
+}
+
+func newFlakyKeysAPI() *flakyKeysAPI {
+	return &flakyKeysAPI{calls: make(map[string]int), values: make(map[string]string)}
+}
+
+func (f *flakyKeysAPI) fail(operation string) error {
+	f.calls[operation]++
+	if f.calls[operation] == 1 {
+		return etcdclientv2.ErrClusterUnavailable
+	}
+	return nil
+}
+
+func (f *flakyKeysAPI) Get(
+	ctx context.Context, key string, opts *etcdclientv2.GetOptions,
+) (*etcdclientv2.Response, error) {
+	if err := f.fail("get"); err != nil {
+		return nil, err
+	}
+	value, ok := f.values[key]
+	if !ok {
+		return nil, etcdclientv2.Error{Code: etcdclientv2.ErrorCodeKeyNotFound, Message: "Key not found"}
+	}
+	return &etcdclientv2.Response{Node: &etcdclientv2.Node{Key: key, Value: value}}, nil
+}
+
+func (f *flakyKeysAPI) Create(ctx context.Context, key, value string) (*etcdclientv2.Response, error) {
+	if err := f.fail("create"); err != nil {
+		return nil, err
+	}
+	f.values[key] = value
+	return &etcdclientv2.Response{Node: &etcdclientv2.Node{Key: key, Value: value}}, nil
+}
+
+func TestEtcdv2RetryUnavailableCluster(t *testing.T) {
+	keysAPI := newFlakyKeysAPI()
+	p := &EtcdClientV2{
+		keysAPI:        keysAPI,
+		memberKeysAPIs: []etcdclientv2.KeysAPI{keysAPI},
+		retryBudget:    5 * time.Second,
+	}
+
+	// The first attempt fails, so each operation must be retried once
+	if err := p.Create("flakyKey", "val1"); err != nil {
+		t.Fatalf("Create wasn't retried: %v", err)
+	}
+	if keysAPI.calls["create"] != 2 {
+		t.Errorf("Expected 2 create attempts, got %d", keysAPI.calls["create"])
+	}
+	val, err := p.Read("flakyKey")
+	if err != nil {
+		t.Fatalf("Read wasn't retried: %v", err)
+	}
+	if val != "val1" {
+		t.Errorf("Expected val1, got %s", val)
+	}
+
+	// A missing key isn't retried
+	calls := keysAPI.calls["get"]
+	if _, err = p.Read("missingKey"); !MatchKeyNotFoundErr(err) {
+		t.Errorf("Expected KeyNotFoundErr, got %v", err)
+	}
+	if keysAPI.calls["get"] != calls+1 {
+		t.Errorf("Expected a missing key to be read once, got %d reads", keysAPI.calls["get"]-calls)
+	}
+
+	// Without a retry budget, the first failure is returned
+	keysAPI = newFlakyKeysAPI()
+	p = &EtcdClientV2{keysAPI: keysAPI, memberKeysAPIs: []etcdclientv2.KeysAPI{keysAPI}}
+	if err = p.Create("flakyKey", "val1"); !MatchUnavailableClusterErr(err) {
+		t.Errorf("Expected UnavailableClusterErr without a retry budget, got %v", err)
+	}
+}
@@ -31,6 +31,9 @@ var (
 	ErrKeyNotFound = errors.New("etcdserver: key not found")
 )
 
+// maxTxnOps is the default limit on the number of operations in a single etcd transaction
+const maxTxnOps = 128
+
 type EtcdClientV3 struct {
 	clientV3  *clientv3.Client
 	endpoints string
@@ -61,6 +64,12 @@ func NewEtcdClientV3(endpoints string) (*EtcdClientV3, error) {
 	// Warn if etcd version isn't what we expect
 	etcdClientV3.checkEtcdVersion()
 
+	// Bring the key layout up to date, refusing to use a store written by a newer Trident
+	if err = etcdClientV3.migrateStoreLayout(); err != nil {
+		etcdClientV3.Stop()
+		return nil, err
+	}
+
 	return etcdClientV3, nil
 }
 
@@ -107,6 +116,12 @@ func NewEtcdClientV3WithTLS(endpoints, etcdV3Cert, etcdV3CACert, etcdV3Key strin
 	// Warn if etcd version isn't what we expect
 	etcdClientV3.checkEtcdVersion()
 
+	// Bring the key layout up to date, refusing to use a store written by a newer Trident
+	if err = etcdClientV3.migrateStoreLayout(); err != nil {
+		etcdClientV3.Stop()
+		return nil, err
+	}
+
 	return etcdClientV3, nil
 }
 
@@ -155,6 +170,22 @@ func (p *EtcdClientV3) checkEtcdVersion() {
 	}
 }
 
+// Ping checks that etcd can be reached by counting the persistent state version keys.  An
+// UnavailableClusterErr is returned if etcd doesn't respond.
+func (p *EtcdClientV3) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, config.PersistentStoreTimeout)
+	defer cancel()
+
+	if _, err := p.clientV3.Get(ctx, config.VersionURL, clientv3.WithCountOnly()); err != nil {
+		if err == context.DeadlineExceeded || err.Error() == grpc.ErrClientConnTimeout.Error() ||
+			strings.Contains(err.Error(), "dial tcp") {
+			return NewPersistentStoreError(UnavailableClusterErr, config.VersionURL)
+		}
+		return err
+	}
+	return nil
+}
+
 // Create creates a key in etcd
 func (p *EtcdClientV3) Create(key, value string) error {
 	_, err := p.Read(key)
@@ -347,18 +378,25 @@ func (p *EtcdClientV3) SetVersion(version *config.PersistentStateVersion) error
 	return p.Set(config.StoreURL, string(versionJSON))
 }
 
-// AddBackend saves the minimally required backend state to the persistent store
+// AddBackend saves the minimally required backend state to the persistent store.  Adding a
+// backend identical to one already stored succeeds, so a write retried after a transient
+// failure isn't mistaken for a duplicate.
 func (p *EtcdClientV3) AddBackend(b *storage.Backend) error {
 	backend := b.ConstructPersistent()
 	backendJSON, err := json.Marshal(backend)
 	if err != nil {
 		return err
 	}
-	err = p.Create(config.BackendURL+"/"+backend.Name, string(backendJSON))
-	if err != nil {
-		return err
+	key := config.BackendURL + "/" + backend.Name
+	err = p.Create(key, string(backendJSON))
+	if MatchKeyExistsErr(err) {
+		storedJSON, readErr := p.Read(key)
+		if readErr != nil {
+			return readErr
+		}
+		return checkStoredBackend(storedJSON, string(backendJSON), key)
 	}
-	return nil
+	return err
 }
 
 // AddBackendSTM saves the minimally required backend state to the persistent store using STM
@@ -477,6 +515,10 @@ func (p *EtcdClientV3) DeleteBackends() error {
 // ReplaceBackendAndUpdateVolumes replaces a backend and updates all volumes to
 // reflect the new backend.
 func (p *EtcdClientV3) ReplaceBackendAndUpdateVolumes(origBackend, newBackend *storage.Backend) error {
+	if err := validateBackendReplacement(origBackend, newBackend); err != nil {
+		return err
+	}
+
 	// It's important to update the persistent store objects in an atomic way.
 	_, err := conc.NewSTMSerializable(context.TODO(), p.clientV3,
 		func(s conc.STM) error {
@@ -637,21 +679,47 @@ func (p *EtcdClientV3) DeleteVolumeIgnoreNotFound(vol *storage.Volume) error {
 
 // GetVolumes retrieves all volumes
 func (p *EtcdClientV3) GetVolumes() ([]*storage.VolumeExternal, error) {
-	volumeList := make([]*storage.VolumeExternal, 0)
-	keys, err := p.ReadKeys(config.VolumeURL)
-	if err != nil && MatchKeyNotFoundErr(err) {
-		return volumeList, nil
-	} else if err != nil {
-		return nil, err
+	return getAllVolumePages(p.GetVolumesPaged)
+}
+
+// GetVolumesPaged retrieves up to limit volumes, ordered by name, starting with the volume
+// identified by continueToken.  It returns the token for the next page, or an empty token
+// if there are no more volumes.  The page is read with a single ranged, limited request so
+// etcd never has to return every volume at once.
+func (p *EtcdClientV3) GetVolumesPaged(
+	continueToken string, limit int,
+) ([]*storage.VolumeExternal, string, error) {
+
+	if err := validatePageLimit(limit); err != nil {
+		return nil, "", err
 	}
-	for _, key := range keys {
-		vol, err := p.GetVolume(strings.TrimPrefix(key, config.VolumeURL+"/"))
-		if err != nil {
-			return nil, err
+
+	// Read one extra key to learn where the next page starts
+	keyPrefix := config.VolumeURL + "/"
+	ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
+	resp, err := p.clientV3.Get(ctx, keyPrefix+continueToken,
+		clientv3.WithRange(clientv3.GetPrefixRangeEnd(keyPrefix)),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend),
+		clientv3.WithLimit(int64(limit+1)))
+	cancel()
+	if err != nil {
+		return nil, "", err
+	}
+
+	volumeList := make([]*storage.VolumeExternal, 0, limit)
+	nextToken := ""
+	for i, kv := range resp.Kvs {
+		if i == limit {
+			nextToken = strings.TrimPrefix(string(kv.Key), keyPrefix)
+			break
 		}
-		volumeList = append(volumeList, vol)
+		volExternal := &storage.VolumeExternal{}
+		if err = json.Unmarshal(kv.Value, volExternal); err != nil {
+			return nil, "", err
+		}
+		volumeList = append(volumeList, volExternal)
 	}
-	return volumeList, nil
+	return volumeList, nextToken, nil
 }
 
 // GetVolumesSTM retrieves all volumes using STM
@@ -680,6 +748,83 @@ func (p *EtcdClientV3) DeleteVolumes() error {
 	return p.deleteKeys(config.VolumeURL)
 }
 
+// DeleteVolumesForBackend deletes all volumes that refer to the specified backend.  Volume
+// keys are named by volume rather than by backend, so the volumes are found by paging
+// through them, and the matching keys are deleted in transactions of up to maxTxnOps keys.
+func (p *EtcdClientV3) DeleteVolumesForBackend(backendUUID string) error {
+
+	keys := make([]string, 0)
+	continueToken := ""
+	for {
+		volumes, nextToken, err := p.GetVolumesPaged(continueToken, VolumePageSize)
+		if err != nil {
+			return err
+		}
+		for _, volume := range volumes {
+			if volume.BackendUUID == backendUUID {
+				keys = append(keys, config.VolumeURL+"/"+volume.Config.Name)
+			}
+		}
+		if nextToken == "" {
+			break
+		}
+		continueToken = nextToken
+	}
+
+	for start := 0; start < len(keys); start += maxTxnOps {
+		end := start + maxTxnOps
+		if end > len(keys) {
+			end = len(keys)
+		}
+		ops := make([]clientv3.Op, 0, end-start)
+		for _, key := range keys[start:end] {
+			ops = append(ops, clientv3.OpDelete(key))
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
+		_, err := p.clientV3.Txn(ctx).Then(ops...).Commit()
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetVolumeEvents returns the recent events recorded for a volume, newest first
+func (p *EtcdClientV3) GetVolumeEvents(volName string) ([]*storage.VolumeEvent, error) {
+	events := make([]*storage.VolumeEvent, 0)
+	eventsJSON, err := p.Read(config.EventURL + "/" + volName)
+	if err != nil && MatchKeyNotFoundErr(err) {
+		return events, nil
+	} else if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(eventsJSON), &events)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// UpdateVolumeEvents replaces the events recorded for a volume
+func (p *EtcdClientV3) UpdateVolumeEvents(volName string, events []*storage.VolumeEvent) error {
+	eventsJSON, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	return p.Set(config.EventURL+"/"+volName, string(eventsJSON))
+}
+
+// DeleteVolumeEvents deletes the events recorded for a volume, if any
+func (p *EtcdClientV3) DeleteVolumeEvents(volName string) error {
+	err := p.Delete(config.EventURL + "/" + volName)
+	if err != nil && !MatchKeyNotFoundErr(err) {
+		return err
+	}
+	return nil
+}
+
 // AddVolumeTransaction logs an AddVolume operation
 func (p *EtcdClientV3) AddVolumeTransaction(volTxn *VolumeTransaction) error {
 	volTxnJSON, err := json.Marshal(volTxn)
@@ -904,6 +1049,36 @@ func (p *EtcdClientV3) GetSnapshots() ([]*storage.SnapshotPersistent, error) {
 	return snapshotList, nil
 }
 
+// GetSnapshotsForVolume retrieves all snapshots of a volume with a single ranged read
+func (p *EtcdClientV3) GetSnapshotsForVolume(volumeName string) ([]*storage.SnapshotPersistent, error) {
+	snapshotList := make([]*storage.SnapshotPersistent, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), config.PersistentStoreTimeout)
+	resp, err := p.clientV3.Get(ctx, config.SnapshotURL+"/"+volumeName+"/",
+		clientv3.WithPrefix(),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	for _, kv := range resp.Kvs {
+		snapPersistent := &storage.SnapshotPersistent{}
+		if err = json.Unmarshal(kv.Value, snapPersistent); err != nil {
+			return nil, err
+		}
+		snapshotList = append(snapshotList, snapPersistent)
+	}
+	return snapshotList, nil
+}
+
+// UpdateSnapshot updates a snapshot's state in the persistent store
+func (p *EtcdClientV3) UpdateSnapshot(snapshot *storage.Snapshot) error {
+	if snapJSON, err := json.Marshal(snapshot.ConstructPersistent()); err != nil {
+		return err
+	} else {
+		return p.Update(config.SnapshotURL+"/"+snapshot.ID(), string(snapJSON))
+	}
+}
+
 // DeleteSnapshot deletes a snapshot from the persistent store
 func (p *EtcdClientV3) DeleteSnapshot(snapshot *storage.Snapshot) error {
 	return p.Delete(config.SnapshotURL + "/" + snapshot.ID())
@@ -364,9 +364,22 @@ func TestEtcdv3DuplicateBackend(t *testing.T) {
 		t.Error(err.Error())
 		t.FailNow()
 	}
+	// Retrying an add that was already persisted succeeds
 	err = p.AddBackend(NFSServer)
-	if err == nil {
-		t.Error("Second Create should have failed!")
+	if err != nil {
+		t.Errorf("Adding an identical backend should have succeeded: %v", err)
+	}
+
+	// A different backend with the same name is still a duplicate
+	NFSServerConfig.SVM = "svm2"
+	err = p.AddBackend(&storage.Backend{
+		Driver: &ontap.NASStorageDriver{
+			Config: NFSServerConfig,
+		},
+		Name: NFSServer.Name,
+	})
+	if !MatchKeyExistsErr(err) {
+		t.Errorf("Adding a different backend with the same name should have failed, got %v", err)
 	}
 	err = p.DeleteBackend(NFSServer)
 	if err != nil {
@@ -843,6 +856,14 @@ func TestEtcdv3ReplaceBackendAndUpdateVolumes(t *testing.T) {
 	}
 }
 
+func TestEtcdv3DeleteVolumesForBackend(t *testing.T) {
+	p, err := NewEtcdClientV3(*etcdV3)
+	if err != nil {
+		t.Fatalf("Failed to create an etcdv3 client: %v", err)
+	}
+	testDeleteVolumesForBackend(t, p)
+}
+
 // TestEtcdv3FailedReplaceBackendAndUpdateVolumes tests that a backend doesn't
 // get updated if one of the volume updates fail.
 func TestEtcdv3FailedReplaceBackendAndUpdateVolumes(t *testing.T) {
@@ -1377,3 +1398,51 @@ func TestEtcdv3AddGetSnapshots(t *testing.T) {
 		t.Error(err.Error())
 	}
 }
+
+func TestEtcdv3GetSnapshotsForVolume(t *testing.T) {
+	p, _ := NewEtcdClientV3(*etcdV3)
+
+	// Adding snapshots across two volumes whose names share a prefix
+	for _, volumeName := range []string{"vol1", "vol10"} {
+		for i := 1; i <= 3; i++ {
+			snapConfig := &storage.SnapshotConfig{
+				Version:            config.OrchestratorAPIVersion,
+				Name:               "snap" + strconv.Itoa(i),
+				InternalName:       "snap" + strconv.Itoa(i),
+				VolumeName:         volumeName,
+				VolumeInternalName: "trident_" + volumeName,
+			}
+			snap := &storage.Snapshot{
+				Config:    snapConfig,
+				Created:   time.Now().UTC().Format(storage.SnapshotTimestampFormat),
+				SizeBytes: 1000000000,
+			}
+			if err := p.AddSnapshot(snap); err != nil {
+				t.Fatal(err.Error())
+			}
+		}
+	}
+	defer p.DeleteSnapshots()
+
+	// Getting only the snapshots of one volume
+	recoveredSnapshots, err := p.GetSnapshotsForVolume("vol1")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(recoveredSnapshots) != 3 {
+		t.Errorf("Expected %d snapshots; retrieved %d", 3, len(recoveredSnapshots))
+	}
+	for _, snap := range recoveredSnapshots {
+		if snap.Config.VolumeName != "vol1" {
+			t.Errorf("Retrieved snapshot %s of volume %s", snap.Config.Name, snap.Config.VolumeName)
+		}
+	}
+
+	// A volume without snapshots returns an empty list
+	recoveredSnapshots, err = p.GetSnapshotsForVolume("vol2")
+	if err != nil {
+		t.Error(err.Error())
+	} else if len(recoveredSnapshots) != 0 {
+		t.Errorf("Expected no snapshots; retrieved %d", len(recoveredSnapshots))
+	}
+}
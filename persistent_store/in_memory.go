@@ -3,6 +3,7 @@
 package persistentstore
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/netapp/trident/config"
@@ -25,6 +26,7 @@ type InMemoryClient struct {
 	nodesAdded          int
 	snapshots           map[string]*storage.SnapshotPersistent
 	snapshotsAdded      int
+	volumeEvents        map[string][]*storage.VolumeEvent
 }
 
 func NewInMemoryClient() *InMemoryClient {
@@ -35,6 +37,7 @@ func NewInMemoryClient() *InMemoryClient {
 		volumeTxns:     make(map[string]*VolumeTransaction),
 		nodes:          make(map[string]*utils.Node),
 		snapshots:      make(map[string]*storage.SnapshotPersistent),
+		volumeEvents:   make(map[string][]*storage.VolumeEvent),
 		version: &config.PersistentStateVersion{
 			"memory", config.OrchestratorAPIVersion,
 		},
@@ -59,6 +62,11 @@ func (c *InMemoryClient) GetConfig() *ClientConfig {
 	return &ClientConfig{}
 }
 
+// Ping always succeeds, since the in-memory store is always available
+func (c *InMemoryClient) Ping(ctx context.Context) error {
+	return nil
+}
+
 func (c *InMemoryClient) GetVersion() (*config.PersistentStateVersion, error) {
 	return c.version, nil
 }
@@ -121,12 +129,26 @@ func (c *InMemoryClient) DeleteBackend(b *storage.Backend) error {
 	return nil
 }
 
-// ReplaceBackendAndUpdateVolumes renames a backend and updates all volumes to
-// reflect the new backend name
+// ReplaceBackendAndUpdateVolumes replaces a backend, keeping its UUID so that
+// all volumes continue to refer to it.
 func (c *InMemoryClient) ReplaceBackendAndUpdateVolumes(
 	origBackend, newBackend *storage.Backend) error {
-	//TODO
-	return NewPersistentStoreError(NotSupported, "")
+
+	if err := validateBackendReplacement(origBackend, newBackend); err != nil {
+		return err
+	}
+	existing, ok := c.backends[origBackend.Name]
+	if !ok {
+		return NewPersistentStoreError(KeyNotFoundErr, origBackend.Name)
+	}
+	if _, ok = c.backends[newBackend.Name]; ok && newBackend.Name != origBackend.Name {
+		return fmt.Errorf("backend %s already exists", newBackend.Name)
+	}
+
+	newBackend.BackendUUID = existing.BackendUUID
+	delete(c.backends, origBackend.Name)
+	c.backends[newBackend.Name] = newBackend.ConstructPersistent()
+	return nil
 }
 
 func (c *InMemoryClient) GetBackends() ([]*storage.BackendPersistent, error) {
@@ -220,6 +242,23 @@ func (c *InMemoryClient) GetVolumes() ([]*storage.VolumeExternal, error) {
 	return ret, nil
 }
 
+func (c *InMemoryClient) GetVolumesPaged(
+	continueToken string, limit int,
+) ([]*storage.VolumeExternal, string, error) {
+
+	if err := validatePageLimit(limit); err != nil {
+		return nil, "", err
+	}
+
+	volumes, err := c.GetVolumes()
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, nextToken := pageVolumes(volumes, continueToken, limit)
+	return page, nextToken, nil
+}
+
 func (c *InMemoryClient) DeleteVolumes() error {
 	if c.volumesAdded == 0 {
 		// Try to match etcd semantics as closely as possible.
@@ -229,6 +268,33 @@ func (c *InMemoryClient) DeleteVolumes() error {
 	return nil
 }
 
+func (c *InMemoryClient) DeleteVolumesForBackend(backendUUID string) error {
+	for name, volume := range c.volumes {
+		if volume.BackendUUID == backendUUID {
+			delete(c.volumes, name)
+		}
+	}
+	return nil
+}
+
+func (c *InMemoryClient) GetVolumeEvents(volName string) ([]*storage.VolumeEvent, error) {
+	events, ok := c.volumeEvents[volName]
+	if !ok {
+		return make([]*storage.VolumeEvent, 0), nil
+	}
+	return events, nil
+}
+
+func (c *InMemoryClient) UpdateVolumeEvents(volName string, events []*storage.VolumeEvent) error {
+	c.volumeEvents[volName] = events
+	return nil
+}
+
+func (c *InMemoryClient) DeleteVolumeEvents(volName string) error {
+	delete(c.volumeEvents, volName)
+	return nil
+}
+
 func (c *InMemoryClient) AddVolumeTransaction(volTxn *VolumeTransaction) error {
 	// AddVolumeTransaction overwrites existing keys, unlike the other methods
 	c.volumeTxns[volTxn.getKey()] = volTxn
@@ -377,6 +443,27 @@ func (c *InMemoryClient) GetSnapshots() ([]*storage.SnapshotPersistent, error) {
 	return ret, nil
 }
 
+// GetSnapshotsForVolume retrieves all snapshots of a volume
+func (c *InMemoryClient) GetSnapshotsForVolume(volumeName string) ([]*storage.SnapshotPersistent, error) {
+	ret := make([]*storage.SnapshotPersistent, 0)
+	for _, s := range c.snapshots {
+		if s.Config.VolumeName == volumeName {
+			ret = append(ret, s)
+		}
+	}
+	return ret, nil
+}
+
+// UpdateSnapshot updates a snapshot state in the persistent store
+func (c *InMemoryClient) UpdateSnapshot(snapshot *storage.Snapshot) error {
+	// UpdateSnapshot requires the snapshot to already exist.
+	if _, ok := c.snapshots[snapshot.ID()]; !ok {
+		return NewPersistentStoreError(KeyNotFoundErr, snapshot.Config.Name)
+	}
+	c.snapshots[snapshot.ID()] = snapshot.ConstructPersistent()
+	return nil
+}
+
 // DeleteSnapshot deletes a snapshot from the persistent store
 func (c *InMemoryClient) DeleteSnapshot(snapshot *storage.Snapshot) error {
 	if _, ok := c.snapshots[snapshot.ID()]; !ok {
@@ -0,0 +1,319 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/storage_class"
+	"github.com/netapp/trident/utils"
+)
+
+const (
+	metricsNamespace = config.OrchestratorName
+	metricsSubsystem = "persistent_store"
+
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+var (
+	// StoreOperationsTotal counts persistent store operations by operation and outcome.
+	StoreOperationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "operations_total",
+			Help:      "The total number of persistent store operations.",
+		},
+		[]string{"operation", "outcome"},
+	)
+
+	// StoreOperationDurationSeconds records persistent store operation latency by operation and outcome.
+	StoreOperationDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "operation_duration_seconds",
+			Help:      "The latency of persistent store operations.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+		},
+		[]string{"operation", "outcome"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(StoreOperationsTotal, StoreOperationDurationSeconds)
+}
+
+var _ Client = &MetricsClient{}
+
+// MetricsClient decorates any persistent store Client, recording the count and latency
+// of every store operation so that all store implementations are instrumented alike.
+type MetricsClient struct {
+	client Client
+}
+
+// NewMetricsClient returns a Client that records metrics for the operations of the supplied Client.
+func NewMetricsClient(client Client) *MetricsClient {
+	return &MetricsClient{client: client}
+}
+
+// observe records the outcome and duration of a single store operation.
+func (m *MetricsClient) observe(operation string, start time.Time, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeFailure
+	}
+	StoreOperationsTotal.WithLabelValues(operation, outcome).Inc()
+	StoreOperationDurationSeconds.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+func (m *MetricsClient) GetConfig() *ClientConfig {
+	return m.client.GetConfig()
+}
+
+func (m *MetricsClient) GetType() StoreType {
+	return m.client.GetType()
+}
+
+func (m *MetricsClient) Stop() error {
+	return m.client.Stop()
+}
+
+func (m *MetricsClient) Ping(ctx context.Context) (err error) {
+	defer func(start time.Time) { m.observe("Ping", start, err) }(time.Now())
+	return m.client.Ping(ctx)
+}
+
+func (m *MetricsClient) GetVersion() (ret *config.PersistentStateVersion, err error) {
+	defer func(start time.Time) { m.observe("GetVersion", start, err) }(time.Now())
+	return m.client.GetVersion()
+}
+
+func (m *MetricsClient) SetVersion(version *config.PersistentStateVersion) (err error) {
+	defer func(start time.Time) { m.observe("SetVersion", start, err) }(time.Now())
+	return m.client.SetVersion(version)
+}
+
+func (m *MetricsClient) AddBackend(b *storage.Backend) (err error) {
+	defer func(start time.Time) { m.observe("AddBackend", start, err) }(time.Now())
+	return m.client.AddBackend(b)
+}
+
+func (m *MetricsClient) AddBackendPersistent(b *storage.BackendPersistent) (err error) {
+	defer func(start time.Time) { m.observe("AddBackendPersistent", start, err) }(time.Now())
+	return m.client.AddBackendPersistent(b)
+}
+
+func (m *MetricsClient) GetBackend(backendName string) (ret *storage.BackendPersistent, err error) {
+	defer func(start time.Time) { m.observe("GetBackend", start, err) }(time.Now())
+	return m.client.GetBackend(backendName)
+}
+
+func (m *MetricsClient) UpdateBackend(b *storage.Backend) (err error) {
+	defer func(start time.Time) { m.observe("UpdateBackend", start, err) }(time.Now())
+	return m.client.UpdateBackend(b)
+}
+
+func (m *MetricsClient) UpdateBackendPersistent(b *storage.BackendPersistent) (err error) {
+	defer func(start time.Time) { m.observe("UpdateBackendPersistent", start, err) }(time.Now())
+	return m.client.UpdateBackendPersistent(b)
+}
+
+func (m *MetricsClient) DeleteBackend(backend *storage.Backend) (err error) {
+	defer func(start time.Time) { m.observe("DeleteBackend", start, err) }(time.Now())
+	return m.client.DeleteBackend(backend)
+}
+
+func (m *MetricsClient) GetBackends() (ret []*storage.BackendPersistent, err error) {
+	defer func(start time.Time) { m.observe("GetBackends", start, err) }(time.Now())
+	return m.client.GetBackends()
+}
+
+func (m *MetricsClient) DeleteBackends() (err error) {
+	defer func(start time.Time) { m.observe("DeleteBackends", start, err) }(time.Now())
+	return m.client.DeleteBackends()
+}
+
+func (m *MetricsClient) ReplaceBackendAndUpdateVolumes(origBackend, newBackend *storage.Backend) (err error) {
+	defer func(start time.Time) { m.observe("ReplaceBackendAndUpdateVolumes", start, err) }(time.Now())
+	return m.client.ReplaceBackendAndUpdateVolumes(origBackend, newBackend)
+}
+
+func (m *MetricsClient) AddVolume(vol *storage.Volume) (err error) {
+	defer func(start time.Time) { m.observe("AddVolume", start, err) }(time.Now())
+	return m.client.AddVolume(vol)
+}
+
+func (m *MetricsClient) AddVolumePersistent(vol *storage.VolumeExternal) (err error) {
+	defer func(start time.Time) { m.observe("AddVolumePersistent", start, err) }(time.Now())
+	return m.client.AddVolumePersistent(vol)
+}
+
+func (m *MetricsClient) GetVolume(volName string) (ret *storage.VolumeExternal, err error) {
+	defer func(start time.Time) { m.observe("GetVolume", start, err) }(time.Now())
+	return m.client.GetVolume(volName)
+}
+
+func (m *MetricsClient) UpdateVolume(vol *storage.Volume) (err error) {
+	defer func(start time.Time) { m.observe("UpdateVolume", start, err) }(time.Now())
+	return m.client.UpdateVolume(vol)
+}
+
+func (m *MetricsClient) UpdateVolumePersistent(vol *storage.VolumeExternal) (err error) {
+	defer func(start time.Time) { m.observe("UpdateVolumePersistent", start, err) }(time.Now())
+	return m.client.UpdateVolumePersistent(vol)
+}
+
+func (m *MetricsClient) DeleteVolume(vol *storage.Volume) (err error) {
+	defer func(start time.Time) { m.observe("DeleteVolume", start, err) }(time.Now())
+	return m.client.DeleteVolume(vol)
+}
+
+func (m *MetricsClient) DeleteVolumeIgnoreNotFound(vol *storage.Volume) (err error) {
+	defer func(start time.Time) { m.observe("DeleteVolumeIgnoreNotFound", start, err) }(time.Now())
+	return m.client.DeleteVolumeIgnoreNotFound(vol)
+}
+
+func (m *MetricsClient) GetVolumes() (ret []*storage.VolumeExternal, err error) {
+	defer func(start time.Time) { m.observe("GetVolumes", start, err) }(time.Now())
+	return m.client.GetVolumes()
+}
+
+func (m *MetricsClient) GetVolumesPaged(
+	continueToken string, limit int,
+) (ret []*storage.VolumeExternal, nextToken string, err error) {
+	defer func(start time.Time) { m.observe("GetVolumesPaged", start, err) }(time.Now())
+	return m.client.GetVolumesPaged(continueToken, limit)
+}
+
+func (m *MetricsClient) DeleteVolumes() (err error) {
+	defer func(start time.Time) { m.observe("DeleteVolumes", start, err) }(time.Now())
+	return m.client.DeleteVolumes()
+}
+
+func (m *MetricsClient) DeleteVolumesForBackend(backendUUID string) (err error) {
+	defer func(start time.Time) { m.observe("DeleteVolumesForBackend", start, err) }(time.Now())
+	return m.client.DeleteVolumesForBackend(backendUUID)
+}
+
+func (m *MetricsClient) GetVolumeEvents(volName string) (ret []*storage.VolumeEvent, err error) {
+	defer func(start time.Time) { m.observe("GetVolumeEvents", start, err) }(time.Now())
+	return m.client.GetVolumeEvents(volName)
+}
+
+func (m *MetricsClient) UpdateVolumeEvents(volName string, events []*storage.VolumeEvent) (err error) {
+	defer func(start time.Time) { m.observe("UpdateVolumeEvents", start, err) }(time.Now())
+	return m.client.UpdateVolumeEvents(volName, events)
+}
+
+func (m *MetricsClient) DeleteVolumeEvents(volName string) (err error) {
+	defer func(start time.Time) { m.observe("DeleteVolumeEvents", start, err) }(time.Now())
+	return m.client.DeleteVolumeEvents(volName)
+}
+
+func (m *MetricsClient) AddVolumeTransaction(volTxn *VolumeTransaction) (err error) {
+	defer func(start time.Time) { m.observe("AddVolumeTransaction", start, err) }(time.Now())
+	return m.client.AddVolumeTransaction(volTxn)
+}
+
+func (m *MetricsClient) GetVolumeTransactions() (ret []*VolumeTransaction, err error) {
+	defer func(start time.Time) { m.observe("GetVolumeTransactions", start, err) }(time.Now())
+	return m.client.GetVolumeTransactions()
+}
+
+func (m *MetricsClient) GetExistingVolumeTransaction(volTxn *VolumeTransaction) (ret *VolumeTransaction, err error) {
+	defer func(start time.Time) { m.observe("GetExistingVolumeTransaction", start, err) }(time.Now())
+	return m.client.GetExistingVolumeTransaction(volTxn)
+}
+
+func (m *MetricsClient) DeleteVolumeTransaction(volTxn *VolumeTransaction) (err error) {
+	defer func(start time.Time) { m.observe("DeleteVolumeTransaction", start, err) }(time.Now())
+	return m.client.DeleteVolumeTransaction(volTxn)
+}
+
+func (m *MetricsClient) AddStorageClass(sc *storageclass.StorageClass) (err error) {
+	defer func(start time.Time) { m.observe("AddStorageClass", start, err) }(time.Now())
+	return m.client.AddStorageClass(sc)
+}
+
+func (m *MetricsClient) GetStorageClass(scName string) (ret *storageclass.Persistent, err error) {
+	defer func(start time.Time) { m.observe("GetStorageClass", start, err) }(time.Now())
+	return m.client.GetStorageClass(scName)
+}
+
+func (m *MetricsClient) GetStorageClasses() (ret []*storageclass.Persistent, err error) {
+	defer func(start time.Time) { m.observe("GetStorageClasses", start, err) }(time.Now())
+	return m.client.GetStorageClasses()
+}
+
+func (m *MetricsClient) DeleteStorageClass(sc *storageclass.StorageClass) (err error) {
+	defer func(start time.Time) { m.observe("DeleteStorageClass", start, err) }(time.Now())
+	return m.client.DeleteStorageClass(sc)
+}
+
+func (m *MetricsClient) AddOrUpdateNode(n *utils.Node) (err error) {
+	defer func(start time.Time) { m.observe("AddOrUpdateNode", start, err) }(time.Now())
+	return m.client.AddOrUpdateNode(n)
+}
+
+func (m *MetricsClient) GetNode(nName string) (ret *utils.Node, err error) {
+	defer func(start time.Time) { m.observe("GetNode", start, err) }(time.Now())
+	return m.client.GetNode(nName)
+}
+
+func (m *MetricsClient) GetNodes() (ret []*utils.Node, err error) {
+	defer func(start time.Time) { m.observe("GetNodes", start, err) }(time.Now())
+	return m.client.GetNodes()
+}
+
+func (m *MetricsClient) DeleteNode(n *utils.Node) (err error) {
+	defer func(start time.Time) { m.observe("DeleteNode", start, err) }(time.Now())
+	return m.client.DeleteNode(n)
+}
+
+func (m *MetricsClient) AddSnapshot(snapshot *storage.Snapshot) (err error) {
+	defer func(start time.Time) { m.observe("AddSnapshot", start, err) }(time.Now())
+	return m.client.AddSnapshot(snapshot)
+}
+
+func (m *MetricsClient) GetSnapshot(volumeName, snapshotName string) (ret *storage.SnapshotPersistent, err error) {
+	defer func(start time.Time) { m.observe("GetSnapshot", start, err) }(time.Now())
+	return m.client.GetSnapshot(volumeName, snapshotName)
+}
+
+func (m *MetricsClient) GetSnapshots() (ret []*storage.SnapshotPersistent, err error) {
+	defer func(start time.Time) { m.observe("GetSnapshots", start, err) }(time.Now())
+	return m.client.GetSnapshots()
+}
+
+func (m *MetricsClient) GetSnapshotsForVolume(volumeName string) (ret []*storage.SnapshotPersistent, err error) {
+	defer func(start time.Time) { m.observe("GetSnapshotsForVolume", start, err) }(time.Now())
+	return m.client.GetSnapshotsForVolume(volumeName)
+}
+
+func (m *MetricsClient) UpdateSnapshot(snapshot *storage.Snapshot) (err error) {
+	defer func(start time.Time) { m.observe("UpdateSnapshot", start, err) }(time.Now())
+	return m.client.UpdateSnapshot(snapshot)
+}
+
+func (m *MetricsClient) DeleteSnapshot(snapshot *storage.Snapshot) (err error) {
+	defer func(start time.Time) { m.observe("DeleteSnapshot", start, err) }(time.Now())
+	return m.client.DeleteSnapshot(snapshot)
+}
+
+func (m *MetricsClient) DeleteSnapshotIgnoreNotFound(snapshot *storage.Snapshot) (err error) {
+	defer func(start time.Time) { m.observe("DeleteSnapshotIgnoreNotFound", start, err) }(time.Now())
+	return m.client.DeleteSnapshotIgnoreNotFound(snapshot)
+}
+
+func (m *MetricsClient) DeleteSnapshots() (err error) {
+	defer func(start time.Time) { m.observe("DeleteSnapshots", start, err) }(time.Now())
+	return m.client.DeleteSnapshots()
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func getStoreOperationCount(operation, outcome string) float64 {
+	return testutil.ToFloat64(StoreOperationsTotal.WithLabelValues(operation, outcome))
+}
+
+func TestMetricsClientCountsOperations(t *testing.T) {
+
+	client := NewMetricsClient(NewInMemoryClient())
+
+	operations := []string{"AddBackend", "GetBackend", "UpdateBackend", "GetBackends", "DeleteBackend"}
+	before := make(map[string]float64)
+	for _, operation := range operations {
+		before[operation] = getStoreOperationCount(operation, OutcomeSuccess)
+	}
+	getBackendFailuresBefore := getStoreOperationCount("GetBackend", OutcomeFailure)
+
+	// Run a CRUD cycle against the instrumented client
+	backend := getFakeBackend()
+	if err := client.AddBackend(backend); err != nil {
+		t.Fatalf("Unable to add backend: %v", err)
+	}
+	if _, err := client.GetBackend(backend.Name); err != nil {
+		t.Fatalf("Unable to get backend: %v", err)
+	}
+	if err := client.UpdateBackend(backend); err != nil {
+		t.Fatalf("Unable to update backend: %v", err)
+	}
+	if _, err := client.GetBackends(); err != nil {
+		t.Fatalf("Unable to get backends: %v", err)
+	}
+	if err := client.DeleteBackend(backend); err != nil {
+		t.Fatalf("Unable to delete backend: %v", err)
+	}
+	if _, err := client.GetBackend(backend.Name); err == nil {
+		t.Fatal("Expected an error getting a deleted backend")
+	}
+
+	for _, operation := range operations {
+		if after := getStoreOperationCount(operation, OutcomeSuccess); after != before[operation]+1 {
+			t.Errorf("Expected %s success count %v, got %v", operation, before[operation]+1, after)
+		}
+	}
+	if after := getStoreOperationCount("GetBackend", OutcomeFailure); after != getBackendFailuresBefore+1 {
+		t.Errorf("Expected GetBackend failure count %v, got %v", getBackendFailuresBefore+1, after)
+	}
+
+	// Non-operational methods are passed through
+	if client.GetType() != MemoryStore {
+		t.Errorf("Expected store type %s, got %s", MemoryStore, client.GetType())
+	}
+}
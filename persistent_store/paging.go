@@ -0,0 +1,72 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/netapp/trident/storage"
+)
+
+// VolumePageSize is the number of volumes GetVolumes reads from the store at a time.
+const VolumePageSize = 500
+
+// volumePageFunc is the signature of a client's GetVolumesPaged method.
+type volumePageFunc func(continueToken string, limit int) ([]*storage.VolumeExternal, string, error)
+
+// getAllVolumePages reads every volume from a store one page at a time.
+func getAllVolumePages(getPage volumePageFunc) ([]*storage.VolumeExternal, error) {
+
+	volumeList := make([]*storage.VolumeExternal, 0)
+	continueToken := ""
+
+	for {
+		volumes, nextToken, err := getPage(continueToken, VolumePageSize)
+		if err != nil {
+			return nil, err
+		}
+		volumeList = append(volumeList, volumes...)
+		if nextToken == "" {
+			return volumeList, nil
+		}
+		continueToken = nextToken
+	}
+}
+
+// validatePageLimit ensures a caller asked for a usable page size.
+func validatePageLimit(limit int) error {
+	if limit <= 0 {
+		return fmt.Errorf("invalid page size %d; the limit must be positive", limit)
+	}
+	return nil
+}
+
+// pageVolumes returns one page of a list of volumes, ordered by name, for stores that cannot
+// page on the server side.  The continue token is the name of the first volume in the next
+// page, or empty if there are no more volumes.
+func pageVolumes(
+	volumes []*storage.VolumeExternal, continueToken string, limit int,
+) ([]*storage.VolumeExternal, string) {
+
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Config.Name < volumes[j].Config.Name })
+
+	start := sort.Search(len(volumes), func(i int) bool { return volumes[i].Config.Name >= continueToken })
+	end := start + limit
+	if end >= len(volumes) {
+		return volumes[start:], ""
+	}
+	return volumes[start:end], volumes[end].Config.Name
+}
+
+// pageNames returns one page of a list of sorted names, using the same continue token
+// semantics as pageVolumes.
+func pageNames(names []string, continueToken string, limit int) ([]string, string) {
+
+	start := sort.SearchStrings(names, continueToken)
+	end := start + limit
+	if end >= len(names) {
+		return names[start:], ""
+	}
+	return names[start:end], names[end]
+}
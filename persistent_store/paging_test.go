@@ -0,0 +1,130 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func getAllVolumeNamesPaged(t *testing.T, client Client, limit int) ([]string, int) {
+
+	names := make([]string, 0)
+	pages := 0
+	continueToken := ""
+
+	for {
+		volumes, nextToken, err := client.GetVolumesPaged(continueToken, limit)
+		if err != nil {
+			t.Fatalf("Unable to get volumes page: %v", err)
+		}
+		if len(volumes) > limit {
+			t.Fatalf("Expected at most %d volumes in page, got %d", limit, len(volumes))
+		}
+		pages++
+		for _, volume := range volumes {
+			names = append(names, volume.Config.Name)
+		}
+		if nextToken == "" {
+			return names, pages
+		}
+		continueToken = nextToken
+	}
+}
+
+func TestGetVolumesPaged(t *testing.T) {
+
+	for _, c := range []struct {
+		name          string
+		volumeCount   int
+		limit         int
+		expectedPages int
+	}{
+		{"empty", 0, 10, 1},
+		{"single page", 5, 10, 1},
+		{"exact page", 10, 10, 1},
+		{"multiple pages", 25, 10, 3},
+		{"page size of one", 3, 1, 3},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+
+			client := NewInMemoryClient()
+			backend := getFakeBackend()
+
+			expectedNames := make([]string, 0, c.volumeCount)
+			for i := 0; i < c.volumeCount; i++ {
+				name := fmt.Sprintf("vol%03d", i)
+				if err := client.AddVolume(getFakeVolumeWithName(name, backend)); err != nil {
+					t.Fatalf("Unable to add volume %s: %v", name, err)
+				}
+				expectedNames = append(expectedNames, name)
+			}
+
+			names, pages := getAllVolumeNamesPaged(t, client, c.limit)
+			if pages != c.expectedPages {
+				t.Errorf("Expected %d pages, got %d", c.expectedPages, pages)
+			}
+			if !reflect.DeepEqual(names, expectedNames) {
+				t.Errorf("Expected volumes %v, got %v", expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestGetVolumesPagedInvalidLimit(t *testing.T) {
+
+	client := NewInMemoryClient()
+
+	for _, limit := range []int{0, -1} {
+		if _, _, err := client.GetVolumesPaged("", limit); err == nil {
+			t.Errorf("Expected an error for page size %d", limit)
+		}
+	}
+}
+
+func TestGetAllVolumePages(t *testing.T) {
+
+	client := NewInMemoryClient()
+	backend := getFakeBackend()
+
+	volumeCount := 2*VolumePageSize + 1
+	for i := 0; i < volumeCount; i++ {
+		if err := client.AddVolume(getFakeVolumeWithName(fmt.Sprintf("vol%04d", i), backend)); err != nil {
+			t.Fatalf("Unable to add volume: %v", err)
+		}
+	}
+
+	volumes, err := getAllVolumePages(client.GetVolumesPaged)
+	if err != nil {
+		t.Fatalf("Unable to get volumes: %v", err)
+	}
+	if len(volumes) != volumeCount {
+		t.Errorf("Expected %d volumes, got %d", volumeCount, len(volumes))
+	}
+}
+
+func TestPageNames(t *testing.T) {
+
+	names := []string{"a", "b", "c", "d", "e"}
+
+	for _, c := range []struct {
+		continueToken string
+		limit         int
+		expectedPage  []string
+		expectedToken string
+	}{
+		{"", 2, []string{"a", "b"}, "c"},
+		{"c", 2, []string{"c", "d"}, "e"},
+		{"e", 2, []string{"e"}, ""},
+		{"", 5, names, ""},
+		{"", 10, names, ""},
+		{"z", 2, []string{}, ""},
+	} {
+		page, nextToken := pageNames(names, c.continueToken, c.limit)
+		if !reflect.DeepEqual(page, c.expectedPage) || nextToken != c.expectedToken {
+			t.Errorf("Expected page %v and token %q for token %q, got %v and %q",
+				c.expectedPage, c.expectedToken, c.continueToken, page, nextToken)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package persistentstore
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -201,6 +202,11 @@ func (c *PassthroughClient) GetConfig() *ClientConfig {
 	return &ClientConfig{}
 }
 
+// Ping always succeeds, since the passthrough store has no backing service
+func (c *PassthroughClient) Ping(ctx context.Context) error {
+	return nil
+}
+
 func (c *PassthroughClient) GetVersion() (*config.PersistentStateVersion, error) {
 	return c.version, nil
 }
@@ -355,6 +361,25 @@ func (c *PassthroughClient) GetVolumes() ([]*storage.VolumeExternal, error) {
 	return volumes, nil
 }
 
+// GetVolumesPaged returns one page of the volumes reported by the backends.  Because the
+// volumes are discovered from the backends themselves, they must all be read to build a page.
+func (c *PassthroughClient) GetVolumesPaged(
+	continueToken string, limit int,
+) ([]*storage.VolumeExternal, string, error) {
+
+	if err := validatePageLimit(limit); err != nil {
+		return nil, "", err
+	}
+
+	volumes, err := c.GetVolumes()
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, nextToken := pageVolumes(volumes, continueToken, limit)
+	return page, nextToken, nil
+}
+
 // getVolumesFromBackend reads all of the volumes managed by a single backend.
 // This method is designed to run in a goroutine, so it passes its results back
 // via a channel that is shared by all such goroutines.
@@ -381,6 +406,22 @@ func (c *PassthroughClient) DeleteVolumes() error {
 	return nil
 }
 
+func (c *PassthroughClient) DeleteVolumesForBackend(backendUUID string) error {
+	return nil
+}
+
+func (c *PassthroughClient) GetVolumeEvents(volName string) ([]*storage.VolumeEvent, error) {
+	return make([]*storage.VolumeEvent, 0), nil
+}
+
+func (c *PassthroughClient) UpdateVolumeEvents(volName string, events []*storage.VolumeEvent) error {
+	return nil
+}
+
+func (c *PassthroughClient) DeleteVolumeEvents(volName string) error {
+	return nil
+}
+
 func (c *PassthroughClient) AddVolumeTransaction(volTxn *VolumeTransaction) error {
 	return nil
 }
@@ -442,6 +483,15 @@ func (c *PassthroughClient) GetSnapshots() ([]*storage.SnapshotPersistent, error
 	return make([]*storage.SnapshotPersistent, 0), nil
 }
 
+// GetSnapshotsForVolume retrieves all snapshots of a volume
+func (c *PassthroughClient) GetSnapshotsForVolume(volumeName string) ([]*storage.SnapshotPersistent, error) {
+	return make([]*storage.SnapshotPersistent, 0), nil
+}
+
+func (c *PassthroughClient) UpdateSnapshot(snapshot *storage.Snapshot) error {
+	return nil
+}
+
 func (c *PassthroughClient) DeleteSnapshot(snapshot *storage.Snapshot) error {
 	return nil
 }
@@ -3,6 +3,7 @@
 package persistentstore
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -219,6 +220,14 @@ func TestPassthroughClient_Stop(t *testing.T) {
 	}
 }
 
+func TestPassthroughClient_Ping(t *testing.T) {
+	p := newPassthroughClient()
+
+	if err := p.Ping(context.Background()); err != nil {
+		t.Errorf("Passthrough client ping failed: %v", err)
+	}
+}
+
 func TestPassthroughClient_GetConfig(t *testing.T) {
 	p := newPassthroughClient()
 
@@ -852,6 +861,21 @@ func TestPassthroughClient_GetSnapshots(t *testing.T) {
 	}
 }
 
+func TestPassthroughClient_GetSnapshotsForVolume(t *testing.T) {
+	p := newPassthroughClient()
+	fakeSnapshot := getFakeSnapshot()
+	_ = p.AddSnapshot(fakeSnapshot)
+
+	result, err := p.GetSnapshotsForVolume(fakeSnapshot.Config.VolumeName)
+
+	if err != nil {
+		t.Error("Could not get snapshots from passthrough client!")
+	}
+	if len(result) != 0 {
+		t.Error("Did not expect to get snapshots from passthrough client!")
+	}
+}
+
 func TestPassthroughClient_DeleteSnapshot(t *testing.T) {
 	p := newPassthroughClient()
 	fakeSnapshot := getFakeSnapshot()
@@ -0,0 +1,30 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"fmt"
+
+	"github.com/netapp/trident/storage"
+)
+
+// validateBackendReplacement ensures a backend may be replaced in the store by another one.
+// Volumes refer to their backend by UUID, so a replacement must keep the same storage driver
+// and must have a config that can be persisted; otherwise the volumes would be orphaned.
+func validateBackendReplacement(origBackend, newBackend *storage.Backend) error {
+
+	if origBackend == nil || newBackend == nil {
+		return fmt.Errorf("cannot replace a backend without both original and new backends")
+	}
+	if origBackend.Driver == nil || newBackend.Driver == nil {
+		return fmt.Errorf("cannot replace backend %s without a storage driver", origBackend.Name)
+	}
+	if origBackend.GetDriverName() != newBackend.GetDriverName() {
+		return fmt.Errorf("cannot replace backend %s; storage driver changed from %s to %s",
+			origBackend.Name, origBackend.GetDriverName(), newBackend.GetDriverName())
+	}
+	if _, err := newBackend.ConstructPersistent().MarshalConfig(); err != nil {
+		return fmt.Errorf("cannot replace backend %s; invalid config: %v", origBackend.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/ontap"
+	"github.com/netapp/trident/storage_drivers/solidfire"
+)
+
+func getOntapNASBackend(name, managementLIF, password string) *storage.Backend {
+	return &storage.Backend{
+		Driver: &ontap.NASStorageDriver{
+			Config: drivers.OntapStorageDriverConfig{
+				CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
+					StorageDriverName: drivers.OntapNASStorageDriverName,
+				},
+				ManagementLIF: managementLIF,
+				DataLIF:       "10.0.0.100",
+				SVM:           "svm1",
+				Username:      "admin",
+				Password:      password,
+			},
+		},
+		Name:        name,
+		BackendUUID: "a7c7d6b9-9c2b-4c3a-9a1e-3b8f0c2d1e4f",
+	}
+}
+
+func TestInMemoryReplaceBackendAndUpdateVolumes(t *testing.T) {
+
+	p := NewInMemoryClient()
+
+	origBackend := getOntapNASBackend("ontapnas", "10.0.0.4", "netapp")
+	if err := p.AddBackend(origBackend); err != nil {
+		t.Fatalf("Backend creation failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		vol := &storage.Volume{
+			Config: &storage.VolumeConfig{
+				Version:  string(config.OrchestratorAPIVersion),
+				Name:     fmt.Sprintf("vol%d", i),
+				Size:     "1GB",
+				Protocol: config.File,
+			},
+			BackendUUID: origBackend.BackendUUID,
+			Pool:        storagePool,
+		}
+		if err := p.AddVolume(vol); err != nil {
+			t.Fatalf("Volume creation failed: %v", err)
+		}
+	}
+
+	// Change the management LIF and password, leaving the UUID unset
+	newBackend := getOntapNASBackend("ontapnas", "10.0.0.5", "newpassword")
+	newBackend.BackendUUID = ""
+	if err := p.ReplaceBackendAndUpdateVolumes(origBackend, newBackend); err != nil {
+		t.Fatalf("ReplaceBackendAndUpdateVolumes failed: %v", err)
+	}
+
+	backend, err := p.GetBackend("ontapnas")
+	if err != nil {
+		t.Fatalf("Backend retrieval failed: %v", err)
+	}
+	if backend.BackendUUID != origBackend.BackendUUID {
+		t.Errorf("Expected backend UUID %s, got %s", origBackend.BackendUUID, backend.BackendUUID)
+	}
+	if backend.Config.OntapConfig == nil {
+		t.Fatal("Expected an ONTAP config in the replaced backend")
+	}
+	if backend.Config.OntapConfig.ManagementLIF != "10.0.0.5" {
+		t.Errorf("Expected management LIF 10.0.0.5, got %s", backend.Config.OntapConfig.ManagementLIF)
+	}
+	if backend.Config.OntapConfig.Password != "newpassword" {
+		t.Error("Expected the replaced backend to have the new password")
+	}
+
+	// The volumes must still resolve to the backend by its unchanged UUID
+	volumes, err := p.GetVolumes()
+	if err != nil || len(volumes) != 3 {
+		t.Fatalf("Volume retrieval failed; volumes: %v, err: %v", volumes, err)
+	}
+	for _, volume := range volumes {
+		if volume.BackendUUID != backend.BackendUUID {
+			t.Errorf("Volume %s refers to backend UUID %s, expected %s", volume.Config.Name,
+				volume.BackendUUID, backend.BackendUUID)
+		}
+	}
+}
+
+func TestInMemoryReplaceBackendDriverChange(t *testing.T) {
+
+	p := NewInMemoryClient()
+
+	origBackend := getOntapNASBackend("backend", "10.0.0.4", "netapp")
+	if err := p.AddBackend(origBackend); err != nil {
+		t.Fatalf("Backend creation failed: %v", err)
+	}
+
+	newBackend := &storage.Backend{
+		Driver: &solidfire.SANStorageDriver{
+			Config: drivers.SolidfireStorageDriverConfig{
+				CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
+					StorageDriverName: drivers.SolidfireSANStorageDriverName,
+				},
+				TenantName: "docker",
+			},
+		},
+		Name: "backend",
+	}
+	if err := p.ReplaceBackendAndUpdateVolumes(origBackend, newBackend); err == nil {
+		t.Error("Expected an error replacing an ONTAP NAS backend with a SolidFire SAN backend")
+	}
+
+	backend, err := p.GetBackend("backend")
+	if err != nil {
+		t.Fatalf("Backend retrieval failed: %v", err)
+	}
+	if backend.Config.OntapConfig == nil || backend.Config.SolidfireConfig != nil {
+		t.Error("Expected the original backend to be left unchanged")
+	}
+}
+
+func TestInMemoryReplaceBackendNonexistent(t *testing.T) {
+
+	p := NewInMemoryClient()
+
+	origBackend := getOntapNASBackend("backend", "10.0.0.4", "netapp")
+	newBackend := getOntapNASBackend("backend", "10.0.0.5", "netapp")
+	if err := p.ReplaceBackendAndUpdateVolumes(origBackend, newBackend); !MatchKeyNotFoundErr(err) {
+		t.Errorf("Expected a key not found error, got %v", err)
+	}
+}
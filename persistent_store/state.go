@@ -0,0 +1,189 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/storage_class"
+	"github.com/netapp/trident/utils"
+)
+
+// StateSchemaVersion identifies the format of an exported State document.  It must be
+// incremented whenever the document changes in a way that older importers can't handle.
+const StateSchemaVersion = "1"
+
+// State is a point-in-time copy of all of Trident's persistent objects, suitable for
+// backing up a persistent store and restoring it into another one.
+type State struct {
+	SchemaVersion      string                        `json:"schemaVersion"`
+	Backends           []*storage.BackendPersistent  `json:"backends"`
+	StorageClasses     []*storageclass.Persistent    `json:"storageClasses"`
+	Volumes            []*storage.VolumeExternal     `json:"volumes"`
+	Snapshots          []*storage.SnapshotPersistent `json:"snapshots"`
+	Nodes              []*utils.Node                 `json:"nodes"`
+	VolumeTransactions []*VolumeTransaction          `json:"volumeTransactions"`
+}
+
+// ImportStateResult reports how many objects were added to a store by ImportState, and how
+// many were skipped because they already existed.
+type ImportStateResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+// ExportState reads every object from the specified store client.
+func ExportState(client Client) (*State, error) {
+
+	var err error
+	state := &State{SchemaVersion: StateSchemaVersion}
+
+	if state.Backends, err = client.GetBackends(); err != nil && !MatchKeyNotFoundErr(err) {
+		return nil, fmt.Errorf("could not export backends: %v", err)
+	}
+	if state.StorageClasses, err = client.GetStorageClasses(); err != nil && !MatchKeyNotFoundErr(err) {
+		return nil, fmt.Errorf("could not export storage classes: %v", err)
+	}
+	if state.Volumes, err = client.GetVolumes(); err != nil && !MatchKeyNotFoundErr(err) {
+		return nil, fmt.Errorf("could not export volumes: %v", err)
+	}
+	if state.Snapshots, err = client.GetSnapshots(); err != nil && !MatchKeyNotFoundErr(err) {
+		return nil, fmt.Errorf("could not export snapshots: %v", err)
+	}
+	if state.Nodes, err = client.GetNodes(); err != nil && !MatchKeyNotFoundErr(err) {
+		return nil, fmt.Errorf("could not export nodes: %v", err)
+	}
+	if state.VolumeTransactions, err = client.GetVolumeTransactions(); err != nil && !MatchKeyNotFoundErr(err) {
+		return nil, fmt.Errorf("could not export volume transactions: %v", err)
+	}
+
+	log.WithFields(log.Fields{
+		"backends":           len(state.Backends),
+		"storageClasses":     len(state.StorageClasses),
+		"volumes":            len(state.Volumes),
+		"snapshots":          len(state.Snapshots),
+		"nodes":              len(state.Nodes),
+		"volumeTransactions": len(state.VolumeTransactions),
+	}).Debug("Exported persistent state.")
+
+	return state, nil
+}
+
+// ImportState writes the objects in an exported State to the specified store client.  Objects
+// that already exist in the store are left untouched.  Objects are added in dependency order
+// (backends before volumes, volumes before snapshots) so a partial import remains consistent.
+func ImportState(client Client, state *State) (*ImportStateResult, error) {
+
+	if state == nil {
+		return nil, fmt.Errorf("no state to import")
+	}
+	if state.SchemaVersion != StateSchemaVersion {
+		return nil, fmt.Errorf("unsupported state schema version %s; expected %s",
+			state.SchemaVersion, StateSchemaVersion)
+	}
+
+	result := &ImportStateResult{}
+
+	// importObject adds an object unless the existence check finds it already present
+	importObject := func(kind, name string, exists func() (bool, error), add func() error) error {
+		found, err := exists()
+		if err != nil {
+			return fmt.Errorf("could not check for %s %s: %v", kind, name, err)
+		}
+		if found {
+			log.WithFields(log.Fields{"kind": kind, "name": name}).Debug("Object exists, skipping import.")
+			result.Skipped++
+			return nil
+		}
+		if err = add(); err != nil {
+			return fmt.Errorf("could not import %s %s: %v", kind, name, err)
+		}
+		result.Imported++
+		return nil
+	}
+
+	// found converts the result of a Get* call into an existence check
+	found := func(err error) (bool, error) {
+		if err == nil {
+			return true, nil
+		} else if MatchKeyNotFoundErr(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, b := range state.Backends {
+		if err := importObject("backend", b.Name,
+			func() (bool, error) { _, err := client.GetBackend(b.Name); return found(err) },
+			func() error { return client.AddBackendPersistent(b) },
+		); err != nil {
+			return result, err
+		}
+	}
+
+	for _, psc := range state.StorageClasses {
+		sc := storageclass.NewFromPersistent(psc)
+		if err := importObject("storage class", sc.GetName(),
+			func() (bool, error) { _, err := client.GetStorageClass(sc.GetName()); return found(err) },
+			func() error { return client.AddStorageClass(sc) },
+		); err != nil {
+			return result, err
+		}
+	}
+
+	for _, v := range state.Volumes {
+		if err := importObject("volume", v.Config.Name,
+			func() (bool, error) { _, err := client.GetVolume(v.Config.Name); return found(err) },
+			func() error { return client.AddVolumePersistent(v) },
+		); err != nil {
+			return result, err
+		}
+	}
+
+	for _, s := range state.Snapshots {
+		snapshot := storage.NewSnapshot(s.Config, s.Created, s.SizeBytes)
+		if err := importObject("snapshot", snapshot.ID(),
+			func() (bool, error) {
+				_, err := client.GetSnapshot(s.Config.VolumeName, s.Config.Name)
+				return found(err)
+			},
+			func() error { return client.AddSnapshot(snapshot) },
+		); err != nil {
+			return result, err
+		}
+	}
+
+	for _, n := range state.Nodes {
+		if err := importObject("node", n.Name,
+			func() (bool, error) { _, err := client.GetNode(n.Name); return found(err) },
+			func() error { return client.AddOrUpdateNode(n) },
+		); err != nil {
+			return result, err
+		}
+	}
+
+	for _, txn := range state.VolumeTransactions {
+		if err := importObject("volume transaction", txn.getKey(),
+			func() (bool, error) {
+				existing, err := client.GetExistingVolumeTransaction(txn)
+				if err != nil {
+					return found(err)
+				}
+				return existing != nil, nil
+			},
+			func() error { return client.AddVolumeTransaction(txn) },
+		); err != nil {
+			return result, err
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"imported": result.Imported,
+		"skipped":  result.Skipped,
+	}).Debug("Imported persistent state.")
+
+	return result, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package persistentstore
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func newPopulatedInMemoryClient(t *testing.T) *InMemoryClient {
+
+	client := NewInMemoryClient()
+
+	backend := getFakeBackend()
+	if err := client.AddBackend(backend); err != nil {
+		t.Fatalf("Unable to add backend: %v", err)
+	}
+	if err := client.AddStorageClass(getFakeStorageClass()); err != nil {
+		t.Fatalf("Unable to add storage class: %v", err)
+	}
+	if err := client.AddVolume(getFakeVolumeWithName("vol1", backend)); err != nil {
+		t.Fatalf("Unable to add volume: %v", err)
+	}
+	if err := client.AddSnapshot(getFakeSnapshot()); err != nil {
+		t.Fatalf("Unable to add snapshot: %v", err)
+	}
+	if err := client.AddOrUpdateNode(getFakeNode()); err != nil {
+		t.Fatalf("Unable to add node: %v", err)
+	}
+	if err := client.AddVolumeTransaction(getFakeVolumeTransactionWithName("vol2")); err != nil {
+		t.Fatalf("Unable to add volume transaction: %v", err)
+	}
+
+	return client
+}
+
+func exportStateJSON(t *testing.T, client Client) []byte {
+
+	state, err := ExportState(client)
+	if err != nil {
+		t.Fatalf("Unable to export state: %v", err)
+	}
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("Unable to marshal state: %v", err)
+	}
+	return stateJSON
+}
+
+func TestExportImportStateRoundTrip(t *testing.T) {
+
+	source := newPopulatedInMemoryClient(t)
+	sourceJSON := exportStateJSON(t, source)
+
+	state := &State{}
+	if err := json.Unmarshal(sourceJSON, state); err != nil {
+		t.Fatalf("Unable to unmarshal state: %v", err)
+	}
+	if state.SchemaVersion != StateSchemaVersion {
+		t.Errorf("Expected schema version %s, got %s", StateSchemaVersion, state.SchemaVersion)
+	}
+
+	// Import into an empty store
+	destination := NewInMemoryClient()
+	result, err := ImportState(destination, state)
+	if err != nil {
+		t.Fatalf("Unable to import state: %v", err)
+	}
+	if result.Imported != 6 || result.Skipped != 0 {
+		t.Errorf("Expected 6 imported and 0 skipped objects, got %d and %d", result.Imported, result.Skipped)
+	}
+
+	// The destination should now export the same document as the source
+	if destinationJSON := exportStateJSON(t, destination); string(destinationJSON) != string(sourceJSON) {
+		t.Errorf("Round-tripped state differs.\nExpected: %s\nGot: %s", sourceJSON, destinationJSON)
+	}
+
+	// Importing again should skip everything
+	result, err = ImportState(destination, state)
+	if err != nil {
+		t.Fatalf("Unable to re-import state: %v", err)
+	}
+	if result.Imported != 0 || result.Skipped != 6 {
+		t.Errorf("Expected 0 imported and 6 skipped objects, got %d and %d", result.Imported, result.Skipped)
+	}
+}
+
+func TestImportStateRejectsUnknownSchemaVersion(t *testing.T) {
+
+	state, err := ExportState(newPopulatedInMemoryClient(t))
+	if err != nil {
+		t.Fatalf("Unable to export state: %v", err)
+	}
+	state.SchemaVersion = "0"
+
+	destination := NewInMemoryClient()
+	if _, err = ImportState(destination, state); err == nil {
+		t.Error("Expected an error importing state with an unknown schema version")
+	}
+	if backends, _ := destination.GetBackends(); len(backends) != 0 {
+		t.Errorf("Expected no backends to be imported, got %d", len(backends))
+	}
+}
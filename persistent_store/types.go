@@ -3,6 +3,7 @@
 package persistentstore
 
 import (
+	"context"
 	"crypto/tls"
 
 	"github.com/netapp/trident/config"
@@ -33,6 +34,7 @@ type Client interface {
 	GetConfig() *ClientConfig
 	GetType() StoreType
 	Stop() error
+	Ping(ctx context.Context) error
 
 	AddBackend(b *storage.Backend) error
 	AddBackendPersistent(b *storage.BackendPersistent) error
@@ -52,7 +54,13 @@ type Client interface {
 	DeleteVolume(vol *storage.Volume) error
 	DeleteVolumeIgnoreNotFound(vol *storage.Volume) error
 	GetVolumes() ([]*storage.VolumeExternal, error)
+	GetVolumesPaged(continueToken string, limit int) ([]*storage.VolumeExternal, string, error)
 	DeleteVolumes() error
+	DeleteVolumesForBackend(backendUUID string) error
+
+	GetVolumeEvents(volName string) ([]*storage.VolumeEvent, error)
+	UpdateVolumeEvents(volName string, events []*storage.VolumeEvent) error
+	DeleteVolumeEvents(volName string) error
 
 	AddVolumeTransaction(volTxn *VolumeTransaction) error
 	GetVolumeTransactions() ([]*VolumeTransaction, error)
@@ -72,6 +80,8 @@ type Client interface {
 	AddSnapshot(snapshot *storage.Snapshot) error
 	GetSnapshot(volumeName, snapshotName string) (*storage.SnapshotPersistent, error)
 	GetSnapshots() ([]*storage.SnapshotPersistent, error)
+	GetSnapshotsForVolume(volumeName string) ([]*storage.SnapshotPersistent, error)
+	UpdateSnapshot(snapshot *storage.Snapshot) error
 	DeleteSnapshot(snapshot *storage.Snapshot) error
 	DeleteSnapshotIgnoreNotFound(snapshot *storage.Snapshot) error
 	DeleteSnapshots() error
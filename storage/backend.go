@@ -5,7 +5,9 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/RoaringBitmap/roaring"
@@ -62,6 +64,34 @@ type Driver interface {
 	GetUpdateType(driver Driver) *roaring.Bitmap
 }
 
+// NodeAccessRemover is implemented by drivers that grant individual nodes access to their storage,
+// so that a node's access may be revoked when the node is removed from Trident.
+type NodeAccessRemover interface {
+	RemoveNodeAccess(node *utils.Node) error
+}
+
+// PoolCapacityReporter is implemented by drivers that can report how much space is in their storage pools.
+type PoolCapacityReporter interface {
+	GetPoolCapacity(pool *Pool) (*PoolCapacity, error)
+}
+
+// SnapshotReserver is implemented by drivers that can reserve space in a volume for its snapshots,
+// as requested by a snapshot's reserve percentage and size.
+type SnapshotReserver interface {
+	ReserveSnapshotSpace(snapConfig *SnapshotConfig) error
+}
+
+// IndependentCloneCreator is implemented by drivers that may create clones which no longer depend on
+// the snapshots they were cloned from, such as clones split from their source volumes.
+type IndependentCloneCreator interface {
+	IsCloneIndependent(cloneConfig *VolumeConfig) bool
+}
+
+// VolumeUsageReporter is implemented by drivers that can report how much of a volume's space is in use.
+type VolumeUsageReporter interface {
+	GetVolumeUsage(volConfig *VolumeConfig) (*VolumeUsage, error)
+}
+
 type Backend struct {
 	Driver      Driver
 	Name        string
@@ -70,12 +100,20 @@ type Backend struct {
 	State       BackendState
 	Storage     map[string]*Pool
 	Volumes     map[string]*Volume
+	// LimitSnapshotsPerVolume is the most snapshots a volume on this backend may have, or 0 for no limit
+	LimitSnapshotsPerVolume int
 }
 
 type UpdateBackendStateRequest struct {
 	State string `json:"state"`
 }
 
+// UpdateBackendCredentialsRequest replaces a backend's credentials.  An empty username keeps the current one.
+type UpdateBackendCredentialsRequest struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password"`
+}
+
 type BackendState string
 
 const (
@@ -193,6 +231,9 @@ func (b *Backend) AddVolume(
 	if err = b.Driver.CreatePrepare(volConfig); err != nil {
 		return nil, err
 	}
+	if err = b.applyInternalNameBase(volConfig); err != nil {
+		return nil, err
+	}
 
 	// Add volume to the backend
 	volumeExists := false
@@ -240,6 +281,58 @@ func (b *Backend) AddVolume(
 	return vol, nil
 }
 
+// PlanVolume checks whether a volume could be created in the specified storage pool, without
+// creating it.  The volume's internal name is set as it would be for a real create, and if the
+// driver reports pool capacity, the volume must fit in the space available.  The returned value
+// reports whether the capacity was checked.
+func (b *Backend) PlanVolume(volConfig *VolumeConfig, storagePool *Pool) (bool, error) {
+
+	log.WithFields(log.Fields{
+		"backend":      b.Name,
+		"volume":       volConfig.Name,
+		"storage_pool": storagePool.Name,
+		"size":         volConfig.Size,
+	}).Debug("Backend#PlanVolume")
+
+	// Ensure backend is ready
+	if err := b.ensureOnline(); err != nil {
+		return false, err
+	}
+
+	if err := b.Driver.CreatePrepare(volConfig); err != nil {
+		return false, err
+	}
+	if err := b.applyInternalNameBase(volConfig); err != nil {
+		return false, err
+	}
+
+	reporter, ok := b.Driver.(PoolCapacityReporter)
+	if !ok {
+		return false, nil
+	}
+	capacity, err := reporter.GetPoolCapacity(storagePool)
+	if err != nil {
+		return false, fmt.Errorf("could not get capacity of pool %s; %v", storagePool.Name, err)
+	} else if capacity == nil {
+		return false, nil
+	}
+
+	size, err := utils.ConvertSizeToBytes(volConfig.Size)
+	if err != nil {
+		return false, fmt.Errorf("could not convert volume size %s; %v", volConfig.Size, err)
+	}
+	sizeBytes, err := strconv.ParseUint(size, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("%v is an invalid volume size; %v", volConfig.Size, err)
+	}
+	if sizeBytes > capacity.AvailableBytes {
+		return false, fmt.Errorf("volume size %d bytes exceeds the %d bytes available in pool %s",
+			sizeBytes, capacity.AvailableBytes, storagePool.Name)
+	}
+
+	return true, nil
+}
+
 func (b *Backend) CloneVolume(volConfig *VolumeConfig) (*Volume, error) {
 
 	log.WithFields(log.Fields{
@@ -264,6 +357,9 @@ func (b *Backend) CloneVolume(volConfig *VolumeConfig) (*Volume, error) {
 	if err := b.Driver.CreatePrepare(volConfig); err != nil {
 		return nil, fmt.Errorf("failed to prepare clone create: %v", err)
 	}
+	if err := b.applyInternalNameBase(volConfig); err != nil {
+		return nil, err
+	}
 
 	err := b.Driver.CreateClone(volConfig)
 	if err != nil {
@@ -310,10 +406,134 @@ func (b *Backend) CloneVolume(volConfig *VolumeConfig) (*Volume, error) {
 	return vol, nil
 }
 
+// applyInternalNameBase derives a volume's internal name from its InternalNameBase, if one was
+// requested, instead of from its Trident name.  The driver's prefix and naming rules still apply,
+// and the result must fit the storage system and not be used by another volume on this backend,
+// including one that Trident doesn't manage.
+func (b *Backend) applyInternalNameBase(volConfig *VolumeConfig) error {
+
+	if volConfig.InternalNameBase == "" {
+		return nil
+	}
+
+	internalName := b.Driver.GetInternalVolumeName(volConfig.InternalNameBase)
+	if err := checkVolumeNameLength(internalName, b.GetDriverName()); err != nil {
+		return fmt.Errorf("invalid volume name for backend %s; %v", b.Name, err)
+	}
+
+	for _, vol := range b.Volumes {
+		if vol.Config.InternalName == internalName && vol.Config.Name != volConfig.Name {
+			return fmt.Errorf("volume name %s is already used by volume %s on backend %s",
+				internalName, vol.Config.Name, b.Name)
+		}
+	}
+	if err := b.Driver.Get(internalName); err == nil {
+		return fmt.Errorf("volume name %s is already used on backend %s", internalName, b.Name)
+	}
+
+	volConfig.InternalName = internalName
+	return nil
+}
+
+// RemoveNodeAccess revokes a node's access to this backend's storage, if the backend's driver
+// grants access to individual nodes.  Removing access for a node that has none is not an error.
+func (b *Backend) RemoveNodeAccess(node *utils.Node) error {
+
+	remover, ok := b.Driver.(NodeAccessRemover)
+	if !ok {
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"backend": b.Name,
+		"node":    node.Name,
+	}).Debug("Backend#RemoveNodeAccess")
+
+	// Ensure backend is ready
+	if err := b.ensureServingOrDeleting(); err != nil {
+		return err
+	}
+
+	return remover.RemoveNodeAccess(node)
+}
+
+// IsCloneIndependent returns true if a clone doesn't depend on the snapshot it was cloned from, so that
+// the snapshot may be deleted while the clone exists.  Clones depend on their snapshots unless the
+// backend's driver says otherwise.
+func (b *Backend) IsCloneIndependent(cloneConfig *VolumeConfig) bool {
+	creator, ok := b.Driver.(IndependentCloneCreator)
+	return ok && creator.IsCloneIndependent(cloneConfig)
+}
+
+// GetVolumeUsage returns how much of a volume's space is in use, or nil if the backend's storage
+// driver doesn't report volume usage.
+func (b *Backend) GetVolumeUsage(volConfig *VolumeConfig) (*VolumeUsage, error) {
+
+	reporter, ok := b.Driver.(VolumeUsageReporter)
+	if !ok {
+		return nil, nil
+	}
+
+	log.WithFields(log.Fields{
+		"backend":      b.Name,
+		"volume":       volConfig.Name,
+		"internalName": volConfig.InternalName,
+	}).Debug("Backend#GetVolumeUsage")
+
+	// Ensure backend is ready
+	if err := b.ensureServing(); err != nil {
+		return nil, err
+	}
+
+	return reporter.GetVolumeUsage(volConfig)
+}
+
+// GetPoolCapacities returns the backend's storage pools, sorted by name, along with the capacity of each
+// pool as reported by the storage driver.  Pools are returned without a capacity if the driver doesn't
+// report one.
+func (b *Backend) GetPoolCapacities() ([]*PoolCapacityExternal, error) {
+
+	log.WithField("backend", b.Name).Debug("Backend#GetPoolCapacities")
+
+	reporter, ok := b.Driver.(PoolCapacityReporter)
+	if ok {
+		// Ensure backend is ready
+		if err := b.ensureServing(); err != nil {
+			return nil, err
+		}
+	}
+
+	pools := make([]*PoolCapacityExternal, 0, len(b.Storage))
+	for _, pool := range b.Storage {
+		external := &PoolCapacityExternal{PoolExternal: *pool.ConstructExternal()}
+		if ok {
+			capacity, err := reporter.GetPoolCapacity(pool)
+			if err != nil {
+				return nil, fmt.Errorf("could not get capacity of pool %s; %v", pool.Name, err)
+			}
+			external.Capacity = capacity
+		}
+		pools = append(pools, external)
+	}
+
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+
+	return pools, nil
+}
+
+// checkVolumeNameLength ensures a volume name fits the storage system behind a storage driver.
+func checkVolumeNameLength(internalName, driverName string) error {
+	if maxLength := drivers.MaxVolumeNameLength(driverName); maxLength > 0 && len(internalName) > maxLength {
+		return fmt.Errorf("volume name %s is longer than the %d characters allowed by %s",
+			internalName, maxLength, driverName)
+	}
+	return nil
+}
+
 func (b *Backend) GetVolumeExternal(volumeName string) (*VolumeExternal, error) {
 
 	// Ensure backend is ready
-	if err := b.ensureOnline(); err != nil {
+	if err := b.ensureServing(); err != nil {
 		return nil, err
 	}
 
@@ -376,7 +596,7 @@ func (b *Backend) ImportVolume(volConfig *VolumeConfig, notManaged bool) (*Volum
 func (b *Backend) ResizeVolume(volName, newSize string) error {
 
 	// Ensure backend is ready
-	if err := b.ensureOnline(); err != nil {
+	if err := b.ensureServing(); err != nil {
 		return err
 	}
 
@@ -406,7 +626,7 @@ func (b *Backend) RemoveVolume(vol *Volume) error {
 	}).Debug("Backend#RemoveVolume")
 
 	// Ensure backend is ready
-	if err := b.ensureOnlineOrDeleting(); err != nil {
+	if err := b.ensureServingOrDeleting(); err != nil {
 		return err
 	}
 
@@ -435,7 +655,7 @@ func (b *Backend) GetSnapshot(snapConfig *SnapshotConfig) (*Snapshot, error) {
 	}).Debug("GetSnapshot.")
 
 	// Ensure backend is ready
-	if err := b.ensureOnline(); err != nil {
+	if err := b.ensureServing(); err != nil {
 		return nil, err
 	}
 
@@ -458,7 +678,7 @@ func (b *Backend) GetSnapshots(volConfig *VolumeConfig) ([]*Snapshot, error) {
 	}).Debug("GetSnapshots.")
 
 	// Ensure backend is ready
-	if err := b.ensureOnline(); err != nil {
+	if err := b.ensureServing(); err != nil {
 		return nil, err
 	}
 
@@ -474,7 +694,7 @@ func (b *Backend) CreateSnapshot(snapConfig *SnapshotConfig) (*Snapshot, error)
 	}).Debug("Attempting snapshot create.")
 
 	// Ensure backend is ready
-	if err := b.ensureOnline(); err != nil {
+	if err := b.ensureServing(); err != nil {
 		return nil, err
 	}
 
@@ -500,6 +720,17 @@ func (b *Backend) CreateSnapshot(snapConfig *SnapshotConfig) (*Snapshot, error)
 		return existingSnapshot, nil
 	}
 
+	// Reserve any space the snapshot asked for before taking it
+	if snapConfig.RequestsSnapshotReserve() {
+		reserver, ok := b.Driver.(SnapshotReserver)
+		if !ok {
+			return nil, fmt.Errorf("backend %s cannot reserve space for snapshots", b.Name)
+		}
+		if err := reserver.ReserveSnapshotSpace(snapConfig); err != nil {
+			return nil, fmt.Errorf("could not reserve space for snapshot %s; %v", snapConfig.Name, err)
+		}
+	}
+
 	// Create snapshot
 	return b.Driver.CreateSnapshot(snapConfig)
 }
@@ -513,7 +744,7 @@ func (b *Backend) RestoreSnapshot(snapConfig *SnapshotConfig) error {
 	}).Debug("Attempting snapshot restore.")
 
 	// Ensure backend is ready
-	if err := b.ensureOnline(); err != nil {
+	if err := b.ensureServing(); err != nil {
 		return err
 	}
 
@@ -530,7 +761,7 @@ func (b *Backend) DeleteSnapshot(snapConfig *SnapshotConfig) error {
 	}).Debug("Attempting snapshot delete.")
 
 	// Ensure backend is ready
-	if err := b.ensureOnlineOrDeleting(); err != nil {
+	if err := b.ensureServingOrDeleting(); err != nil {
 		return err
 	}
 
@@ -609,13 +840,26 @@ func (b *Backend) ensureOnline() error {
 	return nil
 }
 
-func (b *Backend) ensureOnlineOrDeleting() error {
-	if b.State != Online && b.State != Deleting {
+// ensureServing returns an error unless the backend may operate on the volumes it already holds.
+// An Offline backend accepts no new volumes but continues to serve its existing ones.
+func (b *Backend) ensureServing() error {
+	if b.State != Online && b.State != Offline {
 		log.WithFields(log.Fields{
 			"state":         b.State,
-			"expectedState": string(Online) + "/" + string(Deleting),
+			"expectedState": string(Online) + "/" + string(Offline),
 		}).Error("Invalid backend state.")
-		return fmt.Errorf("backend %s is not Online or Deleting", b.Name)
+		return fmt.Errorf("backend %s is not Online or Offline", b.Name)
+	}
+	return nil
+}
+
+func (b *Backend) ensureServingOrDeleting() error {
+	if b.State != Online && b.State != Offline && b.State != Deleting {
+		log.WithFields(log.Fields{
+			"state":         b.State,
+			"expectedState": string(Online) + "/" + string(Offline) + "/" + string(Deleting),
+		}).Error("Invalid backend state.")
+		return fmt.Errorf("backend %s is not Online, Offline, or Deleting", b.Name)
 	}
 	return nil
 }
@@ -631,12 +875,64 @@ type BackendExternal struct {
 	Volumes     []string               `json:"volumes"`
 }
 
+// RedactedValue replaces the value of each secret in a redacted backend config.
+const RedactedValue = "<REDACTED>"
+
+// RedactSecrets returns a copy of a backend config in which the values of any fields that look like
+// secrets, such as passwords, keys, and tokens, are replaced with RedactedValue.  Drivers omit their
+// known credentials from their external configs, but this catches any they miss.
+func RedactSecrets(config interface{}) interface{} {
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		log.Errorf("Could not redact backend config; %v", err)
+		return nil
+	}
+	var redacted interface{}
+	if err = json.Unmarshal(configJSON, &redacted); err != nil {
+		log.Errorf("Could not redact backend config; %v", err)
+		return nil
+	}
+	return redactSecretValues(redacted)
+}
+
+// redactSecretValues redacts the non-empty secrets in a decoded JSON value.
+func redactSecretValues(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if s, ok := field.(string); ok && s != "" && isSecretField(key) {
+				v[key] = RedactedValue
+			} else {
+				v[key] = redactSecretValues(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactSecretValues(item)
+		}
+	}
+	return value
+}
+
+// isSecretField reports whether a config field name looks like it holds a secret.
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range []string{"password", "secret", "token", "apikey", "privatekey"} {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConstructExternal returns the external form of a backend, with any secrets in its config redacted.
 func (b *Backend) ConstructExternal() *BackendExternal {
 	backendExternal := BackendExternal{
 		Name:        b.Name,
 		BackendUUID: b.BackendUUID,
 		Protocol:    b.GetProtocol(),
-		Config:      b.Driver.GetExternalConfig(),
+		Config:      RedactSecrets(b.Driver.GetExternalConfig()),
 		Storage:     make(map[string]interface{}),
 		Online:      b.Online,
 		State:       b.State,
@@ -652,6 +948,24 @@ func (b *Backend) ConstructExternal() *BackendExternal {
 	return &backendExternal
 }
 
+// ConstructExternalWithSecrets returns the external form of a backend with its full config, as
+// stored, including any credentials.  It should be used only when secrets were explicitly requested.
+func (b *Backend) ConstructExternalWithSecrets() (*BackendExternal, error) {
+
+	configJSON, err := b.ConstructPersistent().MarshalConfig()
+	if err != nil {
+		return nil, err
+	}
+	var config interface{}
+	if err = json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return nil, err
+	}
+
+	backendExternal := b.ConstructExternal()
+	backendExternal.Config = config
+	return backendExternal, nil
+}
+
 // Used to store the requisite info for a backend in etcd.  Other than
 // the configuration, all other data will be reconstructed during the bootstrap
 // phase
@@ -673,6 +987,17 @@ type BackendPersistent struct {
 	State       BackendState                   `json:"state"`
 }
 
+// MatchesLabels reports whether any of the backend's storage pools has labels satisfying a
+// label selector.
+func (b *BackendExternal) MatchesLabels(selector sa.Request) bool {
+	for _, storagePool := range b.Storage {
+		if pool, ok := storagePool.(*PoolExternal); ok && pool.MatchesLabels(selector) {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *Backend) ConstructPersistent() *BackendPersistent {
 	persistentBackend := &BackendPersistent{
 		Version:     tridentconfig.OrchestratorAPIVersion,
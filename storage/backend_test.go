@@ -3,7 +3,11 @@
 package storage
 
 import (
+	"strings"
 	"testing"
+
+	sa "github.com/netapp/trident/storage_attribute"
+	drivers "github.com/netapp/trident/storage_drivers"
 )
 
 func assertFalse(t *testing.T, errorMessage string, booleanCondition bool) {
@@ -99,3 +103,101 @@ func TestBackendState(t *testing.T) {
 		assertTrue(t, "Predicate failed", test.predicate(test.input))
 	}
 }
+
+func TestCheckVolumeNameLength(t *testing.T) {
+
+	tests := map[string]struct {
+		name       string
+		driverName string
+		valid      bool
+	}{
+		"Short name":             {"trident_team_a_pvc1", drivers.SolidfireSANStorageDriverName, true},
+		"Name at limit":          {strings.Repeat("a", 64), drivers.SolidfireSANStorageDriverName, true},
+		"Name over limit":        {strings.Repeat("a", 65), drivers.SolidfireSANStorageDriverName, false},
+		"Name over E-Series":     {strings.Repeat("a", 31), drivers.EseriesIscsiStorageDriverName, false},
+		"Name under ONTAP":       {strings.Repeat("a", 65), drivers.OntapNASStorageDriverName, true},
+		"Driver without a limit": {strings.Repeat("a", 500), drivers.FakeStorageDriverName, true},
+	}
+	for testName, test := range tests {
+		err := checkVolumeNameLength(test.name, test.driverName)
+		assertEqual(t, testName, err == nil, test.valid)
+	}
+}
+
+func TestBackendMatchesLabels(t *testing.T) {
+
+	backend := &BackendExternal{
+		Name: "backend1",
+		Storage: map[string]interface{}{
+			"gold": &PoolExternal{
+				Name: "gold",
+				Attributes: map[string]sa.Offer{
+					sa.Labels: sa.NewLabelOffer(map[string]string{"performance": "gold", "region": "east"}),
+				},
+			},
+			"silver": &PoolExternal{
+				Name: "silver",
+				Attributes: map[string]sa.Offer{
+					sa.Labels: sa.NewLabelOffer(map[string]string{"performance": "silver"}),
+				},
+			},
+		},
+	}
+	unlabeled := &PoolExternal{Name: "unlabeled"}
+
+	tests := []struct {
+		selector  string
+		backend   bool
+		unlabeled bool
+	}{
+		{"performance=gold", true, false},
+		{"performance=bronze", false, false},
+		{"performance!=gold", true, false},
+		{"performance!=gold;region", false, false},
+		{"region", true, false},
+		{"zone", false, false},
+		{"!region", true, true},
+		{"!performance", false, true},
+	}
+	for _, test := range tests {
+		selector, err := sa.NewLabelRequest(test.selector)
+		if err != nil {
+			t.Fatalf("Unable to parse selector %s: %v", test.selector, err)
+		}
+		assertEqual(t, "Backend match for "+test.selector, backend.MatchesLabels(selector), test.backend)
+		assertEqual(t, "Unlabeled pool match for "+test.selector, unlabeled.MatchesLabels(selector), test.unlabeled)
+	}
+}
+
+func TestGetSnapshotReserve(t *testing.T) {
+
+	tests := map[string]struct {
+		snapshotReserve string
+		requestedSize   string
+		volumeSize      uint64
+		reserve         int
+		valid           bool
+	}{
+		"Nothing requested":     {"", "", 1000, 0, true},
+		"Percentage only":       {"20", "", 1000, 20, true},
+		"Size only":             {"", "250", 1000, 25, true},
+		"Size rounds up":        {"", "251", 1000, 26, true},
+		"Larger of the two":     {"10", "300", 1000, 30, true},
+		"Percentage wins":       {"40", "300", 1000, 40, true},
+		"Size over the maximum": {"", "950", 1000, 0, false},
+		"Percentage too large":  {"91", "", 1000, 0, false},
+		"Invalid percentage":    {"ten", "", 1000, 0, false},
+		"Volume without a size": {"", "250", 0, 0, false},
+	}
+	for testName, test := range tests {
+		snapConfig := &SnapshotConfig{
+			Name:            "snap1",
+			VolumeName:      "vol1",
+			SnapshotReserve: test.snapshotReserve,
+			RequestedSize:   test.requestedSize,
+		}
+		reserve, err := snapConfig.GetSnapshotReserve(test.volumeSize)
+		assertEqual(t, testName, err == nil, test.valid)
+		assertEqual(t, testName, reserve, test.reserve)
+	}
+}
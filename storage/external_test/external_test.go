@@ -4,10 +4,12 @@ package externaltest
 
 import (
 	"encoding/json"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage"
 	"github.com/netapp/trident/storage/factory"
 	"github.com/netapp/trident/storage/fake"
 	sa "github.com/netapp/trident/storage_attribute"
@@ -51,3 +53,97 @@ func TestConstructExternalBackend(t *testing.T) {
 		t.Error("Found base64 encoding in JSON:  ", string(externalJSON))
 	}
 }
+
+func TestConstructExternalBackendSecrets(t *testing.T) {
+	configJSON, err := fakedriver.NewFakeStorageDriverConfigJSON(
+		"redaction-test",
+		config.File,
+		map[string]*fake.StoragePool{
+			"test-1": {
+				Attrs: map[string]sa.Offer{
+					sa.Media: sa.NewStringOffer(sa.HDD),
+				},
+			},
+		},
+		[]fake.Volume{},
+	)
+	if err != nil {
+		t.Fatal("Unable to construct config JSON.")
+	}
+	var configMap map[string]interface{}
+	if err = json.Unmarshal([]byte(configJSON), &configMap); err != nil {
+		t.Fatal("Unable to parse config JSON:  ", err)
+	}
+	configMap["username"] = "admin"
+	configMap["password"] = "pa55w0rd"
+	secretJSON, err := json.Marshal(configMap)
+	if err != nil {
+		t.Fatal("Unable to generate config JSON:  ", err)
+	}
+	fakeBackend, err := factory.NewStorageBackendForConfig(string(secretJSON))
+	if err != nil {
+		t.Fatal("Unable to construct backend:  ", err)
+	}
+
+	// Secrets are redacted by default
+	externalJSON, err := json.Marshal(fakeBackend.ConstructExternal())
+	if err != nil {
+		t.Fatal("Unable to marshal JSON:  ", err)
+	}
+	if strings.Contains(string(externalJSON), "pa55w0rd") {
+		t.Error("Found the password in the external backend:  ", string(externalJSON))
+	}
+	if !strings.Contains(string(externalJSON), storage.RedactedValue) ||
+		!strings.Contains(string(externalJSON), "admin") {
+		t.Error("Expected only the password to be redacted:  ", string(externalJSON))
+	}
+
+	// The full config is returned when secrets are requested
+	externalBackend, err := fakeBackend.ConstructExternalWithSecrets()
+	if err != nil {
+		t.Fatal("Unable to construct external backend with secrets:  ", err)
+	}
+	externalConfig, ok := externalBackend.Config.(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected a backend config:  ", externalBackend.Config)
+	}
+	if externalConfig["password"] != "pa55w0rd" {
+		t.Errorf("Expected the password with secrets, got %v", externalConfig["password"])
+	}
+
+	// The backend itself keeps its credentials
+	persistentJSON, err := fakeBackend.ConstructPersistent().MarshalConfig()
+	if err != nil {
+		t.Fatal("Unable to marshal config:  ", err)
+	}
+	if !strings.Contains(persistentJSON, "pa55w0rd") {
+		t.Error("Expected the stored config to keep the password:  ", persistentJSON)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	redacted := storage.RedactSecrets(map[string]interface{}{
+		"password":            "pw",
+		"chapInitiatorSecret": "chap",
+		"apiKey":              "key",
+		"empty":               "",
+		"emptyPassword":       "",
+		"nested": []interface{}{
+			map[string]interface{}{"clientPrivateKey": "pem", "name": "pool1"},
+		},
+	})
+
+	expected := map[string]interface{}{
+		"password":            storage.RedactedValue,
+		"chapInitiatorSecret": storage.RedactedValue,
+		"apiKey":              storage.RedactedValue,
+		"empty":               "",
+		"emptyPassword":       "",
+		"nested": []interface{}{
+			map[string]interface{}{"clientPrivateKey": storage.RedactedValue, "name": "pool1"},
+		},
+	}
+	if !reflect.DeepEqual(redacted, expected) {
+		t.Errorf("Expected %v, got %v", expected, redacted)
+	}
+}
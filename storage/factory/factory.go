@@ -97,6 +97,7 @@ func NewStorageBackendForConfig(configJSON string) (sb *storage.Backend, err err
 	}
 
 	sb.State = storage.Online
+	sb.LimitSnapshotsPerVolume = commonConfig.LimitSnapshotsPerVolume
 
 	return sb, err
 }
@@ -1,8 +1,10 @@
 package fake
 
 type Volume struct {
-	Name          string `json:"name"`
-	RequestedPool string `json:"requestedPool"`
-	PhysicalPool  string
-	SizeBytes     uint64 `json:"size"`
+	Name            string `json:"name"`
+	RequestedPool   string `json:"requestedPool"`
+	PhysicalPool    string
+	SizeBytes       uint64 `json:"size"`
+	UsedBytes       uint64 `json:"usedBytes,omitempty"`
+	SnapshotReserve int    `json:"snapshotReserve,omitempty"`
 }
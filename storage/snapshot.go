@@ -5,11 +5,15 @@ package storage
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 )
 
 const SnapshotTimestampFormat = "2006-01-02T15:04:05Z"
 const SnapshotNameFormat = "20060102T150405Z"
 
+// MaxSnapshotReserve is the largest percentage of a volume that may be reserved for its snapshots.
+const MaxSnapshotReserve = 90
+
 var snapshotIDRegex = regexp.MustCompile(`^(?P<volume>[^\s/]+)/(?P<snapshot>[^\s/]+)$`)
 
 type SnapshotConfig struct {
@@ -18,12 +22,58 @@ type SnapshotConfig struct {
 	InternalName       string `json:"internalName,omitempty"`
 	VolumeName         string `json:"volumeName,omitempty"`
 	VolumeInternalName string `json:"volumeInternalName,omitempty"`
+	ImportOriginalName string `json:"importOriginalName,omitempty"`
+	GroupName          string `json:"groupName,omitempty"`
+	CSIManaged         bool   `json:"csiManaged,omitempty"`
+	SnapshotReserve    string `json:"snapshotReserve,omitempty"`
+	RequestedSize      string `json:"requestedSize,omitempty"`
 }
 
 func (c *SnapshotConfig) ID() string {
 	return MakeSnapshotID(c.VolumeName, c.Name)
 }
 
+// RequestsSnapshotReserve returns whether the snapshot asks for space in its volume to be
+// reserved for snapshots.
+func (c *SnapshotConfig) RequestsSnapshotReserve() bool {
+	return c.SnapshotReserve != "" || c.RequestedSize != ""
+}
+
+// GetSnapshotReserve returns the percentage of a volume of the specified size that must be
+// reserved for snapshots to honor the snapshot's reserve percentage and requested size,
+// whichever needs more.
+func (c *SnapshotConfig) GetSnapshotReserve(volumeSizeBytes uint64) (int, error) {
+
+	reserve := 0
+	if c.SnapshotReserve != "" {
+		percent, err := strconv.Atoi(c.SnapshotReserve)
+		if err != nil {
+			return 0, fmt.Errorf("invalid snapshot reserve %s; %v", c.SnapshotReserve, err)
+		}
+		reserve = percent
+	}
+
+	if c.RequestedSize != "" {
+		sizeBytes, err := strconv.ParseUint(c.RequestedSize, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid snapshot size %s; %v", c.RequestedSize, err)
+		}
+		if volumeSizeBytes == 0 {
+			return 0, fmt.Errorf("volume %s has no size to reserve snapshot space in", c.VolumeName)
+		}
+		// Round up, so the reserve is never smaller than the requested size
+		if percent := int((sizeBytes*100 + volumeSizeBytes - 1) / volumeSizeBytes); percent > reserve {
+			reserve = percent
+		}
+	}
+
+	if reserve < 0 || reserve > MaxSnapshotReserve {
+		return 0, fmt.Errorf("snapshot %s needs %d%% of volume %s, but at most %d%% may be reserved",
+			c.Name, reserve, c.VolumeName, MaxSnapshotReserve)
+	}
+	return reserve, nil
+}
+
 func (c *SnapshotConfig) Validate() error {
 	if c.Name == "" || c.VolumeName == "" {
 		return fmt.Errorf("the following fields for \"Snapshot\" are mandatory: name and volumeName")
@@ -31,10 +81,38 @@ func (c *SnapshotConfig) Validate() error {
 	return nil
 }
 
+// SnapshotGroupConfig describes a set of volumes to be snapshotted together.  Each snapshot
+// in the group is named for the group.
+type SnapshotGroupConfig struct {
+	Name    string   `json:"name"`
+	Volumes []string `json:"volumes"`
+}
+
+func (c *SnapshotGroupConfig) Validate() error {
+	if c.Name == "" || len(c.Volumes) == 0 {
+		return fmt.Errorf("the following fields for \"SnapshotGroup\" are mandatory: name and volumes")
+	}
+	return nil
+}
+
 type Snapshot struct {
 	Config    *SnapshotConfig
-	Created   string `json:"dateCreated"` // The UTC time that the snapshot was created, in RFC3339 format
-	SizeBytes int64  `json:"size"`        // The size of the volume at the time the snapshot was created
+	Created   string        `json:"dateCreated"`     // The UTC time that the snapshot was created, in RFC3339 format
+	SizeBytes int64         `json:"size"`            // The size of the volume at the time the snapshot was created
+	State     SnapshotState `json:"state,omitempty"` // Whether the snapshot is ready to use
+}
+
+type SnapshotState string
+
+const (
+	SnapshotStateCreating = SnapshotState("creating")
+	SnapshotStateOnline   = SnapshotState("online")
+)
+
+// IsReady reports whether a snapshot may be used, e.g. as the source of a new volume.  Snapshots
+// recorded before their state was tracked have no state, so they are considered ready.
+func (s SnapshotState) IsReady() bool {
+	return s != SnapshotStateCreating
 }
 
 type SnapshotExternal struct {
@@ -54,6 +132,7 @@ func NewSnapshot(config *SnapshotConfig, created string, sizeBytes int64) *Snaps
 		Config:    config,
 		Created:   created,
 		SizeBytes: sizeBytes,
+		State:     SnapshotStateOnline,
 	}
 }
 
@@ -75,9 +154,15 @@ func (s *Snapshot) ConstructClone() *Snapshot {
 			InternalName:       s.Config.InternalName,
 			VolumeName:         s.Config.VolumeName,
 			VolumeInternalName: s.Config.VolumeInternalName,
+			ImportOriginalName: s.Config.ImportOriginalName,
+			CSIManaged:         s.Config.CSIManaged,
+			GroupName:          s.Config.GroupName,
+			SnapshotReserve:    s.Config.SnapshotReserve,
+			RequestedSize:      s.Config.RequestedSize,
 		},
 		Created:   s.Created,
 		SizeBytes: s.SizeBytes,
+		State:     s.State,
 	}
 }
 
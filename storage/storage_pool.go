@@ -65,3 +65,26 @@ func (pool *Pool) ConstructExternal() *PoolExternal {
 	sort.Strings(external.StorageClasses)
 	return external
 }
+
+// MatchesLabels reports whether the pool's labels satisfy a label selector.  A pool without
+// labels is treated as having an empty set of labels.
+func (pool *PoolExternal) MatchesLabels(selector sa.Request) bool {
+	offer, ok := pool.Attributes[sa.Labels]
+	if !ok {
+		offer = sa.NewLabelOffer()
+	}
+	return offer.Matches(selector)
+}
+
+// PoolCapacity describes the space in a storage pool.  A driver may return nil for a pool whose
+// capacity it can't determine, such as a virtual pool spanning several physical pools.
+type PoolCapacity struct {
+	TotalBytes     uint64 `json:"totalBytes"`
+	UsedBytes      uint64 `json:"usedBytes"`
+	AvailableBytes uint64 `json:"availableBytes"`
+}
+
+type PoolCapacityExternal struct {
+	PoolExternal
+	Capacity *PoolCapacity `json:"capacity,omitempty"`
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"encoding/gob"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/netapp/trident/config"
@@ -17,6 +18,7 @@ type VolumeConfig struct {
 	Version                   string                 `json:"version"`
 	Name                      string                 `json:"name"`
 	InternalName              string                 `json:"internalName"`
+	InternalNameBase          string                 `json:"internalNameBase,omitempty"`
 	Size                      string                 `json:"size"`
 	Protocol                  config.Protocol        `json:"protocol"`
 	SpaceReserve              string                 `json:"spaceReserve"`
@@ -40,6 +42,8 @@ type VolumeConfig struct {
 	QoSType                   string                 `json:"type,omitempty"`
 	ServiceLevel              string                 `json:"serviceLevel,omitempty"`
 	ImportOriginalName        string                 `json:"importOriginalName,omitempty"`
+	FSGroupChangePolicy       string                 `json:"fsGroupChangePolicy,omitempty"`
+	DeletionProtection        bool                   `json:"deletionProtection,omitempty"`
 }
 
 func (c *VolumeConfig) Validate() error {
@@ -66,17 +70,26 @@ func (c *VolumeConfig) ConstructClone() *VolumeConfig {
 }
 
 type Volume struct {
-	Config      *VolumeConfig
-	BackendUUID string // UUID of the storage backend
-	Pool        string // Name of the pool on which this volume was first provisioned
-	Orphaned    bool   // An Orphaned volume isn't currently tracked by the storage backend
-	State       VolumeState
+	Config         *VolumeConfig
+	BackendUUID    string // UUID of the storage backend
+	Pool           string // Name of the pool on which this volume was first provisioned
+	Orphaned       bool   // An Orphaned volume isn't currently tracked by the storage backend
+	State          VolumeState
+	PublishedNodes []string // Names of the nodes the volume is published to, sorted
 }
 
 type UpdateVolumeStateRequest struct {
 	State string `json:"state"`
 }
 
+type UpdateVolumeAccessModeRequest struct {
+	AccessMode config.AccessMode `json:"accessMode"`
+}
+
+type UpdateVolumeDeletionProtectionRequest struct {
+	DeletionProtection bool `json:"deletionProtection"`
+}
+
 type VolumeState string
 
 const (
@@ -125,12 +138,13 @@ func NewVolume(conf *VolumeConfig, backendUUID string, pool string, orphaned boo
 }
 
 type VolumeExternal struct {
-	Config      *VolumeConfig
-	Backend     string      `json:"backend"`     // replaced w/ backendUUID, remains to read old records
-	BackendUUID string      `json:"backendUUID"` // UUID of the storage backend
-	Pool        string      `json:"pool"`
-	Orphaned    bool        `json:"orphaned"`
-	State       VolumeState `json:"state"`
+	Config         *VolumeConfig
+	Backend        string      `json:"backend"`     // replaced w/ backendUUID, remains to read old records
+	BackendUUID    string      `json:"backendUUID"` // UUID of the storage backend
+	Pool           string      `json:"pool"`
+	Orphaned       bool        `json:"orphaned"`
+	State          VolumeState `json:"state"`
+	PublishedNodes []string    `json:"publishedNodes,omitempty"`
 }
 
 func (v *VolumeExternal) GetCHAPSecretName() string {
@@ -143,14 +157,36 @@ func (v *VolumeExternal) GetCHAPSecretName() string {
 
 func (v *Volume) ConstructExternal() *VolumeExternal {
 	return &VolumeExternal{
-		Config:      v.Config,
-		BackendUUID: v.BackendUUID,
-		Pool:        v.Pool,
-		Orphaned:    v.Orphaned,
-		State:       v.State,
+		Config:         v.Config,
+		BackendUUID:    v.BackendUUID,
+		Pool:           v.Pool,
+		Orphaned:       v.Orphaned,
+		State:          v.State,
+		PublishedNodes: append([]string(nil), v.PublishedNodes...),
 	}
 }
 
+// AddPublishedNode records that the volume is published to a node, returning whether it wasn't
+// already recorded.
+func (v *Volume) AddPublishedNode(nodeName string) bool {
+	if utils.SliceContainsString(v.PublishedNodes, nodeName) {
+		return false
+	}
+	v.PublishedNodes = append(v.PublishedNodes, nodeName)
+	sort.Strings(v.PublishedNodes)
+	return true
+}
+
+// RemovePublishedNode records that the volume is no longer published to a node, returning whether
+// it was recorded.
+func (v *Volume) RemovePublishedNode(nodeName string) bool {
+	if !utils.SliceContainsString(v.PublishedNodes, nodeName) {
+		return false
+	}
+	v.PublishedNodes = utils.RemoveStringFromSlice(v.PublishedNodes, nodeName)
+	return true
+}
+
 // VolumeExternalWrapper is used to return volumes and errors via channels between goroutines
 type VolumeExternalWrapper struct {
 	Volume *VolumeExternal
@@ -173,3 +209,48 @@ func (r *ImportVolumeRequest) Validate() error {
 	}
 	return nil
 }
+
+// VolumeUsage describes how much of a volume's space is in use.
+type VolumeUsage struct {
+	TotalBytes uint64 `json:"totalBytes"`
+	UsedBytes  uint64 `json:"usedBytes"`
+}
+
+// VolumePlacement describes where a volume would be created, as determined without creating it.
+type VolumePlacement struct {
+	Backend      string          `json:"backend"`
+	BackendUUID  string          `json:"backendUUID"`
+	Pool         string          `json:"pool"`
+	InternalName string          `json:"internalName"`
+	Protocol     config.Protocol `json:"protocol"`
+
+	// CapacityChecked is false if the backend's storage driver can't report how much space its
+	// pools have available, in which case the volume may not actually fit.
+	CapacityChecked bool `json:"capacityChecked"`
+}
+
+// MaxVolumeEvents is the number of recent events kept for each volume.
+const MaxVolumeEvents = 20
+
+// VolumeEvent records something that happened while provisioning or managing a volume.  Unlike
+// container orchestrator events, these are kept in Trident's persistent store.
+type VolumeEvent struct {
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+}
+
+// PrependVolumeEvent returns a list of events, newest first, with the specified event ahead of
+// the existing ones.  The oldest events are dropped so that no more than limit remain.
+func PrependVolumeEvent(events []*VolumeEvent, event *VolumeEvent, limit int) []*VolumeEvent {
+
+	ret := make([]*VolumeEvent, 0, len(events)+1)
+	ret = append(ret, event)
+	ret = append(ret, events...)
+
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret
+}
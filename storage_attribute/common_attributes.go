@@ -60,3 +60,9 @@ var attrTypes = map[string]Type{
 	TestingAttribute: boolType,
 	NonexistentBool:  boolType,
 }
+
+// IsStorageAttribute reports whether the supplied name is a known storage attribute.
+func IsStorageAttribute(name string) bool {
+	_, ok := attrTypes[name]
+	return ok
+}
@@ -18,6 +18,13 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 		RequiredStorage map[string][]string `json:"requiredStorage,omitempty"`
 		AdditionalPools map[string][]string `json:"additionalStoragePools,omitempty"`
 		ExcludePools    map[string][]string `json:"excludeStoragePools,omitempty"`
+
+		ReclaimPolicy        string `json:"reclaimPolicy,omitempty"`
+		AllowVolumeExpansion *bool  `json:"allowVolumeExpansion,omitempty"`
+
+		FileSystem string `json:"fileSystem,omitempty"`
+
+		SnapshotRetention *SnapshotRetention `json:"snapshotRetention,omitempty"`
 	}
 	err := json.Unmarshal(data, &tmp)
 	if err != nil {
@@ -36,6 +43,10 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	}
 
 	c.ExcludePools = tmp.ExcludePools
+	c.ReclaimPolicy = tmp.ReclaimPolicy
+	c.AllowVolumeExpansion = tmp.AllowVolumeExpansion
+	c.FileSystem = tmp.FileSystem
+	c.SnapshotRetention = tmp.SnapshotRetention
 
 	return err
 }
@@ -49,12 +60,23 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 		Pools           map[string][]string `json:"storagePools,omitempty"`
 		AdditionalPools map[string][]string `json:"additionalStoragePools,omitempty"`
 		ExcludePools    map[string][]string `json:"excludeStoragePools,omitempty"`
+
+		ReclaimPolicy        string `json:"reclaimPolicy,omitempty"`
+		AllowVolumeExpansion *bool  `json:"allowVolumeExpansion,omitempty"`
+
+		FileSystem string `json:"fileSystem,omitempty"`
+
+		SnapshotRetention *SnapshotRetention `json:"snapshotRetention,omitempty"`
 	}
 	tmp.Version = c.Version
 	tmp.Name = c.Name
 	tmp.Pools = c.Pools
 	tmp.AdditionalPools = c.AdditionalPools
 	tmp.ExcludePools = c.ExcludePools
+	tmp.ReclaimPolicy = c.ReclaimPolicy
+	tmp.AllowVolumeExpansion = c.AllowVolumeExpansion
+	tmp.FileSystem = c.FileSystem
+	tmp.SnapshotRetention = c.SnapshotRetention
 	attrs, err := storageattribute.MarshalRequestMap(c.Attributes)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,92 @@
+// Copyright 2019 NetApp, Inc. All Rights Reserved.
+
+package storageclass
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/netapp/trident/storage"
+)
+
+// Validate checks that a snapshot retention policy, if any, has a valid maximum age and count.
+func (r *SnapshotRetention) Validate() error {
+
+	if r == nil {
+		return nil
+	}
+	if _, err := r.getMaxAge(); err != nil {
+		return err
+	}
+	if r.MaxCount < 0 {
+		return fmt.Errorf("invalid snapshot retention max count %d; the count may not be negative", r.MaxCount)
+	}
+	return nil
+}
+
+// getMaxAge parses the maximum age of a snapshot retention policy, which is zero if not set.
+func (r *SnapshotRetention) getMaxAge() (time.Duration, error) {
+
+	if r.MaxAge == "" {
+		return 0, nil
+	}
+	maxAge, err := time.ParseDuration(r.MaxAge)
+	if err != nil {
+		return 0, fmt.Errorf("invalid snapshot retention max age %s; %v", r.MaxAge, err)
+	} else if maxAge < 0 {
+		return 0, fmt.Errorf("invalid snapshot retention max age %s; the age may not be negative", r.MaxAge)
+	}
+	return maxAge, nil
+}
+
+// SnapshotsToPrune returns the snapshots of a volume that exceed a snapshot retention policy as
+// of the specified time, oldest first.  Snapshots that are still being created or whose creation
+// time can't be parsed are never pruned, but the latter still count toward the maximum count.
+// Snapshots created through CSI back container orchestrator objects, so they are neither pruned
+// nor counted.
+func (r *SnapshotRetention) SnapshotsToPrune(snapshots []*storage.Snapshot, now time.Time) []*storage.Snapshot {
+
+	if r == nil {
+		return nil
+	}
+	maxAge, err := r.getMaxAge()
+	if err != nil {
+		return nil
+	}
+
+	type datedSnapshot struct {
+		snapshot *storage.Snapshot
+		created  time.Time
+		dated    bool
+	}
+
+	dated := make([]datedSnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.Config.CSIManaged {
+			continue
+		}
+		created, err := time.Parse(storage.SnapshotTimestampFormat, snapshot.Created)
+		dated = append(dated, datedSnapshot{snapshot: snapshot, created: created, dated: err == nil})
+	}
+
+	// Newest first, so the snapshots within the maximum count come first
+	sort.SliceStable(dated, func(i, j int) bool {
+		if !dated[i].created.Equal(dated[j].created) {
+			return dated[i].created.After(dated[j].created)
+		}
+		return dated[i].snapshot.Config.Name > dated[j].snapshot.Config.Name
+	})
+
+	prune := make([]*storage.Snapshot, 0)
+	for i := len(dated) - 1; i >= 0; i-- {
+		d := dated[i]
+		if !d.dated || d.snapshot.State == storage.SnapshotStateCreating {
+			continue
+		}
+		if (r.MaxCount > 0 && i >= r.MaxCount) || (maxAge > 0 && now.Sub(d.created) > maxAge) {
+			prune = append(prune, d.snapshot)
+		}
+	}
+	return prune
+}
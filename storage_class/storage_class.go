@@ -280,6 +280,10 @@ func (s *StorageClass) GetAdditionalStoragePools() map[string][]string {
 	return s.config.AdditionalPools
 }
 
+func (s *StorageClass) GetSnapshotRetention() *SnapshotRetention {
+	return s.config.SnapshotRetention
+}
+
 func (s *StorageClass) GetStoragePoolsForProtocol(p config.Protocol) []*storage.Pool {
 	ret := make([]*storage.Pool, 0, len(s.pools))
 	// TODO:  Change this to work with indices of backends?
@@ -21,6 +21,24 @@ type Config struct {
 	Pools           map[string][]string                 `json:"storagePools,omitempty"`
 	AdditionalPools map[string][]string                 `json:"additionalStoragePools,omitempty"`
 	ExcludePools    map[string][]string                 `json:"excludeStoragePools,omitempty"`
+
+	// ReclaimPolicy and AllowVolumeExpansion mirror the container orchestrator's storage class,
+	// if any.  A nil AllowVolumeExpansion means the orchestrator didn't declare a preference.
+	ReclaimPolicy        string `json:"reclaimPolicy,omitempty"`
+	AllowVolumeExpansion *bool  `json:"allowVolumeExpansion,omitempty"`
+
+	// FileSystem is the file system for volumes in the class whose requests don't specify one.
+	FileSystem string `json:"fileSystem,omitempty"`
+
+	// SnapshotRetention, if set, limits the snapshots kept of each volume in the class.
+	SnapshotRetention *SnapshotRetention `json:"snapshotRetention,omitempty"`
+}
+
+// SnapshotRetention limits the snapshots kept of a volume.  Snapshots older than MaxAge, or
+// beyond the MaxCount most recent, are pruned.  A zero value disables either limit.
+type SnapshotRetention struct {
+	MaxAge   string `json:"maxAge,omitempty"` // A duration, such as "720h"
+	MaxCount int    `json:"maxCount,omitempty"`
 }
 
 type External struct {
@@ -1011,6 +1011,7 @@ func (d *NFSStorageDriver) GetSnapshot(snapConfig *storage.SnapshotConfig) (*sto
 				Config:    snapConfig,
 				Created:   created,
 				SizeBytes: volume.QuotaInBytes,
+				State:     getSnapshotState(snapshot.LifeCycleState),
 			}, nil
 		}
 	}
@@ -1023,6 +1024,15 @@ func (d *NFSStorageDriver) GetSnapshot(snapConfig *storage.SnapshotConfig) (*sto
 	return nil, nil
 }
 
+// getSnapshotState returns the Trident state of a snapshot in the specified lifecycle state.  A snapshot
+// is only reported as being created until it becomes available.
+func getSnapshotState(lifeCycleState string) storage.SnapshotState {
+	if lifeCycleState == api.StateCreating {
+		return storage.SnapshotStateCreating
+	}
+	return storage.SnapshotStateOnline
+}
+
 // Return the list of snapshots associated with the specified volume
 func (d *NFSStorageDriver) GetSnapshots(volConfig *storage.VolumeConfig) ([]*storage.Snapshot, error) {
 
@@ -1120,16 +1130,23 @@ func (d *NFSStorageDriver) CreateSnapshot(snapConfig *storage.SnapshotConfig) (*
 		return nil, fmt.Errorf("could not create snapshot: %v", err)
 	}
 
-	// Wait for snapshot creation to complete
+	// Wait for snapshot creation to complete.  A snapshot that is still being created when the wait
+	// ends is reported as such, so that it is checked again before it is used.
+	state := storage.SnapshotStateOnline
 	err = d.API.WaitForSnapshotState(snapshot, api.StateAvailable, []string{api.StateError})
 	if err != nil {
-		return nil, err
+		if _, ok := err.(*api.TerminalStateError); ok {
+			return nil, err
+		}
+		log.WithField("snapshot", internalSnapName).Warningf("Snapshot is not yet available: %v", err)
+		state = storage.SnapshotStateCreating
 	}
 
 	return &storage.Snapshot{
 		Config:    snapConfig,
 		Created:   snapshot.Created.Format(storage.SnapshotTimestampFormat),
 		SizeBytes: sourceVolume.QuotaInBytes,
+		State:     state,
 	}, nil
 }
 
@@ -25,5 +25,22 @@ const (
 	FakeStorageDriverName              = "fake"
 )
 
+// maxVolumeNameLengths lists the longest volume names accepted by the storage systems behind
+// each storage driver.  Drivers not listed impose no limit of their own.
+var maxVolumeNameLengths = map[string]int{
+	EseriesIscsiStorageDriverName:      30,
+	OntapNASStorageDriverName:          203,
+	OntapNASFlexGroupStorageDriverName: 203,
+	OntapNASQtreeStorageDriverName:     64,
+	OntapSANStorageDriverName:          203,
+	SolidfireSANStorageDriverName:      64,
+}
+
+// MaxVolumeNameLength returns the longest volume name accepted by a storage driver, or 0 if
+// the driver has no limit.
+func MaxVolumeNameLength(driverName string) int {
+	return maxVolumeNameLengths[driverName]
+}
+
 const UnsetPool = ""
 const DefaultVolumeSize = "1G"
@@ -767,6 +767,59 @@ func (d *SANStorageDriver) Publish(name string, publishInfo *utils.VolumePublish
 	return nil
 }
 
+// RemoveNodeAccess unmaps this driver's volumes from the host that E-Series created for a node's
+// IQN, so that a decommissioned node can no longer reach them.  Volumes mapped to the host group
+// are shared with other nodes and are left alone, as is a node without a host on the array.
+func (d *SANStorageDriver) RemoveNodeAccess(node *utils.Node) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method": "RemoveNodeAccess",
+			"Type":   "SANStorageDriver",
+			"node":   node.Name,
+		}
+		log.WithFields(fields).Debug(">>>> RemoveNodeAccess")
+		defer log.WithFields(fields).Debug("<<<< RemoveNodeAccess")
+	}
+
+	if node.IQN == "" {
+		return nil
+	}
+
+	host, err := d.API.GetHostForIQN(node.IQN)
+	if err != nil {
+		return fmt.Errorf("could not get host for IQN %s: %v", node.IQN, err)
+	} else if host.HostRef == "" {
+		log.WithField("IQN", node.IQN).Debug("No host for IQN, nothing to unmap.")
+		return nil
+	}
+
+	volumes, err := d.API.GetVolumes()
+	if err != nil {
+		return fmt.Errorf("could not get volumes: %v", err)
+	}
+
+	prefix := *d.Config.StoragePrefix
+	for _, volume := range volumes {
+		if !strings.HasPrefix(volume.Label, prefix) || len(volume.Mappings) == 0 {
+			continue
+		}
+		mapping := volume.Mappings[0]
+		if mapping.Type != "host" || mapping.MapRef != host.HostRef {
+			continue
+		}
+		if err = d.API.UnmapVolume(volume); err != nil {
+			return fmt.Errorf("could not unmap volume %s from host %s: %v", volume.Label, host.Label, err)
+		}
+		log.WithFields(log.Fields{
+			"volume": volume.Label,
+			"host":   host.Label,
+		}).Debug("Unmapped volume from host.")
+	}
+
+	return nil
+}
+
 func (d *SANStorageDriver) getISCSITargetInfo() (iSCSINodeName string, iSCSIInterfaces []string, returnError error) {
 
 	targetSettings, err := d.API.GetTargetSettings()
@@ -61,6 +61,9 @@ type StorageDriver struct {
 	// different driver instances with the same config won't actually share
 	// state.
 	DestroyedSnapshots map[string]bool
+
+	// PublishedNodes records the nodes to which each volume has been published
+	PublishedNodes map[string]map[string]bool // map[volumeName]map[nodeName]true
 }
 
 func NewFakeStorageBackend(configJSON string) (sb *storage.Backend, err error) {
@@ -92,6 +95,7 @@ func NewFakeStorageDriver(config drivers.FakeStorageDriverConfig) *StorageDriver
 		DestroyedVolumes:   make(map[string]bool),
 		Snapshots:          make(map[string]map[string]*storage.Snapshot),
 		DestroyedSnapshots: make(map[string]bool),
+		PublishedNodes:     make(map[string]map[string]bool),
 	}
 	_ = driver.populateConfigurationDefaults(&config)
 	_ = driver.initializeStoragePools()
@@ -183,6 +187,7 @@ func (d *StorageDriver) Initialize(
 	d.Config.SerialNumbers = []string{d.Config.InstanceName + "_SN"}
 	d.Snapshots = make(map[string]map[string]*storage.Snapshot)
 	d.DestroyedSnapshots = make(map[string]bool)
+	d.PublishedNodes = make(map[string]map[string]bool)
 
 	s, _ := json.Marshal(d.Config)
 	log.Debugf("FakeStorageDriverConfig: %s", string(s))
@@ -615,6 +620,13 @@ func (d *StorageDriver) CreateClone(volConfig *storage.VolumeConfig) error {
 	return nil
 }
 
+// IsCloneIndependent returns true if a clone is split from its source volume, which the fake
+// driver models by the clone's splitOnClone value alone.
+func (d *StorageDriver) IsCloneIndependent(cloneConfig *storage.VolumeConfig) bool {
+	split, err := strconv.ParseBool(cloneConfig.SplitOnClone)
+	return err == nil && split
+}
+
 func (d *StorageDriver) Import(volumeConfig *storage.VolumeConfig, originalName string, notManaged bool) error {
 
 	log.WithFields(log.Fields{
@@ -684,8 +696,66 @@ func (d *StorageDriver) Destroy(name string) error {
 	return nil
 }
 
+// Publish records that a volume was published to a node, so that tests may check node access.
 func (d *StorageDriver) Publish(name string, publishInfo *utils.VolumePublishInfo) error {
-	return errors.New("fake driver does not support Publish")
+
+	if _, ok := d.Volumes[name]; !ok {
+		return fmt.Errorf("volume %s not found", name)
+	}
+	if publishInfo.HostName == "" {
+		return errors.New("fake driver requires a host name to publish a volume")
+	}
+
+	if d.PublishedNodes[name] == nil {
+		d.PublishedNodes[name] = make(map[string]bool)
+	}
+	d.PublishedNodes[name][publishInfo.HostName] = true
+	return nil
+}
+
+// RemoveNodeAccess forgets every publication of a volume to the specified node.
+func (d *StorageDriver) RemoveNodeAccess(node *utils.Node) error {
+	for _, nodes := range d.PublishedNodes {
+		delete(nodes, node.Name)
+	}
+	return nil
+}
+
+// GetVolumeUsage reports the size of a volume and the space used in it.
+func (d *StorageDriver) GetVolumeUsage(volConfig *storage.VolumeConfig) (*storage.VolumeUsage, error) {
+
+	volume, ok := d.Volumes[volConfig.InternalName]
+	if !ok {
+		return nil, fmt.Errorf("volume %s not found", volConfig.InternalName)
+	}
+
+	return &storage.VolumeUsage{
+		TotalBytes: volume.SizeBytes,
+		UsedBytes:  volume.UsedBytes,
+	}, nil
+}
+
+// GetPoolCapacity reports the configured size of a physical pool and the space left in it.
+// Virtual pools have no capacity of their own.
+func (d *StorageDriver) GetPoolCapacity(pool *storage.Pool) (*storage.PoolCapacity, error) {
+
+	configPool, ok := d.Config.Pools[pool.Name]
+	if !ok {
+		return nil, nil
+	}
+	fakePool, ok := d.fakePools[pool.Name]
+	if !ok {
+		return nil, fmt.Errorf("fake pool %s not found", pool.Name)
+	}
+
+	capacity := &storage.PoolCapacity{
+		TotalBytes:     configPool.Bytes,
+		AvailableBytes: fakePool.Bytes,
+	}
+	if capacity.TotalBytes > capacity.AvailableBytes {
+		capacity.UsedBytes = capacity.TotalBytes - capacity.AvailableBytes
+	}
+	return capacity, nil
 }
 
 // GetSnapshot gets a snapshot.  To distinguish between an API error reading the snapshot
@@ -797,6 +867,25 @@ func (d *StorageDriver) BootstrapSnapshot(snapshot *storage.Snapshot) {
 	}
 }
 
+// ReserveSnapshotSpace raises the snapshot reserve of the given snapshot's volume
+func (d *StorageDriver) ReserveSnapshotSpace(snapConfig *storage.SnapshotConfig) error {
+
+	volume, ok := d.Volumes[snapConfig.VolumeInternalName]
+	if !ok {
+		return fmt.Errorf("source volume %s not found", snapConfig.VolumeInternalName)
+	}
+
+	snapshotReserve, err := snapConfig.GetSnapshotReserve(volume.SizeBytes)
+	if err != nil {
+		return err
+	}
+	if snapshotReserve > volume.SnapshotReserve {
+		volume.SnapshotReserve = snapshotReserve
+		d.Volumes[snapConfig.VolumeInternalName] = volume
+	}
+	return nil
+}
+
 // RestoreSnapshot restores a volume (in place) from a snapshot.
 func (d *StorageDriver) RestoreSnapshot(snapConfig *storage.SnapshotConfig) error {
 
@@ -962,6 +1051,8 @@ func (d *StorageDriver) StoreConfig(b *storage.PersistentStorageBackendConfig) {
 		Protocol:                  d.Config.Protocol,
 		Pools:                     d.Config.Pools,
 		InstanceName:              d.Config.InstanceName,
+		Username:                  d.Config.Username,
+		Password:                  d.Config.Password,
 		Storage:                   cloneFakePools,
 		FakeStorageDriverPool:     cloneFakePool,
 	}
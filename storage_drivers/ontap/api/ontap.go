@@ -994,6 +994,25 @@ func (d Client) VolumeSetSize(name, newSize string) (*azgo.VolumeSizeResponse, e
 	return response, err
 }
 
+// VolumeSetSnapshotReserve sets the percentage of the specified volume reserved for snapshots
+func (d Client) VolumeSetSnapshotReserve(name string, percent int) (*azgo.VolumeModifyIterResponse, error) {
+	volattr := &azgo.VolumeModifyIterRequestAttributes{}
+	spaceattr := azgo.NewVolumeSpaceAttributesType().SetPercentageSnapshotReserve(percent)
+	volSpaceAttrs := azgo.NewVolumeAttributesType().SetVolumeSpaceAttributes(*spaceattr)
+	volattr.SetVolumeAttributes(*volSpaceAttrs)
+
+	queryattr := &azgo.VolumeModifyIterRequestQuery{}
+	volidattr := azgo.NewVolumeIdAttributesType().SetName(azgo.VolumeNameType(name))
+	volIdAttrs := azgo.NewVolumeAttributesType().SetVolumeIdAttributes(*volidattr)
+	queryattr.SetVolumeAttributes(*volIdAttrs)
+
+	response, err := azgo.NewVolumeModifyIterRequest().
+		SetQuery(*queryattr).
+		SetAttributes(*volattr).
+		ExecuteUsing(d.zr)
+	return response, err
+}
+
 // VolumeMount mounts a volume at the specified junction
 func (d Client) VolumeMount(name, junctionPath string) (*azgo.VolumeMountResponse, error) {
 	response, err := azgo.NewVolumeMountRequest().
@@ -1801,6 +1820,39 @@ func (d Client) VserverShowAggrGetIterRequest() (*azgo.VserverShowAggrGetIterRes
 	return response, err
 }
 
+// VserverGetAggregateSpace returns the size of an aggregate assigned to the vserver and the space in it
+// available to the vserver.  Requires ONTAP 9 or later.  An aggregate's size is only visible to
+// cluster-scoped users, so it is NumericalValueNotSet for vserver-scoped users.
+func (d Client) VserverGetAggregateSpace(aggregateName string) (size, available int, err error) {
+
+	response, err := d.VserverShowAggrGetIterRequest()
+	if err = GetError(response, err); err != nil {
+		return NumericalValueNotSet, NumericalValueNotSet, fmt.Errorf(
+			"error getting space for aggregate %v: %v", aggregateName, err)
+	}
+
+	available = NumericalValueNotSet
+	if response.Result.AttributesListPtr != nil {
+		for _, aggr := range response.Result.AttributesListPtr.ShowAggregatesPtr {
+			if string(aggr.AggregateName()) == aggregateName {
+				available = aggr.AvailableSize()
+				break
+			}
+		}
+	}
+	if available == NumericalValueNotSet {
+		return NumericalValueNotSet, NumericalValueNotSet, fmt.Errorf(
+			"aggregate %v is not assigned to SVM %s", aggregateName, d.config.SVM)
+	}
+
+	if size, err = d.getAggregateSize(aggregateName); err != nil {
+		log.WithField("aggregate", aggregateName).Debugf("Could not get aggregate size; %v", err)
+		size = NumericalValueNotSet
+	}
+
+	return size, available, nil
+}
+
 // VSERVER operations END
 /////////////////////////////////////////////////////////////////////////////
 
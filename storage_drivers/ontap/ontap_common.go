@@ -697,6 +697,17 @@ func probeForVolume(name string, client *api.Client) error {
 	}
 }
 
+// IsOntapCloneSplit returns true if a clone is split from its source volume, as requested by the
+// clone or, failing that, by the backend's default.
+func IsOntapCloneSplit(cloneConfig *storage.VolumeConfig, config *drivers.OntapStorageDriverConfig) bool {
+	splitOnClone := cloneConfig.SplitOnClone
+	if splitOnClone == "" {
+		splitOnClone = config.SplitOnClone
+	}
+	split, err := strconv.ParseBool(splitOnClone)
+	return err == nil && split
+}
+
 // Create a volume clone
 func CreateOntapClone(
 	name, source, snapshot string, split bool, config *drivers.OntapStorageDriverConfig, client *api.Client,
@@ -947,6 +958,80 @@ func CreateSnapshot(
 	return nil, fmt.Errorf("could not find snapshot %s for souce volume %s", internalSnapName, internalVolName)
 }
 
+// GetPoolCapacity reports the space in the aggregate behind a storage pool.  The aggregate's size is
+// only visible to cluster-scoped users, so vserver-scoped users see just the space available to
+// the SVM, with the total and used space reported as zero.
+func GetPoolCapacity(
+	pool *storage.Pool, config *drivers.OntapStorageDriverConfig, client *api.Client,
+) (*storage.PoolCapacity, error) {
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "GetPoolCapacity", "Type": "ontap_common", "pool": pool.Name}
+		log.WithFields(fields).Debug(">>>> GetPoolCapacity")
+		defer log.WithFields(fields).Debug("<<<< GetPoolCapacity")
+	}
+
+	size, available, err := client.VserverGetAggregateSpace(pool.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := &storage.PoolCapacity{AvailableBytes: uint64(available)}
+	if size != api.NumericalValueNotSet && size >= available {
+		capacity.TotalBytes = uint64(size)
+		capacity.UsedBytes = uint64(size - available)
+	}
+	return capacity, nil
+}
+
+// ReserveSnapshotSpace raises the snapshot reserve of a snapshot's source volume so that it covers
+// the snapshot's reserve percentage and requested size.  The reserve is never lowered, since other
+// snapshots may depend on it.
+func ReserveSnapshotSpace(
+	snapConfig *storage.SnapshotConfig, config *drivers.OntapStorageDriverConfig, client *api.Client,
+) error {
+
+	internalVolName := snapConfig.VolumeInternalName
+
+	if config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method":       "ReserveSnapshotSpace",
+			"Type":         "ontap_common",
+			"snapshotName": snapConfig.InternalName,
+			"volumeName":   internalVolName,
+		}
+		log.WithFields(fields).Debug(">>>> ReserveSnapshotSpace")
+		defer log.WithFields(fields).Debug("<<<< ReserveSnapshotSpace")
+	}
+
+	volAttrs, err := client.VolumeGet(internalVolName)
+	if err != nil {
+		return fmt.Errorf("could not get volume %s; %v", internalVolName, err)
+	}
+	volSpaceAttrs := volAttrs.VolumeSpaceAttributes()
+
+	snapshotReserve, err := snapConfig.GetSnapshotReserve(uint64(volSpaceAttrs.Size()))
+	if err != nil {
+		return err
+	}
+	if snapshotReserve <= volSpaceAttrs.PercentageSnapshotReserve() {
+		return nil
+	}
+
+	modifyResponse, err := client.VolumeSetSnapshotReserve(internalVolName, snapshotReserve)
+	if err = api.GetError(modifyResponse, err); err != nil {
+		return fmt.Errorf("could not set snapshot reserve of volume %s to %d%%; %v",
+			internalVolName, snapshotReserve, err)
+	}
+
+	log.WithFields(log.Fields{
+		"volume":          internalVolName,
+		"snapshotReserve": snapshotReserve,
+	}).Debug("Raised volume snapshot reserve.")
+
+	return nil
+}
+
 // Restore a volume (in place) from a snapshot.
 func RestoreSnapshot(
 	snapConfig *storage.SnapshotConfig, config *drivers.OntapStorageDriverConfig, client *api.Client,
@@ -1468,7 +1553,8 @@ func createPrepareCommon(d storage.Driver, volConfig *storage.VolumeConfig) erro
 
 	volConfig.InternalName = d.GetInternalVolumeName(volConfig.Name)
 
-	if volConfig.CloneSourceVolume != "" {
+	// The orchestrator supplies the source's internal name, which need not derive from its name
+	if volConfig.CloneSourceVolume != "" && volConfig.CloneSourceVolumeInternal == "" {
 		volConfig.CloneSourceVolumeInternal =
 			d.GetInternalVolumeName(volConfig.CloneSourceVolume)
 	}
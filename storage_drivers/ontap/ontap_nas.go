@@ -282,6 +282,12 @@ func (d *NASStorageDriver) CreateClone(volConfig *storage.VolumeConfig) error {
 	return CreateOntapClone(name, source, snapshot, split, &d.Config, d.API)
 }
 
+// IsCloneIndependent returns true if a clone is split from its source volume, after which it no
+// longer depends on the snapshot it was cloned from.
+func (d *NASStorageDriver) IsCloneIndependent(cloneConfig *storage.VolumeConfig) bool {
+	return IsOntapCloneSplit(cloneConfig, &d.Config)
+}
+
 // Destroy the volume
 func (d *NASStorageDriver) Destroy(name string) error {
 
@@ -478,6 +484,16 @@ func (d *NASStorageDriver) CreateSnapshot(snapConfig *storage.SnapshotConfig) (*
 	return CreateSnapshot(snapConfig, &d.Config, d.API, d.API.VolumeSize)
 }
 
+// GetPoolCapacity reports the space in the aggregate behind a storage pool
+func (d *NASStorageDriver) GetPoolCapacity(pool *storage.Pool) (*storage.PoolCapacity, error) {
+	return GetPoolCapacity(pool, &d.Config, d.API)
+}
+
+// ReserveSnapshotSpace reserves space in a volume for the given snapshot
+func (d *NASStorageDriver) ReserveSnapshotSpace(snapConfig *storage.SnapshotConfig) error {
+	return ReserveSnapshotSpace(snapConfig, &d.Config, d.API)
+}
+
 // RestoreSnapshot restores a volume (in place) from a snapshot.
 func (d *NASStorageDriver) RestoreSnapshot(snapConfig *storage.SnapshotConfig) error {
 
@@ -524,6 +540,27 @@ func (d *NASStorageDriver) Get(name string) error {
 	return GetVolume(name, d.API, &d.Config)
 }
 
+// GetVolumeUsage reports the size of a volume and the space used in it.
+func (d *NASStorageDriver) GetVolumeUsage(volConfig *storage.VolumeConfig) (*storage.VolumeUsage, error) {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "GetVolumeUsage", "Type": "NASStorageDriver", "name": volConfig.InternalName}
+		log.WithFields(fields).Debug(">>>> GetVolumeUsage")
+		defer log.WithFields(fields).Debug("<<<< GetVolumeUsage")
+	}
+
+	volAttrs, err := d.API.VolumeGet(volConfig.InternalName)
+	if err != nil {
+		return nil, fmt.Errorf("could not get volume %s; %v", volConfig.InternalName, err)
+	}
+	volSpaceAttrs := volAttrs.VolumeSpaceAttributes()
+
+	return &storage.VolumeUsage{
+		TotalBytes: uint64(volSpaceAttrs.Size()),
+		UsedBytes:  uint64(volSpaceAttrs.SizeUsed()),
+	}, nil
+}
+
 // Retrieve storage backend capabilities
 func (d *NASStorageDriver) GetStorageBackendSpecs(backend *storage.Backend) error {
 	if d.Config.BackendName == "" {
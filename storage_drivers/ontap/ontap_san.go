@@ -363,6 +363,12 @@ func (d *SANStorageDriver) CreateClone(volConfig *storage.VolumeConfig) error {
 	return CreateOntapClone(name, source, snapshot, split, &d.Config, d.API)
 }
 
+// IsCloneIndependent returns true if a clone is split from its source volume, after which it no
+// longer depends on the snapshot it was cloned from.
+func (d *SANStorageDriver) IsCloneIndependent(cloneConfig *storage.VolumeConfig) bool {
+	return IsOntapCloneSplit(cloneConfig, &d.Config)
+}
+
 func (d *SANStorageDriver) Import(volConfig *storage.VolumeConfig, originalName string, notManaged bool) error {
 	return errors.New("import is not implemented")
 }
@@ -538,6 +544,41 @@ func (d *SANStorageDriver) Publish(name string, publishInfo *utils.VolumePublish
 	return nil
 }
 
+// RemoveNodeAccess removes a node's IQN from the igroup shared by this driver's LUNs, so that a
+// decommissioned node can no longer reach them.  A node not in the igroup is left alone.
+func (d *SANStorageDriver) RemoveNodeAccess(node *utils.Node) error {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{
+			"Method": "RemoveNodeAccess",
+			"Type":   "SANStorageDriver",
+			"node":   node.Name,
+		}
+		log.WithFields(fields).Debug(">>>> RemoveNodeAccess")
+		defer log.WithFields(fields).Debug("<<<< RemoveNodeAccess")
+	}
+
+	if node.IQN == "" {
+		return nil
+	}
+
+	igroupName := d.Config.IgroupName
+	igroupRemoveResponse, err := d.API.IgroupRemove(igroupName, node.IQN, false)
+	err = api.GetError(igroupRemoveResponse, err)
+	zerr, zerrOK := err.(api.ZapiError)
+	logFields := log.Fields{"IQN": node.IQN, "igroup": igroupName}
+	if err == nil {
+		log.WithFields(logFields).Debug("Removed host IQN from igroup.")
+	} else if zerrOK && (zerr.Code() == azgo.EVDISK_ERROR_NODE_NOT_IN_INITGROUP ||
+		zerr.Code() == azgo.EVDISK_ERROR_NO_SUCH_INITGROUP) {
+		log.WithFields(logFields).Debug("Host IQN not in igroup.")
+	} else {
+		return fmt.Errorf("error removing IQN %v from igroup %v: %v", node.IQN, igroupName, err)
+	}
+
+	return nil
+}
+
 func (d *SANStorageDriver) getISCSITargetInfo() (iSCSINodeName string, iSCSIInterfaces []string, returnError error) {
 
 	// Get the SVM iSCSI IQN
@@ -625,6 +666,16 @@ func (d *SANStorageDriver) CreateSnapshot(snapConfig *storage.SnapshotConfig) (*
 	return CreateSnapshot(snapConfig, &d.Config, d.API, d.API.VolumeSize)
 }
 
+// GetPoolCapacity reports the space in the aggregate behind a storage pool
+func (d *SANStorageDriver) GetPoolCapacity(pool *storage.Pool) (*storage.PoolCapacity, error) {
+	return GetPoolCapacity(pool, &d.Config, d.API)
+}
+
+// ReserveSnapshotSpace reserves space in a volume for the given snapshot
+func (d *SANStorageDriver) ReserveSnapshotSpace(snapConfig *storage.SnapshotConfig) error {
+	return ReserveSnapshotSpace(snapConfig, &d.Config, d.API)
+}
+
 // RestoreSnapshot restores a volume (in place) from a snapshot.
 func (d *SANStorageDriver) RestoreSnapshot(snapConfig *storage.SnapshotConfig) error {
 
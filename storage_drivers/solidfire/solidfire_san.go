@@ -971,6 +971,12 @@ func (d *SANStorageDriver) CreateClone(volConfig *storage.VolumeConfig) error {
 	return nil
 }
 
+// IsCloneIndependent returns true, since SolidFire clones are full copies that don't depend on the
+// snapshots they were cloned from.
+func (d *SANStorageDriver) IsCloneIndependent(cloneConfig *storage.VolumeConfig) bool {
+	return true
+}
+
 func (d *SANStorageDriver) Import(volConfig *storage.VolumeConfig, originalName string, notManaged bool) error {
 
 	if d.Config.DebugTraceFlags["method"] {
@@ -1371,6 +1377,31 @@ func (d *SANStorageDriver) Get(name string) error {
 	return nil
 }
 
+// GetPoolCapacity reports the provisioned space of the cluster, which all of the driver's pools share,
+// since they differ only in QoS.
+func (d *SANStorageDriver) GetPoolCapacity(pool *storage.Pool) (*storage.PoolCapacity, error) {
+
+	if d.Config.DebugTraceFlags["method"] {
+		fields := log.Fields{"Method": "GetPoolCapacity", "Type": "SANStorageDriver", "pool": pool.Name}
+		log.WithFields(fields).Debug(">>>> GetPoolCapacity")
+		defer log.WithFields(fields).Debug("<<<< GetPoolCapacity")
+	}
+
+	clusterCapacity, err := d.Client.GetClusterCapacity()
+	if err != nil {
+		return nil, fmt.Errorf("could not get cluster capacity; %v", err)
+	}
+
+	capacity := &storage.PoolCapacity{
+		TotalBytes: uint64(clusterCapacity.MaxProvisionedSpace),
+		UsedBytes:  uint64(clusterCapacity.ProvisionedSpace),
+	}
+	if capacity.TotalBytes > capacity.UsedBytes {
+		capacity.AvailableBytes = capacity.TotalBytes - capacity.UsedBytes
+	}
+	return capacity, nil
+}
+
 // getVolumes returns all volumes for the configured tenant.  The
 // keys are the volume names as reported to Docker.
 func (d *SANStorageDriver) getVolumes() (map[string]api.Volume, error) {
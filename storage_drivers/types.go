@@ -31,6 +31,8 @@ type CommonStorageDriverConfig struct {
 	SerialNumbers     []string              `json:"serialNumbers,omitEmpty"`
 	DriverContext     trident.DriverContext `json:"-"`
 	LimitVolumeSize   string                `json:"limitVolumeSize"`
+	// LimitSnapshotsPerVolume is the most snapshots Trident will create for one volume; 0 means unlimited
+	LimitSnapshotsPerVolume int `json:"limitSnapshotsPerVolume"`
 }
 
 type CommonStorageDriverConfigDefaults struct {
@@ -173,6 +175,9 @@ type FakeStorageDriverConfig struct {
 	// Volumes are the modeled backend volumes that exist when the driver starts.  Optional.
 	Volumes      []fake.Volume `json:"volumes"`
 	InstanceName string        `json:"instanceName"`
+	// Username and Password model a backend's credentials.  Optional.
+	Username string `json:"username"`
+	Password string `json:"password"`
 	FakeStorageDriverPool
 	Storage []FakeStorageDriverPool `json:"storage"`
 }
@@ -259,6 +264,11 @@ func ValidateCommonSettings(configJSON string) (*CommonStorageDriverConfig, erro
 		}
 	}
 
+	// Validate snapshot count limit (if set)
+	if config.LimitSnapshotsPerVolume < 0 {
+		return nil, fmt.Errorf("invalid value for limitSnapshotsPerVolume: %d", config.LimitSnapshotsPerVolume)
+	}
+
 	log.Debugf("Parsed commonConfig: %+v", *config)
 
 	return config, nil
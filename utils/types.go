@@ -27,19 +27,21 @@ type NfsAccessInfo struct {
 }
 
 type VolumePublishInfo struct {
-	Localhost      bool     `json:"localhost,omitempty"`
-	HostIQN        []string `json:"hostIQN,omitempty"`
-	HostIP         []string `json:"hostIP,omitempty"`
-	HostName       string   `json:"hostName,omitempty"`
-	FilesystemType string   `json:"fstype,omitempty"`
-	UseCHAP        bool     `json:"useCHAP,omitempty"`
-	SharedTarget   bool     `json:"sharedTarget,omitempty"`
-	DevicePath     string   `json:"devicePath,omitempty"`
+	Localhost           bool     `json:"localhost,omitempty"`
+	HostIQN             []string `json:"hostIQN,omitempty"`
+	HostIP              []string `json:"hostIP,omitempty"`
+	HostName            string   `json:"hostName,omitempty"`
+	FilesystemType      string   `json:"fstype,omitempty"`
+	UseCHAP             bool     `json:"useCHAP,omitempty"`
+	SharedTarget        bool     `json:"sharedTarget,omitempty"`
+	DevicePath          string   `json:"devicePath,omitempty"`
+	FSGroupChangePolicy string   `json:"fsGroupChangePolicy,omitempty"`
 	VolumeAccessInfo
 }
 
 type Node struct {
-	Name string   `json:"name"`
-	IQN  string   `json:"iqn,omitempty"`
-	IPs  []string `json:"ips,omitempty"`
+	Name           string            `json:"name"`
+	IQN            string            `json:"iqn,omitempty"`
+	IPs            []string          `json:"ips,omitempty"`
+	TopologyLabels map[string]string `json:"topologyLabels,omitempty"`
 }